@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Entry is a single cached response
+type Entry struct {
+	ETag   string      `json:"etag"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// Store is used to store cached responses. Each entry is stored with a set of tags, and all entries with a given tag
+// can be invalidated at once. Every invalidation increments the generation of the invalidated tags, so a response computed
+// while one of its tags was invalidated, possibly on another instance sharing the store, is never stored
+type Store interface {
+	Get(key string) (*Entry, bool, error)
+	// Generation will return the current generation of the given tags
+	Generation(tags []string) (uint64, error)
+	// Set will store the given entry with the given tags, unless any of the tags has been invalidated since the given generation
+	Set(key string, entry *Entry, tags []string, generation uint64) error
+	Invalidate(tags ...string) error
+}
+
+// Tags used to invalidate cached responses
+const (
+	TagStatistics = "statistics"
+	TagBadges     = "badges"
+	// TagAll is added to all cached responses
+	TagAll = "all"
+)
+
+var store Store
+
+// SetStore will set the store used for caching responses. Caching is disabled when store is nil
+func SetStore(s Store) {
+	store = s
+}
+
+// Tag will return the tag of a single entity, e.g. "player:1"
+func Tag(entity string, id int) string {
+	return entity + ":" + strconv.Itoa(id)
+}
+
+// PlayerTag will return the tag used for responses containing data of the given player
+func PlayerTag(id int) string {
+	return Tag("player", id)
+}
+
+// TournamentTag will return the tag used for responses containing data of the given tournament
+func TournamentTag(id int) string {
+	return Tag("tournament", id)
+}
+
+// Invalidate will remove all cached responses with any of the given tags
+func Invalidate(tags ...string) {
+	if store == nil || len(tags) == 0 {
+		return
+	}
+	err := store.Invalidate(tags...)
+	if err != nil {
+		log.Printf("Unable to invalidate cache for %s: %s", strings.Join(tags, ", "), err)
+	}
+}
+
+// InvalidateAll will remove all cached responses, used after changes affecting too many entities to tag
+func InvalidateAll() {
+	Invalidate(TagAll)
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Handler will cache successful responses of the wrapped handler, and answer conditional requests using ETags
+type Handler struct {
+	next http.HandlerFunc
+	tags []string
+}
+
+// NewHandler will wrap the given handler, tagging cached responses with the given tags. Tags may reference route
+// variables, e.g. "player:{id}"
+func NewHandler(next http.HandlerFunc, tags ...string) *Handler {
+	return &Handler{next: next, tags: tags}
+}
+
+// Unwrap will return the wrapped handler
+func (h *Handler) Unwrap() http.Handler {
+	return h.next
+}
+
+// ServeHTTP will serve the response from the cache if possible, otherwise the response is computed and cached
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if store == nil || r.Method != http.MethodGet {
+		h.next(w, r)
+		return
+	}
+
+	key := r.URL.RequestURI()
+	entry, ok, err := store.Get(key)
+	if err != nil {
+		log.Printf("Unable to read %s from cache: %s", key, err)
+	}
+	if !ok {
+		tags := h.resolveTags(r)
+		gen, err := store.Generation(tags)
+		if err != nil {
+			log.Printf("Unable to read cache generation: %s", err)
+			h.next(w, r)
+			return
+		}
+		buffer := &bufferedWriter{header: make(http.Header), status: http.StatusOK}
+		h.next(buffer, r)
+		if buffer.status != http.StatusOK {
+			buffer.writeTo(w)
+			return
+		}
+		sum := sha1.Sum(buffer.body.Bytes())
+		entry = &Entry{ETag: `"` + hex.EncodeToString(sum[:]) + `"`, Header: buffer.header, Body: buffer.body.Bytes()}
+		if err := store.Set(key, entry, tags, gen); err != nil {
+			log.Printf("Unable to write %s to cache: %s", key, err)
+		}
+	}
+
+	for name, values := range entry.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("ETag", entry.ETag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if matchesETag(r.Header.Get("If-None-Match"), entry.ETag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(entry.Body)
+}
+
+// resolveTags will replace route variables in the tags with the values of the current request, and add TagAll
+func (h *Handler) resolveTags(r *http.Request) []string {
+	vars := mux.Vars(r)
+	tags := make([]string, len(h.tags), len(h.tags)+1)
+	for i, tag := range h.tags {
+		for name, value := range vars {
+			tag = strings.ReplaceAll(tag, "{"+name+"}", value)
+		}
+		tags[i] = tag
+	}
+	return append(tags, TagAll)
+}
+
+// matchesETag will check if the If-None-Match header matches the given ETag
+func matchesETag(header string, etag string) bool {
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimSpace(value)
+		if value == "*" || strings.TrimPrefix(value, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedWriter holds a response in memory, so it can be cached before being written
+type bufferedWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedWriter) WriteHeader(status int) {
+	b.status = status
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+// writeTo will write the buffered response to the given writer
+func (b *bufferedWriter) writeTo(w http.ResponseWriter) {
+	for name, values := range b.header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(b.status)
+	w.Write(b.body.Bytes())
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRouter will return a router serving the number of times the handler has been called for /player/{id}
+func newTestRouter(calls *int, status int) *mux.Router {
+	router := mux.NewRouter()
+	router.Handle("/player/{id}", NewHandler(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"calls": %d}`, *calls)
+	}, TagStatistics, "player:{id}")).Methods("GET", "POST")
+	return router
+}
+
+func serve(router *mux.Router, method string, path string, etag string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	if etag != "" {
+		r.Header.Set("If-None-Match", etag)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+// TestHandler will check that responses are cached until invalidated, and that conditional requests are answered
+func TestHandler(t *testing.T) {
+	SetStore(NewMemoryStore(time.Minute, 0))
+	defer SetStore(nil)
+	calls := 0
+	router := newTestRouter(&calls, http.StatusOK)
+
+	w := serve(router, "GET", "/player/1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"calls": 1}`, w.Body.String())
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	w = serve(router, "GET", "/player/1", "")
+	assert.Equal(t, `{"calls": 1}`, w.Body.String(), "should serve response from cache")
+	w = serve(router, "GET", "/player/1", etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	Invalidate(PlayerTag(2))
+	w = serve(router, "GET", "/player/1", "")
+	assert.Equal(t, `{"calls": 1}`, w.Body.String(), "should keep responses of other players")
+
+	Invalidate(PlayerTag(1))
+	w = serve(router, "GET", "/player/1", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"calls": 2}`, w.Body.String(), "should compute response after invalidation")
+
+	InvalidateAll()
+	w = serve(router, "GET", "/player/1", "")
+	assert.Equal(t, `{"calls": 3}`, w.Body.String())
+}
+
+// TestHandler_Uncached will check that errors and other methods are never cached
+func TestHandler_Uncached(t *testing.T) {
+	SetStore(NewMemoryStore(time.Minute, 0))
+	defer SetStore(nil)
+	calls := 0
+	router := newTestRouter(&calls, http.StatusInternalServerError)
+
+	w := serve(router, "GET", "/player/1", "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+	serve(router, "GET", "/player/1", "")
+	assert.Equal(t, 2, calls, "should not cache errors")
+
+	calls = 0
+	router = newTestRouter(&calls, http.StatusOK)
+	serve(router, "POST", "/player/1", "")
+	serve(router, "POST", "/player/1", "")
+	assert.Equal(t, 2, calls, "should not cache other methods than GET")
+}
+
+// TestResolveTags will check that route variables are replaced in tags, and that all entries are tagged with TagAll
+func TestResolveTags(t *testing.T) {
+	h := NewHandler(nil, TagBadges, "player:{id}", "tournament:{tournament_id}")
+	r := mux.SetURLVars(httptest.NewRequest("GET", "/", nil), map[string]string{"id": "1", "tournament_id": "2"})
+	assert.Equal(t, []string{TagBadges, "player:1", "tournament:2", TagAll}, h.resolveTags(r))
+}
+
+// TestMatchesETag will check the If-None-Match header is matched against the ETag
+func TestMatchesETag(t *testing.T) {
+	assert.True(t, matchesETag(`"a"`, `"a"`))
+	assert.True(t, matchesETag(`"b", W/"a"`, `"a"`))
+	assert.True(t, matchesETag(`*`, `"a"`))
+	assert.False(t, matchesETag(`"b"`, `"a"`))
+	assert.False(t, matchesETag(``, `"a"`))
+}
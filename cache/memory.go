@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	entry   *Entry
+	tags    []string
+	expires time.Time
+}
+
+// MemoryStore stores cached responses in process memory
+type MemoryStore struct {
+	mu          sync.Mutex
+	ttl         time.Duration
+	maxEntries  int
+	entries     map[string]*memoryEntry
+	tags        map[string]map[string]bool
+	generations map[string]uint64
+}
+
+// NewMemoryStore will create a new in-process store. Entries expire after the given TTL, and once the store holds
+// maxEntries entries, the entry closest to expiring is evicted
+func NewMemoryStore(ttl time.Duration, maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		ttl:         ttl,
+		maxEntries:  maxEntries,
+		entries:     make(map[string]*memoryEntry),
+		tags:        make(map[string]map[string]bool),
+		generations: make(map[string]uint64),
+	}
+}
+
+// Get will return the entry with the given key, if any
+func (s *MemoryStore) Get(key string) (*Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(e.expires) {
+		s.remove(key)
+		return nil, false, nil
+	}
+	return e.entry, true, nil
+}
+
+// Generation will return the current generation of the given tags
+func (s *MemoryStore) Generation(tags []string) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generation(tags), nil
+}
+
+// Set will store the given entry with the given tags, unless any of the tags has been invalidated since the given generation
+func (s *MemoryStore) Set(key string, entry *Entry, tags []string, generation uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if generation != s.generation(tags) {
+		return nil
+	}
+	if _, ok := s.entries[key]; !ok && s.maxEntries > 0 && len(s.entries) >= s.maxEntries {
+		s.evict()
+	}
+	s.remove(key)
+	s.entries[key] = &memoryEntry{entry: entry, tags: tags, expires: time.Now().Add(s.ttl)}
+	for _, tag := range tags {
+		if s.tags[tag] == nil {
+			s.tags[tag] = make(map[string]bool)
+		}
+		s.tags[tag][key] = true
+	}
+	return nil
+}
+
+// Invalidate will remove all entries with any of the given tags
+func (s *MemoryStore) Invalidate(tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tag := range tags {
+		s.generations[tag]++
+		for key := range s.tags[tag] {
+			s.remove(key)
+		}
+	}
+	return nil
+}
+
+// generation will return the sum of the generations of the given tags, which changes whenever any of them is
+// invalidated as generations only increase. Must be called with the lock held
+func (s *MemoryStore) generation(tags []string) uint64 {
+	var generation uint64
+	for _, tag := range tags {
+		generation += s.generations[tag]
+	}
+	return generation
+}
+
+// evict will remove the entry closest to expiring. Must be called with the lock held
+func (s *MemoryStore) evict() {
+	var oldest string
+	var expires time.Time
+	for key, e := range s.entries {
+		if oldest == "" || e.expires.Before(expires) {
+			oldest = key
+			expires = e.expires
+		}
+	}
+	s.remove(oldest)
+}
+
+// remove will remove the entry with the given key. Must be called with the lock held
+func (s *MemoryStore) remove(key string) {
+	e, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	for _, tag := range e.tags {
+		delete(s.tags[tag], key)
+		if len(s.tags[tag]) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+	delete(s.entries, key)
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemoryStore will check that entries are stored, expired and invalidated by tag
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore(time.Minute, 0)
+	gen, err := s.Generation([]string{"player:1", TagAll})
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Set("/a", &Entry{ETag: "a"}, []string{"player:1", TagAll}, gen))
+	assert.NoError(t, s.Set("/b", &Entry{ETag: "b"}, []string{"player:2", TagAll}, gen))
+	entry, ok, err := s.Get("/a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "a", entry.ETag)
+
+	assert.NoError(t, s.Invalidate("player:1"))
+	_, ok, _ = s.Get("/a")
+	assert.False(t, ok, "should remove entries with the tag")
+	_, ok, _ = s.Get("/b")
+	assert.True(t, ok, "should keep entries without the tag")
+	assert.NotContains(t, s.tags, "player:1")
+
+	assert.NoError(t, s.Invalidate(TagAll))
+	assert.Empty(t, s.entries)
+	assert.Empty(t, s.tags)
+}
+
+// TestMemoryStore_Generation will check that entries computed before one of their tags was invalidated are not stored,
+// while invalidating other tags does not prevent storing them
+func TestMemoryStore_Generation(t *testing.T) {
+	s := NewMemoryStore(time.Minute, 0)
+	tags := []string{"player:1", TagAll}
+	gen, _ := s.Generation(tags)
+	assert.NoError(t, s.Invalidate("player:1"))
+
+	assert.NoError(t, s.Set("/a", &Entry{}, tags, gen))
+	_, ok, _ := s.Get("/a")
+	assert.False(t, ok, "should not store entry from previous generation")
+
+	gen, _ = s.Generation(tags)
+	assert.NoError(t, s.Invalidate("player:2", TagStatistics))
+	assert.NoError(t, s.Set("/a", &Entry{}, tags, gen))
+	_, ok, _ = s.Get("/a")
+	assert.True(t, ok, "should store entry when other tags were invalidated")
+
+	gen, _ = s.Generation(tags)
+	assert.NoError(t, s.Invalidate(TagAll))
+	assert.NoError(t, s.Set("/b", &Entry{}, tags, gen))
+	_, ok, _ = s.Get("/b")
+	assert.False(t, ok, "should not store entry after all entries were invalidated")
+}
+
+// TestMemoryStore_Expiry will check that expired entries are not returned, and that the entry closest to expiring
+// is evicted when the store is full
+func TestMemoryStore_Expiry(t *testing.T) {
+	s := NewMemoryStore(-time.Second, 0)
+	assert.NoError(t, s.Set("/a", &Entry{}, []string{"player:1"}, 0))
+	_, ok, _ := s.Get("/a")
+	assert.False(t, ok, "should not return expired entry")
+	assert.Empty(t, s.tags)
+
+	s = NewMemoryStore(time.Minute, 2)
+	assert.NoError(t, s.Set("/a", &Entry{}, nil, 0))
+	assert.NoError(t, s.Set("/b", &Entry{}, nil, 0))
+	assert.NoError(t, s.Set("/b", &Entry{}, nil, 0))
+	assert.Len(t, s.entries, 2, "should not evict when replacing an entry")
+	assert.NoError(t, s.Set("/c", &Entry{}, nil, 0))
+	assert.Len(t, s.entries, 2)
+	_, ok, _ = s.Get("/a")
+	assert.False(t, ok, "should evict the oldest entry")
+}
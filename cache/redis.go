@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore stores cached responses in Redis, or anything speaking the Redis protocol, so the cache can be shared
+// between multiple instances of the API. Tags are stored as sets holding the keys of all entries with the tag
+type RedisStore struct {
+	client *redis.Client
+	ttl    time.Duration
+	prefix string
+}
+
+// NewRedisStore will create a new store using the Redis server at the given address
+func NewRedisStore(address string, ttl time.Duration) *RedisStore {
+	return &RedisStore{
+		client: redis.NewClient(&redis.Options{Addr: address}),
+		ttl:    ttl,
+		prefix: "kcapp:cache:",
+	}
+}
+
+// Get will return the entry with the given key, if any
+func (s *RedisStore) Get(key string) (*Entry, bool, error) {
+	b, err := s.client.Get(context.Background(), s.prefix+"entry:"+key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	entry := new(Entry)
+	if err := json.Unmarshal(b, entry); err != nil {
+		return nil, false, err
+	}
+	return entry, true, nil
+}
+
+// Generation will return the current generation of the given tags, shared by all instances
+func (s *RedisStore) Generation(tags []string) (uint64, error) {
+	return s.generation(context.Background(), s.client, tags)
+}
+
+// Set will store the given entry with the given tags, unless any of the tags has been invalidated since the given
+// generation. The generations are watched, so an invalidation by another instance while storing the entry aborts the transaction
+func (s *RedisStore) Set(key string, entry *Entry, tags []string, generation uint64) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := s.generation(ctx, tx, tags)
+		if err != nil {
+			return err
+		}
+		if current != generation {
+			return nil
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, s.prefix+"entry:"+key, b, s.ttl)
+			for _, tag := range tags {
+				pipe.SAdd(ctx, s.prefix+"tag:"+tag, key)
+				pipe.Expire(ctx, s.prefix+"tag:"+tag, s.ttl)
+			}
+			return nil
+		})
+		return err
+	}, s.generationKeys(tags)...)
+	if err == redis.TxFailedErr {
+		return nil
+	}
+	return err
+}
+
+// Invalidate will remove all entries with any of the given tags
+func (s *RedisStore) Invalidate(tags ...string) error {
+	ctx := context.Background()
+	for _, tag := range tags {
+		if err := s.client.Incr(ctx, s.prefix+"generation:"+tag).Err(); err != nil {
+			return err
+		}
+		keys, err := s.client.SMembers(ctx, s.prefix+"tag:"+tag).Result()
+		if err != nil {
+			return err
+		}
+		remove := []string{s.prefix + "tag:" + tag}
+		for _, key := range keys {
+			remove = append(remove, s.prefix+"entry:"+key)
+		}
+		if err := s.client.Del(ctx, remove...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generation will return the sum of the generations of the given tags, which changes whenever any of them is
+// invalidated as generations only increase
+func (s *RedisStore) generation(ctx context.Context, client redis.Cmdable, tags []string) (uint64, error) {
+	if len(tags) == 0 {
+		return 0, nil
+	}
+	values, err := client.MGet(ctx, s.generationKeys(tags)...).Result()
+	if err != nil {
+		return 0, err
+	}
+	var generation uint64
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		g, err := strconv.ParseUint(value.(string), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		generation += g
+	}
+	return generation, nil
+}
+
+// generationKeys will return the keys holding the generations of the given tags
+func (s *RedisStore) generationKeys(tags []string) []string {
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = s.prefix + "generation:" + tag
+	}
+	return keys
+}
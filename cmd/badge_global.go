@@ -10,10 +10,12 @@ var recalculateGlobalBadgeCmd = &cobra.Command{
 	Use:   "global",
 	Short: "Recalculate Global Badges",
 	Run: func(cmd *cobra.Command, args []string) {
-		err := data.RecalculateGlobalBadges()
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		diff, err := data.RecalculateGlobalBadges(dryRun)
 		if err != nil {
 			panic(err)
 		}
+		printBadgeDiff(diff)
 	},
 }
 
@@ -1,19 +1,40 @@
 package cmd
 
 import (
+	"context"
+	"strconv"
+
 	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
 	"github.com/spf13/cobra"
 )
 
 // recalculateLegBadgesCmd represents the leg command
 var recalculateLegBadgesCmd = &cobra.Command{
-	Use:   "leg",
+	Use:   "leg [id]",
 	Short: "Recalculate Leg Badges",
+	Long:  `Recalculate badges unlocked in all legs, or only in the given leg`,
+	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		err := data.RecalculateLegBadges()
-		if err != nil {
-			panic(err)
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		var diff *models.BadgeDiff
+		var err error
+		if len(args) > 0 {
+			legID, err := strconv.Atoi(args[0])
+			if err != nil {
+				panic(err)
+			}
+			diff, err = data.RecalculateBadgesForLeg(context.Background(), legID, nil, dryRun)
+			if err != nil {
+				panic(err)
+			}
+		} else {
+			diff, err = data.RecalculateLegBadges(dryRun)
+			if err != nil {
+				panic(err)
+			}
 		}
+		printBadgeDiff(diff)
 	},
 }
 
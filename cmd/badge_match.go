@@ -10,10 +10,12 @@ var recalculateMatchBadgeCmd = &cobra.Command{
 	Use:   "match",
 	Short: "Recalculate Match Badges",
 	Run: func(cmd *cobra.Command, args []string) {
-		err := data.RecalculateMatchBadges()
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		diff, err := data.RecalculateMatchBadges(dryRun)
 		if err != nil {
 			panic(err)
 		}
+		printBadgeDiff(diff)
 	},
 }
 
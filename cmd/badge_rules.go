@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+	"github.com/spf13/cobra"
+)
+
+// badgeRulesCmd represents the badge rules command
+var badgeRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Manage badge rules",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		models.InitDB(models.GetMysqlConnectionString())
+	},
+}
+
+// badgeRulesImportCmd represents the badge rules import command
+var badgeRulesImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import badge rules from a YAML or JSON file",
+	Long: `Import badge rules from a YAML or JSON file.
+	Rules which already exist are updated, built-in badges cannot be overridden`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := importBadgeRules(args[0])
+		if err != nil {
+			panic(err)
+		}
+	},
+}
+
+// importBadgeRules will create or update all badge rules defined in the given file
+func importBadgeRules(file string) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	rules, err := models.ParseBadgeRules(b)
+	if err != nil {
+		return err
+	}
+	return data.ImportBadgeRules(rules)
+}
+
+func init() {
+	badgeCmd.AddCommand(badgeRulesCmd)
+	badgeRulesCmd.AddCommand(badgeRulesImportCmd)
+}
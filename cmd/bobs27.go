@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+	"github.com/spf13/cobra"
+)
+
+// bobs27Cmd represents the bobs27 command
+var bobs27Cmd = &cobra.Command{
+	Use:   "bobs27",
+	Short: "Recalculate Bob's 27 statistics",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := data.RecalculateStatistics(models.BOBS27, legID, since, dryRun)
+		if err != nil {
+			panic(err)
+		}
+	},
+}
+
+func init() {
+	recalculateStatisticsCmd.AddCommand(bobs27Cmd)
+}
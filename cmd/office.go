@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// officeCmd represents the office command
+var officeCmd = &cobra.Command{
+	Use:   "office",
+	Short: "Modify Offices",
+}
+
+func init() {
+	rootCmd.AddCommand(officeCmd)
+}
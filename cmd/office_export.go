@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+	"github.com/spf13/cobra"
+)
+
+// exportOfficeCmd represents the export command
+var exportOfficeCmd = &cobra.Command{
+	Use:   "export <office_id>",
+	Short: "Export an office",
+	Long: `Export all data of an office to a versioned JSON file.
+
+	The export contains players, venues, match presets, tournaments, matches with all legs
+	and visits, badges and Elo, and can be imported into another instance using "office import"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		models.InitDB(models.GetMysqlConnectionString())
+
+		officeID, err := strconv.Atoi(args[0])
+		if err != nil {
+			panic(err)
+		}
+		output, _ := cmd.Flags().GetString("output")
+
+		export, err := data.ExportOffice(officeID)
+		if err != nil {
+			panic(err)
+		}
+		b, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		if output == "" {
+			os.Stdout.Write(b)
+			return
+		}
+		err = os.WriteFile(output, b, 0644)
+		if err != nil {
+			panic(err)
+		}
+		log.Printf("Wrote export of office %d to %s", officeID, output)
+	},
+}
+
+func init() {
+	officeCmd.AddCommand(exportOfficeCmd)
+	exportOfficeCmd.Flags().StringP("output", "o", "", "Write the export to the given file instead of stdout")
+}
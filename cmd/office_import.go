@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+	"github.com/spf13/cobra"
+)
+
+// importOfficeCmd represents the import command
+var importOfficeCmd = &cobra.Command{
+	Use:   "import <filename>",
+	Short: "Import an office",
+	Long: `Import an office from a file created by "office export".
+
+	All IDs are remapped. Existing players are matched by smartcard, and then by name within the office,
+	while venues, presets and tournaments are matched by name. Match modes, owe types and badges
+	must already exist with the same name, or the import fails. Matches which have already
+	been imported are skipped. The import runs in a single transaction`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		models.InitDB(models.GetMysqlConnectionString())
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		output, _ := cmd.Flags().GetString("output")
+
+		b, err := os.ReadFile(args[0])
+		if err != nil {
+			panic(err)
+		}
+		export := new(models.OfficeExport)
+		err = json.Unmarshal(b, export)
+		if err != nil {
+			panic(err)
+		}
+
+		report, err := data.ImportOffice(export, dryRun)
+		if err != nil {
+			panic(err)
+		}
+
+		log.Printf("Import of office %s (version %d) into office %d", export.Office.Name, report.Version, report.OfficeID)
+		w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
+		fmt.Fprintln(w, "Entity\tCreated\tMatched")
+		entities := make(map[string]bool)
+		for entity := range report.Created {
+			entities[entity] = true
+		}
+		for entity := range report.Matched {
+			entities[entity] = true
+		}
+		names := make([]string, 0, len(entities))
+		for entity := range entities {
+			names = append(names, entity)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "%s\t%d\t%d\n", name, report.Created[name], report.Matched[name])
+		}
+		w.Flush()
+
+		if output != "" {
+			b, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			err = os.WriteFile(output, b, 0644)
+			if err != nil {
+				panic(err)
+			}
+			log.Printf("Wrote import report to %s", output)
+		}
+	},
+}
+
+func init() {
+	officeCmd.AddCommand(importOfficeCmd)
+	importOfficeCmd.Flags().Bool("dry-run", true, "Report changes without executing them")
+	importOfficeCmd.Flags().StringP("output", "o", "", "Write the import report as JSON to the given file")
+}
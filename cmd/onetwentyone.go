@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+	"github.com/spf13/cobra"
+)
+
+// onetwentyoneCmd represents the 121 command
+var onetwentyoneCmd = &cobra.Command{
+	Use:   "121",
+	Short: "Recalculate 121 statistics",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := data.RecalculateStatistics(models.ONETWENTYONE, legID, since, dryRun)
+		if err != nil {
+			panic(err)
+		}
+	},
+}
+
+func init() {
+	recalculateStatisticsCmd.AddCommand(onetwentyoneCmd)
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/kcapp/api/models"
+	"github.com/spf13/cobra"
+)
+
+// oweCmd represents the owe command
+var oweCmd = &cobra.Command{
+	Use:   "owe",
+	Short: "Modify owes",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		models.InitDB(models.GetMysqlConnectionString())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(oweCmd)
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/kcapp/api/data"
+	"github.com/spf13/cobra"
+)
+
+// oweBackfillCmd represents the owe backfill command
+var oweBackfillCmd = &cobra.Command{
+	Use:   "backfill",
+	Short: "Add current owes as opening balances to an empty owes ledger",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := data.BackfillOweLedger()
+		if err != nil {
+			panic(err)
+		}
+	},
+}
+
+func init() {
+	oweCmd.AddCommand(oweBackfillCmd)
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// playerCmd represents the player command
+var playerCmd = &cobra.Command{
+	Use:   "player",
+	Short: "Modify Players",
+}
+
+func init() {
+	rootCmd.AddCommand(playerCmd)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+	"github.com/spf13/cobra"
+)
+
+// mergePlayerCmd represents the merge command
+var mergePlayerCmd = &cobra.Command{
+	Use:   "merge <player_id> <duplicate_id>",
+	Short: "Merge two players",
+	Long: `Merge a duplicate player into the given player.
+
+	All scores, legs, matches, Elo, badges, owes, tournaments and match presets of the
+	duplicate are moved to the given player, and the duplicate is deactivated.
+	Statistics and Elo for the affected matches are recalculated afterwards`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		models.InitDB(models.GetMysqlConnectionString())
+
+		playerID, err := strconv.Atoi(args[0])
+		if err != nil {
+			panic(err)
+		}
+		duplicateID, err := strconv.Atoi(args[1])
+		if err != nil {
+			panic(err)
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		output, _ := cmd.Flags().GetString("output")
+
+		report, err := data.MergePlayers(playerID, duplicateID, dryRun)
+		if err != nil {
+			panic(err)
+		}
+
+		log.Printf("Merge of player %d into %d affects %d matches and %d legs", duplicateID, playerID, len(report.Matches), len(report.Legs))
+		w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
+		fmt.Fprintln(w, "Table\tRows")
+		tables := make([]string, 0, len(report.UpdatedRows))
+		for table := range report.UpdatedRows {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+		for _, table := range tables {
+			fmt.Fprintf(w, "%s\t%d\n", table, report.UpdatedRows[table])
+		}
+		w.Flush()
+		for _, failure := range report.RecalculationErrors {
+			log.Printf("Unable to recalculate %s, run the recalculate commands to retry", failure)
+		}
+
+		if output != "" {
+			b, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			err = os.WriteFile(output, b, 0644)
+			if err != nil {
+				panic(err)
+			}
+			log.Printf("Wrote merge report to %s", output)
+		}
+	},
+}
+
+func init() {
+	playerCmd.AddCommand(mergePlayerCmd)
+	mergePlayerCmd.Flags().Bool("dry-run", true, "Report changes without executing them")
+	mergePlayerCmd.Flags().StringP("output", "o", "", "Write the merge report as JSON to the given file")
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+	"github.com/spf13/cobra"
+)
+
+// playerRecordsCmd represents the player records command
+var playerRecordsCmd = &cobra.Command{
+	Use:   "records",
+	Short: "Modify personal records",
+}
+
+// recalculatePlayerRecordsCmd represents the player records recalculate command
+var recalculatePlayerRecordsCmd = &cobra.Command{
+	Use:   "recalculate",
+	Short: "Recalculate personal records",
+	Long: `Recalculate personal records of all players.
+
+	This will remove all records, and replay every finished leg in the order they were played,
+	storing the history of records set by each player`,
+	Run: func(cmd *cobra.Command, args []string) {
+		models.InitDB(models.GetMysqlConnectionString())
+		err := data.RecalculatePlayerRecords()
+		if err != nil {
+			panic(err)
+		}
+	},
+}
+
+func init() {
+	playerCmd.AddCommand(playerRecordsCmd)
+	playerRecordsCmd.AddCommand(recalculatePlayerRecordsCmd)
+}
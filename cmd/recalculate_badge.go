@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
 	"github.com/kcapp/api/models"
 	"github.com/spf13/cobra"
 )
@@ -9,12 +13,33 @@ import (
 var recalculateBadgeCmd = &cobra.Command{
 	Use:   "recalculate",
 	Short: "Recalculate badge",
-	Long:  `Recalculate badges earned by each player`,
+	Long: `Recalculate badges earned by each player.
+	Badges which are missing are added, badges which no longer apply are revoked, and wrong levels and times are updated`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		models.InitDB(models.GetMysqlConnectionString())
 	},
 }
 
+// printBadgeDiff will print the given badge differences
+func printBadgeDiff(diff *models.BadgeDiff) {
+	if diff.IsEmpty() {
+		fmt.Println("All badges are up to date")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
+	for _, award := range diff.Added {
+		fmt.Fprintf(w, "+\t%s\n", award)
+	}
+	for _, award := range diff.Removed {
+		fmt.Fprintf(w, "-\t%s\n", award)
+	}
+	for _, change := range diff.Changed {
+		fmt.Fprintf(w, "~\t%s\n\t-> %s\n", change.From, change.To)
+	}
+	w.Flush()
+}
+
 func init() {
 	badgeCmd.AddCommand(recalculateBadgeCmd)
+	recalculateBadgeCmd.PersistentFlags().Bool("dry-run", false, "Print the differences without changing any badges")
 }
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/kcapp/api/data"
+	"github.com/spf13/cobra"
+)
+
+// rollupCmd represents the rollup command
+var rollupCmd = &cobra.Command{
+	Use:   "rollup",
+	Short: "Recalculate global and office statistics rollups",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := data.RecalculateStatisticsRollups(since, dryRun)
+		if err != nil {
+			panic(err)
+		}
+	},
+}
+
+func init() {
+	recalculateStatisticsCmd.AddCommand(rollupCmd)
+}
@@ -1,15 +1,27 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gorilla/mux"
+	"github.com/kcapp/api/cache"
 	"github.com/kcapp/api/controllers"
 	controllers_v2 "github.com/kcapp/api/controllers/v2"
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/idempotency"
+	"github.com/kcapp/api/logging"
+	"github.com/kcapp/api/metrics"
 	"github.com/kcapp/api/models"
+	"github.com/kcapp/api/openapi"
+	"github.com/kcapp/api/ratelimit"
+	"github.com/kcapp/api/report"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -20,19 +32,65 @@ var serveCmd = &cobra.Command{
 	Short: "Start the API",
 	Run: func(cmd *cobra.Command, args []string) {
 		models.InitDB(models.GetMysqlConnectionString())
+		models.DB.SetMaxOpenConns(viper.GetInt("db.max_open_connections"))
+		logging.Init(os.Stderr)
+		metrics.Register(models.DB)
+		data.UpdateActiveMetrics()
+		switch viper.GetString("cache.store") {
+		case "memory":
+			cache.SetStore(cache.NewMemoryStore(viper.GetDuration("cache.ttl"), viper.GetInt("cache.max_entries")))
+		case "redis":
+			cache.SetStore(cache.NewRedisStore(viper.GetString("cache.redis_address"), viper.GetDuration("cache.ttl")))
+		case "none":
+		default:
+			log.Fatalf("Unknown cache store '%s'", viper.GetString("cache.store"))
+		}
+
+		idempotency.SetTTL(viper.GetDuration("idempotency.ttl"))
+		if file := viper.GetString("badges.rules_file"); file != "" {
+			if err := importBadgeRules(file); err != nil {
+				log.Fatalf("Unable to load badge rules from '%s': %s", file, err)
+			}
+		}
+
+		switch viper.GetString("reports.notifier.type") {
+		case "log":
+			report.SetNotifier(&report.LogNotifier{Format: viper.GetString("reports.notifier.format")})
+		case "webhook":
+			report.SetNotifier(report.NewWebhookNotifier(viper.GetString("reports.notifier.url"), viper.GetString("reports.notifier.format")))
+		case "none":
+		default:
+			log.Fatalf("Unknown report notifier '%s'", viper.GetString("reports.notifier.type"))
+		}
+		var schedules []*models.ReportSchedule
+		if err := viper.UnmarshalKey("reports.schedules", &schedules); err != nil {
+			log.Fatalf("Unable to read report schedules: %s", err)
+		}
+		if err := report.Validate(schedules); err != nil {
+			log.Fatalf("Unable to read report schedules: %s", err)
+		}
 
 		router := mux.NewRouter()
+		router.Use(logging.Middleware, metrics.Middleware)
+		if rate := viper.GetFloat64("ratelimit.rate"); rate > 0 {
+			limiter := ratelimit.NewLimiter(rate, viper.GetInt("ratelimit.burst"), viper.GetBool("ratelimit.trust_forwarded_for"))
+			limiter.Exempt(viper.GetStringSlice("ratelimit.exempt")...)
+			router.Use(limiter.Middleware)
+		}
 		router.Methods("OPTIONS").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Access-Control-Request-Headers, Access-Control-Request-Method, Connection, Host, Origin, User-Agent, Referer, Cache-Control, X-header")
+			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Access-Control-Request-Headers, Access-Control-Request-Method, Connection, Host, Origin, User-Agent, Referer, Cache-Control, X-header, Idempotency-Key")
 			w.WriteHeader(http.StatusNoContent)
 			return
 		})
 
 		router.HandleFunc("/health", controllers.Healthcheck).Methods("HEAD")
+		router.HandleFunc("/health/live", controllers.Liveness).Methods("GET", "HEAD")
+		router.HandleFunc("/health/ready", controllers.Readiness).Methods("GET", "HEAD")
+		router.Handle("/metrics", metrics.Handler()).Methods("GET")
 
-		router.HandleFunc("/match", controllers.NewMatch).Methods("POST")
+		router.Handle("/match", idempotency.NewHandler(controllers.NewMatch)).Methods("POST")
 		router.HandleFunc("/match/active", controllers.GetActiveMatches).Methods("GET")
 		router.HandleFunc("/match/types", controllers.GetMatchesTypes).Methods("GET")
 		router.HandleFunc("/match/modes", controllers.GetMatchesModes).Methods("GET")
@@ -55,9 +113,9 @@ var serveCmd = &cobra.Command{
 		router.HandleFunc("/leg/{id}/order", controllers.ChangePlayerOrder).Methods("PUT")
 		router.HandleFunc("/leg/{id}/warmup", controllers.StartWarmup).Methods("PUT")
 		router.HandleFunc("/leg/{id}/undo", controllers.UndoFinishLeg).Methods("PUT")
-		router.HandleFunc("/leg/{id}/finish", controllers.FinishLeg).Methods("PUT")
+		router.Handle("/leg/{id}/finish", idempotency.NewHandler(controllers.FinishLeg)).Methods("PUT")
 
-		router.HandleFunc("/visit", controllers.AddVisit).Methods("POST")
+		router.Handle("/visit", idempotency.NewHandler(controllers.AddVisit)).Methods("POST")
 		router.HandleFunc("/visit/{id}/modify", controllers.ModifyVisit).Methods("PUT")
 		router.HandleFunc("/visit/{id}", controllers.DeleteVisit).Methods("DELETE")
 		router.HandleFunc("/visit/{leg_id}/last", controllers.DeleteLastVisit).Methods("DELETE")
@@ -67,16 +125,23 @@ var serveCmd = &cobra.Command{
 		router.HandleFunc("/player/compare", controllers.GetPlayersX01Statistics).Methods("GET")
 		router.HandleFunc("/player/{id}", controllers.GetPlayer).Methods("GET")
 		router.HandleFunc("/player/{id}", controllers.UpdatePlayer).Methods("PUT")
-		router.HandleFunc("/player/{id}/statistics", controllers.GetPlayerStatistics).Methods("GET")
+		router.Handle("/player/{id}/statistics", cache.NewHandler(controllers.GetPlayerStatistics, "player:{id}")).Methods("GET")
 		router.HandleFunc("/player/{id}/hits", controllers.GetPlayerHits).Methods("PUT")
 		router.HandleFunc("/player/{id}/statistics/previous", controllers.GetPlayerX01PreviousStatistics).Methods("GET")
+		router.Handle("/player/{id}/statistics/pressure", cache.NewHandler(controllers.GetPlayerPressureStatistics, "player:{id}")).Methods("GET")
 		router.HandleFunc("/player/{id}/progression", controllers.GetPlayerProgression).Methods("GET")
+		router.Handle("/player/{id}/trend", cache.NewHandler(controllers.GetPlayerTrend, "player:{id}")).Methods("GET")
+		router.Handle("/player/{id}/records", cache.NewHandler(controllers.GetPlayerRecords, "player:{id}")).Methods("GET")
 		router.HandleFunc("/player/{id}/checkouts", controllers.GetPlayerCheckouts).Methods("GET")
 		router.HandleFunc("/player/{id}/tournament", controllers.GetPlayerTournamentStandings).Methods("GET")
 		router.HandleFunc("/player/{id}/badges", controllers.GetPlayerBadges).Methods("GET")
+		router.Handle("/player/{id}/badges/progress", cache.NewHandler(controllers.GetPlayerBadgeProgress, cache.TagBadges, "player:{id}")).Methods("GET")
 		router.HandleFunc("/player/{id}/elo/{start}/{limit}", controllers.GetPlayerEloChangelog).Methods("GET")
 		router.HandleFunc("/player/{player_1}/vs/{player_2}", controllers.GetPlayerHeadToHead).Methods("GET")
 		router.HandleFunc("/player/{player_1}/vs/{player_2}/simulate", controllers.SimulateMatch).Methods("PUT")
+		router.HandleFunc("/player/{id}/merge/{duplicate_id}", controllers.MergePlayers).Methods("POST")
+		router.HandleFunc("/player/{id}/export", controllers.ExportPlayer).Methods("GET")
+		router.HandleFunc("/player/{id}/anonymise", controllers.AnonymisePlayer).Methods("PUT")
 		router.HandleFunc("/player", controllers.AddPlayer).Methods("POST")
 		router.HandleFunc("/player/{id}/calendar", controllers.GetPlayerCalendar).Methods("GET")
 		router.HandleFunc("/player/{id}/random/{starting_score}", controllers.GetRandomLegForPlayer).Methods("GET")
@@ -85,6 +150,11 @@ var serveCmd = &cobra.Command{
 
 		// v2
 		router.HandleFunc("/players", controllers_v2.GetPlayers).Methods("GET")
+		router.HandleFunc("/players/{id}/elo", controllers_v2.GetPlayerEloChangelog).Methods("GET")
+		router.HandleFunc("/matches", controllers_v2.GetMatches).Methods("GET")
+		router.HandleFunc("/legs", controllers_v2.GetLegs).Methods("GET")
+		router.HandleFunc("/tournaments", controllers_v2.GetTournaments).Methods("GET")
+		router.HandleFunc("/badges/{id}/unlocks", controllers_v2.GetBadgeUnlocks).Methods("GET")
 
 		router.HandleFunc("/preset", controllers.AddPreset).Methods("POST")
 		router.HandleFunc("/preset", controllers.GetPresets).Methods("GET")
@@ -96,14 +166,27 @@ var serveCmd = &cobra.Command{
 
 		router.HandleFunc("/statistics/global", controllers.GetGlobalStatistics).Methods("GET")
 		router.HandleFunc("/statistics/global/fnc", controllers.GetGlobalStatisticsFnc).Methods("GET")
+		router.HandleFunc("/statistics/global/fnc/{from}/{to}", controllers.GetGlobalStatisticsFnc).Methods("GET")
+		router.HandleFunc("/statistics/global/{from}/{to}", controllers.GetGlobalStatistics).Methods("GET")
 		router.HandleFunc("/statistics/office/{from}/{to}", controllers.GetOfficeStatistics).Methods("GET")
 		router.HandleFunc("/statistics/office/{office_id}/{from}/{to}", controllers.GetOfficeStatistics).Methods("GET")
 		router.HandleFunc("/statistics/{dart}/hits", controllers.GetDartStatistics).Methods("GET")
 		router.HandleFunc("/statistics/x01/player/{legs}", controllers.GetPlayersLastXLegsStatistics).Methods("GET")
-		router.HandleFunc("/statistics/{match_type}/{from}/{to}", controllers.GetStatistics).Methods("GET")
+		router.Handle("/statistics/{match_type}/{from}/{to}", cache.NewHandler(controllers.GetStatistics, cache.TagStatistics)).Methods("GET")
+
+		router.Handle("/leaderboard/{match_type}", cache.NewHandler(controllers.GetLeaderboard, cache.TagStatistics)).Methods("GET")
+		router.Handle("/leaderboard/{match_type}/office/{office_id}", cache.NewHandler(controllers.GetLeaderboard, cache.TagStatistics)).Methods("GET")
+
+		router.Handle("/records/office/{id}", cache.NewHandler(controllers.GetOfficeRecords, cache.TagStatistics)).Methods("GET")
+
+		router.HandleFunc("/report/{id}", controllers.GetReport).Methods("GET")
 
 		router.HandleFunc("/owe", controllers.GetOwes).Methods("GET")
 		router.HandleFunc("/owe/payback", controllers.RegisterPayback).Methods("PUT")
+		router.HandleFunc("/owe/history", controllers.GetOweHistory).Methods("GET")
+		router.HandleFunc("/owe/settle", controllers.GetOweSettlement).Methods("GET")
+		router.HandleFunc("/owe/settle", controllers.ApplyOweSettlement).Methods("POST")
+		router.HandleFunc("/owe/player/{id}", controllers.GetOweBalanceSheet).Methods("GET")
 
 		router.HandleFunc("/owetype", controllers.GetOweTypes).Methods("GET")
 
@@ -119,6 +202,11 @@ var serveCmd = &cobra.Command{
 		router.HandleFunc("/venue/{id}/spectate", controllers.SpectateVenue).Methods("GET")
 		router.HandleFunc("/venue/{id}/players", controllers.GetRecentPlayers).Methods("GET")
 		router.HandleFunc("/venue/{id}/matches", controllers.GetActiveVenueMatches).Methods("GET")
+		router.HandleFunc("/venue/queue", controllers.EnqueueVenueMatch).Methods("POST")
+		router.HandleFunc("/venue/{id}/queue", controllers.GetVenueQueue).Methods("GET")
+		router.HandleFunc("/venue/{id}/queue", controllers.ReorderVenueQueue).Methods("PUT")
+		router.HandleFunc("/venue/{id}/queue/events", controllers.SubscribeVenueQueue).Methods("GET")
+		router.HandleFunc("/venue/{id}/queue/{match_id}", controllers.DequeueVenueMatch).Methods("DELETE")
 
 		router.HandleFunc("/tournament", controllers.NewTournament).Methods("POST")
 		router.HandleFunc("/tournament/generate", controllers.GenerateTournament).Methods("POST")
@@ -138,17 +226,26 @@ var serveCmd = &cobra.Command{
 		router.HandleFunc("/tournament/{id}/matches", controllers.GetTournamentMatches).Methods("GET")
 		router.HandleFunc("/tournament/{id}/matches/result", controllers.GetTournamentMatchResults).Methods("GET")
 		router.HandleFunc("/tournament/{id}/metadata", controllers.GetMatchMetadataForTournament).Methods("GET")
-		router.HandleFunc("/tournament/{id}/overview", controllers.GetTournamentOverview).Methods("GET")
-		router.HandleFunc("/tournament/{id}/statistics", controllers.GetTournamentStatistics).Methods("GET")
+		router.Handle("/tournament/{id}/overview", cache.NewHandler(controllers.GetTournamentOverview, "tournament:{id}")).Methods("GET")
+		router.Handle("/tournament/{id}/statistics", cache.NewHandler(controllers.GetTournamentStatistics, "tournament:{id}")).Methods("GET")
+		router.Handle("/tournament/{id}/statistics/pressure", cache.NewHandler(controllers.GetTournamentPressureStatistics, "tournament:{id}")).Methods("GET")
 		router.HandleFunc("/tournament/match/{id}/next", controllers.GetNextTournamentMatch).Methods("GET")
 		router.HandleFunc("/tournament/{id}/probabilities", controllers.GetTournamentProbabilities).Methods("GET")
 		router.HandleFunc("/tournament/match/{id}/probabilities", controllers.GetMatchProbabilities).Methods("GET")
 
 		router.HandleFunc("/badge", controllers.GetBadges).Methods("GET")
-		router.HandleFunc("/badge/statistics", controllers.GetBadgesStatistics).Methods("GET")
+		router.Handle("/badge/statistics", cache.NewHandler(controllers.GetBadgesStatistics, cache.TagBadges)).Methods("GET")
+		router.HandleFunc("/badge/rule", controllers.GetBadgeRules).Methods("GET")
+		router.HandleFunc("/badge/rule", controllers.AddBadgeRule).Methods("POST")
+		router.HandleFunc("/badge/rule/dryrun", controllers.DryRunBadgeRule).Methods("POST")
+		router.HandleFunc("/badge/rule/{id}", controllers.GetBadgeRule).Methods("GET")
+		router.HandleFunc("/badge/rule/{id}", controllers.UpdateBadgeRule).Methods("PUT")
+		router.HandleFunc("/badge/rule/{id}", controllers.DeleteBadgeRule).Methods("DELETE")
 		router.HandleFunc("/badge/{id}", controllers.GetBadge).Methods("GET")
 		router.HandleFunc("/badge/{id}/statistics", controllers.GetBadgeStatistics).Methods("GET")
 
+		router.HandleFunc("/graphql", controllers.ExecuteGraphQL).Methods("GET", "POST")
+
 		router.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
 			versionInfo := struct {
 				Version   string `json:"version"`
@@ -162,12 +259,76 @@ var serveCmd = &cobra.Command{
 			json.NewEncoder(w).Encode(versionInfo)
 		}).Methods("GET")
 
+		doc, err := openapi.NewDocument(router, openapi.Info{Title: "kcapp API", Version: models.Version}, routes)
+		if err != nil {
+			panic(err)
+		}
+		router.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			json.NewEncoder(w).Encode(doc)
+		}).Methods("GET")
+		router.Use(doc.Middleware)
+
 		port := viper.GetInt("api.port")
-		log.Printf("Listening on port %d", port)
-		log.Println(http.ListenAndServe(fmt.Sprintf("0.0.0.0:%d", port), router))
+		server := &http.Server{
+			Addr:              fmt.Sprintf("0.0.0.0:%d", port),
+			Handler:           router,
+			ReadTimeout:       viper.GetDuration("api.read_timeout"),
+			ReadHeaderTimeout: viper.GetDuration("api.read_header_timeout"),
+			WriteTimeout:      viper.GetDuration("api.write_timeout"),
+			IdleTimeout:       viper.GetDuration("api.idle_timeout"),
+		}
+		server.RegisterOnShutdown(data.CloseVenueQueueSubscriptions)
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		go report.Start(ctx, schedules)
+		go data.RecalculateQueuedLegs(ctx)
+		go func() {
+			log.Printf("Listening on port %d", port)
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Panic(err)
+			}
+		}()
+		<-ctx.Done()
+		stop()
+
+		// Stop routing new requests here, and let in-flight requests (e.g. visit transactions) finish
+		log.Printf("Shutting down, draining connections")
+		controllers.SetShuttingDown()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), viper.GetDuration("api.shutdown_timeout"))
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Unable to gracefully shut down: %s", err)
+		}
+		models.DB.Close()
+		log.Printf("Shut down")
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(serveCmd)
+
+	viper.SetDefault("api.read_timeout", "15s")
+	viper.SetDefault("api.read_header_timeout", "5s")
+	viper.SetDefault("api.write_timeout", "60s")
+	viper.SetDefault("api.idle_timeout", "120s")
+	viper.SetDefault("api.shutdown_timeout", "30s")
+	viper.SetDefault("api.ready_max_pool_usage", 0.9)
+	viper.SetDefault("db.max_open_connections", 0)
+	viper.SetDefault("db.schema_version", 0)
+	viper.SetDefault("cache.store", "memory")
+	viper.SetDefault("cache.ttl", "5m")
+	viper.SetDefault("cache.max_entries", 1000)
+	viper.SetDefault("cache.redis_address", "localhost:6379")
+	viper.SetDefault("ratelimit.rate", 0)
+	viper.SetDefault("ratelimit.burst", 30)
+	viper.SetDefault("ratelimit.trust_forwarded_for", false)
+	// GraphQL only supports queries
+	viper.SetDefault("ratelimit.exempt", []string{"/graphql"})
+	viper.SetDefault("idempotency.ttl", "24h")
+	viper.SetDefault("badges.rules_file", "")
+	viper.SetDefault("reports.notifier.type", "none")
+	viper.SetDefault("reports.notifier.format", "markdown")
 }
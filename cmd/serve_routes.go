@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"github.com/kcapp/api/graphql"
+	"github.com/kcapp/api/models"
+	"github.com/kcapp/api/openapi"
+)
+
+// routes describes the request and response bodies of all routes, used to generate the OpenAPI document
+var routes = openapi.Routes{
+	"GET /health/live":  {Response: map[string]bool{}},
+	"HEAD /health/live": {Response: map[string]bool{}},
+	"GET /health/ready": {Response: models.Readiness{}},
+
+	"POST /match":                                              {Request: models.Match{}, Response: models.Match{}},
+	"GET /match/active":                                        {Response: []*models.Match{}},
+	"GET /match/types":                                         {Response: []*models.MatchType{}},
+	"GET /match/modes":                                         {Response: []*models.MatchMode{}},
+	"GET /match/outshot":                                       {Response: []*models.OutshotType{}},
+	"GET /match":                                               {Response: []*models.Match{}},
+	"GET /match/{id}":                                          {Response: models.Match{}},
+	"PUT /match/{id}":                                          {Request: models.Match{}, Response: models.Match{}},
+	"PUT /match/{id}/score":                                    {Request: models.MatchResult{}, Response: models.Match{}},
+	"GET /match/{id}/metadata":                                 {Response: models.MatchMetadata{}},
+	"POST /match/{id}/rematch":                                 {Response: models.Match{}},
+	"GET /match/{id}/statistics":                               {Response: openapi.Any{}},
+	"GET /match/{id}/legs":                                     {Response: []*models.Leg{}},
+	"GET /match/{start}/{limit}":                               {Response: []*models.Match{}},
+	"GET /leg/active":                                          {Response: []*models.Leg{}},
+	"GET /leg/{id}":                                            {Response: models.Leg{}},
+	"GET /leg/{id}/statistics":                                 {Response: openapi.Any{}},
+	"GET /leg/{id}/players":                                    {Response: []*models.Player2Leg{}},
+	"PUT /leg/{id}/order":                                      {Request: map[string]int{}, Response: []*models.Player2Leg{}},
+	"PUT /leg/{id}/warmup":                                     {Request: models.Venue{}},
+	"PUT /leg/{id}/finish":                                     {Request: map[string]int{}},
+	"POST /visit":                                              {Request: models.Visit{}, Response: models.Visit{}},
+	"PUT /visit/{id}/modify":                                   {Request: models.Visit{}},
+	"GET /player":                                              {Response: map[int]*models.Player{}},
+	"GET /player/active":                                       {Response: map[int]*models.Player{}},
+	"GET /player/compare":                                      {Response: []*models.StatisticsX01{}},
+	"GET /player/{id}":                                         {Response: models.Player{}},
+	"PUT /player/{id}":                                         {Request: models.Player{}},
+	"GET /player/{id}/statistics":                              {Response: models.PlayerStatistics{}},
+	"PUT /player/{id}/hits":                                    {Request: models.Visit{}, Response: []*models.Visit{}},
+	"GET /player/{id}/progression":                             {Response: map[string]*models.StatisticsX01{}},
+	"GET /player/{id}/trend":                                   {Response: models.PlayerTrend{}},
+	"GET /player/{id}/records":                                 {Response: models.PlayerRecords{}},
+	"GET /player/{id}/checkouts":                               {Response: []*models.CheckoutStatistics{}},
+	"GET /player/{id}/tournament":                              {Response: []*models.PlayerTournamentStanding{}},
+	"GET /player/{id}/badges":                                  {Response: []*models.PlayerBadge{}},
+	"GET /player/{id}/badges/progress":                         {Response: []*models.BadgeProgress{}},
+	"GET /player/{id}/statistics/previous":                     {Response: models.StatisticsX01{}},
+	"GET /player/{id}/statistics/pressure":                     {Response: models.StatisticsPressure{}},
+	"GET /player/{id}/elo/{start}/{limit}":                     {Response: models.PlayerEloChangelogs{}},
+	"GET /player/{player_1}/vs/{player_2}":                     {Response: models.StatisticsHead2Head{}},
+	"POST /player/{id}/merge/{duplicate_id}":                   {Response: models.PlayerMergeReport{}},
+	"GET /player/{id}/export":                                  {Response: models.PlayerExport{}},
+	"PUT /player/{id}/anonymise":                               {Response: models.Player{}},
+	"POST /player":                                             {Request: models.Player{}},
+	"GET /player/{id}/random/{starting_score}":                 {Response: []*models.Visit{}},
+	"GET /player/{id}/statistics/{match_type}":                 {Response: openapi.Any{}},
+	"GET /player/{id}/statistics/{match_type}/history/{limit}": {Response: []*models.Leg{}},
+	"PUT /player/{player_1}/vs/{player_2}/simulate": {
+		Request: struct {
+			Player1Score int `json:"player1_score"`
+			Player2Score int `json:"player2_score"`
+		}{},
+		Response: struct {
+			Player1OldElo int `json:"player1_old_elo"`
+			Player1NewElo int `json:"player1_new_elo"`
+			Player2OldElo int `json:"player2_old_elo"`
+			Player2NewElo int `json:"player2_new_elo"`
+		}{},
+	},
+
+	"GET /players":             {Response: models.Page{}},
+	"GET /players/{id}/elo":    {Response: models.Page{}},
+	"GET /matches":             {Response: models.Page{}},
+	"GET /legs":                {Response: models.Page{}},
+	"GET /tournaments":         {Response: models.Page{}},
+	"GET /badges/{id}/unlocks": {Response: models.Page{}},
+
+	"POST /preset":     {Request: models.MatchPreset{}},
+	"GET /preset":      {Response: []*models.MatchPreset{}},
+	"GET /preset/{id}": {Response: models.MatchPreset{}},
+	"PUT /preset/{id}": {Request: models.MatchPreset{}},
+
+	"GET /option/default": {Response: models.DefaultOptions{}},
+
+	"GET /leaderboard/{match_type}":                    {Response: models.Leaderboard{}},
+	"GET /leaderboard/{match_type}/office/{office_id}": {Response: models.Leaderboard{}},
+	"GET /records/office/{id}":                         {Response: []*models.PlayerRecord{}},
+	"GET /report/{id}":                                 {Response: models.Report{}},
+	"GET /statistics/global":                           {Response: map[int]*models.GlobalStatistics{}},
+	"GET /statistics/global/fnc":                       {Response: map[int]*models.GlobalStatistics{}},
+	"GET /statistics/global/fnc/{from}/{to}":           {Response: map[int]*models.GlobalStatistics{}},
+	"GET /statistics/global/{from}/{to}":               {Response: map[int]*models.GlobalStatistics{}},
+	"GET /statistics/office/{from}/{to}":               {Response: []*models.OfficeStatistics{}},
+	"GET /statistics/office/{office_id}/{from}/{to}":   {Response: []*models.OfficeStatistics{}},
+	"GET /statistics/{dart}/hits":                      {Response: map[int]*models.Hits{}},
+	"GET /statistics/x01/player/{legs}":                {Response: []*models.StatisticsX01{}},
+	"GET /statistics/{match_type}/{from}/{to}":         {Response: openapi.Any{}},
+
+	"GET /owe":             {Response: []*models.Owe{}},
+	"PUT /owe/payback":     {Request: models.Owe{}},
+	"GET /owe/history":     {Response: []*models.OweTransaction{}},
+	"GET /owe/settle":      {Response: []*models.Owe{}},
+	"POST /owe/settle":     {Response: []*models.Owe{}},
+	"GET /owe/player/{id}": {Response: models.OweBalanceSheet{}},
+	"GET /owetype":         {Response: []*models.OweType{}},
+
+	"POST /office":     {Request: models.Office{}},
+	"PUT /office/{id}": {Request: models.Office{}},
+	"GET /office":      {Response: map[int]*models.Office{}},
+
+	"POST /venue":              {Request: models.Venue{}},
+	"PUT /venue/{id}":          {Request: models.Venue{}},
+	"GET /venue":               {Response: []*models.Venue{}},
+	"GET /venue/{id}":          {Response: models.Venue{}},
+	"GET /venue/{id}/config":   {Response: models.VenueConfig{}},
+	"GET /venue/{id}/spectate": {Response: []*models.Match{}},
+	"GET /venue/{id}/players":  {Response: []int{}},
+	"GET /venue/{id}/matches":  {Response: []*models.Match{}},
+	"POST /venue/queue":        {Request: models.VenueQueueInput{}, Response: models.VenueQueue{}},
+	"GET /venue/{id}/queue":    {Response: models.VenueQueue{}},
+	"PUT /venue/{id}/queue":    {Request: models.VenueQueueOrder{}, Response: models.VenueQueue{}},
+
+	"POST /tournament":                         {Request: models.Tournament{}, Response: models.Tournament{}},
+	"POST /tournament/generate":                {Request: models.GenerateTournamentInput{}, Response: models.Tournament{}},
+	"POST /tournament/generate/playoffs/{id}":  {Request: models.GeneratePlayoffsInput{}, Response: models.Tournament{}},
+	"GET /tournament":                          {Response: []*models.Tournament{}},
+	"GET /tournament/current":                  {Response: models.Tournament{}},
+	"GET /tournament/current/{office_id}":      {Response: models.Tournament{}},
+	"GET /tournament/office/{office_id}":       {Response: []*models.Tournament{}},
+	"POST /tournament/groups":                  {Request: models.TournamentGroup{}},
+	"GET /tournament/groups":                   {Response: map[int]*models.TournamentGroup{}},
+	"GET /tournament/standings":                {Response: []*models.TournamentStanding{}},
+	"GET /tournament/preset":                   {Response: []*models.TournamentPreset{}},
+	"GET /tournament/preset/{id}":              {Response: models.TournamentPreset{}},
+	"GET /tournament/{id}":                     {Response: models.Tournament{}},
+	"POST /tournament/{id}/player":             {Request: models.Player2Tournament{}, Response: []*models.Match{}},
+	"GET /tournament/{id}/player/{player_id}":  {Response: []*models.Match{}},
+	"GET /tournament/{id}/matches":             {Response: map[int][]*models.Match{}},
+	"GET /tournament/{id}/matches/result":      {Response: openapi.Any{}},
+	"GET /tournament/{id}/metadata":            {Response: []*models.MatchMetadata{}},
+	"GET /tournament/{id}/overview":            {Response: map[int][]*models.TournamentOverview{}},
+	"GET /tournament/{id}/statistics":          {Response: models.TournamentStatistics{}},
+	"GET /tournament/{id}/statistics/pressure": {Response: []*models.StatisticsPressure{}},
+	"GET /tournament/match/{id}/next":          {Response: models.Match{}},
+	"GET /tournament/{id}/probabilities":       {Response: []*models.Probability{}},
+	"GET /tournament/match/{id}/probabilities": {Response: models.Probability{}},
+
+	"GET /badge":                 {Response: []*models.Badge{}},
+	"GET /badge/statistics":      {Response: []*models.BadgeStatistics{}},
+	"GET /badge/rule":            {Response: []*models.BadgeRule{}},
+	"POST /badge/rule":           {Request: models.BadgeRule{}, Response: models.BadgeRule{}},
+	"POST /badge/rule/dryrun":    {Request: models.BadgeRule{}, Response: models.BadgeRuleDryRun{}},
+	"GET /badge/rule/{id}":       {Response: models.BadgeRule{}},
+	"PUT /badge/rule/{id}":       {Request: models.BadgeRule{}, Response: models.BadgeRule{}},
+	"GET /badge/{id}":            {Response: models.Badge{}},
+	"GET /badge/{id}/statistics": {Response: []*models.PlayerBadge{}},
+
+	"GET /graphql":  {Response: openapi.Any{}},
+	"POST /graphql": {Request: graphql.Request{}, Response: openapi.Any{}},
+
+	"GET /version": {Response: struct {
+		Version   string `json:"version"`
+		GitCommit string `json:"git_commit"`
+	}{}},
+}
@@ -2,19 +2,20 @@ package controllers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
 )
 
 func GetBadges(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	badges, err := data.GetBadges()
 	if err != nil {
-		log.Println("Unable to get badges")
+		logger.Println("Unable to get badges")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -24,16 +25,17 @@ func GetBadges(w http.ResponseWriter, r *http.Request) {
 
 func GetBadge(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	badge, err := data.GetBadge(id)
 	if err != nil {
-		log.Println("Unable to get badge")
+		logger.Println("Unable to get badge")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -43,9 +45,10 @@ func GetBadge(w http.ResponseWriter, r *http.Request) {
 
 func GetBadgesStatistics(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	badges, err := data.GetBadgesStatistics()
 	if err != nil {
-		log.Println("Unable to get badge statistics")
+		logger.Println("Unable to get badge statistics")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -55,16 +58,17 @@ func GetBadgesStatistics(w http.ResponseWriter, r *http.Request) {
 
 func GetBadgeStatistics(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	badges, err := data.GetBadgeStatistics(id)
 	if err != nil {
-		log.Println("Unable to get badge statistics")
+		logger.Println("Unable to get badge statistics")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
+	"github.com/kcapp/api/models"
+)
+
+// GetBadgeRules will return all badge rules
+func GetBadgeRules(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	rules, err := data.GetBadgeRules()
+	if err != nil {
+		logger.Println("Unable to get badge rules", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(rules)
+}
+
+// GetBadgeRule will return the badge rule for the given badge
+func GetBadgeRule(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rule, err := data.GetBadgeRule(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "badge rule not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		logger.Println("Unable to get badge rule", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(rule)
+}
+
+// AddBadgeRule will create a new badge defined by a rule
+func AddBadgeRule(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	var rule models.BadgeRule
+	err := json.NewDecoder(r.Body).Decode(&rule)
+	if err != nil {
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
+		return
+	}
+	created, err := data.AddBadgeRule(rule)
+	if err != nil {
+		writeBadgeRuleError(w, r, "Unable to add badge rule", err)
+		return
+	}
+	json.NewEncoder(w).Encode(created)
+}
+
+// UpdateBadgeRule will update the rule of the given badge
+func UpdateBadgeRule(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var rule models.BadgeRule
+	err = json.NewDecoder(r.Body).Decode(&rule)
+	if err != nil {
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
+		return
+	}
+	updated, err := data.UpdateBadgeRule(id, rule)
+	if err != nil {
+		writeBadgeRuleError(w, r, "Unable to update badge rule", err)
+		return
+	}
+	json.NewEncoder(w).Encode(updated)
+}
+
+// DeleteBadgeRule will delete the given badge rule, and all unlocks of the badge
+func DeleteBadgeRule(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = data.DeleteBadgeRule(id)
+	if err != nil {
+		writeBadgeRuleError(w, r, "Unable to delete badge rule", err)
+		return
+	}
+}
+
+// DryRunBadgeRule will show who would unlock the given rule, based on legs or matches from the last "since" days
+func DryRunBadgeRule(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	var rule models.BadgeRule
+	err := json.NewDecoder(r.Body).Decode(&rule)
+	if err != nil {
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
+		return
+	}
+	since, err := strconv.Atoi(r.URL.Query().Get("since"))
+	if err != nil {
+		since = 30
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 1000
+	}
+	result, err := data.DryRunBadgeRule(rule, time.Now().AddDate(0, 0, -since), limit)
+	if err != nil {
+		writeBadgeRuleError(w, r, "Unable to dry run badge rule", err)
+		return
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+func writeBadgeRuleError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	logger := logging.Logger(r.Context())
+	switch t := err.(type) {
+	default:
+		logger.Println(message, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	case *models.BadgeRuleError:
+		logger.Println(message, t)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
@@ -1,6 +1,10 @@
 package controllers
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/kcapp/api/openapi"
+)
 
 // SetHeaders will set the default headers used by all requests
 func SetHeaders(w http.ResponseWriter) {
@@ -9,3 +13,8 @@ func SetHeaders(w http.ResponseWriter) {
 	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
 	w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
 }
+
+// WriteDecodeError will write a structured 400 Bad Request for a request body which could not be decoded
+func WriteDecodeError(w http.ResponseWriter, err error) {
+	openapi.WriteError(w, openapi.DecodeError(err))
+}
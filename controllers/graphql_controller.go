@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kcapp/api/graphql"
+)
+
+// ExecuteGraphQL will execute a GraphQL query, given either as body of a POST request or as query parameters of a GET request
+func ExecuteGraphQL(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	request := new(graphql.Request)
+	if r.Method == http.MethodGet {
+		params := r.URL.Query()
+		request.Query = params.Get("query")
+		request.OperationName = params.Get("operationName")
+		if variables := params.Get("variables"); variables != "" {
+			err := json.Unmarshal([]byte(variables), &request.Variables)
+			if err != nil {
+				WriteDecodeError(w, err)
+				return
+			}
+		}
+	} else {
+		err := json.NewDecoder(r.Body).Decode(request)
+		if err != nil {
+			WriteDecodeError(w, err)
+			return
+		}
+	}
+	json.NewEncoder(w).Encode(graphql.Execute(r.Context(), request))
+}
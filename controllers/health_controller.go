@@ -1,12 +1,46 @@
 package controllers
 
 import (
+	"encoding/json"
 	"net/http"
+	"sync/atomic"
+
+	"github.com/kcapp/api/data"
+	"github.com/spf13/viper"
 )
 
+// shuttingDown is set once the server starts draining connections
+var shuttingDown atomic.Bool
+
+// SetShuttingDown will make the readiness check fail, so no new requests are routed to this instance
+func SetShuttingDown() {
+	shuttingDown.Store(true)
+}
+
 // Healthcheck will return OK
 func Healthcheck(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("☄ HTTP status code returned!"))
 }
+
+// Liveness will return OK as long as the process is able to serve requests
+func Liveness(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]bool{"alive": true})
+}
+
+// Readiness will return OK if the database is reachable, the schema is up to date and the connection pool is not saturated
+func Readiness(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+
+	readiness := data.GetReadiness(viper.GetInt64("db.schema_version"), viper.GetFloat64("api.ready_max_pool_usage"))
+	if shuttingDown.Load() {
+		readiness.AddCheck("shutdown", false, "server is shutting down")
+	}
+	if !readiness.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(readiness)
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/guregu/null"
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
+	"github.com/kcapp/api/models"
+)
+
+// GetLeaderboard will return players ranked by the key metric of the given match type, globally or within an office
+func GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	matchType, err := strconv.Atoi(params["match_type"])
+	if err != nil {
+		logger.Println("Invalid match_type parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var officeID null.Int
+	if _, ok := params["office_id"]; ok {
+		id, err := strconv.Atoi(params["office_id"])
+		if err != nil {
+			logger.Println("Invalid office_id parameter")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		officeID = null.IntFrom(int64(id))
+	}
+	minimumLegs, err := strconv.Atoi(r.URL.Query().Get("min_legs"))
+	if err != nil || minimumLegs < 1 {
+		minimumLegs = models.LeaderboardMinimumLegs
+	}
+
+	leaderboard, err := data.GetLeaderboard(matchType, officeID, minimumLegs)
+	if err != nil {
+		switch t := err.(type) {
+		default:
+			logger.Println("Unable to get leaderboard", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		case *models.LeaderboardError:
+			logger.Println("Unable to get leaderboard", t)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+	json.NewEncoder(w).Encode(leaderboard)
+}
@@ -2,13 +2,14 @@ package controllers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/guregu/null"
 	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
 	"github.com/kcapp/api/models"
+	"github.com/kcapp/api/openapi"
 
 	"github.com/gorilla/mux"
 )
@@ -16,17 +17,18 @@ import (
 // GetLegsForMatch will return a list of all legs for the given match ID
 func GetLegsForMatch(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	matchID, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	legs, err := data.GetLegsForMatch(matchID)
 	if err != nil {
-		log.Println("Unable to get legs", err)
+		logger.Println("Unable to get legs", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -36,17 +38,18 @@ func GetLegsForMatch(w http.ResponseWriter, r *http.Request) {
 // GetLeg will return a leg specified by the given id
 func GetLeg(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	legID, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	leg, err := data.GetLeg(legID)
 	if err != nil {
-		log.Println("Unable to get leg", err)
+		logger.Println("Unable to get leg", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -56,9 +59,10 @@ func GetLeg(w http.ResponseWriter, r *http.Request) {
 // GetActiveLegs will return a list of all legs which are currently active
 func GetActiveLegs(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	legs, err := data.GetActiveLegs()
 	if err != nil {
-		log.Println("Unable to get legs", err)
+		logger.Println("Unable to get legs", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -68,17 +72,18 @@ func GetActiveLegs(w http.ResponseWriter, r *http.Request) {
 // GetLegPlayers will return a leg specified by the given id
 func GetLegPlayers(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	legID, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	players, err := data.GetLegPlayers(legID)
 	if err != nil {
-		log.Printf("[%d] Unable to get players for leg: %s", legID, err)
+		logger.Printf("[%d] Unable to get players for leg: %s", legID, err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -88,23 +93,24 @@ func GetLegPlayers(w http.ResponseWriter, r *http.Request) {
 // GetStatisticsForLeg will return statistics for all players in the given leg
 func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	legID, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	leg, err := data.GetLeg(legID)
 	if err != nil {
-		log.Println("Unable to get leg")
+		logger.Println("Unable to get leg")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	match, err := data.GetMatch(leg.MatchID)
 	if err != nil {
-		log.Println("Unable to get Match")
+		logger.Println("Unable to get Match")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -116,7 +122,7 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	if matchType == models.SHOOTOUT {
 		stats, err := data.GetShootoutStatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get shootout statistics", err)
+			logger.Println("Unable to get shootout statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -124,7 +130,7 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	} else if matchType == models.CRICKET {
 		stats, err := data.GetCricketStatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get cricket statistics", err)
+			logger.Println("Unable to get cricket statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -132,7 +138,7 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	} else if matchType == models.DARTSATX {
 		stats, err := data.GetDartsAtXStatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get Darts At X statistics", err)
+			logger.Println("Unable to get Darts At X statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -140,7 +146,7 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	} else if matchType == models.AROUNDTHECLOCK {
 		stats, err := data.GetAroundTheClockStatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get Around the Clock statistics", err)
+			logger.Println("Unable to get Around the Clock statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -148,7 +154,7 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	} else if matchType == models.AROUNDTHEWORLD {
 		stats, err := data.GetAroundTheWorldStatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get Around the World statistics", err)
+			logger.Println("Unable to get Around the World statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -156,7 +162,7 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	} else if matchType == models.SHANGHAI {
 		stats, err := data.GetShanghaiStatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get Shanghai statistics", err)
+			logger.Println("Unable to get Shanghai statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -164,7 +170,7 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	} else if matchType == models.TICTACTOE {
 		stats, err := data.GetTicTacToeStatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get Tic Tac Toe statistics", err)
+			logger.Println("Unable to get Tic Tac Toe statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -172,7 +178,7 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	} else if matchType == models.BERMUDATRIANGLE {
 		stats, err := data.GetBermudaTriangleStatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get Bermuda Triangle statistics", err)
+			logger.Println("Unable to get Bermuda Triangle statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -180,7 +186,7 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	} else if matchType == models.FOURTWENTY {
 		stats, err := data.Get420StatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get 420 statistics", err)
+			logger.Println("Unable to get 420 statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -188,7 +194,7 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	} else if matchType == models.KILLBULL {
 		stats, err := data.GetKillBullStatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get Kill Bull statistics", err)
+			logger.Println("Unable to get Kill Bull statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -196,7 +202,7 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	} else if matchType == models.GOTCHA {
 		stats, err := data.GetGotchaStatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get Gotcha statistics", err)
+			logger.Println("Unable to get Gotcha statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -204,7 +210,7 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	} else if matchType == models.JDCPRACTICE {
 		stats, err := data.GetJDCPracticeStatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get JDC Practice statistics", err)
+			logger.Println("Unable to get JDC Practice statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -212,7 +218,7 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	} else if matchType == models.KNOCKOUT {
 		stats, err := data.GetKnockoutStatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get Knockout statistics", err)
+			logger.Println("Unable to get Knockout statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -220,7 +226,7 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	} else if matchType == models.SCAM {
 		stats, err := data.GetScamStatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get Scam statistics", err)
+			logger.Println("Unable to get Scam statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -228,7 +234,39 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	} else if matchType == models.ONESEVENTY {
 		stats, err := data.Get170StatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get 170 statistics", err)
+			logger.Println("Unable to get 170 statistics", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(stats)
+	} else if matchType == models.BOBS27 {
+		stats, err := data.GetBobs27StatisticsForLeg(legID)
+		if err != nil {
+			logger.Println("Unable to get Bob's 27 statistics", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(stats)
+	} else if matchType == models.ONETWENTYONE {
+		stats, err := data.Get121StatisticsForLeg(legID)
+		if err != nil {
+			logger.Println("Unable to get 121 statistics", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(stats)
+	} else if matchType == models.GOLF {
+		stats, err := data.GetGolfStatisticsForLeg(legID)
+		if err != nil {
+			logger.Println("Unable to get Golf statistics", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(stats)
+	} else if matchType == models.BASEBALL {
+		stats, err := data.GetBaseballStatisticsForLeg(legID)
+		if err != nil {
+			logger.Println("Unable to get Baseball statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -236,7 +274,7 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 	} else {
 		stats, err := data.GetX01StatisticsForLeg(legID)
 		if err != nil {
-			log.Println("Unable to get x01 statistics", err)
+			logger.Println("Unable to get x01 statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -247,31 +285,32 @@ func GetStatisticsForLeg(w http.ResponseWriter, r *http.Request) {
 // ChangePlayerOrder will modify the order of players for the given leg
 func ChangePlayerOrder(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	legID, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	orderMap := make(map[string]int)
 	err = json.NewDecoder(r.Body).Decode(&orderMap)
 	if err != nil {
-		log.Println("Unable to deserialize order body", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize order body", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	err = data.ChangePlayerOrder(legID, orderMap)
 	if err != nil {
-		log.Println("Unable to change player order", err)
+		logger.Println("Unable to change player order", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	players, err := data.GetLegPlayers(legID)
 	if err != nil {
-		log.Printf("[%d] Unable to get players for leg: %s", legID, err)
+		logger.Printf("[%d] Unable to get players for leg: %s", legID, err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -281,24 +320,25 @@ func ChangePlayerOrder(w http.ResponseWriter, r *http.Request) {
 // StartWarmup will set the leg as warm up
 func StartWarmup(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	legID, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	venue := new(models.Venue)
 	err = json.NewDecoder(r.Body).Decode(&venue)
 	if err != nil {
-		log.Println("Unable to deserialize venue body", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize venue body", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	err = data.StartWarmup(legID, int(venue.ID.Int64))
 	if err != nil {
-		log.Println("Unable to start warmup", err)
+		logger.Println("Unable to start warmup", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -307,17 +347,18 @@ func StartWarmup(w http.ResponseWriter, r *http.Request) {
 // DeleteLeg will delete a leg
 func DeleteLeg(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	legID, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	err = data.DeleteLeg(legID)
+	err = data.DeleteLeg(r.Context(), legID)
 	if err != nil {
-		log.Println("Unable to delete leg", err)
+		logger.Println("Unable to delete leg", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -326,17 +367,18 @@ func DeleteLeg(w http.ResponseWriter, r *http.Request) {
 // UndoFinishLeg will undo a finalized leg
 func UndoFinishLeg(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	legID, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	err = data.UndoLegFinish(legID)
+	err = data.UndoLegFinish(r.Context(), legID)
 	if err != nil {
-		log.Println("Unable to undo leg finish", err)
+		logger.Println("Unable to undo leg finish", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -345,10 +387,11 @@ func UndoFinishLeg(w http.ResponseWriter, r *http.Request) {
 // FinishLeg will finalize a leg without a proper finish
 func FinishLeg(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	legID, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -356,18 +399,19 @@ func FinishLeg(w http.ResponseWriter, r *http.Request) {
 	body := make(map[string]int)
 	err = json.NewDecoder(r.Body).Decode(&body)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		WriteDecodeError(w, err)
 		return
 	}
 	winnerID, ok := body["winner_id"]
 	if !ok {
-		http.Error(w, "winner_id is required", http.StatusBadRequest)
+		openapi.WriteError(w, &openapi.Error{Error: "invalid request body",
+			Fields: []*openapi.FieldError{{Path: "$.winner_id", Message: "is required"}}})
 		return
 	}
 
-	err = data.FinishLeg(legID, winnerID, null.IntFrom(int64(winnerID)))
+	err = data.FinishLeg(r.Context(), legID, winnerID, null.IntFrom(int64(winnerID)))
 	if err != nil {
-		log.Println("Unable to finish leg", err)
+		logger.Println("Unable to finish leg", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -2,12 +2,12 @@ package controllers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
 	"github.com/kcapp/api/models"
 
 	"github.com/gorilla/mux"
@@ -16,22 +16,23 @@ import (
 // NewMatch will start a new match
 func NewMatch(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	var matchInput models.Match
 	err := json.NewDecoder(r.Body).Decode(&matchInput)
 	if err != nil {
-		log.Println("Unable to deserialize body", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
-	match, err := data.NewMatch(matchInput)
+	match, err := data.NewMatch(r.Context(), matchInput)
 	if err != nil {
 		switch t := err.(type) {
 		default:
-			log.Println("Unable to start new match", err)
+			logger.Println("Unable to start new match", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		case *models.MatchConfigError:
-			log.Println("Unable to start new match", t)
+			logger.Println("Unable to start new match", t)
 			http.Error(w, err.Error(), http.StatusBadRequest)
 		}
 		return
@@ -42,17 +43,18 @@ func NewMatch(w http.ResponseWriter, r *http.Request) {
 // UpdateMatch will update a match
 func UpdateMatch(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	var matchInput models.Match
 	err := json.NewDecoder(r.Body).Decode(&matchInput)
 	if err != nil {
-		log.Println("Unable to deserialize body", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	match, err := data.UpdateMatch(matchInput)
 	if err != nil {
-		log.Println("Unable to update match", err)
+		logger.Println("Unable to update match", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -62,16 +64,17 @@ func UpdateMatch(w http.ResponseWriter, r *http.Request) {
 // ReMatch will start a new match with same settings as the given match ID
 func ReMatch(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	match, err := data.GetMatch(id)
 	if err != nil {
-		log.Println("Unable to get match: ", err)
+		logger.Println("Unable to get match: ", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -83,7 +86,7 @@ func ReMatch(w http.ResponseWriter, r *http.Request) {
 	}
 	players, err := data.GetPlayersScore(int(match.CurrentLegID.Int64))
 	if err != nil {
-		log.Println("Unable to get players in match: ", err)
+		logger.Println("Unable to get players in match: ", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -94,9 +97,9 @@ func ReMatch(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	match.CreatedAt = time.Now().UTC()
-	match, err = data.NewMatch(*match)
+	match, err = data.NewMatch(r.Context(), *match)
 	if err != nil {
-		log.Println("Unable to rematch: ", err)
+		logger.Println("Unable to rematch: ", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -106,9 +109,10 @@ func ReMatch(w http.ResponseWriter, r *http.Request) {
 // GetMatches will return a list of all matches
 func GetMatches(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	matches, err := data.GetMatches()
 	if err != nil {
-		log.Println("Unable to get matches", err)
+		logger.Println("Unable to get matches", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -118,13 +122,14 @@ func GetMatches(w http.ResponseWriter, r *http.Request) {
 // GetActiveMatches will return a list of active matches
 func GetActiveMatches(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	since, err := strconv.Atoi(r.URL.Query().Get("since"))
 	if err != nil {
 		since = 2
 	}
 	matches, err := data.GetActiveMatches(since)
 	if err != nil {
-		log.Println("Unable to get active matches", err)
+		logger.Println("Unable to get active matches", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -133,16 +138,17 @@ func GetActiveMatches(w http.ResponseWriter, r *http.Request) {
 
 func GetMatchProbabilities(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	prob, err := data.GetMatchProbabilities(id)
 	if err != nil {
-		log.Println("Unable to get match probabilities", err)
+		logger.Println("Unable to get match probabilities", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -152,28 +158,29 @@ func GetMatchProbabilities(w http.ResponseWriter, r *http.Request) {
 // GetMatchesLimit will return N matches from the given starting point
 func GetMatchesLimit(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	start, err := strconv.Atoi(params["start"])
 	if err != nil {
-		log.Println("Invalid start parameter")
+		logger.Println("Invalid start parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	limit, err := strconv.Atoi(params["limit"])
 	if err != nil {
-		log.Println("Invalid limit parameter")
+		logger.Println("Invalid limit parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	matches, err := data.GetMatchesLimit(start, limit)
 	if err != nil {
-		log.Println("Unable to get matches", err)
+		logger.Println("Unable to get matches", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	count, err := data.GetMatchesCount()
 	if err != nil {
-		log.Println("Unable to get matches", err)
+		logger.Println("Unable to get matches", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -184,16 +191,17 @@ func GetMatchesLimit(w http.ResponseWriter, r *http.Request) {
 // GetMatch will reurn a the match with the given ID
 func GetMatch(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	match, err := data.GetMatch(id)
 	if err != nil {
-		log.Println("Unable to get match: ", err)
+		logger.Println("Unable to get match: ", err)
 		http.Error(w, "Unable to get match", http.StatusBadRequest)
 		return
 	}
@@ -203,10 +211,11 @@ func GetMatch(w http.ResponseWriter, r *http.Request) {
 // SetScore will set the score of a given match
 func SetScore(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -214,14 +223,14 @@ func SetScore(w http.ResponseWriter, r *http.Request) {
 	var input models.MatchResult
 	err = json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		log.Println("Unable to deserialize body", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	match, err := data.SetScore(id, input)
 	if err != nil {
-		log.Println("Unable to set score for match: ", err)
+		logger.Println("Unable to set score for match: ", err)
 		http.Error(w, "Unable to set score for match", http.StatusBadRequest)
 		return
 	}
@@ -231,16 +240,17 @@ func SetScore(w http.ResponseWriter, r *http.Request) {
 // GetMatchMetadata will return metadata for the given match
 func GetMatchMetadata(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	metadata, err := data.GetMatchMetadata(id)
 	if err != nil {
-		log.Println("Unable to get match metadata: ", err)
+		logger.Println("Unable to get match metadata: ", err)
 		http.Error(w, "Unable to get match metadata", http.StatusBadRequest)
 		return
 	}
@@ -250,16 +260,17 @@ func GetMatchMetadata(w http.ResponseWriter, r *http.Request) {
 // GetMatchMetadataForTournament will return metadata for all matches in a tournament
 func GetMatchMetadataForTournament(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	tournamentID, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	metadata, err := data.GetMatchMetadataForTournament(tournamentID)
 	if err != nil {
-		log.Println("Unable to get match metadata for tournament: ", err)
+		logger.Println("Unable to get match metadata for tournament: ", err)
 		http.Error(w, "Unable to get match metadata for tournament", http.StatusBadRequest)
 		return
 	}
@@ -269,158 +280,38 @@ func GetMatchMetadataForTournament(w http.ResponseWriter, r *http.Request) {
 // GetStatisticsForMatch will return statistics for all players in the given match
 func GetStatisticsForMatch(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	matchID, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	match, err := data.GetMatch(matchID)
 	if err != nil {
-		log.Printf("Unable to get Match %d", matchID)
+		logger.Printf("Unable to get Match %d", matchID)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	if match.MatchType.ID == models.SHOOTOUT {
-		stats, err := data.GetShootoutStatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get shootout statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
-	} else if match.MatchType.ID == models.CRICKET {
-		stats, err := data.GetCricketStatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get cricket statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
-	} else if match.MatchType.ID == models.DARTSATX {
-		stats, err := data.GetDartsAtXStatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get darts at x statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
-	} else if match.MatchType.ID == models.AROUNDTHECLOCK {
-		stats, err := data.GetAroundTheClockStatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get around the clock statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
-	} else if match.MatchType.ID == models.AROUNDTHEWORLD {
-		stats, err := data.GetAroundTheWorldStatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get around the world statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
-	} else if match.MatchType.ID == models.SHANGHAI {
-		stats, err := data.GetShanghaiStatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get shanghai statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
-	} else if match.MatchType.ID == models.TICTACTOE {
-		stats, err := data.GetTicTacToeStatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get tic tac toe statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
-	} else if match.MatchType.ID == models.BERMUDATRIANGLE {
-		stats, err := data.GetBermudaTriangleStatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get bermuda triangle statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
-	} else if match.MatchType.ID == models.FOURTWENTY {
-		stats, err := data.Get420StatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get 420 statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
-	} else if match.MatchType.ID == models.KILLBULL {
-		stats, err := data.GetKillBullStatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get Kill Bull statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
-	} else if match.MatchType.ID == models.GOTCHA {
-		stats, err := data.GetGotchaStatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get Gotcha statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
-	} else if match.MatchType.ID == models.JDCPRACTICE {
-		stats, err := data.GetJDCPracticeStatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get JDC Practice statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
-	} else if match.MatchType.ID == models.KNOCKOUT {
-		stats, err := data.GetKnockoutStatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get Knockout statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
-	} else if match.MatchType.ID == models.SCAM {
-		stats, err := data.GetScamStatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get Scam statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
-	} else if match.MatchType.ID == models.ONESEVENTY {
-		stats, err := data.Get170StatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get 170 statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
-	} else {
-		stats, err := data.GetX01StatisticsForMatch(matchID)
-		if err != nil {
-			log.Printf("Unable to get x01 statistics for match %d: %s", matchID, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		json.NewEncoder(w).Encode(stats)
+	stats, err := data.GetStatisticsForMatch(matchID, match.MatchType.ID)
+	if err != nil {
+		logger.Printf("Unable to get statistics for match %d: %s", matchID, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
+	json.NewEncoder(w).Encode(stats)
 }
 
 // GetMatchesModes will return all match modes
 func GetMatchesModes(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	modes, err := data.GetMatchModes()
 	if err != nil {
-		log.Println("Unable to get match modes", err)
+		logger.Println("Unable to get match modes", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -430,9 +321,10 @@ func GetMatchesModes(w http.ResponseWriter, r *http.Request) {
 // GetMatchesTypes will return all match types
 func GetMatchesTypes(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	types, err := data.GetMatchTypes()
 	if err != nil {
-		log.Println("Unable to get match types", err)
+		logger.Println("Unable to get match types", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -442,9 +334,10 @@ func GetMatchesTypes(w http.ResponseWriter, r *http.Request) {
 // GetOutshotTypes will return all outshot types
 func GetOutshotTypes(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	types, err := data.GetOutshotTypes()
 	if err != nil {
-		log.Println("Unable to get outshot types", err)
+		logger.Println("Unable to get outshot types", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
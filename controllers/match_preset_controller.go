@@ -2,11 +2,11 @@ package controllers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
 	"github.com/kcapp/api/models"
 
 	"github.com/gorilla/mux"
@@ -14,17 +14,18 @@ import (
 
 // AddPreset will create a new preset
 func AddPreset(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context())
 	var preset models.MatchPreset
 	err := json.NewDecoder(r.Body).Decode(&preset)
 	if err != nil {
-		log.Println("Unable to deserialize preset json", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize preset json", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	err = data.AddPreset(preset)
 	if err != nil {
-		log.Println("Unable to add preset", err)
+		logger.Println("Unable to add preset", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -33,9 +34,10 @@ func AddPreset(w http.ResponseWriter, r *http.Request) {
 // GetPresets will return a list of all presets
 func GetPresets(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	players, err := data.GetPresets()
 	if err != nil {
-		log.Println("Unable to get presets", err)
+		logger.Println("Unable to get presets", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -45,16 +47,17 @@ func GetPresets(w http.ResponseWriter, r *http.Request) {
 // GetPreset will return a preset with the given ID
 func GetPreset(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	preset, err := data.GetPreset(id)
 	if err != nil {
-		log.Println("Unable to get preset", err)
+		logger.Println("Unable to get preset", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -63,10 +66,11 @@ func GetPreset(w http.ResponseWriter, r *http.Request) {
 
 // UpdatePreset will update the given preset
 func UpdatePreset(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -74,14 +78,14 @@ func UpdatePreset(w http.ResponseWriter, r *http.Request) {
 	var preset models.MatchPreset
 	err = json.NewDecoder(r.Body).Decode(&preset)
 	if err != nil {
-		log.Println("Unable to deserialize preset json", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize preset json", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	err = data.UpdatePreset(id, preset)
 	if err != nil {
-		log.Println("Unable to update preset", err)
+		logger.Println("Unable to update preset", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -90,16 +94,17 @@ func UpdatePreset(w http.ResponseWriter, r *http.Request) {
 // DeletePreset will delete a preset with the given ID
 func DeletePreset(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	err = data.DeletePreset(id)
 	if err != nil {
-		log.Println("Unable to delete preset", err)
+		logger.Println("Unable to delete preset", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -2,28 +2,29 @@ package controllers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
 	"github.com/kcapp/api/models"
 )
 
 // AddOffice will create a new office
 func AddOffice(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context())
 	var office models.Office
 	err := json.NewDecoder(r.Body).Decode(&office)
 	if err != nil {
-		log.Println("Unable to deserialize office json", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize office json", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	err = data.AddOffice(office)
 	if err != nil {
-		log.Println("Unable to add office", err)
+		logger.Println("Unable to add office", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -31,10 +32,11 @@ func AddOffice(w http.ResponseWriter, r *http.Request) {
 
 // UpdateOffice will update the given office
 func UpdateOffice(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -42,14 +44,14 @@ func UpdateOffice(w http.ResponseWriter, r *http.Request) {
 	var office models.Office
 	err = json.NewDecoder(r.Body).Decode(&office)
 	if err != nil {
-		log.Println("Unable to deserialize office json", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize office json", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	err = data.UpdateOffice(id, office)
 	if err != nil {
-		log.Println("Unable to update office", err)
+		logger.Println("Unable to update office", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -58,9 +60,10 @@ func UpdateOffice(w http.ResponseWriter, r *http.Request) {
 // GetOffices will return all offices
 func GetOffices(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	offices, err := data.GetOffices()
 	if err != nil {
-		log.Println("Unable to get offices", err)
+		logger.Println("Unable to get offices", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -70,18 +73,40 @@ func GetOffices(w http.ResponseWriter, r *http.Request) {
 // GetOffice will return a office with the given ID
 func GetOffice(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	office, err := data.GetOffice(id)
 	if err != nil {
-		log.Println("Unable to get office", err)
+		logger.Println("Unable to get office", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	json.NewEncoder(w).Encode(office)
 }
+
+// GetOfficeRecords will return the all-time records of players in the given office
+func GetOfficeRecords(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	records, err := data.GetOfficeRecords(id)
+	if err != nil {
+		logger.Println("Unable to get office records", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(records)
+}
@@ -2,18 +2,19 @@ package controllers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 
 	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
 )
 
 // GetDefaultOptions will return the default options
 func GetDefaultOptions(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	offices, err := data.GetDefaultOptions()
 	if err != nil {
-		log.Println("Unable to get default options", err)
+		logger.Println("Unable to get default options", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
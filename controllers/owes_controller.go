@@ -2,19 +2,23 @@ package controllers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
+	"strconv"
 
+	"github.com/gorilla/mux"
+	"github.com/guregu/null"
 	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
 	"github.com/kcapp/api/models"
 )
 
 // GetOwes will return a list of all matches
 func GetOwes(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	owes, err := data.GetOwes()
 	if err != nil {
-		log.Println("Unable to get owes", err)
+		logger.Println("Unable to get owes", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -24,28 +28,116 @@ func GetOwes(w http.ResponseWriter, r *http.Request) {
 // RegisterPayback will register a payback between the given players
 func RegisterPayback(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	var owe models.Owe
 	err := json.NewDecoder(r.Body).Decode(&owe)
 	if err != nil {
-		log.Println("Unable to deserialize body", err)
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
+		return
+	}
+
+	err = data.RegisterPayback(r.Context(), owe)
+	if err != nil {
+		logger.Println("Unable to register payback", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetOweHistory will return the transactions in the owes ledger, optionally limited to a single player
+func GetOweHistory(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := r.URL.Query()
+	playerID := null.Int{}
+	if id := params.Get("player_id"); id != "" {
+		i, err := strconv.Atoi(id)
+		if err != nil {
+			logger.Println("Invalid player id parameter")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		playerID = null.IntFrom(int64(i))
+	}
+	limit, err := strconv.Atoi(params.Get("limit"))
+	if err != nil {
+		limit = 100
+	}
+	history, err := data.GetOweHistory(playerID, limit)
+	if err != nil {
+		logger.Println("Unable to get owe history", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(history)
+}
+
+// GetOweBalanceSheet will return the balance between the given player and all other players
+func GetOweBalanceSheet(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	playerID, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	sheet, err := data.GetOweBalanceSheet(playerID)
+	if err != nil {
+		logger.Println("Unable to get owe balance sheet", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(sheet)
+}
 
-	err = data.RegisterPayback(owe)
+// GetOweSettlement will suggest paybacks to settle owes between the given players
+func GetOweSettlement(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	ids, err := sliceAtoi(r.URL.Query()["player_id"])
+	if err != nil {
+		logger.Println("Unable to convert params to int")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	paybacks, err := data.GetOweSettlement(ids)
+	if err != nil {
+		logger.Println("Unable to get owe settlement", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(paybacks)
+}
+
+// ApplyOweSettlement will settle owes between the given players, returning the paybacks to make
+func ApplyOweSettlement(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	ids, err := sliceAtoi(r.URL.Query()["player_id"])
+	if err != nil {
+		logger.Println("Unable to convert params to int")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	paybacks, err := data.ApplyOweSettlement(r.Context(), ids)
 	if err != nil {
-		log.Println("Unable to register payback", err)
+		logger.Println("Unable to apply owe settlement", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	json.NewEncoder(w).Encode(paybacks)
 }
 
 // GetOweTypes will return all owe types
 func GetOweTypes(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	owes, err := data.GetOweTypes()
 	if err != nil {
-		log.Println("Unable to get owe types", err)
+		logger.Println("Unable to get owe types", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
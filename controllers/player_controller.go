@@ -4,12 +4,13 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
-	"log"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
 	"github.com/kcapp/api/models"
 
 	"github.com/gorilla/mux"
@@ -19,9 +20,10 @@ import (
 // GetPlayers will return a map containing all players
 func GetPlayers(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	players, err := data.GetPlayers()
 	if err != nil {
-		log.Println("Unable to get players", err)
+		logger.Println("Unable to get players", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -31,9 +33,10 @@ func GetPlayers(w http.ResponseWriter, r *http.Request) {
 // GetActivePlayers will return a map containing all active players
 func GetActivePlayers(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	players, err := data.GetActivePlayers()
 	if err != nil {
-		log.Println("Unable to get active players", err)
+		logger.Println("Unable to get active players", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -43,16 +46,17 @@ func GetActivePlayers(w http.ResponseWriter, r *http.Request) {
 // GetPlayer will return a player with the given ID
 func GetPlayer(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	player, err := data.GetPlayer(id)
 	if err != nil {
-		log.Println("Unable to get player", err)
+		logger.Println("Unable to get player", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -62,28 +66,29 @@ func GetPlayer(w http.ResponseWriter, r *http.Request) {
 // GetPlayerEloChangelog will return the elo changelog for the given player
 func GetPlayerEloChangelog(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	start, err := strconv.Atoi(params["start"])
 	if err != nil {
-		log.Println("Invalid start parameter")
+		logger.Println("Invalid start parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	limit, err := strconv.Atoi(params["limit"])
 	if err != nil {
-		log.Println("Invalid limit parameter")
+		logger.Println("Invalid limit parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	changelog, err := data.GetPlayerEloChangelog(id, start, limit)
 	if err != nil {
-		log.Println("Unable to get player elo changelog", err)
+		logger.Println("Unable to get player elo changelog", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -93,24 +98,25 @@ func GetPlayerEloChangelog(w http.ResponseWriter, r *http.Request) {
 // GetPlayerX01Statistics will return statistics for the given player
 func GetPlayerX01Statistics(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	stats, err := data.GetPlayerX01Statistics(id)
 	if err != nil {
-		log.Println("Unable to get player statistics")
+		logger.Println("Unable to get player statistics")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	visits, err := data.GetPlayerVisitCount(id)
 	if err != nil {
-		log.Println("Unable to get visits for player", err)
+		logger.Println("Unable to get visits for player", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -125,10 +131,11 @@ func GetPlayerX01Statistics(w http.ResponseWriter, r *http.Request) {
 // GetPlayerStatistics will return statistics for the given player
 func GetPlayerStatistics(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -136,7 +143,7 @@ func GetPlayerStatistics(w http.ResponseWriter, r *http.Request) {
 
 	x01, err := data.GetPlayerX01Statistics(id)
 	if err != nil {
-		log.Println("Unable to get player x01 statistics")
+		logger.Println("Unable to get player x01 statistics")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -147,18 +154,19 @@ func GetPlayerStatistics(w http.ResponseWriter, r *http.Request) {
 // GetPlayerHits will return dart hits for the given player
 func GetPlayerHits(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	var visit models.Visit
 	err = json.NewDecoder(r.Body).Decode(&visit)
 	if err != nil {
-		log.Println("Unable to deserialize body", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
 		return
 	}
 	if visit.SecondDart == nil {
@@ -167,7 +175,7 @@ func GetPlayerHits(w http.ResponseWriter, r *http.Request) {
 
 	hits, err := data.GetPlayerHits(id, visit)
 	if err != nil {
-		log.Println("Unable to get player hits")
+		logger.Println("Unable to get player hits")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -177,16 +185,17 @@ func GetPlayerHits(w http.ResponseWriter, r *http.Request) {
 // GetPlayerMatchTypeStatistics will return statistics for the given player
 func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	matchType, err := strconv.Atoi(params["match_type"])
 	if err != nil {
-		log.Println("Invalid match type parameter")
+		logger.Println("Invalid match type parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -195,7 +204,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.X01:
 		stats, err := data.GetX01StatisticsForPlayer(id, models.X01)
 		if err != nil {
-			log.Println("Unable to get X01 statistics for player", err)
+			logger.Println("Unable to get X01 statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -205,7 +214,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.SHOOTOUT:
 		stats, err := data.GetShootoutStatisticsForPlayer(id)
 		if err != nil {
-			log.Println("Unable to get Cricket statistics for player", err)
+			logger.Println("Unable to get Cricket statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -215,7 +224,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.X01HANDICAP:
 		stats, err := data.GetX01StatisticsForPlayer(id, models.X01HANDICAP)
 		if err != nil {
-			log.Println("Unable to get X01 handicap statistics for player", err)
+			logger.Println("Unable to get X01 handicap statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -225,7 +234,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.CRICKET:
 		stats, err := data.GetCricketStatisticsForPlayer(id)
 		if err != nil {
-			log.Println("Unable to get Cricket statistics for player", err)
+			logger.Println("Unable to get Cricket statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -235,7 +244,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.DARTSATX:
 		stats, err := data.GetDartsAtXStatisticsForPlayer(id)
 		if err != nil {
-			log.Println("Unable to get Darts at X statistics for player", err)
+			logger.Println("Unable to get Darts at X statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -245,7 +254,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.AROUNDTHEWORLD:
 		stats, err := data.GetAroundTheWorldStatisticsForPlayer(id)
 		if err != nil {
-			log.Println("Unable to get Around The World Statistics for player", err)
+			logger.Println("Unable to get Around The World Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -255,7 +264,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.SHANGHAI:
 		stats, err := data.GetShanghaiStatisticsForPlayer(id)
 		if err != nil {
-			log.Println("Unable to get Shanghai Statistics for player", err)
+			logger.Println("Unable to get Shanghai Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -265,7 +274,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.AROUNDTHECLOCK:
 		stats, err := data.GetAroundTheClockStatisticsForPlayer(id)
 		if err != nil {
-			log.Println("Unable to get Around the Clock Statistics for player", err)
+			logger.Println("Unable to get Around the Clock Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -275,7 +284,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.TICTACTOE:
 		stats, err := data.GetTicTacToeStatisticsForPlayer(id)
 		if err != nil {
-			log.Println("Unable to get Tic Tac Toe Statistics for player", err)
+			logger.Println("Unable to get Tic Tac Toe Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -285,7 +294,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.BERMUDATRIANGLE:
 		stats, err := data.GetBermudaTriangleStatisticsForPlayer(id)
 		if err != nil {
-			log.Println("Unable to get Bermuda Triangle Statistics for player", err)
+			logger.Println("Unable to get Bermuda Triangle Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -295,7 +304,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.FOURTWENTY:
 		stats, err := data.Get420StatisticsForPlayer(id)
 		if err != nil {
-			log.Println("Unable to get 420 Statistics for player", err)
+			logger.Println("Unable to get 420 Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -305,7 +314,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.KILLBULL:
 		stats, err := data.GetKillBullStatisticsForPlayer(id)
 		if err != nil {
-			log.Println("Unable to get Kill Bull Statistics for player", err)
+			logger.Println("Unable to get Kill Bull Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -315,7 +324,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.GOTCHA:
 		stats, err := data.GetGotchaStatisticsForPlayer(id)
 		if err != nil {
-			log.Println("Unable to get Gotcha Statistics for player", err)
+			logger.Println("Unable to get Gotcha Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -325,7 +334,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.JDCPRACTICE:
 		stats, err := data.GetJDCPracticeStatisticsForPlayer(id)
 		if err != nil {
-			log.Println("Unable to get JDC Practice Statistics for player", err)
+			logger.Println("Unable to get JDC Practice Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -335,7 +344,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.KNOCKOUT:
 		stats, err := data.GetKnockoutStatisticsForPlayer(id)
 		if err != nil {
-			log.Println("Unable to get Knockout Statistics for player", err)
+			logger.Println("Unable to get Knockout Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -345,7 +354,47 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.SCAM:
 		stats, err := data.GetScamStatisticsForPlayer(id)
 		if err != nil {
-			log.Println("Unable to get Scam Statistics for player", err)
+			logger.Println("Unable to get Scam Statistics for player", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(stats)
+		return
+
+	case models.BOBS27:
+		stats, err := data.GetBobs27StatisticsForPlayer(id)
+		if err != nil {
+			logger.Println("Unable to get Bob's 27 Statistics for player", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(stats)
+		return
+
+	case models.ONETWENTYONE:
+		stats, err := data.Get121StatisticsForPlayer(id)
+		if err != nil {
+			logger.Println("Unable to get 121 Statistics for player", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(stats)
+		return
+
+	case models.GOLF:
+		stats, err := data.GetGolfStatisticsForPlayer(id)
+		if err != nil {
+			logger.Println("Unable to get Golf Statistics for player", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(stats)
+		return
+
+	case models.BASEBALL:
+		stats, err := data.GetBaseballStatisticsForPlayer(id)
+		if err != nil {
+			logger.Println("Unable to get Baseball Statistics for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -353,7 +402,7 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 		return
 
 	default:
-		log.Println("Unknown match type parameter")
+		logger.Println("Unknown match type parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -362,22 +411,23 @@ func GetPlayerMatchTypeStatistics(w http.ResponseWriter, r *http.Request) {
 // GetPlayerMatchTypeHistory will return history of match statistics for the given player
 func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	matchType, err := strconv.Atoi(params["match_type"])
 	if err != nil {
-		log.Println("Invalid match type parameter")
+		logger.Println("Invalid match type parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	limit, err := strconv.Atoi(params["limit"])
 	if err != nil {
-		log.Println("Invalid limit parameter")
+		logger.Println("Invalid limit parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -386,7 +436,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.X01:
 		legs, err := data.GetX01HistoryForPlayer(id, 0, limit, models.X01)
 		if err != nil {
-			log.Println("Unable to get X01 history for player", err)
+			logger.Println("Unable to get X01 history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -396,7 +446,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.SHOOTOUT:
 		legs, err := data.GetShootoutHistoryForPlayer(id, 0, limit)
 		if err != nil {
-			log.Println("Unable to get Shootout history for player", err)
+			logger.Println("Unable to get Shootout history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -406,7 +456,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.X01HANDICAP:
 		legs, err := data.GetX01HistoryForPlayer(id, 0, limit, models.X01HANDICAP)
 		if err != nil {
-			log.Println("Unable to get X01 handicap history for player", err)
+			logger.Println("Unable to get X01 handicap history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -416,7 +466,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.CRICKET:
 		legs, err := data.GetCricketHistoryForPlayer(id, 0, limit)
 		if err != nil {
-			log.Println("Unable to get Cricket history for player", err)
+			logger.Println("Unable to get Cricket history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -426,7 +476,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.DARTSATX:
 		legs, err := data.GetDartsAtXHistoryForPlayer(id, 0, limit)
 		if err != nil {
-			log.Println("Unable to get Darts at X history for player", err)
+			logger.Println("Unable to get Darts at X history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -436,7 +486,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.AROUNDTHEWORLD:
 		legs, err := data.GetAroundTheWorldHistoryForPlayer(id, 0, limit)
 		if err != nil {
-			log.Println("Unable to get Around The World history for player", err)
+			logger.Println("Unable to get Around The World history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -446,7 +496,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.SHANGHAI:
 		legs, err := data.GetShanghaiHistoryForPlayer(id, 0, limit)
 		if err != nil {
-			log.Println("Unable to get Shanghai history for player", err)
+			logger.Println("Unable to get Shanghai history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -456,7 +506,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.AROUNDTHECLOCK:
 		legs, err := data.GetAroundTheClockHistoryForPlayer(id, 0, limit)
 		if err != nil {
-			log.Println("Unable to get Around the Clock history for player", err)
+			logger.Println("Unable to get Around the Clock history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -466,7 +516,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.TICTACTOE:
 		legs, err := data.GetTicTacToeHistoryForPlayer(id, 0, limit)
 		if err != nil {
-			log.Println("Unable to get Tic Tac Toe history for player", err)
+			logger.Println("Unable to get Tic Tac Toe history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -476,7 +526,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.BERMUDATRIANGLE:
 		legs, err := data.GetBermudaTriangleHistoryForPlayer(id, 0, limit)
 		if err != nil {
-			log.Println("Unable to get Bermuda Triangle history for player", err)
+			logger.Println("Unable to get Bermuda Triangle history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -486,7 +536,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.FOURTWENTY:
 		legs, err := data.Get420HistoryForPlayer(id, 0, limit)
 		if err != nil {
-			log.Println("Unable to get 420 history for player", err)
+			logger.Println("Unable to get 420 history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -496,7 +546,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.KILLBULL:
 		legs, err := data.GetKillBullHistoryForPlayer(id, 0, limit)
 		if err != nil {
-			log.Println("Unable to get Kill Bull history for player", err)
+			logger.Println("Unable to get Kill Bull history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -506,7 +556,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.GOTCHA:
 		legs, err := data.GetGotchaHistoryForPlayer(id, 0, limit)
 		if err != nil {
-			log.Println("Unable to get Gotcha history for player", err)
+			logger.Println("Unable to get Gotcha history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -515,7 +565,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.JDCPRACTICE:
 		legs, err := data.GetJDCPracticeHistoryForPlayer(id, 0, limit)
 		if err != nil {
-			log.Println("Unable to get JDC Practice history for player", err)
+			logger.Println("Unable to get JDC Practice history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -525,7 +575,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.KNOCKOUT:
 		legs, err := data.GetKnockoutHistoryForPlayer(id, 0, limit)
 		if err != nil {
-			log.Println("Unable to get Knockout history for player", err)
+			logger.Println("Unable to get Knockout history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -535,7 +585,47 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 	case models.SCAM:
 		legs, err := data.GetScamHistoryForPlayer(id, 0, limit)
 		if err != nil {
-			log.Println("Unable to get Scam history for player", err)
+			logger.Println("Unable to get Scam history for player", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(legs)
+		return
+
+	case models.BOBS27:
+		legs, err := data.GetBobs27HistoryForPlayer(id, 0, limit)
+		if err != nil {
+			logger.Println("Unable to get Bob's 27 history for player", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(legs)
+		return
+
+	case models.ONETWENTYONE:
+		legs, err := data.Get121HistoryForPlayer(id, 0, limit)
+		if err != nil {
+			logger.Println("Unable to get 121 history for player", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(legs)
+		return
+
+	case models.GOLF:
+		legs, err := data.GetGolfHistoryForPlayer(id, 0, limit)
+		if err != nil {
+			logger.Println("Unable to get Golf history for player", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(legs)
+		return
+
+	case models.BASEBALL:
+		legs, err := data.GetBaseballHistoryForPlayer(id, 0, limit)
+		if err != nil {
+			logger.Println("Unable to get Baseball history for player", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -543,7 +633,7 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 		return
 
 	default:
-		log.Println("Unknown match type parameter")
+		logger.Println("Unknown match type parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -552,17 +642,18 @@ func GetPlayerMatchTypeHistory(w http.ResponseWriter, r *http.Request) {
 // GetPlayerX01PreviousStatistics will return statistics for the given player
 func GetPlayerX01PreviousStatistics(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	stats, err := data.GetPlayerX01PreviousStatistics(id)
 	if err != nil {
-		log.Println("Unable to get player statistics")
+		logger.Println("Unable to get player statistics")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -573,6 +664,7 @@ func GetPlayerX01PreviousStatistics(w http.ResponseWriter, r *http.Request) {
 // GetPlayersX01Statistics will return statistics for the given players
 func GetPlayersX01Statistics(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := r.URL.Query()["id"]
 	if params == nil {
 		http.Error(w, "No players specified to compare", http.StatusBadRequest)
@@ -580,14 +672,14 @@ func GetPlayersX01Statistics(w http.ResponseWriter, r *http.Request) {
 	}
 	ids, err := sliceAtoi(params)
 	if err != nil {
-		log.Println("Unable to convert params to int")
+		logger.Println("Unable to convert params to int")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	stats, err := data.GetPlayersX01Statistics(ids)
 	if err != nil {
-		log.Println("Unable to get players statistics")
+		logger.Println("Unable to get players statistics")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -596,17 +688,18 @@ func GetPlayersX01Statistics(w http.ResponseWriter, r *http.Request) {
 
 // AddPlayer will create a new player
 func AddPlayer(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context())
 	var player models.Player
 	err := json.NewDecoder(r.Body).Decode(&player)
 	if err != nil {
-		log.Println("Unable to deserialize player json", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize player json", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	err = data.AddPlayer(player)
 	if err != nil {
-		log.Println("Unable to add player", err)
+		logger.Println("Unable to add player", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -614,10 +707,11 @@ func AddPlayer(w http.ResponseWriter, r *http.Request) {
 
 // UpdatePlayer will update the given player
 func UpdatePlayer(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -625,14 +719,14 @@ func UpdatePlayer(w http.ResponseWriter, r *http.Request) {
 	var player models.Player
 	err = json.NewDecoder(r.Body).Decode(&player)
 	if err != nil {
-		log.Println("Unable to deserialize player json", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize player json", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	err = data.UpdatePlayer(id, player)
 	if err != nil {
-		log.Println("Unable to update player", err)
+		logger.Println("Unable to update player", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -641,17 +735,81 @@ func UpdatePlayer(w http.ResponseWriter, r *http.Request) {
 // GetPlayerProgression will return statistics for the given player
 func GetPlayerProgression(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	stats, err := data.GetPlayerProgression(id)
 	if err != nil {
-		log.Println("Unable to get player progression", err)
+		logger.Println("Unable to get player progression", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(stats)
+}
+
+// GetPlayerTrend will return the form of the given player over the most recent legs
+func GetPlayerTrend(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	trend, err := data.GetPlayerTrend(id)
+	if err != nil {
+		logger.Println("Unable to get player trend", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(trend)
+}
+
+// GetPlayerRecords will return the personal records of the given player, and the timeline of records set
+func GetPlayerRecords(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	records, err := data.GetPlayerRecords(id)
+	if err != nil {
+		logger.Println("Unable to get player records", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(records)
+}
+
+// GetPlayerPressureStatistics will return how the given player performs depending on the situation of the match
+func GetPlayerPressureStatistics(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := data.GetPlayerPressureStatistics(id)
+	if err != nil {
+		logger.Println("Unable to get player pressure statistics", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -661,17 +819,18 @@ func GetPlayerProgression(w http.ResponseWriter, r *http.Request) {
 // GetPlayerCheckouts will return all checkouts done by a player
 func GetPlayerCheckouts(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	checkouts, err := data.GetPlayerCheckouts(id)
 	if err != nil {
-		log.Println("Unable to get player checkouts")
+		logger.Println("Unable to get player checkouts")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -682,17 +841,18 @@ func GetPlayerCheckouts(w http.ResponseWriter, r *http.Request) {
 // GetPlayerTournamentStandings will return all tournament standings for the given player
 func GetPlayerTournamentStandings(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	standings, err := data.GetPlayerTournamentStandings(id)
 	if err != nil {
-		log.Println("Unable to get player tournament standings")
+		logger.Println("Unable to get player tournament standings")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -703,17 +863,18 @@ func GetPlayerTournamentStandings(w http.ResponseWriter, r *http.Request) {
 // GetPlayerBadges returns all badges for a given player
 func GetPlayerBadges(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	badges, err := data.GetPlayerBadges(id)
 	if err != nil {
-		log.Println("Unable to get player badges")
+		logger.Println("Unable to get player badges")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -721,26 +882,49 @@ func GetPlayerBadges(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(badges)
 }
 
+// GetPlayerBadgeProgress will return the progress of the given player towards each badge
+func GetPlayerBadgeProgress(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	progress, err := data.GetPlayerBadgeProgress(id)
+	if err != nil {
+		logger.Println("Unable to get player badge progress", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(progress)
+}
+
 // GetPlayerHeadToHead will return head to head statistics between the given players
 func GetPlayerHeadToHead(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	player1, err := strconv.Atoi(params["player_1"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	player2, err := strconv.Atoi(params["player_2"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	head2head, err := data.GetPlayerHeadToHead(player1, player2)
 	if err != nil {
-		log.Println("Unable to get player head to head statistics")
+		logger.Println("Unable to get player head to head statistics")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -748,19 +932,131 @@ func GetPlayerHeadToHead(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(head2head)
 }
 
+// ExportPlayer will return all data stored for the given player, either as JSON or as a ZIP archive
+func ExportPlayer(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	export, err := data.GetPlayerExport(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "player not found", http.StatusNotFound)
+			return
+		}
+		logger.Printf("Unable to export player %d: %s", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "zip" {
+		var buf bytes.Buffer
+		err = export.WriteZip(&buf)
+		if err != nil {
+			logger.Printf("Unable to create export archive for player %d: %s", id, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"kcapp-player-%d.zip\"", id))
+		w.Write(buf.Bytes())
+		return
+	}
+	json.NewEncoder(w).Encode(export)
+}
+
+// AnonymisePlayer will remove all personal information about the given player
+func AnonymisePlayer(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	player, err := data.AnonymisePlayer(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "player not found", http.StatusNotFound)
+			return
+		}
+		logger.Printf("Unable to anonymise player %d: %s", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(player)
+}
+
+// MergePlayers will merge the duplicate player into the given player
+func MergePlayers(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	duplicateID, err := strconv.Atoi(params["duplicate_id"])
+	if err != nil {
+		logger.Println("Invalid duplicate_id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Merging cannot be undone, so it has to be explicitly requested with dry_run=false
+	dryRun := true
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			logger.Println("Invalid dry_run parameter")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := data.MergePlayers(id, duplicateID, dryRun)
+	if err != nil {
+		switch err.(type) {
+		case *models.PlayerMergeError:
+			logger.Printf("Unable to merge player %d into %d: %s", duplicateID, id, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			if err == sql.ErrNoRows {
+				http.Error(w, "player not found", http.StatusNotFound)
+				return
+			}
+			logger.Printf("Unable to merge player %d into %d: %s", duplicateID, id, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
 // SimulateMatch will return the result of a match between the two players
 func SimulateMatch(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	player1, err := strconv.Atoi(params["player_1"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	player2, err := strconv.Atoi(params["player_2"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -771,14 +1067,14 @@ func SimulateMatch(w http.ResponseWriter, r *http.Request) {
 	}
 	err = json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		log.Println("Unable to deserialize body", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	elos, err := data.GetPlayersElo(player1, player2)
 	if err != nil {
-		log.Println("Unable to get player elos")
+		logger.Println("Unable to get player elos")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -799,6 +1095,7 @@ func SimulateMatch(w http.ResponseWriter, r *http.Request) {
 // GetPlayerCalendar will return a calendar feed for all official matches for the given player
 func GetPlayerCalendar(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	w.Header().Set("Content-type", "text/calendar")
 	w.Header().Set("charset", "utf-8")
 	w.Header().Set("Content-Disposition", "inline")
@@ -807,20 +1104,20 @@ func GetPlayerCalendar(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	matches, err := data.GetPlayerOfficialMatches(id)
 	if err != nil {
-		log.Println("Unable to get official matches for player", err)
+		logger.Println("Unable to get official matches for player", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	players, err := data.GetPlayers()
 	if err != nil {
-		log.Println("Unable to get players", err)
+		logger.Println("Unable to get players", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -857,17 +1154,18 @@ func GetPlayerCalendar(w http.ResponseWriter, r *http.Request) {
 // GetRandomLegForPlayer will return a random leg for a given player and starting score
 func GetRandomLegForPlayer(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	startingScore, err := strconv.Atoi(params["starting_score"])
 	if err != nil {
-		log.Println("Invalid starting score parameter")
+		logger.Println("Invalid starting score parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -878,7 +1176,7 @@ func GetRandomLegForPlayer(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Not enough data for player", http.StatusBadRequest)
 			return
 		}
-		log.Println("Unable to get official matches for player", err)
+		logger.Println("Unable to get official matches for player", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
+)
+
+// GetReport will return the report with the given id, rendered to the format given by the format query parameter
+func GetReport(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	report, err := data.GetReport(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "report not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		logger.Println("Unable to get report", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	body, contentType, err := report.Render(r.URL.Query().Get("format"))
+	if err != nil {
+		logger.Println("Unable to render report", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write([]byte(body))
+}
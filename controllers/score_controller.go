@@ -2,35 +2,36 @@ package controllers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
 	"github.com/kcapp/api/models"
 )
 
 // AddVisit will add the visit to the database
 func AddVisit(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	var visit models.Visit
 	err := json.NewDecoder(r.Body).Decode(&visit)
 	if err != nil {
-		log.Println("Unable to deserialize body", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
 		return
 	}
 	err = visit.ValidateInput()
 	if err != nil {
-		log.Println("Invalid visit", err)
+		logger.Println("Invalid visit", err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	insertedVisit, err := data.AddVisit(visit)
+	insertedVisit, err := data.AddVisit(r.Context(), visit)
 	if err != nil {
-		log.Printf(`[%d] Unable to add visit (%s)`, visit.LegID, err)
+		logger.Printf(`[%d] Unable to add visit (%s)`, visit.LegID, err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -40,17 +41,18 @@ func AddVisit(w http.ResponseWriter, r *http.Request) {
 // ModifyVisit will modify the scores of the given visit
 func ModifyVisit(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	var visit models.Visit
 	err := json.NewDecoder(r.Body).Decode(&visit)
 	if err != nil {
-		log.Println("Unable to deserialize body", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
-	err = data.ModifyVisit(visit)
+	err = data.ModifyVisit(r.Context(), visit)
 	if err != nil {
-		log.Println("Unable to modify visit", err)
+		logger.Println("Unable to modify visit", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -59,16 +61,17 @@ func ModifyVisit(w http.ResponseWriter, r *http.Request) {
 // DeleteVisit will delete the given visit
 func DeleteVisit(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	err = data.DeleteVisit(id)
+	err = data.DeleteVisit(r.Context(), id)
 	if err != nil {
-		log.Println("Unable to delete visit: ", err)
+		logger.Println("Unable to delete visit: ", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -77,16 +80,17 @@ func DeleteVisit(w http.ResponseWriter, r *http.Request) {
 // DeleteLastVisit will delete the last visit for a given leg
 func DeleteLastVisit(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	legID, err := strconv.Atoi(params["leg_id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	err = data.DeleteLastVisit(legID)
+	err = data.DeleteLastVisit(r.Context(), legID)
 	if err != nil {
-		log.Println("Unable to delete visit: ", err)
+		logger.Println("Unable to delete visit: ", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -2,22 +2,23 @@ package controllers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
 	"github.com/kcapp/api/models"
 )
 
 // GetStatistics will return statistics for the given match type
 func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	matchType, err := strconv.Atoi(params["match_type"])
 	if err != nil {
-		log.Println("Invalid match type parameter")
+		logger.Println("Invalid match type parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -26,7 +27,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.X01:
 		statistics, err := data.GetX01Statistics(params["from"], params["to"], matchType, 301, 501)
 		if err != nil {
-			log.Println("Unable to get X01 statistics", err)
+			logger.Println("Unable to get X01 statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -36,7 +37,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.SHOOTOUT:
 		stats, err := data.GetShootoutStatistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get Shootout statistics", err)
+			logger.Println("Unable to get Shootout statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -46,7 +47,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.X01HANDICAP:
 		statistics, err := data.GetX01Statistics(params["from"], params["to"], matchType, 301, 501)
 		if err != nil {
-			log.Println("Unable to get X01 handicap statistics", err)
+			logger.Println("Unable to get X01 handicap statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -56,7 +57,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.CRICKET:
 		stats, err := data.GetCricketStatistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get Cricket statistics", err)
+			logger.Println("Unable to get Cricket statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -66,7 +67,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.DARTSATX:
 		stats, err := data.GetDartsAtXStatistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get Darts At X statistics", err)
+			logger.Println("Unable to get Darts At X statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -76,7 +77,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.AROUNDTHEWORLD:
 		stats, err := data.GetAroundTheWorldStatistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get Around The World statistics", err)
+			logger.Println("Unable to get Around The World statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -86,7 +87,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.SHANGHAI:
 		stats, err := data.GetShanghaiStatistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get Shanghai statistics", err)
+			logger.Println("Unable to get Shanghai statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -96,7 +97,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.AROUNDTHECLOCK:
 		stats, err := data.GetAroundTheClockStatistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get Around The Clock statistics", err)
+			logger.Println("Unable to get Around The Clock statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -106,7 +107,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.TICTACTOE:
 		stats, err := data.GetTicTacToeStatistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get Tic Tac Toe statistics", err)
+			logger.Println("Unable to get Tic Tac Toe statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -116,7 +117,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.BERMUDATRIANGLE:
 		stats, err := data.GetBermudaTriangleStatistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get Bermuda Triangle statistics", err)
+			logger.Println("Unable to get Bermuda Triangle statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -126,7 +127,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.FOURTWENTY:
 		stats, err := data.Get420Statistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get 420 Statistics", err)
+			logger.Println("Unable to get 420 Statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -136,7 +137,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.KILLBULL:
 		stats, err := data.GetKillBullStatistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get Kill Bull Statistics", err)
+			logger.Println("Unable to get Kill Bull Statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -146,7 +147,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.GOTCHA:
 		stats, err := data.GetGotchaStatistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get Gotcha Statistics", err)
+			logger.Println("Unable to get Gotcha Statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -156,7 +157,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.JDCPRACTICE:
 		stats, err := data.GetJDCPracticeStatistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get JDC Practice Statistics", err)
+			logger.Println("Unable to get JDC Practice Statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -166,7 +167,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.KNOCKOUT:
 		stats, err := data.GetKnockoutStatistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get Knockout Statistics", err)
+			logger.Println("Unable to get Knockout Statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -176,7 +177,7 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.SCAM:
 		stats, err := data.GetScamStatistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get Scam Statistics", err)
+			logger.Println("Unable to get Scam Statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -185,39 +186,79 @@ func GetStatistics(w http.ResponseWriter, r *http.Request) {
 	case models.ONESEVENTY:
 		stats, err := data.Get170Statistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get 170 Statistics", err)
+			logger.Println("Unable to get 170 Statistics", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(stats)
+		return
+	case models.BOBS27:
+		stats, err := data.GetBobs27Statistics(params["from"], params["to"])
+		if err != nil {
+			logger.Println("Unable to get Bob's 27 Statistics", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(stats)
+		return
+	case models.ONETWENTYONE:
+		stats, err := data.Get121Statistics(params["from"], params["to"])
+		if err != nil {
+			logger.Println("Unable to get 121 Statistics", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(stats)
+		return
+	case models.GOLF:
+		stats, err := data.GetGolfStatistics(params["from"], params["to"])
+		if err != nil {
+			logger.Println("Unable to get Golf Statistics", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(stats)
+		return
+	case models.BASEBALL:
+		stats, err := data.GetBaseballStatistics(params["from"], params["to"])
+		if err != nil {
+			logger.Println("Unable to get Baseball Statistics", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		json.NewEncoder(w).Encode(stats)
 		return
 	default:
-		log.Println("Unknown match type parameter")
+		logger.Println("Unknown match type parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 }
 
-// GetGlobalStatistics will return some global statistics for all matches
+// GetGlobalStatistics will return some global statistics for all matches, optionally within the given period
 func GetGlobalStatistics(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
 	SetHeaders(w)
 
-	global, err := data.GetGlobalStatistics()
+	global, err := data.GetGlobalStatistics(params["from"], params["to"])
 	if err != nil {
-		log.Println("Unable to get global statistics", err)
+		logger.Println("Unable to get global statistics", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	json.NewEncoder(w).Encode(global)
 }
 
-// GetGlobalStatisticsFnc will return global fish and chips counter
+// GetGlobalStatisticsFnc will return global fish and chips counter, optionally within the given period
 func GetGlobalStatisticsFnc(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
 	SetHeaders(w)
 
-	global, err := data.GetGlobalStatisticsFnc()
+	global, err := data.GetGlobalStatisticsFnc(params["from"], params["to"])
 	if err != nil {
-		log.Println("Unable to get global fish and chips statistics", err)
+		logger.Println("Unable to get global fish and chips statistics", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -226,6 +267,7 @@ func GetGlobalStatisticsFnc(w http.ResponseWriter, r *http.Request) {
 
 // GetOfficeStatistics will return statistics for the given office
 func GetOfficeStatistics(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	SetHeaders(w)
 
@@ -233,7 +275,7 @@ func GetOfficeStatistics(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		statistics, err := data.GetOfficeStatistics(params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get statistics for office", err)
+			logger.Println("Unable to get statistics for office", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -241,7 +283,7 @@ func GetOfficeStatistics(w http.ResponseWriter, r *http.Request) {
 	} else {
 		statistics, err := data.GetOfficeStatisticsForOffice(id, params["from"], params["to"])
 		if err != nil {
-			log.Println("Unable to get statistics for office", err)
+			logger.Println("Unable to get statistics for office", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -251,18 +293,19 @@ func GetOfficeStatistics(w http.ResponseWriter, r *http.Request) {
 
 // GetDartStatistics will return dart statistics for all players
 func GetDartStatistics(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	SetHeaders(w)
 
 	dart, err := strconv.Atoi(params["dart"])
 	if err != nil {
-		log.Println("Unable to get dart", err)
+		logger.Println("Unable to get dart", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	statistics, err := data.GetDartStatistics(dart)
 	if err != nil {
-		log.Println("Unable to get dart statistics", err)
+		logger.Println("Unable to get dart statistics", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -272,10 +315,11 @@ func GetDartStatistics(w http.ResponseWriter, r *http.Request) {
 // GetPlayersLastXLegsStatistics will return statistics for the last X legs played by all players
 func GetPlayersLastXLegsStatistics(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 
 	global, err := data.GetPlayersLastXLegsStatistics()
 	if err != nil {
-		log.Println("Unable to get last x legs statistics", err)
+		logger.Println("Unable to get last x legs statistics", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
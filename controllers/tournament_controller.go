@@ -2,7 +2,6 @@ package controllers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"sort"
 	"strconv"
@@ -11,15 +10,17 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/guregu/null"
 	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
 	"github.com/kcapp/api/models"
 )
 
 // GetTournaments will return all tournaments
 func GetTournaments(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	tournaments, err := data.GetTournaments()
 	if err != nil {
-		log.Println("Unable to get tournaments", err)
+		logger.Println("Unable to get tournaments", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -28,17 +29,18 @@ func GetTournaments(w http.ResponseWriter, r *http.Request) {
 
 // AddTournamentGroup will add a new tournament group
 func AddTournamentGroup(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context())
 	var group models.TournamentGroup
 	err := json.NewDecoder(r.Body).Decode(&group)
 	if err != nil {
-		log.Println("Unable to deserialize group json", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize group json", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	err = data.AddTournamentGroup(group)
 	if err != nil {
-		log.Println("Unable to add group", err)
+		logger.Println("Unable to add group", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -47,9 +49,10 @@ func AddTournamentGroup(w http.ResponseWriter, r *http.Request) {
 // GetTournamentGroups will return all tournaments
 func GetTournamentGroups(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	groups, err := data.GetTournamentGroups()
 	if err != nil {
-		log.Println("Unable to get tournament groups", err)
+		logger.Println("Unable to get tournament groups", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -59,16 +62,17 @@ func GetTournamentGroups(w http.ResponseWriter, r *http.Request) {
 // GetTournament will return the given tournament
 func GetTournament(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	tournament, err := data.GetTournament(id)
 	if err != nil {
-		log.Println("Unable to get tournament", err)
+		logger.Println("Unable to get tournament", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -78,9 +82,10 @@ func GetTournament(w http.ResponseWriter, r *http.Request) {
 // GetCurrentTournament will return the current active tournament
 func GetCurrentTournament(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	tournament, err := data.GetCurrentTournament()
 	if err != nil {
-		log.Println("Unable to get tournament", err)
+		logger.Println("Unable to get tournament", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -94,16 +99,17 @@ func GetCurrentTournament(w http.ResponseWriter, r *http.Request) {
 // GetCurrentTournamentForOffice will return the current active tournament for a given office
 func GetCurrentTournamentForOffice(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	officeID, err := strconv.Atoi(params["office_id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	tournament, err := data.GetCurrentTournamentForOffice(officeID)
 	if err != nil {
-		log.Println("Unable to get tournament for office", err)
+		logger.Println("Unable to get tournament for office", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -117,16 +123,17 @@ func GetCurrentTournamentForOffice(w http.ResponseWriter, r *http.Request) {
 // GetTournamentsForOffice will return all tournaments for given office
 func GetTournamentsForOffice(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	officeID, err := strconv.Atoi(params["office_id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	tournament, err := data.GetTournamentsForOffice(officeID)
 	if err != nil {
-		log.Println("Unable to get tournaments for office", err)
+		logger.Println("Unable to get tournaments for office", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -139,16 +146,17 @@ func GetTournamentsForOffice(w http.ResponseWriter, r *http.Request) {
 
 func GetTournamentProbabilities(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	prob, err := data.GetTournamentProbabilities(id)
 	if err != nil {
-		log.Println("Unable to get tournament probabilities", err)
+		logger.Println("Unable to get tournament probabilities", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -158,16 +166,17 @@ func GetTournamentProbabilities(w http.ResponseWriter, r *http.Request) {
 // GetTournamentMatches will return all matches for the given tournament
 func GetTournamentMatches(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	matches, err := data.GetTournamentMatches(id)
 	if err != nil {
-		log.Println("Unable to get tournament matches", err)
+		logger.Println("Unable to get tournament matches", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -177,22 +186,23 @@ func GetTournamentMatches(w http.ResponseWriter, r *http.Request) {
 // GetTournamentMatchResults will reurn match results for the given ID
 func GetTournamentMatchResults(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	tournamentMatches, err := data.GetTournamentMatches(id)
 	if err != nil {
-		log.Println("Unable to get tournament matches", err)
+		logger.Println("Unable to get tournament matches", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	players, err := data.GetPlayers()
 	if err != nil {
-		log.Println("Unable to get players: ", err)
+		logger.Println("Unable to get players: ", err)
 		http.Error(w, "Unable to get players", http.StatusBadRequest)
 		return
 	}
@@ -270,16 +280,17 @@ func GetTournamentMatchResults(w http.ResponseWriter, r *http.Request) {
 // GetTournamentOverview will return statistics for the given tournament
 func GetTournamentOverview(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	stats, err := data.GetTournamentOverview(id)
 	if err != nil {
-		log.Println("Unable to get tournament overview", err)
+		logger.Println("Unable to get tournament overview", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -289,16 +300,37 @@ func GetTournamentOverview(w http.ResponseWriter, r *http.Request) {
 // GetTournamentStatistics will return statistics for the given tournament
 func GetTournamentStatistics(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	stats, err := data.GetTournamentStatistics(id)
 	if err != nil {
-		log.Println("Unable to get tournament statistics", err)
+		logger.Println("Unable to get tournament statistics", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(stats)
+}
+
+// GetTournamentPressureStatistics will return how each player performs depending on the situation of the match
+func GetTournamentPressureStatistics(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	stats, err := data.GetTournamentPressureStatistics(id)
+	if err != nil {
+		logger.Println("Unable to get tournament pressure statistics", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -308,16 +340,17 @@ func GetTournamentStatistics(w http.ResponseWriter, r *http.Request) {
 // GetNextTournamentMatch will return the next tournament match
 func GetNextTournamentMatch(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	match, err := data.GetNextTournamentMatch(id)
 	if err != nil {
-		log.Println("Unable to get next tournament match", err)
+		logger.Println("Unable to get next tournament match", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -331,9 +364,10 @@ func GetNextTournamentMatch(w http.ResponseWriter, r *http.Request) {
 // GetTournamentStandings will return statistics for the given tournament
 func GetTournamentStandings(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	stats, err := data.GetTournamentStandings()
 	if err != nil {
-		log.Println("Unable to get tournament standings", err)
+		logger.Println("Unable to get tournament standings", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -343,17 +377,18 @@ func GetTournamentStandings(w http.ResponseWriter, r *http.Request) {
 // NewTournament will create a new tournament
 func NewTournament(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	var tournamentInput models.Tournament
 	err := json.NewDecoder(r.Body).Decode(&tournamentInput)
 	if err != nil {
-		log.Println("Unable to deserialize body", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	tournament, err := data.NewTournament(tournamentInput)
 	if err != nil {
-		log.Println("Unable to create new tournament", err)
+		logger.Println("Unable to create new tournament", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -363,17 +398,18 @@ func NewTournament(w http.ResponseWriter, r *http.Request) {
 // GenerateTournament will generate a new tournament
 func GenerateTournament(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	var input models.GenerateTournamentInput
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		log.Println("Unable to deserialize body", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
-	tournament, err := data.GenerateTournament(input)
+	tournament, err := data.GenerateTournament(r.Context(), input)
 	if err != nil {
-		log.Println("Unable to create new tournament", err)
+		logger.Println("Unable to create new tournament", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -384,10 +420,11 @@ func GenerateTournament(w http.ResponseWriter, r *http.Request) {
 // GeneratePlayoffsTournament will generate a new playoffs tournament
 func GeneratePlayoffsTournament(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -395,14 +432,14 @@ func GeneratePlayoffsTournament(w http.ResponseWriter, r *http.Request) {
 	var input models.GeneratePlayoffsInput
 	err = json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		log.Println("Unable to deserialize body", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
-	tournament, err := data.GeneratePlayoffsTournament(id, input)
+	tournament, err := data.GeneratePlayoffsTournament(r.Context(), id, input)
 	if err != nil {
-		log.Println("Unable to create new tournament", err)
+		logger.Println("Unable to create new tournament", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -413,23 +450,24 @@ func GeneratePlayoffsTournament(w http.ResponseWriter, r *http.Request) {
 // GetTournamentPlayerMatches will return all matches for the given tournament and player
 func GetTournamentPlayerMatches(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	playerID, err := strconv.Atoi(params["player_id"])
 	if err != nil {
-		log.Println("Invalid player id parameter")
+		logger.Println("Invalid player id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	matches, err := data.GetTournamentMatchesForPlayer(id, playerID)
 	if err != nil {
-		log.Println("Unable to get official matches for player", err)
+		logger.Println("Unable to get official matches for player", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -439,25 +477,26 @@ func GetTournamentPlayerMatches(w http.ResponseWriter, r *http.Request) {
 // AddPlayerToTournament will add the given player to the tournament
 func AddPlayerToTournament(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	var input models.Player2Tournament
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		log.Println("Unable to deserialize body", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	matches, err := data.AddPlayerToTournament(input.PlayerID, input.TournamentGroupID, id)
+	matches, err := data.AddPlayerToTournament(r.Context(), input.PlayerID, input.TournamentGroupID, id)
 	if err != nil {
-		log.Println("Unable to add player to tournament", err)
+		logger.Println("Unable to add player to tournament", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
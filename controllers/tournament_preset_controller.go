@@ -2,12 +2,12 @@ package controllers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
 )
 
 /*
@@ -17,7 +17,7 @@ func AddTournamentPreset(w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&preset)
 	if err != nil {
 		log.Println("Unable to deserialize preset json", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		WriteDecodeError(w, err)
 		return
 	}
 
@@ -32,9 +32,10 @@ func AddTournamentPreset(w http.ResponseWriter, r *http.Request) {
 // GetTournamentPresets will return a list of all presets
 func GetTournamentPresets(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	players, err := data.GetTournamentPresets()
 	if err != nil {
-		log.Println("Unable to get presets", err)
+		logger.Println("Unable to get presets", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -44,16 +45,17 @@ func GetTournamentPresets(w http.ResponseWriter, r *http.Request) {
 // GetTournamentPreset will return a preset with the given ID
 func GetTournamentPreset(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	preset, err := data.GetTournamentPreset(id)
 	if err != nil {
-		log.Println("Unable to get preset", err)
+		logger.Println("Unable to get preset", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -75,7 +77,7 @@ func UpdateTournamentPreset(w http.ResponseWriter, r *http.Request) {
 	err = json.NewDecoder(r.Body).Decode(&preset)
 	if err != nil {
 		log.Println("Unable to deserialize preset json", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		WriteDecodeError(w, err)
 		return
 	}
 
@@ -0,0 +1,29 @@
+package controllers_v2
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	data_v2 "github.com/kcapp/api/data/v2"
+	"github.com/kcapp/api/logging"
+)
+
+// GetBadgeUnlocks will return a page of unlocks of the given badge
+func GetBadgeUnlocks(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opts, ok := parseListOptions(w, r, "-created_at")
+	if !ok {
+		return
+	}
+	page, err := data_v2.GetBadgeUnlocks(id, opts)
+	writePage(w, r, page, err, "Unable to get badge unlocks")
+}
@@ -1,6 +1,13 @@
 package controllers_v2
 
-import "net/http"
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/logging"
+	"github.com/kcapp/api/models"
+)
 
 // SetHeaders will set the default headers used by all requests
 func SetHeaders(w http.ResponseWriter) {
@@ -9,3 +16,39 @@ func SetHeaders(w http.ResponseWriter) {
 	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
 	w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
 }
+
+// parseListOptions will parse the pagination, sort and filter options of the request, writing a 400 Bad Request if invalid
+func parseListOptions(w http.ResponseWriter, r *http.Request, defaultSort string) (*models.ListOptions, bool) {
+	logger := logging.Logger(r.Context())
+	opts, err := models.ParseListOptions(r.URL.Query(), defaultSort)
+	if err != nil {
+		logger.Println("Invalid list options", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return opts, true
+}
+
+// writePage will write the given page, with a link to the next page if there is one
+func writePage(w http.ResponseWriter, r *http.Request, page *models.Page, err error, message string) {
+	logger := logging.Logger(r.Context())
+	if err != nil {
+		switch err.(type) {
+		case *models.ListOptionsError:
+			logger.Println(message, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			logger.Println(message, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if page.NextCursor.Valid {
+		next := *r.URL
+		query := next.Query()
+		query.Set("cursor", page.NextCursor.String)
+		next.RawQuery = query.Encode()
+		page.Next = null.StringFrom(next.RequestURI())
+	}
+	json.NewEncoder(w).Encode(page)
+}
@@ -0,0 +1,18 @@
+package controllers_v2
+
+import (
+	"net/http"
+
+	data_v2 "github.com/kcapp/api/data/v2"
+)
+
+// GetLegs will return a page of legs
+func GetLegs(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	opts, ok := parseListOptions(w, r, "-created_at")
+	if !ok {
+		return
+	}
+	page, err := data_v2.GetLegs(opts)
+	writePage(w, r, page, err, "Unable to get legs")
+}
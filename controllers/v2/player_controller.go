@@ -1,21 +1,40 @@
 package controllers_v2
 
 import (
-	"encoding/json"
-	"log"
 	"net/http"
+	"strconv"
 
+	"github.com/gorilla/mux"
 	data_v2 "github.com/kcapp/api/data/v2"
+	"github.com/kcapp/api/logging"
 )
 
-// GetPlayers will return a map containing all players
+// GetPlayers will return a page of players
 func GetPlayers(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
-	players, err := data_v2.GetPlayers()
+	opts, ok := parseListOptions(w, r, "name")
+	if !ok {
+		return
+	}
+	page, err := data_v2.GetPlayersPage(opts)
+	writePage(w, r, page, err, "Unable to get players")
+}
+
+// GetPlayerEloChangelog will return a page of the Elo changelog for the given player
+func GetPlayerEloChangelog(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Unable to get players", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opts, ok := parseListOptions(w, r, "-id")
+	if !ok {
 		return
 	}
-	json.NewEncoder(w).Encode(players)
+	page, err := data_v2.GetPlayerEloChangelog(id, opts)
+	writePage(w, r, page, err, "Unable to get Elo changelog")
 }
@@ -0,0 +1,18 @@
+package controllers_v2
+
+import (
+	"net/http"
+
+	data_v2 "github.com/kcapp/api/data/v2"
+)
+
+// GetTournaments will return a page of tournaments
+func GetTournaments(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	opts, ok := parseListOptions(w, r, "-start_time")
+	if !ok {
+		return
+	}
+	page, err := data_v2.GetTournaments(opts)
+	writePage(w, r, page, err, "Unable to get tournaments")
+}
@@ -2,28 +2,29 @@ package controllers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
 	"github.com/kcapp/api/models"
 )
 
 // AddVenue will create a new venue
 func AddVenue(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context())
 	var venue models.Venue
 	err := json.NewDecoder(r.Body).Decode(&venue)
 	if err != nil {
-		log.Println("Unable to deserialize venue json", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize venue json", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	err = data.AddVenue(venue)
 	if err != nil {
-		log.Println("Unable to add venue", err)
+		logger.Println("Unable to add venue", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -31,10 +32,11 @@ func AddVenue(w http.ResponseWriter, r *http.Request) {
 
 // UpdateVenue will update the given venue
 func UpdateVenue(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -42,14 +44,14 @@ func UpdateVenue(w http.ResponseWriter, r *http.Request) {
 	var venue models.Venue
 	err = json.NewDecoder(r.Body).Decode(&venue)
 	if err != nil {
-		log.Println("Unable to deserialize venue json", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		logger.Println("Unable to deserialize venue json", err)
+		WriteDecodeError(w, err)
 		return
 	}
 
 	err = data.UpdateVenue(id, venue)
 	if err != nil {
-		log.Println("Unable to update venue", err)
+		logger.Println("Unable to update venue", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -58,9 +60,10 @@ func UpdateVenue(w http.ResponseWriter, r *http.Request) {
 // GetVenues will return all venues
 func GetVenues(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	venues, err := data.GetVenues()
 	if err != nil {
-		log.Println("Unable to get venues", err)
+		logger.Println("Unable to get venues", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -70,16 +73,17 @@ func GetVenues(w http.ResponseWriter, r *http.Request) {
 // GetVenue will return the given venue
 func GetVenue(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	venue, err := data.GetVenue(id)
 	if err != nil {
-		log.Println("Unable to get venue", err)
+		logger.Println("Unable to get venue", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -89,16 +93,17 @@ func GetVenue(w http.ResponseWriter, r *http.Request) {
 // GetVenueConfiguration will return the configuration for the given venue
 func GetVenueConfiguration(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	config, err := data.GetVenueConfiguration(id)
 	if err != nil {
-		log.Println("Unable to get venue configuration", err)
+		logger.Println("Unable to get venue configuration", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -108,16 +113,17 @@ func GetVenueConfiguration(w http.ResponseWriter, r *http.Request) {
 // SpectateVenue will spectate the current match active at a given venue
 func SpectateVenue(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	matches, err := data.SpectateVenue(id)
 	if err != nil {
-		log.Println("Unable to spectate venue", err)
+		logger.Println("Unable to spectate venue", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -127,16 +133,17 @@ func SpectateVenue(w http.ResponseWriter, r *http.Request) {
 // GetRecentPlayers will get all players who recently played at agiven venue
 func GetRecentPlayers(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	players, err := data.GetRecentPlayers(id)
 	if err != nil {
-		log.Println("Unable to get recent players at venue", err)
+		logger.Println("Unable to get recent players at venue", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -146,16 +153,17 @@ func GetRecentPlayers(w http.ResponseWriter, r *http.Request) {
 // GetActiveVenueMatches will return a list of active matches
 func GetActiveVenueMatches(w http.ResponseWriter, r *http.Request) {
 	SetHeaders(w)
+	logger := logging.Logger(r.Context())
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		log.Println("Invalid id parameter")
+		logger.Println("Invalid id parameter")
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	matches, err := data.GetActiveVenueMatches(id)
 	if err != nil {
-		log.Println("Unable to get active matches", err)
+		logger.Println("Unable to get active matches", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
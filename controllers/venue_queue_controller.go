@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/logging"
+	"github.com/kcapp/api/models"
+)
+
+// venueQueueRefreshInterval is how often subscribers get an updated queue, so wait estimates stay current
+const venueQueueRefreshInterval = 15 * time.Second
+
+// GetVenueQueue will return what is playing now, and up next at the given venue
+func GetVenueQueue(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	queue, err := data.GetVenueQueue(id)
+	if err != nil {
+		logger.Println("Unable to get venue queue", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(queue)
+}
+
+// EnqueueVenueMatch will add a match to the queue of the given venue, or the next free venue
+func EnqueueVenueMatch(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	var input models.VenueQueueInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
+		return
+	}
+	queue, err := data.EnqueueVenueMatch(input)
+	if err != nil {
+		writeVenueQueueError(w, r, "Unable to queue match", err)
+		return
+	}
+	json.NewEncoder(w).Encode(queue)
+}
+
+// ReorderVenueQueue will change the order of the queue of the given venue
+func ReorderVenueQueue(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var order models.VenueQueueOrder
+	err = json.NewDecoder(r.Body).Decode(&order)
+	if err != nil {
+		logger.Println("Unable to deserialize body", err)
+		WriteDecodeError(w, err)
+		return
+	}
+	queue, err := data.ReorderVenueQueue(id, order)
+	if err != nil {
+		writeVenueQueueError(w, r, "Unable to reorder venue queue", err)
+		return
+	}
+	json.NewEncoder(w).Encode(queue)
+}
+
+// DequeueVenueMatch will remove a match from the queue of the given venue
+func DequeueVenueMatch(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	matchID, err := strconv.Atoi(params["match_id"])
+	if err != nil {
+		logger.Println("Invalid match id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = data.DequeueVenueMatch(id, matchID)
+	if err != nil {
+		writeVenueQueueError(w, r, "Unable to remove match from venue queue", err)
+		return
+	}
+}
+
+// SubscribeVenueQueue will stream the queue of the given venue as Server-Sent Events, sending the full queue every
+// time it changes
+func SubscribeVenueQueue(w http.ResponseWriter, r *http.Request) {
+	SetHeaders(w)
+	logger := logging.Logger(r.Context())
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		logger.Println("Invalid id parameter")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Streams outlive the write timeout of the server
+	rc := http.NewResponseController(w)
+	if err = rc.SetWriteDeadline(time.Time{}); err != nil {
+		logger.Println("Unable to stream venue queue", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	updates, unsubscribe := data.SubscribeVenueQueue(id)
+	defer unsubscribe()
+	ticker := time.NewTicker(venueQueueRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		queue, err := data.GetVenueQueue(id)
+		if err != nil {
+			logger.Println("Unable to get venue queue", err)
+			return
+		}
+		b, err := json.Marshal(queue)
+		if err != nil {
+			logger.Println("Unable to serialize venue queue", err)
+			return
+		}
+		_, err = fmt.Fprintf(w, "event: queue\ndata: %s\n\n", b)
+		if err != nil {
+			return
+		}
+		rc.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case _, ok := <-updates:
+			if !ok {
+				return
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeVenueQueueError will write 400 Bad Request for invalid queue operations, and 500 for all other errors
+func writeVenueQueueError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	logger := logging.Logger(r.Context())
+	switch t := err.(type) {
+	default:
+		logger.Println(message, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	case *models.VenueQueueError:
+		logger.Println(message, t)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
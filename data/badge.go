@@ -1,12 +1,16 @@
 package data
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/guregu/null"
+	"github.com/kcapp/api/cache"
+	"github.com/kcapp/api/logging"
 	"github.com/kcapp/api/models"
 )
 
@@ -188,34 +192,40 @@ func GetBadgeStatistics(badgeID int) ([]*models.PlayerBadge, error) {
 	return badges, nil
 }
 
-func CheckLegForBadges(leg *models.Leg, statistics map[int]*models.PlayerBadgeStatistics) error {
-	tx, err := models.DB.Begin()
+func CheckLegForBadges(ctx context.Context, leg *models.Leg, statistics map[int]*models.PlayerBadgeStatistics) error {
+	awards, err := getLegBadgeAwards(ctx, leg, statistics)
 	if err != nil {
 		return err
 	}
-	playersMap, err := GetPlayersScore(leg.ID)
+	err = addBadgeAwards(ctx, awards)
 	if err != nil {
 		return err
 	}
+	cache.Invalidate(cache.TagBadges)
+
+	return nil
+}
+
+// getLegBadgeAwards will return all badges unlocked in the given leg
+func getLegBadgeAwards(ctx context.Context, leg *models.Leg, statistics map[int]*models.PlayerBadgeStatistics) ([]*models.BadgeAward, error) {
+	playersMap, err := GetPlayersScore(leg.ID)
+	if err != nil {
+		return nil, err
+	}
 	players := make([]*models.Player2Leg, 0, len(playersMap))
 	for _, value := range playersMap {
 		players = append(players, value)
 	}
 
+	awards := make([]*models.BadgeAward, 0)
 	for _, badge := range models.LegBadges {
 		valid, playerID, visitID := badge.Validate(leg)
 		if valid {
 			if playerID != nil {
-				err = addBadge(tx, badge.GetID(), nil, nil, *playerID, nil, &leg.ID, visitID, nil, leg.UpdatedAt)
-				if err != nil {
-					return err
-				}
+				awards = append(awards, newLegBadgeAward(badge.GetID(), *playerID, leg, visitID))
 			} else {
 				for _, playerID := range leg.Players {
-					err = addBadge(tx, badge.GetID(), nil, nil, playerID, nil, &leg.ID, visitID, nil, leg.UpdatedAt)
-					if err != nil {
-						return err
-					}
+					awards = append(awards, newLegBadgeAward(badge.GetID(), playerID, leg, visitID))
 				}
 			}
 		}
@@ -224,10 +234,7 @@ func CheckLegForBadges(leg *models.Leg, statistics map[int]*models.PlayerBadgeSt
 	for _, badge := range models.LegPlayerBadges {
 		valid, playerID := badge.Validate(leg, players)
 		if valid {
-			err = addBadge(tx, badge.GetID(), nil, nil, *playerID, nil, &leg.ID, nil, nil, leg.UpdatedAt)
-			if err != nil {
-				return err
-			}
+			awards = append(awards, newLegBadgeAward(badge.GetID(), *playerID, leg, nil))
 		}
 	}
 
@@ -236,10 +243,10 @@ func CheckLegForBadges(leg *models.Leg, statistics map[int]*models.PlayerBadgeSt
 			stats := statistics[playerID]
 			valid, level, visitID := badge.Validate(stats, leg.Visits)
 			if valid {
-				err = addBadge(tx, badge.GetID(), level, badge.Levels(), playerID, nil, &leg.ID, visitID, nil, leg.UpdatedAt)
-				if err != nil {
-					return err
-				}
+				award := newLegBadgeAward(badge.GetID(), playerID, leg, visitID)
+				award.Level = null.IntFrom(int64(*level))
+				award.Value = null.IntFrom(int64(badge.Levels()[*level-1]))
+				awards = append(awards, award)
 			}
 		}
 	}
@@ -248,20 +255,86 @@ func CheckLegForBadges(leg *models.Leg, statistics map[int]*models.PlayerBadgeSt
 		for _, playerID := range leg.Players {
 			valid, visitID := badge.Validate(playerID, leg.Visits)
 			if valid {
-				err = addBadge(tx, badge.GetID(), nil, nil, playerID, nil, &leg.ID, visitID, nil, leg.UpdatedAt)
-				if err != nil {
-					return err
-				}
+				awards = append(awards, newLegBadgeAward(badge.GetID(), playerID, leg, visitID))
 			}
 		}
 	}
-	tx.Commit()
 
-	return nil
+	typeAwards, err := getMatchTypeBadgeAwards(leg)
+	if err != nil {
+		return nil, err
+	}
+	awards = append(awards, typeAwards...)
+
+	ruleAwards, err := getLegBadgeRuleAwards(ctx, leg)
+	if err != nil {
+		return nil, err
+	}
+	return append(awards, ruleAwards...), nil
+}
+
+// getMatchTypeBadgeAwards will return all badges for the type of the given leg unlocked in it
+func getMatchTypeBadgeAwards(leg *models.Leg) ([]*models.BadgeAward, error) {
+	awards := make([]*models.BadgeAward, 0)
+	var statistics *models.LegStatistics
+	for _, badge := range models.MatchTypeBadges {
+		if badge.GetMatchType() != leg.LegType.ID {
+			continue
+		}
+		if statistics == nil {
+			var err error
+			statistics, err = getLegStatistics(leg)
+			if err != nil {
+				return nil, err
+			}
+		}
+		valid, playerIDs := badge.Validate(leg, statistics)
+		if valid {
+			for _, playerID := range playerIDs {
+				awards = append(awards, newLegBadgeAward(badge.GetID(), playerID, leg, nil))
+			}
+		}
+	}
+	return awards, nil
+}
+
+// getLegStatistics will return the statistics of the given leg, for the type of the leg
+func getLegStatistics(leg *models.Leg) (*models.LegStatistics, error) {
+	statistics := new(models.LegStatistics)
+	var err error
+	switch leg.LegType.ID {
+	case models.CRICKET:
+		statistics.Cricket, err = GetCricketStatisticsForLeg(leg.ID)
+	case models.SHOOTOUT:
+		statistics.Shootout, err = GetShootoutStatisticsForLeg(leg.ID)
+	case models.AROUNDTHECLOCK:
+		statistics.AroundThe, err = GetAroundTheClockStatisticsForLeg(leg.ID)
+	case models.TICTACTOE:
+		statistics.TicTacToe, err = GetTicTacToeStatisticsForLeg(leg.ID)
+	case models.KNOCKOUT:
+		statistics.Knockout, err = GetKnockoutStatisticsForLeg(leg.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return statistics, nil
+}
+
+func newLegBadgeAward(badgeID int, playerID int, leg *models.Leg, visitID *int) *models.BadgeAward {
+	award := &models.BadgeAward{
+		BadgeID:   badgeID,
+		PlayerID:  playerID,
+		LegID:     null.IntFrom(int64(leg.ID)),
+		CreatedAt: null.TimeFrom(leg.UpdatedAt),
+	}
+	if visitID != nil {
+		award.VisitID = null.IntFrom(int64(*visitID))
+	}
+	return award
 }
 
-func CheckMatchForBadges(match *models.Match) error {
-	tx, err := models.DB.Begin()
+func CheckMatchForBadges(ctx context.Context, match *models.Match) error {
+	awards, err := getMatchBadgeAwards(ctx, match)
 	if err != nil {
 		return err
 	}
@@ -270,48 +343,63 @@ func CheckMatchForBadges(match *models.Match) error {
 	if err != nil {
 		return err
 	}
-
-	when := time.Now()
-	if !match.EndTime.IsZero() {
-		when = match.EndTime
+	matchStatistics, err := GetX01StatisticsForMatch(match.ID)
+	if err != nil {
+		return err
 	}
-	for _, badge := range models.MatchBadges {
-		valid, playerIDs := badge.Validate(match)
+	for _, badge := range models.LeaderboardBadges {
+		valid, playerID, opponentPlayerID := badge.Validate(match, matchStatistics, leaderboard)
 		if valid {
-			if playerIDs != nil {
-				for _, playerID := range playerIDs {
-					err = addBadge(tx, badge.GetID(), nil, nil, playerID, &match.ID, nil, nil, nil, when)
-					if err != nil {
-						return err
-					}
-				}
-			} else {
-				for _, playerID := range match.Players {
-					err = addBadge(tx, badge.GetID(), nil, nil, playerID, &match.ID, nil, nil, nil, when)
-					if err != nil {
-						return err
-					}
-				}
+			award := newMatchBadgeAward(badge.GetID(), *playerID, match)
+			if opponentPlayerID != nil {
+				award.OpponentPlayerID = null.IntFrom(int64(*opponentPlayerID))
 			}
-
+			awards = append(awards, award)
 		}
 	}
-	matchStatistics, err := GetX01StatisticsForMatch(match.ID)
+	err = addBadgeAwards(ctx, awards)
 	if err != nil {
 		return err
 	}
-	for _, badge := range models.LeaderboardBadges {
-		valid, playerID, opponentPlayerID := badge.Validate(match, matchStatistics, leaderboard)
+	cache.Invalidate(cache.TagBadges)
+
+	return nil
+}
+
+// getMatchBadgeAwards will return all badges unlocked in the given match. Leaderboard badges are not included, as they
+// depend on the leaderboard at the time the match was played
+func getMatchBadgeAwards(ctx context.Context, match *models.Match) ([]*models.BadgeAward, error) {
+	awards := make([]*models.BadgeAward, 0)
+	for _, badge := range models.MatchBadges {
+		valid, playerIDs := badge.Validate(match)
 		if valid {
-			err = addBadge(tx, badge.GetID(), nil, nil, *playerID, &match.ID, nil, nil, opponentPlayerID, when)
-			if err != nil {
-				return err
+			if playerIDs == nil {
+				playerIDs = match.Players
+			}
+			for _, playerID := range playerIDs {
+				awards = append(awards, newMatchBadgeAward(badge.GetID(), playerID, match))
 			}
 		}
 	}
-	tx.Commit()
 
-	return nil
+	ruleAwards, err := getMatchBadgeRuleAwards(ctx, match)
+	if err != nil {
+		return nil, err
+	}
+	return append(awards, ruleAwards...), nil
+}
+
+func newMatchBadgeAward(badgeID int, playerID int, match *models.Match) *models.BadgeAward {
+	when := time.Now()
+	if !match.EndTime.IsZero() {
+		when = match.EndTime
+	}
+	return &models.BadgeAward{
+		BadgeID:   badgeID,
+		PlayerID:  playerID,
+		MatchID:   null.IntFrom(int64(match.ID)),
+		CreatedAt: null.TimeFrom(when),
+	}
 }
 
 func AddGlobalBadge(playerID int, badge models.GlobalBadge) error {
@@ -325,6 +413,7 @@ func AddGlobalBadgeWithTime(playerID int, badge models.GlobalBadge, when time.Ti
 		return err
 	}
 	log.Printf("Added global badge %d to player %d", badge.GetID(), playerID)
+	cache.Invalidate(cache.TagBadges)
 	return nil
 }
 
@@ -336,6 +425,7 @@ func AddGlobalLevelBadge(playerID int, level int, badge models.GlobalLevelBadge)
 		return err
 	}
 	log.Printf("Added global badge %d to player %d", badge.GetID(), playerID)
+	cache.Invalidate(cache.TagBadges)
 	return nil
 }
 
@@ -346,34 +436,48 @@ func AddTournamentBadge(playerID int, tournamentID int, badge models.GlobalBadge
 		return err
 	}
 	log.Printf("Added tournament badge %d to player %d", badge.GetID(), playerID)
+	cache.Invalidate(cache.TagBadges)
 	return nil
 }
 
-func addBadge(tx *sql.Tx, badgeID int, level *int, levels []int, playerID int, matchID *int, legID *int, visitID *int, opponentPlayerID *int, when time.Time) error {
-	if level != nil {
-		_, err := tx.Exec(`INSERT INTO player2badge(player_id, badge_id, level, value, leg_id, visit_id,created_at) VALUES (?, ?, ?, ?, ?, ?, ?)
-		ON DUPLICATE KEY UPDATE leg_id=IF(?>level,?,leg_id), visit_id=IF(?>level,?,visit_id), created_at=IF(?>level,?,created_at), value=IF(?>level,?,value),level=?`,
-			playerID, badgeID, level, levels[*level-1], legID, visitID, when, level, legID, level, visitID, level, when, level, levels[*level-1], level)
+// addBadgeAwards will store all the given awards in a single transaction
+func addBadgeAwards(ctx context.Context, awards []*models.BadgeAward) error {
+	return models.Transaction(models.DB, func(tx *sql.Tx) error {
+		for _, award := range awards {
+			if err := addBadgeAward(ctx, tx, award); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// addBadgeAward will store the given award. Badges with levels are only updated when a higher level is reached, while
+// other badges are only stored the first time they are unlocked
+func addBadgeAward(ctx context.Context, tx *sql.Tx, award *models.BadgeAward) error {
+	logger := logging.Logger(ctx)
+	if !award.CreatedAt.Valid {
+		award.CreatedAt = null.TimeFrom(time.Now())
+	}
+	if award.Level.Valid {
+		_, err := tx.Exec(`INSERT INTO player2badge(player_id, badge_id, level, value, match_id, leg_id, visit_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE match_id=IF(?>level,?,match_id), leg_id=IF(?>level,?,leg_id), visit_id=IF(?>level,?,visit_id),
+			created_at=IF(?>level,?,created_at), value=IF(?>level,?,value), level=GREATEST(level,?)`,
+			award.PlayerID, award.BadgeID, award.Level, award.Value, award.MatchID, award.LegID, award.VisitID, award.CreatedAt,
+			award.Level, award.MatchID, award.Level, award.LegID, award.Level, award.VisitID,
+			award.Level, award.CreatedAt, award.Level, award.Value, award.Level)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
-		log.Printf("Added badge %d (level %d) to player %d on leg %d", badgeID, *level, playerID, *legID)
+		logger.Printf("Added %s", award)
 	} else {
-		_, err := tx.Exec("INSERT IGNORE INTO player2badge (player_id, badge_id, match_id, leg_id, visit_id, opponent_player_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
-			playerID, badgeID, matchID, legID, visitID, opponentPlayerID, when)
+		_, err := tx.Exec(`INSERT IGNORE INTO player2badge (player_id, badge_id, match_id, leg_id, visit_id, tournament_id, opponent_player_id, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			award.PlayerID, award.BadgeID, award.MatchID, award.LegID, award.VisitID, award.TournamentID, award.OpponentPlayerID, award.CreatedAt)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
-		if legID != nil {
-			log.Printf("Added badge %d to player %d on leg %d", badgeID, playerID, *legID)
-		} else if matchID != nil {
-			log.Printf("Added badge %d to player %d on match %d", badgeID, playerID, *matchID)
-		} else {
-			log.Printf("Added badge %d to player %d", badgeID, playerID)
-
-		}
+		logger.Printf("Added %s", award)
 	}
 	return nil
 }
@@ -0,0 +1,186 @@
+package data
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/models"
+)
+
+// nearMisses returns the closest a player has been to each one-off badge which can be tracked from the score table
+var nearMisses = map[int]func(playerID int) (*models.BadgeNearMiss, error){
+	// Triple Threat
+	31: func(playerID int) (*models.BadgeNearMiss, error) {
+		// Each of triple 20, 19 and 18 counts once, as hitting the same triple twice does not unlock the badge
+		hits := anyDart("%[1]s = 20 AND %[1]s_multiplier = 3") + " + " + anyDart("%[1]s = 19 AND %[1]s_multiplier = 3") + " + " +
+			anyDart("%[1]s = 18 AND %[1]s_multiplier = 3")
+		return getBestVisit(playerID, hits, "%d of triple 20, 19 and 18 in one visit")
+	},
+	// Bull Bull Bull
+	34: func(playerID int) (*models.BadgeNearMiss, error) {
+		return getBestVisit(playerID, countDarts("%[1]s = 25 AND %[1]s_multiplier = 2"), "%d of 3 bulls in one visit")
+	},
+	// So Close
+	35: func(playerID int) (*models.BadgeNearMiss, error) {
+		return getBestVisit(playerID, countDarts("%[1]s = 1 AND %[1]s_multiplier = 3"), "%d of 3 triple 1s in one visit")
+	},
+	// Close to Perfect
+	16: func(playerID int) (*models.BadgeNearMiss, error) {
+		return getFewestDartsCheckout(playerID, 14)
+	},
+	// Perfection
+	40: func(playerID int) (*models.BadgeNearMiss, error) {
+		return getFewestDartsCheckout(playerID, 9)
+	},
+}
+
+// GetPlayerBadgeProgress will return the progress of the given player towards each badge. Level badges show the current value
+// and the next threshold, while one-off badges show the closest near-misses where they can be tracked
+func GetPlayerBadgeProgress(playerID int) ([]*models.BadgeProgress, error) {
+	badges, err := GetBadges()
+	if err != nil {
+		return nil, err
+	}
+	playerBadges, err := GetPlayerBadges(playerID)
+	if err != nil {
+		return nil, err
+	}
+	unlocked := make(map[int]*models.PlayerBadge)
+	for _, badge := range playerBadges {
+		unlocked[badge.Badge.ID] = badge
+	}
+	statistics, err := GetPlayerBadgeStatistics([]int{playerID}, nil)
+	if err != nil {
+		return nil, err
+	}
+	stats := statistics[playerID]
+	matchTypes, err := GetPlayersMatchTypes()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := GetBadgeRules()
+	if err != nil {
+		return nil, err
+	}
+
+	levels := make(map[int][]int)
+	for _, badge := range models.VisitBadgesLevel {
+		levels[badge.GetID()] = badge.Levels()
+	}
+	versatile := new(models.BadgeVersatilePlayer)
+	levels[versatile.GetID()] = versatile.Levels()
+	for _, rule := range rules {
+		if len(rule.Levels) > 0 {
+			levels[rule.ID] = rule.Levels
+		}
+	}
+	values := map[int]int{
+		1:                 stats.Score100sPlus,
+		2:                 stats.Score140sPlus,
+		3:                 stats.Score180s,
+		46:                len(stats.Shanghais),
+		versatile.GetID(): matchTypes[playerID],
+	}
+
+	progress := make([]*models.BadgeProgress, 0)
+	for _, badge := range badges {
+		playerBadge, isUnlocked := unlocked[badge.ID]
+		if (badge.Secret || badge.Hidden) && !isUnlocked {
+			// Don't give away badges which are not supposed to be known
+			continue
+		}
+
+		if badgeLevels, ok := levels[badge.ID]; ok {
+			value, ok := values[badge.ID]
+			if !ok && isUnlocked {
+				// Use the stored value, or the threshold of the unlocked level if the value is not tracked
+				if playerBadge.Value.Valid {
+					value = int(playerBadge.Value.Int64)
+				} else if playerBadge.Level.Valid && int(playerBadge.Level.Int64) <= len(badgeLevels) {
+					value = badgeLevels[playerBadge.Level.Int64-1]
+				}
+			}
+			progress = append(progress, models.NewLevelProgress(badge, value, badgeLevels))
+			continue
+		}
+
+		misses := make([]*models.BadgeNearMiss, 0)
+		if nearMiss, ok := nearMisses[badge.ID]; ok && !isUnlocked {
+			miss, err := nearMiss(playerID)
+			if err != nil {
+				return nil, err
+			}
+			misses = append(misses, miss)
+		}
+		progress = append(progress, models.NewNearMissProgress(badge, isUnlocked, misses))
+	}
+	sort.Slice(progress, func(i, j int) bool {
+		return progress[i].Badge.ID < progress[j].Badge.ID
+	})
+	return progress, nil
+}
+
+// countDarts returns an SQL expression counting the darts of a visit matching the given condition, where %[1]s is replaced
+// by each dart column
+func countDarts(condition string) string {
+	return fmt.Sprintf("IFNULL(%s, 0) + IFNULL(%s, 0) + IFNULL(%s, 0)",
+		fmt.Sprintf(condition, "s.first_dart"), fmt.Sprintf(condition, "s.second_dart"), fmt.Sprintf(condition, "s.third_dart"))
+}
+
+// anyDart returns an SQL expression which is 1 if any dart of a visit matches the given condition, where %[1]s is replaced
+// by each dart column
+func anyDart(condition string) string {
+	return fmt.Sprintf("IFNULL((%s) OR (%s) OR (%s), 0)",
+		fmt.Sprintf(condition, "s.first_dart"), fmt.Sprintf(condition, "s.second_dart"), fmt.Sprintf(condition, "s.third_dart"))
+}
+
+// getBestVisit will return the visit with the most hits out of 3, as counted by the given SQL expression
+func getBestVisit(playerID int, hits string, hint string) (*models.BadgeNearMiss, error) {
+	var visitID, legID, best int
+	err := models.DB.QueryRow(fmt.Sprintf(`
+		SELECT
+			s.id, s.leg_id,
+			%s AS 'hits'
+		FROM score s
+			JOIN leg l ON l.id = s.leg_id
+			JOIN matches m ON m.id = l.match_id
+		WHERE s.player_id = ? AND l.is_finished = 1 AND m.is_abandoned = 0
+		ORDER BY hits DESC, s.id
+		LIMIT 1`, hits),
+		playerID).Scan(&visitID, &legID, &best)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	miss := models.NewNearMiss(fmt.Sprintf(hint, best), best, 3)
+	if best > 0 {
+		miss.VisitID = null.IntFrom(int64(visitID))
+		miss.LegID = null.IntFrom(int64(legID))
+	}
+	return miss, nil
+}
+
+// getFewestDartsCheckout will return the 501 leg won by the player in the fewest darts
+func getFewestDartsCheckout(playerID int, required int) (*models.BadgeNearMiss, error) {
+	var legID, darts int
+	err := models.DB.QueryRow(`
+		SELECT s.leg_id, s.darts_thrown
+		FROM statistics_x01 s
+			JOIN leg l ON l.id = s.leg_id
+			JOIN matches m ON m.id = l.match_id
+		WHERE s.player_id = ? AND l.winner_id = s.player_id AND l.starting_score = 501
+			AND COALESCE(l.leg_type_id, m.match_type_id) = ? AND m.is_abandoned = 0 AND m.is_walkover = 0
+		ORDER BY s.darts_thrown, s.leg_id
+		LIMIT 1`, playerID, models.X01).Scan(&legID, &darts)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	miss := models.NewNearMissLowerIsBetter(fmt.Sprintf("Checked out 501 in %d darts, %d or fewer needed", darts, required), darts, required)
+	miss.LegID = null.IntFrom(int64(legID))
+	return miss, nil
+}
@@ -0,0 +1,331 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/cache"
+	"github.com/kcapp/api/logging"
+	"github.com/kcapp/api/models"
+)
+
+// globalBadges are all badges unlocked outside of legs and matches
+var globalBadges = []models.GlobalBadge{
+	new(models.BadgeKcappSupporter),
+	new(models.BadgeSayMyName),
+	new(models.BadgeItsOfficial),
+	new(models.BadgeTournament1st),
+	new(models.BadgeTournament2nd),
+	new(models.BadgeTournament3rd),
+	new(models.BadgeUntouchable),
+	new(models.BadgeByeForNow),
+	new(models.BadgeOldTimer),
+	new(models.BadgeVersatilePlayer),
+}
+
+// legRecalculation is a leg waiting to have its badges and records recalculated, with any players no longer in the leg.
+// The context of the request which queued it is kept so the recalculation is logged with the same request ID
+type legRecalculation struct {
+	ctx       context.Context
+	legID     int
+	playerIDs []int
+}
+
+// legRecalculations are legs queued for recalculation after they were modified, undone or deleted
+var legRecalculations = make(chan legRecalculation, 1000)
+
+// queueLegRecalculation will recalculate badges and records of the given leg in the background, keeping it off the request path
+func queueLegRecalculation(ctx context.Context, legID int, playerIDs []int) {
+	select {
+	case legRecalculations <- legRecalculation{ctx: context.WithoutCancel(ctx), legID: legID, playerIDs: playerIDs}:
+	default:
+		logging.Logger(ctx).Printf("[%d] Recalculation queue is full, run 'badge recalculate leg %d' and 'player records recalculate' to recalculate it", legID, legID)
+	}
+}
+
+// queueFinishedLegRecalculation will queue the given leg for recalculation if it is finished, as unfinished legs have
+// not unlocked any badges or set any records yet
+func queueFinishedLegRecalculation(ctx context.Context, legID int) error {
+	var isFinished bool
+	err := models.DB.QueryRow("SELECT is_finished FROM leg WHERE id = ?", legID).Scan(&isFinished)
+	if err != nil {
+		return err
+	}
+	if isFinished {
+		queueLegRecalculation(ctx, legID, nil)
+	}
+	return nil
+}
+
+// RecalculateQueuedLegs will recalculate legs as they are queued, one at a time, until the context is done
+func RecalculateQueuedLegs(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case r := <-legRecalculations:
+			if _, err := RecalculateBadgesForLeg(r.ctx, r.legID, r.playerIDs, false); err != nil {
+				logging.Logger(r.ctx).Printf("[%d] Unable to recalculate badges: %s", r.legID, err)
+			}
+			if err := recalculateLegRecords(r.ctx, r.legID, r.playerIDs); err != nil {
+				logging.Logger(r.ctx).Printf("[%d] Unable to recalculate records: %s", r.legID, err)
+			}
+		}
+	}
+}
+
+// getLegBadgeIDs returns the ids of all badges unlocked in a leg
+func getLegBadgeIDs() ([]int, error) {
+	ids := make([]int, 0)
+	for _, badge := range models.LegBadges {
+		ids = append(ids, badge.GetID())
+	}
+	for _, badge := range models.LegPlayerBadges {
+		ids = append(ids, badge.GetID())
+	}
+	for _, badge := range models.VisitBadgesLevel {
+		ids = append(ids, badge.GetID())
+	}
+	for _, badge := range models.VisitBadges {
+		ids = append(ids, badge.GetID())
+	}
+	for _, badge := range models.MatchTypeBadges {
+		ids = append(ids, badge.GetID())
+	}
+	return appendBadgeRuleIDs(ids, models.BadgeRuleScopeVisit, models.BadgeRuleScopeLeg)
+}
+
+// getMatchBadgeIDs returns the ids of all badges unlocked in a match, except leaderboard badges which cannot be recalculated
+func getMatchBadgeIDs() ([]int, error) {
+	ids := make([]int, 0)
+	for _, badge := range models.MatchBadges {
+		ids = append(ids, badge.GetID())
+	}
+	return appendBadgeRuleIDs(ids, models.BadgeRuleScopeMatch)
+}
+
+// appendBadgeRuleIDs will append the ids of all active badge rules with one of the given scopes
+func appendBadgeRuleIDs(ids []int, scopes ...string) ([]int, error) {
+	rules, err := getActiveBadgeRules()
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		for _, scope := range scopes {
+			if rule.Scope == scope {
+				ids = append(ids, rule.ID)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// getStoredBadgeAwards will return the stored awards of the given badges, for the given players, or all players if nil
+func getStoredBadgeAwards(badgeIDs []int, playerIDs []int) (models.BadgeAwards, error) {
+	awards := make(models.BadgeAwards)
+	if len(badgeIDs) == 0 || (playerIDs != nil && len(playerIDs) == 0) {
+		return awards, nil
+	}
+	query := `
+		SELECT
+			player_id, badge_id, level, value, match_id, leg_id, visit_id, tournament_id, opponent_player_id, created_at
+		FROM player2badge
+		WHERE badge_id IN (?)`
+	args := []interface{}{badgeIDs}
+	if playerIDs != nil {
+		query += " AND player_id IN (?)"
+		args = append(args, playerIDs)
+	}
+	q, args, err := sqlx.In(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		award := new(models.BadgeAward)
+		err := rows.Scan(&award.PlayerID, &award.BadgeID, &award.Level, &award.Value, &award.MatchID, &award.LegID,
+			&award.VisitID, &award.TournamentID, &award.OpponentPlayerID, &award.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		awards[award.Key()] = award
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return awards, nil
+}
+
+// applyBadgeDiff will add, remove and update stored badges so they match the expected badges
+func applyBadgeDiff(ctx context.Context, diff *models.BadgeDiff) error {
+	logger := logging.Logger(ctx)
+	if diff.IsEmpty() {
+		return nil
+	}
+	err := models.Transaction(models.DB, func(tx *sql.Tx) error {
+		for _, award := range diff.Added {
+			if !award.CreatedAt.Valid {
+				award.CreatedAt.SetValid(time.Now())
+			}
+			_, err := tx.Exec(`
+				INSERT INTO player2badge (player_id, badge_id, level, value, match_id, leg_id, visit_id, tournament_id, opponent_player_id, created_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				award.PlayerID, award.BadgeID, award.Level, award.Value, award.MatchID, award.LegID, award.VisitID,
+				award.TournamentID, award.OpponentPlayerID, award.CreatedAt)
+			if err != nil {
+				return err
+			}
+			logger.Printf("Added %s", award)
+		}
+		for _, award := range diff.Removed {
+			_, err := tx.Exec("DELETE FROM player2badge WHERE player_id = ? AND badge_id = ?", award.PlayerID, award.BadgeID)
+			if err != nil {
+				return err
+			}
+			logger.Printf("Revoked %s", award)
+		}
+		for _, change := range diff.Changed {
+			award := change.To
+			_, err := tx.Exec(`
+				UPDATE player2badge SET
+					level = ?, value = ?, match_id = ?, leg_id = ?, visit_id = ?, tournament_id = ?, opponent_player_id = ?,
+					created_at = COALESCE(?, created_at)
+				WHERE player_id = ? AND badge_id = ?`,
+				award.Level, award.Value, award.MatchID, award.LegID, award.VisitID, award.TournamentID, award.OpponentPlayerID,
+				award.CreatedAt, award.PlayerID, award.BadgeID)
+			if err != nil {
+				return err
+			}
+			logger.Printf("Changed %s to %s", change.From, award)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	cache.Invalidate(cache.TagBadges)
+	return nil
+}
+
+// getLegBadgeAwardsForLeg will return all badges unlocked in the given leg, based on statistics up until the leg
+func getLegBadgeAwardsForLeg(ctx context.Context, legID int) ([]*models.BadgeAward, error) {
+	leg, err := GetLeg(legID)
+	if err != nil {
+		return nil, err
+	}
+	statistics, err := GetPlayerBadgeStatistics(leg.Players, &legID)
+	if err != nil {
+		return nil, err
+	}
+	return getLegBadgeAwards(ctx, leg, statistics)
+}
+
+// RecalculateBadgesForLeg will recalculate all badges unlocked in the given leg, after it was modified, undone or deleted.
+// Badges which no longer apply are revoked, or moved to the next leg of the player where they were unlocked.
+// Level badges unlocked in later legs are not recalculated, as this requires recalculating all legs of the player
+func RecalculateBadgesForLeg(ctx context.Context, legID int, playerIDs []int, dryRun bool) (*models.BadgeDiff, error) {
+	badgeIDs, err := getLegBadgeIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var isFinished bool
+	err = models.DB.QueryRow(`
+		SELECT l.is_finished = 1 AND l.has_scores = 1 AND m.is_abandoned = 0 AND m.is_bye = 0 AND m.is_walkover = 0
+		FROM leg l JOIN matches m ON m.id = l.match_id WHERE l.id = ?`, legID).Scan(&isFinished)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// Include players who have badges from this leg, as players of deleted legs might be gone
+	rows, err := models.DB.Query(`
+		SELECT player_id FROM player2leg WHERE leg_id = ?
+		UNION
+		SELECT player_id FROM player2badge WHERE leg_id = ?`, legID, legID)
+	if err != nil {
+		return nil, err
+	}
+	players, err := scanIDs(rows)
+	if err != nil {
+		return nil, err
+	}
+	players = append(players, playerIDs...)
+
+	stored, err := getStoredBadgeAwards(badgeIDs, players)
+	if err != nil {
+		return nil, err
+	}
+	expected := make(models.BadgeAwards)
+	fromLeg := make(map[models.BadgeAwardKey]*models.BadgeAward)
+	for key, award := range stored {
+		if award.LegID.Valid && int(award.LegID.Int64) == legID {
+			fromLeg[key] = award
+		} else {
+			expected[key] = award
+		}
+	}
+	if isFinished {
+		awards, err := getLegBadgeAwardsForLeg(ctx, legID)
+		if err != nil {
+			return nil, err
+		}
+		for _, award := range awards {
+			expected.Add(award)
+		}
+	}
+
+	// Badges which were unlocked in this leg, but no longer are, might have been unlocked in a later leg
+	for key, award := range fromLeg {
+		current, ok := expected[key]
+		if ok && current.Level.Int64 >= award.Level.Int64 {
+			continue
+		}
+		rows, err := models.DB.Query(`
+			SELECT l.id
+			FROM leg l
+				JOIN player2leg p2l ON p2l.leg_id = l.id
+				JOIN matches m ON m.id = l.match_id
+			WHERE p2l.player_id = ? AND l.id > ? AND l.is_finished = 1 AND l.has_scores = 1
+				AND m.is_abandoned = 0 AND m.is_bye = 0 AND m.is_walkover = 0
+			ORDER BY l.id`, key.PlayerID, legID)
+		if err != nil {
+			return nil, err
+		}
+		legs, err := scanIDs(rows)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range legs {
+			awards, err := getLegBadgeAwardsForLeg(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			found := false
+			for _, award := range awards {
+				if award.Key() == key {
+					expected.Add(award)
+					found = true
+				}
+			}
+			if found && !award.Level.Valid {
+				break
+			}
+		}
+	}
+
+	diff := models.DiffBadgeAwards(expected, stored)
+	if !dryRun {
+		err = applyBadgeDiff(ctx, diff)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return diff, nil
+}
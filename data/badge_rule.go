@@ -0,0 +1,367 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/cache"
+	"github.com/kcapp/api/logging"
+	"github.com/kcapp/api/models"
+)
+
+// badgeRulesTTL is how long loaded rules are used before reloading, so changes made by other instances are picked up
+const badgeRulesTTL = time.Minute
+
+var badgeRules struct {
+	sync.Mutex
+	rules  []*models.BadgeRule
+	loaded time.Time
+}
+
+// GetBadgeRules will return all badge rules
+func GetBadgeRules() ([]*models.BadgeRule, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			b.id, b.name, b.description, b.filename, b.hidden, b.secret,
+			r.scope, r.condition_expr, r.value_expr, r.levels, r.is_active, r.office_id
+		FROM badge_rule r
+			JOIN badge b ON b.id = r.badge_id
+		ORDER BY b.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]*models.BadgeRule, 0)
+	for rows.Next() {
+		rule := new(models.BadgeRule)
+		var levels null.String
+		err := rows.Scan(&rule.ID, &rule.Name, &rule.Description, &rule.Filename, &rule.Hidden, &rule.Secret,
+			&rule.Scope, &rule.Condition, &rule.Value, &levels, &rule.IsActive, &rule.OfficeID)
+		if err != nil {
+			return nil, err
+		}
+		if levels.Valid {
+			if err := json.Unmarshal([]byte(levels.String), &rule.Levels); err != nil {
+				return nil, err
+			}
+		}
+		rules = append(rules, rule)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetBadgeRule will return the badge rule for the given badge
+func GetBadgeRule(badgeID int) (*models.BadgeRule, error) {
+	rules, err := GetBadgeRules()
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule.ID == badgeID {
+			return rule, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// AddBadgeRule will create a new badge defined by the given rule. Ids below MinBadgeRuleID are reserved for built-in badges
+func AddBadgeRule(rule models.BadgeRule) (*models.BadgeRule, error) {
+	if rule.ID < models.MinBadgeRuleID {
+		return nil, &models.BadgeRuleError{Err: fmt.Errorf("badge %d is reserved for built-in badges, ids from %d can be used", rule.ID, models.MinBadgeRuleID)}
+	}
+	var exists bool
+	err := models.DB.QueryRow("SELECT COUNT(*) > 0 FROM badge WHERE id = ?", rule.ID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, &models.BadgeRuleError{Err: fmt.Errorf("badge %d already exists", rule.ID)}
+	}
+	return saveBadgeRule(rule)
+}
+
+// UpdateBadgeRule will update the badge rule for the given badge. Built-in badges cannot be updated
+func UpdateBadgeRule(badgeID int, rule models.BadgeRule) (*models.BadgeRule, error) {
+	_, err := GetBadgeRule(badgeID)
+	if err == sql.ErrNoRows {
+		return nil, &models.BadgeRuleError{Err: fmt.Errorf("badge %d is not defined by a rule", badgeID)}
+	} else if err != nil {
+		return nil, err
+	}
+	rule.ID = badgeID
+	return saveBadgeRule(rule)
+}
+
+// ImportBadgeRules will create or update the given rules, used to load rules from configuration
+func ImportBadgeRules(rules []*models.BadgeRule) error {
+	existing, err := GetBadgeRules()
+	if err != nil {
+		return err
+	}
+	isRule := make(map[int]bool)
+	for _, rule := range existing {
+		isRule[rule.ID] = true
+	}
+	for _, rule := range rules {
+		if isRule[rule.ID] {
+			_, err = UpdateBadgeRule(rule.ID, *rule)
+		} else {
+			_, err = AddBadgeRule(*rule)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	log.Printf("Imported %d badge rules", len(rules))
+	return nil
+}
+
+// saveBadgeRule will validate and store the given rule, together with the badge it defines
+func saveBadgeRule(rule models.BadgeRule) (*models.BadgeRule, error) {
+	if err := rule.Compile(); err != nil {
+		return nil, err
+	}
+	var levels null.String
+	var numLevels null.Int
+	if len(rule.Levels) > 0 {
+		b, err := json.Marshal(rule.Levels)
+		if err != nil {
+			return nil, err
+		}
+		levels = null.StringFrom(string(b))
+		numLevels = null.IntFrom(int64(len(rule.Levels)))
+	}
+
+	err := models.Transaction(models.DB, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO badge (id, name, description, filename, hidden, secret, levels) VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE name = VALUES(name), description = VALUES(description), filename = VALUES(filename),
+				hidden = VALUES(hidden), secret = VALUES(secret), levels = VALUES(levels)`,
+			rule.ID, rule.Name, rule.Description, rule.Filename, rule.Hidden, rule.Secret, numLevels)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO badge_rule (badge_id, scope, condition_expr, value_expr, levels, is_active, office_id, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+			ON DUPLICATE KEY UPDATE scope = VALUES(scope), condition_expr = VALUES(condition_expr), value_expr = VALUES(value_expr),
+				levels = VALUES(levels), is_active = VALUES(is_active), office_id = VALUES(office_id), updated_at = NOW()`,
+			rule.ID, rule.Scope, rule.Condition, rule.Value, levels, rule.IsActive, rule.OfficeID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Saved badge rule %d (%s)", rule.ID, rule.Name)
+	resetBadgeRules()
+	cache.Invalidate(cache.TagBadges)
+	return &rule, nil
+}
+
+// DeleteBadgeRule will delete the given badge rule, together with the badge and all unlocks of it
+func DeleteBadgeRule(badgeID int) error {
+	_, err := GetBadgeRule(badgeID)
+	if err == sql.ErrNoRows {
+		return &models.BadgeRuleError{Err: fmt.Errorf("badge %d is not defined by a rule", badgeID)}
+	} else if err != nil {
+		return err
+	}
+	err = models.Transaction(models.DB, func(tx *sql.Tx) error {
+		for _, query := range []string{
+			"DELETE FROM player2badge WHERE badge_id = ?",
+			"DELETE FROM badge_rule WHERE badge_id = ?",
+			"DELETE FROM badge WHERE id = ?",
+		} {
+			if _, err := tx.Exec(query, badgeID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("Deleted badge rule %d", badgeID)
+	resetBadgeRules()
+	cache.Invalidate(cache.TagBadges)
+	return nil
+}
+
+// getActiveBadgeRules will return all active and compiled badge rules, reloading them once they are too old
+func getActiveBadgeRules() ([]*models.BadgeRule, error) {
+	badgeRules.Lock()
+	defer badgeRules.Unlock()
+	if badgeRules.rules != nil && time.Since(badgeRules.loaded) < badgeRulesTTL {
+		return badgeRules.rules, nil
+	}
+
+	rules, err := GetBadgeRules()
+	if err != nil {
+		return nil, err
+	}
+	active := make([]*models.BadgeRule, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.IsActive {
+			continue
+		}
+		if err := rule.Compile(); err != nil {
+			log.Printf("Skipping invalid badge rule %d: %s", rule.ID, err)
+			continue
+		}
+		active = append(active, rule)
+	}
+	badgeRules.rules = active
+	badgeRules.loaded = time.Now()
+	return active, nil
+}
+
+// resetBadgeRules will make the rules reload on next use
+func resetBadgeRules() {
+	badgeRules.Lock()
+	defer badgeRules.Unlock()
+	badgeRules.rules = nil
+}
+
+// getLegBadgeRuleAwards will return all badges with visit or leg scope rules unlocked in the given leg. Rules which fail
+// to evaluate are logged and skipped
+func getLegBadgeRuleAwards(ctx context.Context, leg *models.Leg) ([]*models.BadgeAward, error) {
+	awards := make([]*models.BadgeAward, 0)
+	rules, err := getActiveBadgeRules()
+	if err != nil || len(rules) == 0 {
+		return awards, err
+	}
+	var officeID null.Int
+	err = models.DB.QueryRow("SELECT office_id FROM matches WHERE id = ?", leg.MatchID).Scan(&officeID)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		unlocks, err := rule.EvaluateLeg(leg, officeID)
+		if err != nil {
+			// A broken rule should not prevent the leg from being finished, or other badges from being unlocked
+			logging.Logger(ctx).Printf("[%d] Skipping badge rule %d: %s", leg.ID, rule.ID, err)
+			continue
+		}
+		for _, unlock := range unlocks {
+			awards = append(awards, unlock.Award())
+		}
+	}
+	return awards, nil
+}
+
+// getMatchBadgeRuleAwards will return all badges with match scope rules unlocked in the given match. Rules which fail
+// to evaluate are logged and skipped
+func getMatchBadgeRuleAwards(ctx context.Context, match *models.Match) ([]*models.BadgeAward, error) {
+	awards := make([]*models.BadgeAward, 0)
+	rules, err := getActiveBadgeRules()
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		unlocks, err := rule.EvaluateMatch(match)
+		if err != nil {
+			logging.Logger(ctx).Printf("Skipping badge rule %d for match %d: %s", rule.ID, match.ID, err)
+			continue
+		}
+		for _, unlock := range unlocks {
+			awards = append(awards, unlock.Award())
+		}
+	}
+	return awards, nil
+}
+
+// DryRunBadgeRule will evaluate the given rule against legs or matches finished since the given time, showing who
+// would unlock it without unlocking anything. At most limit legs or matches are evaluated, newest first, and a rule which
+// fails to evaluate returns a BadgeRuleError
+func DryRunBadgeRule(rule models.BadgeRule, since time.Time, limit int) (*models.BadgeRuleDryRun, error) {
+	if err := rule.Compile(); err != nil {
+		return nil, err
+	}
+	// Inactive rules can be tried out before they are enabled
+	rule.IsActive = true
+	result := &models.BadgeRuleDryRun{Rule: &rule, Unlocks: make([]*models.BadgeRuleUnlock, 0)}
+
+	if rule.Scope == models.BadgeRuleScopeMatch {
+		rows, err := models.DB.Query(`
+			SELECT m.id FROM matches m
+			WHERE m.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0 AND m.is_bye = 0 AND m.updated_at >= ?
+			ORDER BY m.id DESC LIMIT ?`, since, limit)
+		if err != nil {
+			return nil, err
+		}
+		ids, err := scanIDs(rows)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			match, err := GetMatch(id)
+			if err != nil {
+				return nil, err
+			}
+			unlocks, err := rule.EvaluateMatch(match)
+			if err != nil {
+				return nil, &models.BadgeRuleError{Err: fmt.Errorf("match %d: %w", match.ID, err)}
+			}
+			result.Evaluated++
+			result.Unlocks = append(result.Unlocks, unlocks...)
+		}
+		return result, nil
+	}
+
+	rows, err := models.DB.Query(`
+		SELECT l.id FROM leg l
+		WHERE l.is_finished = 1 AND l.has_scores = 1 AND l.end_time >= ?
+		ORDER BY l.id DESC LIMIT ?`, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := scanIDs(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		leg, err := GetLeg(id)
+		if err != nil {
+			return nil, err
+		}
+		var officeID null.Int
+		err = models.DB.QueryRow("SELECT office_id FROM matches WHERE id = ?", leg.MatchID).Scan(&officeID)
+		if err != nil {
+			return nil, err
+		}
+		unlocks, err := rule.EvaluateLeg(leg, officeID)
+		if err != nil {
+			return nil, &models.BadgeRuleError{Err: fmt.Errorf("leg %d: %w", leg.ID, err)}
+		}
+		result.Evaluated++
+		result.Unlocks = append(result.Unlocks, unlocks...)
+	}
+	return result, nil
+}
+
+// scanIDs will read a single integer column from all rows, and close the rows
+func scanIDs(rows *sql.Rows) ([]int, error) {
+	defer rows.Close()
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
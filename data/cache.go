@@ -0,0 +1,45 @@
+package data
+
+import (
+	"log"
+
+	"github.com/kcapp/api/cache"
+	"github.com/kcapp/api/models"
+)
+
+// invalidateMatch will invalidate all cached responses containing data of the given match
+func invalidateMatch(match *models.Match) {
+	tags := []string{cache.TagStatistics, cache.TagBadges}
+	for _, playerID := range match.Players {
+		tags = append(tags, cache.PlayerTag(playerID))
+	}
+	if match.TournamentID.Valid {
+		tags = append(tags, cache.TournamentTag(int(match.TournamentID.Int64)))
+	}
+	cache.Invalidate(tags...)
+}
+
+// invalidateLeg will invalidate all cached responses containing data of the match of the given leg. Errors are only
+// logged, since the leg has already been updated when this is called
+func invalidateLeg(legID int) {
+	leg, err := GetLeg(legID)
+	if err != nil {
+		log.Printf("[%d] Unable to invalidate cached responses of leg: %s", legID, err)
+		return
+	}
+	match, err := GetMatch(leg.MatchID)
+	if err != nil {
+		log.Printf("[%d] Unable to invalidate cached responses of match %d: %s", legID, leg.MatchID, err)
+		return
+	}
+	invalidateMatch(match)
+}
+
+// invalidatePlayers will invalidate all cached responses containing data of the given players
+func invalidatePlayers(playerIDs []int) {
+	tags := make([]string, 0, len(playerIDs))
+	for _, playerID := range playerIDs {
+		tags = append(tags, cache.PlayerTag(playerID))
+	}
+	cache.Invalidate(tags...)
+}
@@ -0,0 +1,167 @@
+package data
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/models"
+)
+
+// getHeadToHeadLegs will return the outcome of all finished legs of the given matches, ordered by match and leg
+func getHeadToHeadLegs(matchIDs []int) ([]*models.Head2HeadLeg, error) {
+	legs := make([]*models.Head2HeadLeg, 0)
+	if len(matchIDs) == 0 {
+		return legs, nil
+	}
+	q, args, err := sqlx.In(`
+		SELECT
+			l.match_id, l.id, mt.id, mt.name, mt.description, mm.wins_required, m.winner_id, l.winner_id, p2l.player_id
+		FROM leg l
+			JOIN matches m ON m.id = l.match_id
+			JOIN match_type mt ON mt.id = m.match_type_id
+			JOIN match_mode mm ON mm.id = m.match_mode_id
+			JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.order = 1
+		WHERE l.match_id IN (?) AND l.is_finished = 1
+		ORDER BY l.match_id, l.id`, matchIDs)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		leg := new(models.Head2HeadLeg)
+		leg.MatchType = new(models.MatchType)
+		err := rows.Scan(&leg.MatchID, &leg.LegID, &leg.MatchType.ID, &leg.MatchType.Name, &leg.MatchType.Description,
+			&leg.WinsRequired, &leg.MatchWinnerID, &leg.WinnerID, &leg.FirstPlayerID)
+		if err != nil {
+			return nil, err
+		}
+		legs = append(legs, leg)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return legs, nil
+}
+
+// getHeadToHeadEloHistory will return the Elo changes of both players in matches against each other, oldest first
+func getHeadToHeadEloHistory(player1 int, player2 int) ([]*models.PlayerEloChangelog, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			home.id, home.match_id, m.updated_at,
+			if(m.tournament_id is null, false, true) as 'is_official',
+			mm.short_name as 'match_mode',
+			mt.name as 'match_type', m.winner_id,
+			home.player_id, home.old_elo, home.new_elo, home.old_tournament_elo, home.new_tournament_elo,
+			away.player_id, away.old_elo, away.new_elo, away.old_tournament_elo, away.new_tournament_elo
+		FROM player_elo_changelog home
+			JOIN player_elo_changelog away ON away.match_id = home.match_id AND away.player_id = ?
+			JOIN matches m on m.id = home.match_id
+			JOIN match_type mt on m.match_type_id = mt.id
+			JOIN match_mode mm on m.match_mode_id = mm.id
+		WHERE home.player_id = ?
+		ORDER BY home.id`, player2, player1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]*models.PlayerEloChangelog, 0)
+	for rows.Next() {
+		change := new(models.PlayerEloChangelog)
+		home := new(models.PlayerElo)
+		away := new(models.PlayerElo)
+		err := rows.Scan(&change.ID, &change.MatchID, &change.FinishedAt, &change.IsOfficial, &change.MatchMode,
+			&change.MatchType, &change.WinnerID,
+			&home.PlayerID, &home.CurrentElo, &home.CurrentEloNew, &home.TournamentElo, &home.TournamentEloNew,
+			&away.PlayerID, &away.CurrentElo, &away.CurrentEloNew, &away.TournamentElo, &away.TournamentEloNew)
+		if err != nil {
+			return nil, err
+		}
+		change.HomePlayer = home
+		change.AwayPlayer = away
+		history = append(history, change)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// getHeadToHeadMoments will return 180s, high checkouts and badges unlocked by the two players against each other
+func getHeadToHeadMoments(player1 int, player2 int, legIDs []int) ([]*models.Head2HeadMoment, error) {
+	moments := make([]*models.Head2HeadMoment, 0)
+	if len(legIDs) > 0 {
+		q, args, err := sqlx.In(`
+			SELECT ?, s.player_id, l.match_id, s.leg_id, s.id, NULL, 180, l.end_time
+			FROM score s
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+			WHERE s.leg_id IN (?) AND s.is_bust = 0
+				AND IFNULL(l.leg_type_id, m.match_type_id) IN (?)
+				AND (IFNULL(s.first_dart, 0) * s.first_dart_multiplier +
+					IFNULL(s.second_dart, 0) * s.second_dart_multiplier +
+					IFNULL(s.third_dart, 0) * s.third_dart_multiplier) = 180
+			UNION ALL
+			SELECT ?, x.player_id, l.match_id, x.leg_id, NULL, NULL, x.checkout, l.end_time
+			FROM statistics_x01 x
+				JOIN leg l ON l.id = x.leg_id
+			WHERE x.leg_id IN (?) AND x.checkout >= ?`,
+			models.MomentMaximum, legIDs, []int{models.X01, models.SHOOTOUT, models.X01HANDICAP},
+			models.MomentHighCheckout, legIDs, models.Head2HeadHighCheckout)
+		if err != nil {
+			return nil, err
+		}
+		legMoments, err := scanHeadToHeadMoments(q, args...)
+		if err != nil {
+			return nil, err
+		}
+		moments = append(moments, legMoments...)
+	}
+
+	// Badges unlocked in a leg between the two, or with the other player as opponent
+	query := `
+		SELECT ?, player_id, match_id, leg_id, visit_id, badge_id, level, created_at
+		FROM player2badge
+		WHERE player_id IN (?, ?) AND (opponent_player_id IN (?, ?)`
+	args := []interface{}{models.MomentBadge, player1, player2, player1, player2}
+	if len(legIDs) > 0 {
+		query += " OR leg_id IN (?)"
+		args = append(args, legIDs)
+	}
+	q, args, err := sqlx.In(query+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	badgeMoments, err := scanHeadToHeadMoments(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	moments = append(moments, badgeMoments...)
+	return models.SortHead2HeadMoments(moments), nil
+}
+
+// scanHeadToHeadMoments will return the moments selected by the given query
+func scanHeadToHeadMoments(query string, args ...interface{}) ([]*models.Head2HeadMoment, error) {
+	rows, err := models.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	moments := make([]*models.Head2HeadMoment, 0)
+	for rows.Next() {
+		m := new(models.Head2HeadMoment)
+		err := rows.Scan(&m.Type, &m.PlayerID, &m.MatchID, &m.LegID, &m.VisitID, &m.BadgeID, &m.Value, &m.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		moments = append(moments, m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return moments, nil
+}
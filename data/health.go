@@ -0,0 +1,94 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/models"
+)
+
+// requiredTables are tables created by schema.sql, which are not yet part of the database migrations
+var requiredTables = []string{
+	"statistics_bobs_27", "statistics_121", "statistics_golf", "statistics_baseball", "statistics_rollup",
+	"statistics_rollup_checkout", "owe_transaction", "venue_queue", "badge_rule", "report", "player_record"}
+
+// GetReadiness will check database connectivity, that the schema is migrated to at least the given version, that all
+// tables from schema.sql exist, and that the connection pool usage is below the given ratio
+func GetReadiness(minSchemaVersion int64, maxPoolUsage float64) *models.Readiness {
+	readiness := &models.Readiness{Ready: true}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	err := models.DB.PingContext(ctx)
+	if err != nil {
+		readiness.AddCheck("database", false, err.Error())
+		return readiness
+	}
+	readiness.AddCheck("database", true, "")
+
+	if minSchemaVersion > 0 {
+		var version int64
+		err = models.DB.QueryRowContext(ctx, "SELECT IFNULL(MAX(version_id), 0) FROM goose_db_version WHERE is_applied = 1").Scan(&version)
+		if err != nil {
+			readiness.AddCheck("schema", false, err.Error())
+		} else if version < minSchemaVersion {
+			readiness.AddCheck("schema", false, fmt.Sprintf("schema version %d is older than required version %d", version, minSchemaVersion))
+		} else {
+			readiness.AddCheck("schema", true, fmt.Sprintf("schema version %d", version))
+		}
+	}
+
+	missing, err := getMissingTables(ctx, requiredTables)
+	if err != nil {
+		readiness.AddCheck("tables", false, err.Error())
+	} else if len(missing) > 0 {
+		readiness.AddCheck("tables", false, fmt.Sprintf("missing tables %s, apply schema.sql", strings.Join(missing, ", ")))
+	} else {
+		readiness.AddCheck("tables", true, "")
+	}
+
+	stats := models.DB.Stats()
+	if stats.MaxOpenConnections > 0 {
+		usage := float64(stats.InUse) / float64(stats.MaxOpenConnections)
+		message := fmt.Sprintf("%d of %d connections in use", stats.InUse, stats.MaxOpenConnections)
+		readiness.AddCheck("pool", usage < maxPoolUsage, message)
+	} else {
+		readiness.AddCheck("pool", true, fmt.Sprintf("%d connections in use", stats.InUse))
+	}
+	return readiness
+}
+
+// getMissingTables will return the given tables which do not exist in the database
+func getMissingTables(ctx context.Context, tables []string) ([]string, error) {
+	q, args, err := sqlx.In("SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name IN (?)", tables)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		existing[strings.ToLower(table)] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	missing := make([]string, 0)
+	for _, table := range tables {
+		if !existing[table] {
+			missing = append(missing, table)
+		}
+	}
+	return missing, nil
+}
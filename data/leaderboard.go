@@ -0,0 +1,131 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/models"
+)
+
+// leaderboardMetric is the key metric players are ranked by for a match type, aggregated over legs in s
+type leaderboardMetric struct {
+	name          string
+	table         string
+	value         string
+	lowerIsBetter bool
+}
+
+// leaderboardMetrics contains the key metric of every match type with a leaderboard
+var leaderboardMetrics = map[int]leaderboardMetric{
+	models.X01:             {"three_dart_avg", "statistics_x01", "SUM(s.ppd_score) * 3 / SUM(s.darts_thrown)", false},
+	models.SHOOTOUT:        {"avg_score", "statistics_shootout", "AVG(s.score)", false},
+	models.X01HANDICAP:     {"three_dart_avg", "statistics_x01", "SUM(s.ppd_score) * 3 / SUM(s.darts_thrown)", false},
+	models.CRICKET:         {"mpr", "statistics_cricket", "SUM(s.total_marks) / SUM(s.rounds)", false},
+	models.DARTSATX:        {"hit_rate", "statistics_darts_at_x", "AVG(s.hit_rate)", false},
+	models.AROUNDTHEWORLD:  {"mpr", "statistics_around_the", "AVG(s.mpr)", false},
+	models.SHANGHAI:        {"avg_score", "statistics_around_the", "AVG(s.score)", false},
+	models.AROUNDTHECLOCK:  {"darts_to_finish", "statistics_around_the", "AVG(CASE WHEN s.score = 21 THEN s.darts_thrown END)", true},
+	models.TICTACTOE:       {"numbers_closed", "statistics_tic_tac_toe", "AVG(s.numbers_closed)", false},
+	models.BERMUDATRIANGLE: {"avg_score", "statistics_bermuda_triangle", "AVG(s.score)", false},
+	models.FOURTWENTY:      {"hit_rate", "statistics_420", "AVG(s.total_hit_rate)", false},
+	models.KILLBULL:        {"avg_score", "statistics_kill_bull", "AVG(s.score)", false},
+	models.GOTCHA:          {"win_percentage", "statistics_gotcha", "AVG(s.leg_winner_id = s.player_id) * 100", false},
+	models.JDCPRACTICE:     {"avg_score", "statistics_jdc_practice", "AVG(s.score)", false},
+	models.KNOCKOUT:        {"win_percentage", "statistics_knockout", "AVG(s.leg_winner_id = s.player_id) * 100", false},
+	models.SCAM:            {"ppd", "statistics_scam", "AVG(s.ppd)", false},
+	models.ONESEVENTY:      {"checkout_percentage", "statistics_170", "SUM(s.checkout_completed) / SUM(s.checkout_attempts) * 100", false},
+	models.BOBS27:          {"avg_score", "statistics_bobs_27", "AVG(s.score)", false},
+	models.ONETWENTYONE:    {"checkout_percentage", "statistics_121", "SUM(s.checkouts) / SUM(s.attempts) * 100", false},
+	models.GOLF:            {"avg_score", "statistics_golf", "AVG(s.score)", true},
+	models.BASEBALL:        {"avg_score", "statistics_baseball", "AVG(s.score)", false},
+}
+
+// GetLeaderboard will return all eligible players ranked by the key metric of the given match type, within the given
+// office or globally if not valid. Each player is ranked on their last legs, and compared to the leaderboard a week ago
+func GetLeaderboard(matchType int, officeID null.Int, minimumLegs int) (*models.Leaderboard, error) {
+	metric, ok := leaderboardMetrics[matchType]
+	if !ok {
+		return nil, &models.LeaderboardError{Err: fmt.Errorf("no leaderboard for match type %d", matchType)}
+	}
+	opts, err := GetDefaultOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	leaderboard := &models.Leaderboard{
+		MatchType:         matchType,
+		Metric:            metric.name,
+		LowerIsBetter:     metric.lowerIsBetter,
+		OfficeID:          officeID,
+		LastLegs:          opts.LeaderboardLastLegsCount,
+		MinimumLegs:       minimumLegs,
+		ActivePeriodWeeks: opts.LeaderboardActivePeriodWeeks,
+	}
+	now := time.Now()
+	leaderboard.Entries, err = getLeaderboardEntries(matchType, metric, leaderboard, now)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := getLeaderboardEntries(matchType, metric, leaderboard, now.AddDate(0, 0, -7))
+	if err != nil {
+		return nil, err
+	}
+	leaderboard.SetPrevious(previous)
+	return leaderboard, nil
+}
+
+// getLeaderboardEntries will return the ranked entries of the given leaderboard, as it was at the given time
+func getLeaderboardEntries(matchType int, metric leaderboardMetric, leaderboard *models.Leaderboard, at time.Time) ([]*models.LeaderboardEntry, error) {
+	activeSince := at.AddDate(0, 0, -7*leaderboard.ActivePeriodWeeks)
+	rows, err := models.DB.Query(fmt.Sprintf(`
+		SELECT
+			s.player_id,
+			s.player_office_id,
+			COUNT(s.leg_id) AS 'legs_played',
+			MAX(s.leg_end_time) AS 'last_played_leg',
+			%s AS 'value'
+		FROM (
+			SELECT
+				s.*, p.office_id AS 'player_office_id', l.end_time AS 'leg_end_time', l.winner_id AS 'leg_winner_id',
+				ROW_NUMBER() OVER (PARTITION BY s.player_id ORDER BY l.id DESC) AS 'row_num'
+			FROM %s s
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+				JOIN player p ON p.id = s.player_id
+			WHERE IFNULL(l.leg_type_id, m.match_type_id) = ?
+				AND l.is_finished = 1 AND l.end_time < ?
+				AND m.is_abandoned = 0 AND m.is_walkover = 0 AND m.is_bye = 0
+				AND p.active = 1 AND p.is_bot = 0 AND p.is_placeholder = 0
+				AND (? IS NULL OR p.office_id = ?)
+		) s
+		WHERE s.row_num <= ?
+		GROUP BY s.player_id, s.player_office_id
+		HAVING COUNT(s.leg_id) >= ? AND MAX(s.leg_end_time) >= ?`, metric.value, metric.table),
+		matchType, at, leaderboard.OfficeID, leaderboard.OfficeID, leaderboard.LastLegs, leaderboard.MinimumLegs, activeSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*models.LeaderboardEntry, 0)
+	for rows.Next() {
+		entry := new(models.LeaderboardEntry)
+		var value null.Float
+		err := rows.Scan(&entry.PlayerID, &entry.OfficeID, &entry.LegsPlayed, &entry.LastPlayedLeg, &value)
+		if err != nil {
+			return nil, err
+		}
+		// Players without a value, such as never finishing Around the Clock, are not ranked
+		if !value.Valid {
+			continue
+		}
+		entry.Value = value.Float64
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	models.RankLeaderboard(entries, metric.lowerIsBetter)
+	return entries, nil
+}
@@ -1,18 +1,21 @@
 package data
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"math"
 	"sort"
 
 	"github.com/guregu/null"
 	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/logging"
 	"github.com/kcapp/api/models"
 	"github.com/kcapp/api/util"
 )
 
 // NewLeg will create a new leg for the given match
-func NewLeg(matchID int, startingScore int, players []int, matchType *int) (*models.Leg, error) {
+func NewLeg(ctx context.Context, matchID int, startingScore int, players []int, matchType *int) (*models.Leg, error) {
 	tx, err := models.DB.Begin()
 	if err != nil {
 		return nil, err
@@ -94,6 +97,21 @@ func NewLeg(matchID int, startingScore int, players []int, matchType *int) (*mod
 			tx.Rollback()
 			return nil, err
 		}
+	} else if *matchType == models.ONETWENTYONE {
+		params := match.Legs[0].Parameters
+		_, err = tx.Exec("INSERT INTO leg_parameters (leg_id, max_rounds, points_to_win, min_target, max_target) VALUES (?, ?, ?, ?, ?)",
+			legID, params.MaxRounds, params.PointsToWin, params.MinTarget, params.MaxTarget)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	} else if *matchType == models.GOLF || *matchType == models.BASEBALL {
+		params := match.Legs[0].Parameters
+		_, err = tx.Exec("INSERT INTO leg_parameters (leg_id, max_rounds) VALUES (?, ?)", legID, params.MaxRounds)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	}
 
 	for idx, playerID := range players {
@@ -120,13 +138,21 @@ func NewLeg(matchID int, startingScore int, players []int, matchType *int) (*mod
 		}
 	}
 	tx.Commit()
-	log.Printf("[%d] Started new leg", legID)
+	logging.Logger(ctx).Printf("[%d] Started new leg", legID)
+	UpdateActiveMetrics()
 
 	return GetLeg(int(legID))
 }
 
 // FinishLeg will finalize a leg by updating the winner and writing statistics for each player
-func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
+func FinishLeg(ctx context.Context, legID int, currentPlayer int, winnerID null.Int) error {
+	defer lockLeg(legID)()
+	return finishLeg(ctx, legID, currentPlayer, winnerID)
+}
+
+// finishLeg will finalize a leg, must be called with the leg locked
+func finishLeg(ctx context.Context, legID int, currentPlayer int, winnerID null.Int) error {
+	logger := logging.Logger(ctx)
 	tx, err := models.DB.Begin()
 	if err != nil {
 		return err
@@ -150,12 +176,152 @@ func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
 		return err
 	}
 	leg.WinnerPlayerID = winnerID
-	log.Printf("[%d] Finished with player %d winning", legID, winnerID.ValueOrZero())
+	logger.Printf("[%d] Finished with player %d winning", legID, winnerID.ValueOrZero())
+
+	err = insertLegStatistics(ctx, tx, leg, matchType)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Check if match is finished or not
+	winsMap, err := GetWinsPerPlayer(match.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Determine how many legs has been played, and how many current player has won
+	playedLegs := 1
+	currentPlayerWins := 1
+	for playerID, wins := range winsMap {
+		playedLegs += wins
+		if playerID == int(winnerID.ValueOrZero()) {
+			currentPlayerWins += wins
+		}
+	}
+
+	isFinished := false
+	isTieBreak := false
+	if currentPlayerWins == match.MatchMode.WinsRequired {
+		// Match finished, current player won
+		isFinished = true
+		_, err = tx.Exec("UPDATE matches SET is_finished = 1, winner_id = ? WHERE id = ?", winnerID, match.ID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		// Add owes between players in match
+		if match.OweType != nil {
+			for _, playerID := range match.Players {
+				if playerID == int(winnerID.ValueOrZero()) {
+					// Don't add payback to ourself
+					continue
+				}
+				_, err = tx.Exec(`
+					INSERT INTO owes (player_ower_id, player_owee_id, owe_type_id, amount) VALUES (?, ?, ?, 1)
+					ON DUPLICATE KEY UPDATE amount = amount + 1`, playerID, winnerID, match.OweTypeID)
+				if err != nil {
+					tx.Rollback()
+					return err
+				}
+				err = addOweTransaction(tx, playerID, int(winnerID.Int64), match.OweTypeID, 1, null.IntFrom(int64(match.ID)), false)
+				if err != nil {
+					tx.Rollback()
+					return err
+				}
+				logger.Printf("Added owes of %s from player %d to player %d", match.OweType.Item.String, playerID, winnerID.Int64)
+			}
+		}
+		match.WinnerID = winnerID
+		logger.Printf("Match %d finished with player %d winning", match.ID, winnerID.ValueOrZero())
+	} else if match.MatchMode.LegsRequired.Valid && playedLegs == int(match.MatchMode.LegsRequired.Int64) {
+		// Match finished, draw
+		isFinished = true
+		_, err = tx.Exec("UPDATE matches SET is_finished = 1 WHERE id = ?", match.ID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		logger.Printf("Match %d finished with a Draw", match.ID)
+	} else if playedLegs == (int(match.MatchMode.LegsRequired.Int64)-1) && match.MatchMode.TieBreakMatchTypeID.Valid {
+		isTieBreak = true
+	}
+	match.IsFinished = isFinished
+
+	err = addStatisticsRollup(ctx, tx, legID, 1, isFinished && !match.IsAbandoned && !match.IsBye && !match.IsWalkover)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	tx.Commit()
+	UpdateActiveMetrics()
+
+	if isFinished {
+		// Update Elo for players if match is finished
+		err = UpdateEloForMatch(match.ID)
+		if err != nil {
+			return err
+		}
+
+		if match.TournamentID.Valid {
+			err = AdvanceTournamentAfterMatch(match)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = CheckMatchForBadges(ctx, match)
+		if err != nil {
+			return err
+		}
+
+		err = removeFromVenueQueue(ctx, match.ID)
+		if err != nil {
+			return err
+		}
+		if match.VenueID.Valid {
+			notifyVenueQueue(int(match.VenueID.Int64))
+		}
+	} else {
+		logger.Printf("Match %d is not finished, creating next leg", match.ID)
+		var matchType *int
+		if isTieBreak {
+			matchType = new(int)
+			*matchType = int(match.MatchMode.TieBreakMatchTypeID.Int64)
+		}
+		_, err = NewLeg(ctx, match.ID, leg.StartingScore, leg.Players, matchType)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Calculate badges earned in this leg
+	statistics, err := GetPlayerBadgeStatistics(leg.Players, nil)
+	if err != nil {
+		return err
+	}
+	err = CheckLegForBadges(ctx, leg, statistics)
+	if err != nil {
+		return err
+	}
+	err = CheckLegForRecords(ctx, leg, matchType)
+	if err != nil {
+		// The leg is already finished, records can be rebuilt using 'player records recalculate'
+		logger.Printf("[%d] Unable to check for records: %s", legID, err)
+	}
+	invalidateMatch(match)
+
+	return nil
+}
 
+// insertLegStatistics will calculate and insert statistics for all players in the given finished leg
+func insertLegStatistics(ctx context.Context, tx *sql.Tx, leg *models.Leg, matchType int) error {
+	logger := logging.Logger(ctx)
+	legID := leg.ID
 	if matchType == models.SHOOTOUT {
 		statisticsMap, err := CalculateShootoutStatistics(legID)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 		for playerID, stats := range statisticsMap {
@@ -164,15 +330,13 @@ func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
 				VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, legID, playerID, stats.Score, stats.PPD, stats.Score60sPlus,
 				stats.Score100sPlus, stats.Score140sPlus, stats.Score180s)
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
-			log.Printf("[%d] Inserting shootout statistics for player %d", legID, playerID)
+			logger.Printf("[%d] Inserting shootout statistics for player %d", legID, playerID)
 		}
 	} else if matchType == models.CRICKET {
 		statisticsMap, err := CalculateCricketStatistics(legID)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 		for playerID, stats := range statisticsMap {
@@ -182,15 +346,13 @@ func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
 				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, legID, playerID, stats.TotalMarks, stats.Rounds, stats.Score, stats.FirstNineMarks,
 				stats.MPR, stats.FirstNineMPR, stats.Marks5, stats.Marks6, stats.Marks7, stats.Marks8, stats.Marks9)
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
-			log.Printf("[%d] Inserting cricket statistics for player %d", legID, playerID)
+			logger.Printf("[%d] Inserting cricket statistics for player %d", legID, playerID)
 		}
 	} else if matchType == models.DARTSATX {
 		statisticsMap, err := CalculateDartsAtXStatistics(legID)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 		for playerID, stats := range statisticsMap {
@@ -200,15 +362,13 @@ func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
 				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, legID, playerID, stats.Score, stats.Singles, stats.Doubles, stats.Triples, stats.HitRate,
 				stats.Hits5, stats.Hits6, stats.Hits7, stats.Hits8, stats.Hits9)
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
-			log.Printf("[%d] Inserting Darts At %d statistics for player %d", legID, leg.StartingScore, playerID)
+			logger.Printf("[%d] Inserting Darts At %d statistics for player %d", legID, leg.StartingScore, playerID)
 		}
 	} else if matchType == models.AROUNDTHECLOCK {
 		statisticsMap, err := CalculateAroundTheClockStatistics(legID)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 		for playerID, stats := range statisticsMap {
@@ -221,15 +381,13 @@ func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
 				stats.Hitrates[11], stats.Hitrates[12], stats.Hitrates[13], stats.Hitrates[14], stats.Hitrates[15], stats.Hitrates[16], stats.Hitrates[17], stats.Hitrates[18], stats.Hitrates[19],
 				stats.Hitrates[20], stats.Hitrates[25])
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
-			log.Printf("[%d] Inserting Around the Clock statistics for player %d", legID, playerID)
+			logger.Printf("[%d] Inserting Around the Clock statistics for player %d", legID, playerID)
 		}
 	} else if matchType == models.AROUNDTHEWORLD || matchType == models.SHANGHAI {
 		statisticsMap, err := CalculateAroundTheWorldStatistics(legID, matchType)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 		for playerID, stats := range statisticsMap {
@@ -242,15 +400,13 @@ func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
 				stats.Hitrates[11], stats.Hitrates[12], stats.Hitrates[13], stats.Hitrates[14], stats.Hitrates[15], stats.Hitrates[16], stats.Hitrates[17], stats.Hitrates[18], stats.Hitrates[19],
 				stats.Hitrates[20], stats.Hitrates[25])
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
-			log.Printf("[%d] Inserting Around the World/Shanghai statistics for player %d", legID, playerID)
+			logger.Printf("[%d] Inserting Around the World/Shanghai statistics for player %d", legID, playerID)
 		}
 	} else if matchType == models.TICTACTOE {
 		statisticsMap, err := CalculateTicTacToeStatistics(legID)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 		for playerID, stats := range statisticsMap {
@@ -258,15 +414,13 @@ func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
 				INSERT INTO statistics_tic_tac_toe (leg_id, player_id, darts_thrown, score, numbers_closed, highest_closed) VALUES (?,?,?,?,?,?)`, legID,
 				playerID, stats.DartsThrown, stats.Score, stats.NumbersClosed, stats.HighestClosed)
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
-			log.Printf("[%d] Inserting Tic Tac Toe statistics for player %d", legID, playerID)
+			logger.Printf("[%d] Inserting Tic Tac Toe statistics for player %d", legID, playerID)
 		}
 	} else if matchType == models.BERMUDATRIANGLE {
 		statisticsMap, err := CalculateBermudaTriangleStatistics(legID)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 		for playerID, stats := range statisticsMap {
@@ -277,15 +431,13 @@ func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
 				stats.Hitrates[3], stats.Hitrates[4], stats.Hitrates[5], stats.Hitrates[6], stats.Hitrates[7], stats.Hitrates[8], stats.Hitrates[9], stats.Hitrates[10], stats.Hitrates[11], stats.Hitrates[12],
 				stats.HitCount)
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
-			log.Printf("[%d] Inserting Bermuda Triangle statistics for player %d", legID, playerID)
+			logger.Printf("[%d] Inserting Bermuda Triangle statistics for player %d", legID, playerID)
 		}
 	} else if matchType == models.FOURTWENTY {
 		statisticsMap, err := Calculate420Statistics(legID)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 		for playerID, stats := range statisticsMap {
@@ -296,15 +448,13 @@ func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
 				stats.Hitrates[7], stats.Hitrates[8], stats.Hitrates[9], stats.Hitrates[10], stats.Hitrates[11], stats.Hitrates[12], stats.Hitrates[13], stats.Hitrates[14], stats.Hitrates[15], stats.Hitrates[16],
 				stats.Hitrates[17], stats.Hitrates[18], stats.Hitrates[19], stats.Hitrates[20], stats.Hitrates[25])
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
-			log.Printf("[%d] Inserting Four Twenty statistics for player %d", legID, playerID)
+			logger.Printf("[%d] Inserting Four Twenty statistics for player %d", legID, playerID)
 		}
 	} else if matchType == models.KILLBULL {
 		statisticsMap, err := CalculateKillBullStatistics(legID)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 		for playerID, stats := range statisticsMap {
@@ -312,15 +462,13 @@ func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
 						INSERT INTO statistics_kill_bull (leg_id, player_id, darts_thrown, score, marks3, marks4, marks5, marks6, longest_streak, times_busted, total_hit_rate) VALUES (?,?,?,?,?,?,?,?,?,?,?)`,
 				legID, playerID, stats.DartsThrown, stats.Score, stats.Marks3, stats.Marks4, stats.Marks5, stats.Marks6, stats.LongestStreak, stats.TimesBusted, stats.TotalHitRate)
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
-			log.Printf("[%d] Inserting Kill Bull statistics for player %d", legID, playerID)
+			logger.Printf("[%d] Inserting Kill Bull statistics for player %d", legID, playerID)
 		}
 	} else if matchType == models.GOTCHA {
 		statisticsMap, err := CalculateGotchaStatistics(legID)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 		for playerID, stats := range statisticsMap {
@@ -328,15 +476,13 @@ func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
 				INSERT INTO statistics_gotcha (leg_id, player_id, darts_thrown, highest_score, times_reset, others_reset, score) VALUES (?,?,?,?,?,?,?)`,
 				legID, playerID, stats.DartsThrown, stats.HighestScore, stats.TimesReset, stats.OthersReset, stats.Score)
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
-			log.Printf("[%d] Inserting Gotcha statistics for player %d", legID, playerID)
+			logger.Printf("[%d] Inserting Gotcha statistics for player %d", legID, playerID)
 		}
 	} else if matchType == models.JDCPRACTICE {
 		statisticsMap, err := CalculateJDCPracticeStatistics(legID)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 		for playerID, stats := range statisticsMap {
@@ -344,15 +490,13 @@ func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
 				INSERT INTO statistics_jdc_practice (leg_id, player_id, darts_thrown, score, mpr, shanghai_count, doubles_hitrate) VALUES (?,?,?,?,?,?,?)`,
 				legID, playerID, stats.DartsThrown, stats.Score, stats.MPR, stats.ShanghaiCount, stats.DoublesHitrate)
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
-			log.Printf("[%d] Inserting JDC Practice statistics for player %d", legID, playerID)
+			logger.Printf("[%d] Inserting JDC Practice statistics for player %d", legID, playerID)
 		}
 	} else if matchType == models.KNOCKOUT {
 		statisticsMap, err := CalculateKnockoutStatistics(legID)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 		for playerID, stats := range statisticsMap {
@@ -360,15 +504,13 @@ func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
 				INSERT INTO statistics_knockout (leg_id, player_id, darts_thrown, avg_score, lives_lost, lives_taken, final_position) VALUES (?,?,?,?,?,?,?)`,
 				legID, playerID, stats.DartsThrown, stats.AvgScore, stats.LivesLost, stats.LivesTaken, stats.FinalPosition)
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
-			log.Printf("[%d] Inserting Knockout statistics for player %d", legID, playerID)
+			logger.Printf("[%d] Inserting Knockout statistics for player %d", legID, playerID)
 		}
 	} else if matchType == models.SCAM {
 		statisticsMap, err := CalculateScamStatistics(legID)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 		for playerID, stats := range statisticsMap {
@@ -376,15 +518,13 @@ func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
 				INSERT INTO statistics_scam (leg_id, player_id, darts_thrown_stopper, darts_thrown_scorer, mpr, ppd, score) VALUES (?,?,?,?,?,?,?)`,
 				legID, playerID, stats.DartsThrownStopper, stats.DartsThrownScorer, stats.MPR, stats.PPD, stats.Score)
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
-			log.Printf("[%d] Inserting Scam statistics for player %d", legID, playerID)
+			logger.Printf("[%d] Inserting Scam statistics for player %d", legID, playerID)
 		}
 	} else if matchType == models.ONESEVENTY {
 		statisticsMap, err := Calculate170Statistics(legID)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 		for playerID, stats := range statisticsMap {
@@ -396,145 +536,116 @@ func FinishLeg(legID int, currentPlayer int, winnerID null.Int) error {
 				stats.CheckoutPercentage, stats.CheckoutAttempts, stats.CheckoutCompleted, stats.HighestCheckout, stats.DartsThrown, stats.CheckoutDarts[9],
 				stats.CheckoutDarts[8], stats.CheckoutDarts[7], stats.CheckoutDarts[6], stats.CheckoutDarts[5], stats.CheckoutDarts[4], stats.CheckoutDarts[3])
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
-			log.Printf("[%d] Inserting 170 statistics for player %d", legID, playerID)
+			logger.Printf("[%d] Inserting 170 statistics for player %d", legID, playerID)
 		}
-	} else {
-		statisticsMap, err := CalculateX01Statistics(legID)
+	} else if matchType == models.BOBS27 {
+		statisticsMap, err := CalculateBobs27Statistics(legID)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 		for playerID, stats := range statisticsMap {
 			_, err = tx.Exec(`
-				INSERT INTO statistics_x01
-					(leg_id, player_id, ppd, ppd_score, first_nine_ppd, first_nine_ppd_score, checkout_percentage, checkout_attempts, checkout, darts_thrown, 60s_plus,
-					 100s_plus, 140s_plus, 180s, accuracy_20, accuracy_19, overall_accuracy)
-				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, legID, playerID, stats.PPD, stats.PPDScore, stats.FirstNinePPD, stats.FirstNinePPDScore,
-				stats.CheckoutPercentage, stats.CheckoutAttempts, stats.Checkout, stats.DartsThrown, stats.Score60sPlus, stats.Score100sPlus, stats.Score140sPlus,
-				stats.Score180s, stats.AccuracyStatistics.Accuracy20, stats.AccuracyStatistics.Accuracy19, stats.AccuracyStatistics.AccuracyOverall)
+				INSERT INTO statistics_bobs_27 (leg_id, player_id, darts_thrown, score, highest_score, rounds_played, doubles_hit, doubles_hitrate, is_busted)
+				VALUES (?,?,?,?,?,?,?,?,?)`, legID, playerID, stats.DartsThrown, stats.Score, stats.HighestScore, stats.RoundsPlayed, stats.DoublesHit,
+				stats.DoublesHitrate, stats.IsBusted)
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
-			log.Printf("[%d] Inserting x01 statistics for player %d", legID, playerID)
+			logger.Printf("[%d] Inserting Bob's 27 statistics for player %d", legID, playerID)
 		}
-	}
-
-	// Check if match is finished or not
-	winsMap, err := GetWinsPerPlayer(match.ID)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	// Determine how many legs has been played, and how many current player has won
-	playedLegs := 1
-	currentPlayerWins := 1
-	for playerID, wins := range winsMap {
-		playedLegs += wins
-		if playerID == int(winnerID.ValueOrZero()) {
-			currentPlayerWins += wins
-		}
-	}
-
-	isFinished := false
-	isTieBreak := false
-	if currentPlayerWins == match.MatchMode.WinsRequired {
-		// Match finished, current player won
-		isFinished = true
-		_, err = tx.Exec("UPDATE matches SET is_finished = 1, winner_id = ? WHERE id = ?", winnerID, match.ID)
+	} else if matchType == models.ONETWENTYONE {
+		statisticsMap, err := Calculate121Statistics(legID)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
-		// Add owes between players in match
-		if match.OweType != nil {
-			for _, playerID := range match.Players {
-				if playerID == int(winnerID.ValueOrZero()) {
-					// Don't add payback to ourself
-					continue
-				}
-				_, err = tx.Exec(`
-					INSERT INTO owes (player_ower_id, player_owee_id, owe_type_id, amount) VALUES (?, ?, ?, 1)
-					ON DUPLICATE KEY UPDATE amount = amount + 1`, playerID, winnerID, match.OweTypeID)
-				if err != nil {
-					tx.Rollback()
-					return err
-				}
-				log.Printf("Added owes of %s from player %d to player %d", match.OweType.Item.String, playerID, winnerID.Int64)
+		for playerID, stats := range statisticsMap {
+			_, err = tx.Exec(`
+				INSERT INTO statistics_121 (leg_id, player_id, darts_thrown, attempts, checkouts, checkout_percentage, highest_checkout, highest_target, checkout_darts)
+				VALUES (?,?,?,?,?,?,?,?,?)`, legID, playerID, stats.DartsThrown, stats.Attempts, stats.Checkouts, stats.CheckoutPercentage,
+				stats.HighestCheckout, stats.HighestTarget, stats.CheckoutDarts)
+			if err != nil {
+				return err
 			}
+			logger.Printf("[%d] Inserting 121 statistics for player %d", legID, playerID)
 		}
-		match.WinnerID = winnerID
-		log.Printf("Match %d finished with player %d winning", match.ID, winnerID.ValueOrZero())
-	} else if match.MatchMode.LegsRequired.Valid && playedLegs == int(match.MatchMode.LegsRequired.Int64) {
-		// Match finished, draw
-		isFinished = true
-		_, err = tx.Exec("UPDATE matches SET is_finished = 1 WHERE id = ?", match.ID)
-		if err != nil {
-			tx.Rollback()
-			return err
-		}
-		log.Printf("Match %d finished with a Draw", match.ID)
-	} else if playedLegs == (int(match.MatchMode.LegsRequired.Int64)-1) && match.MatchMode.TieBreakMatchTypeID.Valid {
-		isTieBreak = true
-	}
-	match.IsFinished = isFinished
-	tx.Commit()
-
-	if isFinished {
-		// Update Elo for players if match is finished
-		err = UpdateEloForMatch(match.ID)
+	} else if matchType == models.GOLF {
+		statisticsMap, err := CalculateGolfStatistics(legID)
 		if err != nil {
 			return err
 		}
-
-		if match.TournamentID.Valid {
-			err = AdvanceTournamentAfterMatch(match)
+		for playerID, stats := range statisticsMap {
+			_, err = tx.Exec(`
+				INSERT INTO statistics_golf (leg_id, player_id, darts_thrown, score, holes_played, holes_in_one, holes_missed, hit_rate)
+				VALUES (?,?,?,?,?,?,?,?)`, legID, playerID, stats.DartsThrown, stats.Score, stats.HolesPlayed, stats.HolesInOne,
+				stats.HolesMissed, stats.HitRate)
 			if err != nil {
 				return err
 			}
+			logger.Printf("[%d] Inserting Golf statistics for player %d", legID, playerID)
 		}
-
-		err = CheckMatchForBadges(match)
+	} else if matchType == models.BASEBALL {
+		statisticsMap, err := CalculateBaseballStatistics(legID)
 		if err != nil {
 			return err
 		}
-	} else {
-		log.Printf("Match %d is not finished, creating next leg", match.ID)
-		var matchType *int
-		if isTieBreak {
-			matchType = new(int)
-			*matchType = int(match.MatchMode.TieBreakMatchTypeID.Int64)
+		for playerID, stats := range statisticsMap {
+			_, err = tx.Exec(`
+				INSERT INTO statistics_baseball (leg_id, player_id, darts_thrown, score, innings_played, highest_inning, perfect_innings, hit_rate)
+				VALUES (?,?,?,?,?,?,?,?)`, legID, playerID, stats.DartsThrown, stats.Score, stats.InningsPlayed, stats.HighestInning,
+				stats.PerfectInnings, stats.HitRate)
+			if err != nil {
+				return err
+			}
+			logger.Printf("[%d] Inserting Baseball statistics for player %d", legID, playerID)
 		}
-		_, err = NewLeg(match.ID, leg.StartingScore, leg.Players, matchType)
+	} else {
+		statisticsMap, err := CalculateX01Statistics(legID)
 		if err != nil {
 			return err
 		}
+		for playerID, stats := range statisticsMap {
+			_, err = tx.Exec(`
+				INSERT INTO statistics_x01
+					(leg_id, player_id, ppd, ppd_score, first_nine_ppd, first_nine_ppd_score, checkout_percentage, checkout_attempts, checkout, darts_thrown, 60s_plus,
+					 100s_plus, 140s_plus, 180s, accuracy_20, accuracy_19, overall_accuracy)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, legID, playerID, stats.PPD, stats.PPDScore, stats.FirstNinePPD, stats.FirstNinePPDScore,
+				stats.CheckoutPercentage, stats.CheckoutAttempts, stats.Checkout, stats.DartsThrown, stats.Score60sPlus, stats.Score100sPlus, stats.Score140sPlus,
+				stats.Score180s, stats.AccuracyStatistics.Accuracy20, stats.AccuracyStatistics.Accuracy19, stats.AccuracyStatistics.AccuracyOverall)
+			if err != nil {
+				return err
+			}
+			logger.Printf("[%d] Inserting x01 statistics for player %d", legID, playerID)
+		}
 	}
-
-	// Calculate badges earned in this leg
-	statistics, err := GetPlayerBadgeStatistics(leg.Players, nil)
-	if err != nil {
-		return err
-	}
-	err = CheckLegForBadges(leg, statistics)
-	if err != nil {
-		return err
-	}
-
 	return nil
 }
 
 // UndoLegFinish will undo a finalized leg
-func UndoLegFinish(legID int) error {
+func UndoLegFinish(ctx context.Context, legID int) error {
 	tx, err := models.DB.Begin()
 	if err != nil {
 		return err
 	}
 
+	// Remove the leg from the statistics rollup before it is reopened
+	var legFinished, matchFinished bool
+	err = tx.QueryRow(`
+		SELECT l.is_finished, m.is_finished = 1 AND m.is_abandoned = 0 AND m.is_bye = 0 AND m.is_walkover = 0
+		FROM leg l JOIN matches m ON m.id = l.match_id WHERE l.id = ?`, legID).Scan(&legFinished, &matchFinished)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if legFinished {
+		err = addStatisticsRollup(ctx, tx, legID, -1, matchFinished)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
 	// Undo the finalized match
 	_, err = tx.Exec("UPDATE matches SET is_finished = 0, winner_id = NULL WHERE id = (SELECT match_id FROM leg WHERE id = ?)", legID)
 	if err != nil {
@@ -618,6 +729,26 @@ func UndoLegFinish(legID int) error {
 		tx.Rollback()
 		return err
 	}
+	_, err = tx.Exec("DELETE FROM statistics_bobs_27 WHERE leg_id = ?", legID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM statistics_121 WHERE leg_id = ?", legID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM statistics_golf WHERE leg_id = ?", legID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM statistics_baseball WHERE leg_id = ?", legID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
 	// Delete any earned badges
 	_, err = tx.Exec("DELETE FROM player2badge WHERE leg_id = ? OR match_id IN (SELECT match_id FROM leg WHERE id = ?)", legID, legID)
@@ -626,6 +757,13 @@ func UndoLegFinish(legID int) error {
 		return err
 	}
 
+	// Delete any records set
+	_, err = tx.Exec("DELETE FROM player_record WHERE leg_id = ?", legID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// Remove the last score
 	_, err = tx.Exec("DELETE FROM score WHERE leg_id = ? ORDER BY id DESC LIMIT 1", legID)
 	if err != nil {
@@ -652,7 +790,13 @@ func UndoLegFinish(legID int) error {
 	}
 
 	tx.Commit()
-	log.Printf("[%d] Undo finish of leg", legID)
+	logging.Logger(ctx).Printf("[%d] Undo finish of leg", legID)
+	UpdateActiveMetrics()
+	if legFinished {
+		// Badges are unlocked again when the leg is finished
+		queueLegRecalculation(ctx, legID, nil)
+	}
+	invalidateLeg(legID)
 	return nil
 }
 
@@ -696,7 +840,7 @@ func GetLegsForMatch(matchID int) ([]*models.Leg, error) {
 
 		matchType := leg.LegType.ID
 		if matchType == models.X01 || matchType == models.X01HANDICAP || matchType == models.TICTACTOE || matchType == models.KNOCKOUT ||
-			matchType == models.ONESEVENTY {
+			matchType == models.ONESEVENTY || matchType == models.ONETWENTYONE || matchType == models.GOLF || matchType == models.BASEBALL {
 			leg.Parameters, err = GetLegParameters(leg.ID)
 			if err != nil {
 				return nil, err
@@ -713,6 +857,25 @@ func GetLegsForMatch(matchID int) ([]*models.Leg, error) {
 
 // GetLegs returns all legs with the given IDs
 func GetLegs(ids []int) ([]*models.Leg, error) {
+	return getLegs("l.id IN (?)", ids)
+}
+
+// GetLegsForMatches returns all legs of the given matches, by match ID
+func GetLegsForMatches(matchIDs []int) (map[int][]*models.Leg, error) {
+	legs, err := getLegs("l.match_id IN (?)", matchIDs)
+	if err != nil {
+		return nil, err
+	}
+	matchLegs := make(map[int][]*models.Leg)
+	for _, leg := range legs {
+		matchLegs[leg.MatchID] = append(matchLegs[leg.MatchID], leg)
+	}
+	return matchLegs, nil
+}
+
+// getLegs returns all legs matching the given condition, which may contain a list parameter. Visits and parameters
+// of all legs are loaded in a single query each
+func getLegs(condition string, args ...interface{}) ([]*models.Leg, error) {
 	q, args, err := sqlx.In(`
 		SELECT
 			l.id, l.end_time, l.starting_score, l.is_finished,
@@ -723,9 +886,9 @@ func GetLegs(ids []int) ([]*models.Leg, error) {
 			LEFT JOIN player2leg p2l ON p2l.leg_id = l.id
 			LEFT JOIN matches m ON m.id = l.match_id
 			LEFT JOIN match_type mt on mt.id = IFNULL(l.leg_type_id, m.match_type_id)
-		WHERE l.id IN(?)
+		WHERE `+condition+`
 		GROUP BY l.id
-		ORDER BY l.id ASC`, ids)
+		ORDER BY l.id ASC`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -736,6 +899,7 @@ func GetLegs(ids []int) ([]*models.Leg, error) {
 	defer rows.Close()
 
 	legs := make([]*models.Leg, 0)
+	ids := make([]int, 0)
 	for rows.Next() {
 		leg := new(models.Leg)
 		leg.LegType = new(models.MatchType)
@@ -747,26 +911,38 @@ func GetLegs(ids []int) ([]*models.Leg, error) {
 			return nil, err
 		}
 		leg.Players = util.StringToIntArray(players)
-		visits, err := GetLegVisits(leg.ID)
-		if err != nil {
-			return nil, err
-		}
-		leg.Visits = visits
-
-		matchType := leg.LegType.ID
-		if matchType == models.X01 || matchType == models.X01HANDICAP || matchType == models.TICTACTOE || matchType == models.KNOCKOUT ||
-			matchType == models.ONESEVENTY {
-			leg.Parameters, err = GetLegParameters(leg.ID)
-			if err != nil {
-				return nil, err
-			}
-		}
 		legs = append(legs, leg)
+		ids = append(ids, leg.ID)
 	}
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
+	if len(legs) == 0 {
+		return legs, nil
+	}
 
+	visits, err := getLegsVisits(ids)
+	if err != nil {
+		return nil, err
+	}
+	parameters, err := getLegsParameters(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, leg := range legs {
+		leg.Visits = visits[leg.ID]
+		if leg.Visits == nil {
+			leg.Visits = make([]*models.Visit, 0)
+		}
+		matchType := leg.LegType.ID
+		if matchType == models.X01 || matchType == models.X01HANDICAP || matchType == models.TICTACTOE || matchType == models.KNOCKOUT ||
+			matchType == models.ONESEVENTY || matchType == models.ONETWENTYONE || matchType == models.GOLF || matchType == models.BASEBALL {
+			leg.Parameters = parameters[leg.ID]
+			if leg.Parameters == nil {
+				leg.Parameters = new(models.LegParameters)
+			}
+		}
+	}
 	return legs, nil
 }
 
@@ -809,7 +985,7 @@ func GetLegsOfType(matchType int, playerID int, start int, limit int, loadVisits
 			leg.Visits = visits
 		}
 		if matchType == models.X01 || matchType == models.TICTACTOE || matchType == models.KNOCKOUT ||
-			matchType == models.ONESEVENTY {
+			matchType == models.ONESEVENTY || matchType == models.ONETWENTYONE || matchType == models.GOLF || matchType == models.BASEBALL {
 			leg.Parameters, err = GetLegParameters(leg.ID)
 			if err != nil {
 				return nil, err
@@ -959,7 +1135,8 @@ func GetLeg(id int) (*models.Leg, error) {
 
 	matchType := leg.LegType.ID
 	if matchType == models.X01 || matchType == models.X01HANDICAP || matchType == models.TICTACTOE ||
-		matchType == models.KNOCKOUT || matchType == models.ONESEVENTY {
+		matchType == models.KNOCKOUT || matchType == models.ONESEVENTY || matchType == models.ONETWENTYONE ||
+		matchType == models.GOLF || matchType == models.BASEBALL {
 		leg.Parameters, err = GetLegParameters(id)
 		if err != nil {
 			return nil, err
@@ -973,13 +1150,19 @@ func GetLeg(id int) (*models.Leg, error) {
 		p2l.Hits = make(models.HitsMap)
 		if matchType == models.DARTSATX || matchType == models.AROUNDTHECLOCK || matchType == models.AROUNDTHEWORLD || matchType == models.SHANGHAI ||
 			matchType == models.TICTACTOE || matchType == models.BERMUDATRIANGLE || matchType == models.GOTCHA || matchType == models.JDCPRACTICE ||
-			matchType == models.SHOOTOUT || matchType == models.SCAM {
+			matchType == models.SHOOTOUT || matchType == models.SCAM || matchType == models.GOLF || matchType == models.BASEBALL {
 			p2l.CurrentScore = 0
 		} else if matchType == models.KNOCKOUT {
 			p2l.CurrentScore = 0
 			p2l.Lives = null.IntFrom(leg.Parameters.StartingLives.Int64)
 		} else if matchType == models.FOURTWENTY {
 			p2l.CurrentScore = 420
+		} else if matchType == models.BOBS27 {
+			p2l.CurrentScore = models.Bobs27StartingScore
+		} else if matchType == models.ONETWENTYONE {
+			p2l.CurrentScore = leg.StartingScore
+			p2l.StartingScore = leg.StartingScore
+			p2l.CurrentPoints = null.IntFrom(0)
 		} else if matchType == models.X01HANDICAP {
 			players, err := GetPlayersScore(id)
 			if err != nil {
@@ -1016,6 +1199,8 @@ func GetLeg(id int) (*models.Leg, error) {
 	dartsThrown := 0
 	visitCount := 0
 	round := 1
+	// Rounds played by each player, used by game types where players can be knocked out
+	playerRounds := make(map[int]int)
 	for i, visit := range visits {
 		if i > 0 && i%len(leg.Players) == 0 {
 			round++
@@ -1090,6 +1275,15 @@ func GetLeg(id int) (*models.Leg, error) {
 			} else if matchType == models.JDCPRACTICE {
 				score = visit.CalculateJDCPracticeScore(round - 1)
 				scores[visit.PlayerID].CurrentScore += score
+			} else if matchType == models.BOBS27 {
+				score = visit.CalculateBobs27Score(playerRounds[visit.PlayerID])
+				scores[visit.PlayerID].CurrentScore += score
+			} else if matchType == models.GOLF {
+				score = visit.CalculateGolfScore(round)
+				scores[visit.PlayerID].CurrentScore += score
+			} else if matchType == models.BASEBALL {
+				score = visit.CalculateBaseballScore(round)
+				scores[visit.PlayerID].CurrentScore += score
 			} else if matchType == models.KNOCKOUT {
 				player := scores[visit.PlayerID]
 				player.CurrentScore = visit.GetScore()
@@ -1116,7 +1310,7 @@ func GetLeg(id int) (*models.Leg, error) {
 					score = visit.CalculateScamScore(scores)
 					scores[visit.PlayerID].CurrentScore += score
 				}
-			} else if matchType == models.ONESEVENTY {
+			} else if matchType == models.ONESEVENTY || matchType == models.ONETWENTYONE {
 				// This is done below regardless of if the visit was a bust
 			} else if matchType == models.X01 || matchType == models.X01HANDICAP {
 				outshotType := models.OUTSHOTDOUBLE
@@ -1133,7 +1327,11 @@ func GetLeg(id int) (*models.Leg, error) {
 		if matchType == models.ONESEVENTY {
 			player := scores[visit.PlayerID]
 			visit.Score = visit.Calculate170Score(round, player)
+		} else if matchType == models.ONETWENTYONE {
+			player := scores[visit.PlayerID]
+			visit.Score = visit.Calculate121Score(player, leg.Parameters)
 		}
+		playerRounds[visit.PlayerID]++
 
 		visit.Scores = make(map[int]int)
 		visit.Scores[visit.PlayerID] = scores[visit.PlayerID].CurrentScore
@@ -1307,13 +1505,17 @@ func StartWarmup(legID int, venueID int) error {
 		log.Printf("[%d] Moved leg to new venue %d", legID, venueID)
 	}
 	tx.Commit()
+	if venueID > 0 {
+		notifyVenueQueue(venueID)
+	}
 
 	log.Printf("[%d] Started warmup", legID)
 	return nil
 }
 
 // DeleteLeg will delete the current leg and update match with previous leg
-func DeleteLeg(legID int) error {
+func DeleteLeg(ctx context.Context, legID int) error {
+	logger := logging.Logger(ctx)
 	leg, err := GetLeg(legID)
 	if err != nil {
 		return err
@@ -1324,11 +1526,19 @@ func DeleteLeg(legID int) error {
 		return err
 	}
 
-	return models.Transaction(models.DB, func(tx *sql.Tx) error {
+	err = models.Transaction(models.DB, func(tx *sql.Tx) error {
+		// The match is either deleted or abandoned below, so none of its legs are part of the statistics anymore
+		if err = removeMatchStatisticsRollup(ctx, tx, match); err != nil {
+			return err
+		}
+		// Records set in later legs are recalculated once the leg is deleted
+		if _, err = tx.Exec("DELETE FROM player_record WHERE leg_id = ?", legID); err != nil {
+			return err
+		}
 		if _, err = tx.Exec("DELETE FROM leg WHERE id = ?", legID); err != nil {
 			return err
 		}
-		log.Printf("[%d] Deleted leg", legID)
+		logger.Printf("[%d] Deleted leg", legID)
 
 		var previousLeg *int
 		err := models.DB.QueryRow("SELECT MAX(id) FROM leg WHERE match_id = ? AND is_finished = 1", match.ID).Scan(&previousLeg)
@@ -1339,16 +1549,25 @@ func DeleteLeg(legID int) error {
 			if _, err = tx.Exec("DELETE FROM matches WHERE id = ?", match.ID); err != nil {
 				return err
 			}
-			log.Printf("Delete match without any leg %d", match.ID)
+			logger.Printf("Delete match without any leg %d", match.ID)
 		} else {
 			_, err = tx.Exec("UPDATE matches SET current_leg_id = ?, is_abandoned = 1, is_finished = 1 WHERE id = ?", previousLeg, match.ID)
 			if err != nil {
 				return err
 			}
-			log.Printf("[%d] Updated current leg of match %d", previousLeg, match.ID)
+			logger.Printf("[%d] Updated current leg of match %d", previousLeg, match.ID)
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	if leg.IsFinished {
+		queueLegRecalculation(ctx, legID, leg.Players)
+	}
+	UpdateActiveMetrics()
+	invalidateMatch(match)
+	return nil
 }
 
 // GetLegParameters will return leg parameters for the given leg
@@ -1358,9 +1577,9 @@ func GetLegParameters(legID int) (*models.LegParameters, error) {
 	var ost null.Int
 	err := models.DB.QueryRow(`
 		SELECT outshot_type_id, number_1, number_2, number_3, number_4, number_5, number_6, number_7, number_8, number_9, starting_lives, 
-			points_to_win, max_rounds
+			points_to_win, max_rounds, min_target, max_target
 		FROM leg_parameters WHERE leg_id = ?`, legID).Scan(&ost, &n[0], &n[1], &n[2], &n[3], &n[4], &n[5], &n[6], &n[7], &n[8],
-		&params.StartingLives, &params.PointsToWin, &params.MaxRounds)
+		&params.StartingLives, &params.PointsToWin, &params.MaxRounds, &params.MinTarget, &params.MaxTarget)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return new(models.LegParameters), nil
@@ -1385,6 +1604,53 @@ func GetLegParameters(legID int) (*models.LegParameters, error) {
 	return params, nil
 }
 
+// getLegsParameters will return the parameters of the given legs, by leg ID. Legs without parameters are not included
+func getLegsParameters(legIDs []int) (map[int]*models.LegParameters, error) {
+	q, args, err := sqlx.In(`
+		SELECT lp.leg_id, ot.id, ot.name, ot.short_name, lp.number_1, lp.number_2, lp.number_3, lp.number_4, lp.number_5,
+			lp.number_6, lp.number_7, lp.number_8, lp.number_9, lp.starting_lives, lp.points_to_win, lp.max_rounds, lp.min_target, lp.max_target
+		FROM leg_parameters lp
+			LEFT JOIN outshot_type ot ON ot.id = lp.outshot_type_id
+		WHERE lp.leg_id IN (?)`, legIDs)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	parameters := make(map[int]*models.LegParameters)
+	for rows.Next() {
+		params := new(models.LegParameters)
+		n := make([]null.Int, 9)
+		var ostID null.Int
+		var ostName, ostShortName null.String
+		err := rows.Scan(&params.LegID, &ostID, &ostName, &ostShortName, &n[0], &n[1], &n[2], &n[3], &n[4], &n[5], &n[6], &n[7], &n[8],
+			&params.StartingLives, &params.PointsToWin, &params.MaxRounds, &params.MinTarget, &params.MaxTarget)
+		if err != nil {
+			return nil, err
+		}
+		if ostID.Valid {
+			params.OutshotType = &models.OutshotType{ID: int(ostID.Int64), Name: ostName.String, ShortName: ostShortName.String}
+		}
+		if n[0].Valid {
+			numbers := make([]int, 9)
+			for i, num := range n {
+				numbers[i] = int(num.Int64)
+			}
+			params.Numbers = numbers
+		}
+		params.Hits = make(map[int]int)
+		parameters[params.LegID] = params
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return parameters, nil
+}
+
 // GetLegMatchType returns the match type for a given leg
 func GetLegMatchType(legID int) (*int, error) {
 	var matchType int
@@ -1480,7 +1746,7 @@ func getLegWinner(leg *models.Leg, visit models.Visit, matchType int) (*null.Int
 	winnerID := null.IntFrom(int64(visit.PlayerID))
 	if matchType == models.SHOOTOUT || matchType == models.DARTSATX || matchType == models.AROUNDTHEWORLD ||
 		(matchType == models.SHANGHAI && !visit.IsShanghai()) || matchType == models.BERMUDATRIANGLE ||
-		matchType == models.JDCPRACTICE || matchType == models.SCAM {
+		matchType == models.JDCPRACTICE || matchType == models.SCAM || matchType == models.BOBS27 || matchType == models.BASEBALL {
 		// For certain game types we need to check the scores of each player to determine which player won the leg with the highest score
 		scores, err := GetPlayersScore(leg.ID)
 		if err != nil {
@@ -1513,6 +1779,27 @@ func getLegWinner(leg *models.Leg, visit models.Visit, matchType int) (*null.Int
 				winnerID = null.IntFrom(int64(playerID))
 			}
 		}
+	} else if matchType == models.GOLF {
+		// Lowest number of strokes wins, and equal strokes is a draw
+		scores, err := GetPlayersScore(leg.ID)
+		if err != nil {
+			return nil, err
+		}
+		lowestScore := math.MaxInt32
+		isDraw := false
+		for playerID, player := range scores {
+			if player.CurrentScore == lowestScore {
+				isDraw = true
+			}
+			if player.CurrentScore < lowestScore {
+				lowestScore = player.CurrentScore
+				winnerID = null.IntFrom(int64(playerID))
+				isDraw = false
+			}
+		}
+		if isDraw {
+			winnerID = null.IntFromPtr(nil)
+		}
 	} else if matchType == models.TICTACTOE && !leg.Parameters.IsTicTacToeWinner(visit.PlayerID) {
 		// If current player did not win, this game is a draw
 		winnerID = null.IntFromPtr(nil)
@@ -1526,7 +1813,7 @@ func getLegWinner(leg *models.Leg, visit models.Visit, matchType int) (*null.Int
 				winnerID = null.IntFrom(int64(player.PlayerID))
 			}
 		}
-	} else if matchType == models.ONESEVENTY {
+	} else if matchType == models.ONESEVENTY || matchType == models.ONETWENTYONE {
 		scores, err := GetPlayersScore(leg.ID)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,38 @@
+package data
+
+import "sync"
+
+// legLock is a lock for a single leg, counting the number of goroutines holding or waiting for it
+type legLock struct {
+	sync.Mutex
+	refs int
+}
+
+var (
+	legLocksMu sync.Mutex
+	legLocks   = make(map[int]*legLock)
+)
+
+// lockLeg will lock the given leg, so writes to different legs do not block each other. The returned function
+// must be called to unlock the leg
+func lockLeg(legID int) func() {
+	legLocksMu.Lock()
+	lock, ok := legLocks[legID]
+	if !ok {
+		lock = new(legLock)
+		legLocks[legID] = lock
+	}
+	lock.refs++
+	legLocksMu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		legLocksMu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(legLocks, legID)
+		}
+		legLocksMu.Unlock()
+	}
+}
@@ -1,19 +1,26 @@
 package data
 
 import (
-	"database/sql"
+	"context"
 	"errors"
 	"log"
 	"math"
 	"time"
 
 	"github.com/guregu/null"
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/logging"
+	"github.com/kcapp/api/metrics"
 	"github.com/kcapp/api/models"
 	"github.com/kcapp/api/util"
 )
 
 // NewMatch will insert a new match in the database
-func NewMatch(match models.Match) (*models.Match, error) {
+func NewMatch(ctx context.Context, match models.Match) (*models.Match, error) {
+	err := match.Legs[0].Parameters.Validate(match.MatchType.ID)
+	if err != nil {
+		return nil, err
+	}
 	tx, err := models.DB.Begin()
 	if err != nil {
 		return nil, err
@@ -79,6 +86,21 @@ func NewMatch(match models.Match) (*models.Match, error) {
 			tx.Rollback()
 			return nil, err
 		}
+	} else if match.MatchType.ID == models.ONETWENTYONE {
+		params := match.Legs[0].Parameters
+		_, err = tx.Exec("INSERT INTO leg_parameters (leg_id, points_to_win, max_rounds, min_target, max_target) VALUES (?, ?, ?, ?, ?)",
+			legID, params.PointsToWin, params.MaxRounds, params.MinTarget, params.MaxTarget)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	} else if match.MatchType.ID == models.GOLF || match.MatchType.ID == models.BASEBALL {
+		params := match.Legs[0].Parameters
+		_, err = tx.Exec("INSERT INTO leg_parameters (leg_id, max_rounds) VALUES (?, ?)", legID, params.MaxRounds)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	}
 
 	tx.Exec("UPDATE matches SET current_leg_id = ? WHERE id = ?", legID, matchID)
@@ -112,7 +134,11 @@ func NewMatch(match models.Match) (*models.Match, error) {
 
 	}
 	tx.Commit()
-	log.Printf("Started new match %d", matchID)
+	logging.Logger(ctx).Printf("Started new match %d", matchID)
+	UpdateActiveMetrics()
+	if match.VenueID.Valid {
+		notifyVenueQueue(int(match.VenueID.Int64))
+	}
 	return GetMatch(int(matchID))
 }
 
@@ -197,6 +223,35 @@ func GetMatchesCount() (int, error) {
 	return count, nil
 }
 
+// GetActiveCount returns the number of legs and matches which have had a visit within the given number of minutes
+func GetActiveCount(since int) (int, int, error) {
+	var legs, matches int
+	err := models.DB.QueryRow(`
+		SELECT COUNT(DISTINCT l.id), COUNT(DISTINCT m.id)
+		FROM matches m
+			JOIN leg l ON l.id = m.current_leg_id
+		WHERE m.is_finished = 0 AND m.is_abandoned = 0 AND m.is_walkover <> 1 AND m.is_bye <> 1
+			AND l.is_finished <> 1 AND l.updated_at > NOW() - INTERVAL ? MINUTE`, since).Scan(&legs, &matches)
+	if err != nil {
+		return 0, 0, err
+	}
+	return legs, matches, nil
+}
+
+// activeMinutes is the number of minutes since the last visit for a leg to be counted as active in the metrics
+const activeMinutes = 2
+
+// UpdateActiveMetrics will update the metrics of legs and matches currently being played
+func UpdateActiveMetrics() {
+	legs, matches, err := GetActiveCount(activeMinutes)
+	if err != nil {
+		log.Printf("Unable to get active legs and matches for metrics: %s", err)
+		return
+	}
+	metrics.ActiveLegs.Set(float64(legs))
+	metrics.ActiveMatches.Set(float64(matches))
+}
+
 // GetActiveMatches returns all active matches
 func GetActiveMatches(since int) ([]*models.Match, error) {
 	rows, err := models.DB.Query(`
@@ -463,6 +518,80 @@ func GetMatch(id int) (*models.Match, error) {
 	return m, nil
 }
 
+// GetMatchesByID returns a map of the matches with the given IDs. Legs and Elo changes of the matches are not loaded
+func GetMatchesByID(ids []int) (map[int]*models.Match, error) {
+	q, args, err := sqlx.In(`
+		SELECT
+			m.id, m.is_finished, m.is_abandoned, m.is_walkover, m.is_bye, m.current_leg_id, m.winner_id, m.office_id, m.is_practice, m.created_at, m.updated_at,
+			m.owe_type_id, m.venue_id, mt.id, mt.name, mt.description, mm.id, mm.name, mm.short_name, mm.wins_required,
+			mm.legs_required, mm.tiebreak_match_type_id, mm.is_draw_possible, mm.is_challenge, ot.id, ot.item, v.id, v.name, v.office_id, v.description,
+			MAX(l.updated_at) AS 'last_throw',
+			MIN(s.created_at) AS 'first_throw',
+			MAX(l.end_time) AS 'end_time',
+			GROUP_CONCAT(DISTINCT p2l.player_id ORDER BY p2l.order) AS 'players',
+			m.tournament_id, t.id, t.name, t.office_id, tg.id, tg.name, t.is_season, t.is_playoffs, t.is_finished
+		FROM matches m
+			JOIN match_type mt ON mt.id = m.match_type_id
+			JOIN match_mode mm ON mm.id = m.match_mode_id
+			LEFT JOIN leg l ON l.match_id = m.id
+			LEFT JOIN score s ON s.leg_id = l.id
+			LEFT JOIN owe_type ot ON ot.id = m.owe_type_id
+			LEFT JOIN venue v on v.id = m.venue_id
+			LEFT JOIN player2leg p2l ON p2l.match_id = m.id
+			LEFT JOIN player2tournament p2t ON p2t.tournament_id = m.tournament_id AND p2t.player_id = p2l.player_id
+			LEFT JOIN tournament t ON t.id = p2t.tournament_id
+			LEFT JOIN tournament_group tg ON tg.id = p2t.tournament_group_id
+		WHERE m.id IN (?)
+		GROUP BY m.id`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make(map[int]*models.Match)
+	for rows.Next() {
+		m := new(models.Match)
+		m.MatchType = new(models.MatchType)
+		m.MatchMode = new(models.MatchMode)
+		ot := new(models.OweType)
+		venue := new(models.Venue)
+		tournament := new(models.MatchTournament)
+		var players string
+		var endTime null.Time
+		err := rows.Scan(&m.ID, &m.IsFinished, &m.IsAbandoned, &m.IsWalkover, &m.IsBye, &m.CurrentLegID, &m.WinnerID, &m.OfficeID, &m.IsPractice,
+			&m.CreatedAt, &m.UpdatedAt, &m.OweTypeID, &m.VenueID, &m.MatchType.ID, &m.MatchType.Name, &m.MatchType.Description,
+			&m.MatchMode.ID, &m.MatchMode.Name, &m.MatchMode.ShortName, &m.MatchMode.WinsRequired, &m.MatchMode.LegsRequired, &m.MatchMode.TieBreakMatchTypeID,
+			&m.MatchMode.IsDrawPossible, &m.MatchMode.IsChallenge, &ot.ID, &ot.Item, &venue.ID, &venue.Name, &venue.OfficeID, &venue.Description,
+			&m.LastThrow, &m.FirstThrow, &endTime, &players, &m.TournamentID, &tournament.TournamentID, &tournament.TournamentName, &tournament.OfficeID,
+			&tournament.TournamentGroupID, &tournament.TournamentGroupName, &tournament.IsSeason, &tournament.IsPlayoffs, &tournament.IsFinished)
+		if err != nil {
+			return nil, err
+		}
+		if m.OweTypeID.Valid {
+			m.OweType = ot
+		}
+		if m.VenueID.Valid && m.VenueID.Int64 != 0 {
+			m.Venue = venue
+		}
+		if m.TournamentID.Valid {
+			m.Tournament = tournament
+		}
+		if m.IsFinished && endTime.Valid {
+			m.EndTime = endTime.Time
+		}
+		m.Players = util.StringToIntArray(players)
+		matches[m.ID] = m
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
 // SetScore will set the score of a given match
 func SetScore(matchID int, result models.MatchResult) (*models.Match, error) {
 	tx, err := models.DB.Begin()
@@ -554,15 +683,27 @@ func SetScore(matchID int, result models.MatchResult) (*models.Match, error) {
 			return nil, err
 		}
 	}
+	invalidateMatch(match)
 	return GetMatch(int(matchID))
 }
 
 // GetMatchMetadata returns a metadata about the given match
 func GetMatchMetadata(id int) (*models.MatchMetadata, error) {
+	metadata, err := GetMatchesMetadata([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := metadata[id]; ok {
+		return m, nil
+	}
 	m := new(models.MatchMetadata)
 	m.TournamentGroup = new(models.TournamentGroup)
-	var playersStr string
-	err := models.DB.QueryRow(`
+	return m, nil
+}
+
+// GetMatchesMetadata returns metadata about each of the given matches which has any
+func GetMatchesMetadata(ids []int) (map[int]*models.MatchMetadata, error) {
+	q, args, err := sqlx.In(`
 		SELECT
 			mm.id, mm.match_id, mm.order_of_play, mm.match_displayname, mm.elimination,
 			mm.trophy, mm.promotion, mm.semi_final, mm.grand_final, mm.winner_outcome_match_id,
@@ -572,22 +713,40 @@ func GetMatchMetadata(id int) (*models.MatchMetadata, error) {
 		FROM match_metadata mm
 			LEFT JOIN tournament_group tg ON tg.id = mm.tournament_group_id
 			LEFT JOIN player2leg p2l ON p2l.match_id = mm.match_id
-		WHERE mm.match_id = ?
-		GROUP BY mm.match_id`, id).Scan(&m.ID, &m.MatchID, &m.OrderOfPlay, &m.MatchDisplayname, &m.Elimination,
-		&m.Trophy, &m.Promotion, &m.SemiFinal, &m.GrandFinal, &m.WinnerOutcomeMatchID, &m.IsWinnerOutcomeHome,
-		&m.LooserOutcomeMatchID, &m.IsLooserOutcomeHome, &m.WinnerOutcome, &m.LooserOutcome, &m.LooserOutcomeStanding,
-		&m.TournamentGroup.ID, &m.TournamentGroup.Name, &playersStr)
-	if err != nil && err != sql.ErrNoRows {
+		WHERE mm.match_id IN (?)
+		GROUP BY mm.match_id`, ids)
+	if err != nil {
 		return nil, err
 	}
-
-	players := util.StringToIntArray(playersStr)
-	if len(players) == 2 {
-		m.HomePlayer = players[0]
-		m.AwayPlayer = players[1]
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return m, nil
+	metadata := make(map[int]*models.MatchMetadata)
+	for rows.Next() {
+		m := new(models.MatchMetadata)
+		m.TournamentGroup = new(models.TournamentGroup)
+		var playersStr string
+		err := rows.Scan(&m.ID, &m.MatchID, &m.OrderOfPlay, &m.MatchDisplayname, &m.Elimination,
+			&m.Trophy, &m.Promotion, &m.SemiFinal, &m.GrandFinal, &m.WinnerOutcomeMatchID, &m.IsWinnerOutcomeHome,
+			&m.LooserOutcomeMatchID, &m.IsLooserOutcomeHome, &m.WinnerOutcome, &m.LooserOutcome, &m.LooserOutcomeStanding,
+			&m.TournamentGroup.ID, &m.TournamentGroup.Name, &playersStr)
+		if err != nil {
+			return nil, err
+		}
+		players := util.StringToIntArray(playersStr)
+		if len(players) == 2 {
+			m.HomePlayer = players[0]
+			m.AwayPlayer = players[1]
+		}
+		metadata[m.MatchID] = m
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return metadata, nil
 }
 
 // GetMatchMetadataForTournament returns metadata for all matches in a given tournament
@@ -758,7 +917,6 @@ func GetHeadToHeadMatches(player1 int, player2 int) ([]*models.Match, error) {
 			JOIN leg l ON l.match_id = m.id
 		WHERE l.num_players = 2
 			AND m.is_finished = 1 AND m.is_abandoned = 0 AND m.is_bye = 0
-			AND m.match_type_id = 1
 			AND p2l.player_id IN (?, ?)
 		GROUP BY m.id
 			HAVING COUNT(DISTINCT p2l.player_id) = 2
@@ -832,33 +990,164 @@ func GetPlayerLastMatches(playerID int, limit int) ([]*models.Match, error) {
 
 // GetMatchEloChange returns Elo change for each player in the given match
 func GetMatchEloChange(id int) (map[int]*models.PlayerElo, error) {
-	rows, err := models.DB.Query(`
+	changes, err := GetMatchesEloChange([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if change, ok := changes[id]; ok {
+		return change, nil
+	}
+	return make(map[int]*models.PlayerElo), nil
+}
+
+// GetMatchesEloChange returns Elo change for each player in each of the given matches
+func GetMatchesEloChange(ids []int) (map[int]map[int]*models.PlayerElo, error) {
+	q, args, err := sqlx.In(`
 		SELECT
+			match_id,
 			player_id,
 			old_elo,
 			new_elo,
 			new_tournament_elo,
 			old_tournament_elo
 		FROM player_elo_changelog
-		WHERE match_id = ?`, id)
+		WHERE match_id IN (?)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	change := make(map[int]*models.PlayerElo)
+	changes := make(map[int]map[int]*models.PlayerElo)
 	for rows.Next() {
+		var matchID int
 		elo := new(models.PlayerElo)
-		err := rows.Scan(&elo.PlayerID, &elo.CurrentElo, &elo.CurrentEloNew, &elo.TournamentEloNew, &elo.TournamentElo)
+		err := rows.Scan(&matchID, &elo.PlayerID, &elo.CurrentElo, &elo.CurrentEloNew, &elo.TournamentEloNew, &elo.TournamentElo)
 		if err != nil {
 			return nil, err
 		}
-		change[elo.PlayerID] = elo
+		if changes[matchID] == nil {
+			changes[matchID] = make(map[int]*models.PlayerElo)
+		}
+		changes[matchID][elo.PlayerID] = elo
 	}
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
-	return change, nil
+	return changes, nil
+}
+
+// GetStatisticsForMatch will return statistics for all players in the given match, based on the match type
+func GetStatisticsForMatch(matchID int, matchType int) (interface{}, error) {
+	switch matchType {
+	case models.SHOOTOUT:
+		return GetShootoutStatisticsForMatch(matchID)
+	case models.CRICKET:
+		return GetCricketStatisticsForMatch(matchID)
+	case models.DARTSATX:
+		return GetDartsAtXStatisticsForMatch(matchID)
+	case models.AROUNDTHECLOCK:
+		return GetAroundTheClockStatisticsForMatch(matchID)
+	case models.AROUNDTHEWORLD:
+		return GetAroundTheWorldStatisticsForMatch(matchID)
+	case models.SHANGHAI:
+		return GetShanghaiStatisticsForMatch(matchID)
+	case models.TICTACTOE:
+		return GetTicTacToeStatisticsForMatch(matchID)
+	case models.BERMUDATRIANGLE:
+		return GetBermudaTriangleStatisticsForMatch(matchID)
+	case models.FOURTWENTY:
+		return Get420StatisticsForMatch(matchID)
+	case models.KILLBULL:
+		return GetKillBullStatisticsForMatch(matchID)
+	case models.GOTCHA:
+		return GetGotchaStatisticsForMatch(matchID)
+	case models.JDCPRACTICE:
+		return GetJDCPracticeStatisticsForMatch(matchID)
+	case models.KNOCKOUT:
+		return GetKnockoutStatisticsForMatch(matchID)
+	case models.SCAM:
+		return GetScamStatisticsForMatch(matchID)
+	case models.ONESEVENTY:
+		return Get170StatisticsForMatch(matchID)
+	case models.BOBS27:
+		return GetBobs27StatisticsForMatch(matchID)
+	case models.ONETWENTYONE:
+		return Get121StatisticsForMatch(matchID)
+	case models.GOLF:
+		return GetGolfStatisticsForMatch(matchID)
+	case models.BASEBALL:
+		return GetBaseballStatisticsForMatch(matchID)
+	default:
+		return GetX01StatisticsForMatch(matchID)
+	}
+}
+
+// GetStatisticsForMatches will return statistics for all players in each of the given matches, which must all be of
+// the given match type
+func GetStatisticsForMatches(ids []int, matchType int) (map[int]interface{}, error) {
+	switch matchType {
+	case models.SHOOTOUT:
+		return statisticsByMatch(ids, GetShootoutStatisticsForMatches)
+	case models.CRICKET:
+		return statisticsByMatch(ids, GetCricketStatisticsForMatches)
+	case models.DARTSATX:
+		return statisticsByMatch(ids, GetDartsAtXStatisticsForMatches)
+	case models.AROUNDTHECLOCK:
+		return statisticsByMatch(ids, GetAroundTheClockStatisticsForMatches)
+	case models.AROUNDTHEWORLD:
+		return statisticsByMatch(ids, GetAroundTheWorldStatisticsForMatches)
+	case models.SHANGHAI:
+		return statisticsByMatch(ids, GetShanghaiStatisticsForMatches)
+	case models.TICTACTOE:
+		return statisticsByMatch(ids, GetTicTacToeStatisticsForMatches)
+	case models.BERMUDATRIANGLE:
+		return statisticsByMatch(ids, GetBermudaTriangleStatisticsForMatches)
+	case models.FOURTWENTY:
+		return statisticsByMatch(ids, Get420StatisticsForMatches)
+	case models.KILLBULL:
+		return statisticsByMatch(ids, GetKillBullStatisticsForMatches)
+	case models.GOTCHA:
+		return statisticsByMatch(ids, GetGotchaStatisticsForMatches)
+	case models.JDCPRACTICE:
+		return statisticsByMatch(ids, GetJDCPracticeStatisticsForMatches)
+	case models.KNOCKOUT:
+		return statisticsByMatch(ids, GetKnockoutStatisticsForMatches)
+	case models.SCAM:
+		return statisticsByMatch(ids, GetScamStatisticsForMatches)
+	case models.ONESEVENTY:
+		return statisticsByMatch(ids, Get170StatisticsForMatches)
+	case models.BOBS27:
+		return statisticsByMatch(ids, GetBobs27StatisticsForMatches)
+	case models.ONETWENTYONE:
+		return statisticsByMatch(ids, Get121StatisticsForMatches)
+	case models.GOLF:
+		return statisticsByMatch(ids, GetGolfStatisticsForMatches)
+	case models.BASEBALL:
+		return statisticsByMatch(ids, GetBaseballStatisticsForMatches)
+	default:
+		return statisticsByMatch(ids, GetX01StatisticsForMatches)
+	}
+}
+
+// statisticsByMatch will fetch statistics of the given matches, with an empty list for matches without statistics
+func statisticsByMatch[T any](ids []int, fetch func(ids []int) (map[int][]T, error)) (map[int]interface{}, error) {
+	statistics, err := fetch(ids)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[int]interface{}, len(ids))
+	for _, id := range ids {
+		stats, ok := statistics[id]
+		if !ok {
+			stats = make([]T, 0)
+		}
+		values[id] = stats
+	}
+	return values, nil
 }
 
 // SwapPlayers will swap the two players for the given match
@@ -893,8 +1182,7 @@ func GetBadgeMatchesToRecalculate() ([]int, error) {
 		SELECT m.id FROM matches m
 			LEFT JOIN leg l ON l.match_id = m.id
 		WHERE m.is_abandoned = 0 AND m.is_bye = 0 AND m.is_walkover = 0
-			AND m.is_finished = 1 AND l.has_scores = 1 AND m.match_type_id = 1
-			AND m.id = 21800
+			AND m.is_finished = 1 AND l.has_scores = 1
 		GROUP BY m.id
 		ORDER BY m.id ASC`)
 	if err != nil {
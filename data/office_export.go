@@ -0,0 +1,1150 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/cache"
+	"github.com/kcapp/api/models"
+)
+
+// ExportOffice will export all players, venues, presets, tournaments, matches, badges and Elo of the given office
+func ExportOffice(officeID int) (*models.OfficeExport, error) {
+	office, err := GetOffice(officeID)
+	if err != nil {
+		return nil, err
+	}
+	export := &models.OfficeExport{
+		Version:    models.OfficeExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Office:     office,
+	}
+
+	// Include players from other offices if they have played matches in this office
+	export.Players, err = getOfficeExportPlayers(officeID)
+	if err != nil {
+		return nil, err
+	}
+	playerIDs := make(map[int]bool)
+	for _, player := range export.Players {
+		playerIDs[player.ID] = true
+	}
+
+	export.Venues, err = getOfficeExportVenues(officeID)
+	if err != nil {
+		return nil, err
+	}
+	export.Presets, err = getOfficeExportPresets(playerIDs)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := GetTournamentGroups()
+	if err != nil {
+		return nil, err
+	}
+	export.TournamentGroups = make([]*models.TournamentGroup, 0, len(groups))
+	for _, group := range groups {
+		export.TournamentGroups = append(export.TournamentGroups, group)
+	}
+	sort.Slice(export.TournamentGroups, func(i, j int) bool { return export.TournamentGroups[i].ID < export.TournamentGroups[j].ID })
+	export.Tournaments, err = getOfficeExportTournaments(officeID)
+	if err != nil {
+		return nil, err
+	}
+	export.Matches, err = getOfficeExportMatches(officeID)
+	if err != nil {
+		return nil, err
+	}
+	export.Badges, err = getOfficeExportBadges(export)
+	if err != nil {
+		return nil, err
+	}
+	export.Elo, err = getOfficeExportElo(officeID)
+	if err != nil {
+		return nil, err
+	}
+	export.EloChangelog, err = getOfficeExportEloChangelog(officeID)
+	if err != nil {
+		return nil, err
+	}
+	export.MatchModes, err = getOfficeExportReferences("SELECT id, `name` FROM match_mode ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	export.OweTypes, err = getOfficeExportReferences("SELECT id, IFNULL(item, '') FROM owe_type ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	export.BadgeTypes, err = getOfficeExportReferences("SELECT id, `name` FROM badge ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Exported office %d with %d players and %d matches", officeID, len(export.Players), len(export.Matches))
+	return export, nil
+}
+
+// ImportOffice will import the given export, with the statistics of every leg, in a single transaction. All IDs are remapped, and existing players are
+// matched by smartcard or by name within the office. Match modes, owe types and badges are matched by name, and the
+// import fails if any of them do not exist. If dryRun is set, the transaction is rolled back and only the report is returned
+func ImportOffice(export *models.OfficeExport, dryRun bool) (*models.OfficeImportReport, error) {
+	err := export.Validate()
+	if err != nil {
+		return nil, err
+	}
+	report := &models.OfficeImportReport{
+		Version: export.Version,
+		DryRun:  dryRun,
+		Created: make(map[string]int),
+		Matched: make(map[string]int),
+		Players: make([]*models.OfficeImportPlayer, 0),
+	}
+
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	err = newOfficeImport(tx, report).run(export)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if dryRun {
+		tx.Rollback()
+		log.Printf("Dry-run of importing office %s, no changes made", export.Office.Name)
+		return report, nil
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Imported office %s (%d) with %d new matches", export.Office.Name, report.OfficeID, report.Created["matches"])
+	cache.InvalidateAll()
+	return report, nil
+}
+
+// officeImport keeps track of how IDs in the export are mapped to IDs in this instance
+type officeImport struct {
+	tx          *sql.Tx
+	report      *models.OfficeImportReport
+	officeID    int64
+	players     map[int64]int64
+	venues      map[int64]int64
+	groups      map[int64]int64
+	tournaments map[int64]int64
+	matches     map[int64]int64
+	legs        map[int64]int64
+	visits      map[int64]int64
+	matchModes  map[int64]int64
+	oweTypes    map[int64]int64
+	badgeTypes  map[int64]int64
+	newPlayers  map[int64]bool
+	newMatches  map[int64]bool
+}
+
+func newOfficeImport(tx *sql.Tx, report *models.OfficeImportReport) *officeImport {
+	return &officeImport{
+		tx:          tx,
+		report:      report,
+		players:     make(map[int64]int64),
+		venues:      make(map[int64]int64),
+		groups:      make(map[int64]int64),
+		tournaments: make(map[int64]int64),
+		matches:     make(map[int64]int64),
+		legs:        make(map[int64]int64),
+		visits:      make(map[int64]int64),
+		newPlayers:  make(map[int64]bool),
+		newMatches:  make(map[int64]bool),
+	}
+}
+
+// remap will return the new ID for the given ID, keeping it null if not set
+func remap(ids map[int64]int64, id null.Int) null.Int {
+	if !id.Valid {
+		return id
+	}
+	return null.IntFrom(ids[id.Int64])
+}
+
+// run will insert all entities of the export, in order of dependencies
+func (imp *officeImport) run(export *models.OfficeExport) error {
+	steps := []func(*models.OfficeExport) error{
+		imp.resolveReferences, imp.importOffice, imp.importPlayers, imp.importVenues, imp.importPresets, imp.importTournaments,
+		imp.importMatches, imp.importBadges, imp.importElo,
+	}
+	for _, step := range steps {
+		err := step(export)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveReferences will map the match modes, owe types and badges used in the export to those of this instance by
+// name, failing before anything is imported if any of them do not exist
+func (imp *officeImport) resolveReferences(export *models.OfficeExport) error {
+	matchModes := make(map[int64]bool)
+	oweTypes := make(map[int64]bool)
+	badgeTypes := make(map[int64]bool)
+	for _, preset := range export.Presets {
+		matchModes[int64(preset.MatchModeID)] = true
+	}
+	for _, match := range export.Matches {
+		matchModes[int64(match.MatchModeID)] = true
+		if match.OweTypeID.Valid {
+			oweTypes[match.OweTypeID.Int64] = true
+		}
+	}
+	for _, badge := range export.Badges {
+		badgeTypes[int64(badge.BadgeID)] = true
+	}
+
+	var err error
+	imp.matchModes, err = imp.resolveReference(export, "match mode", "match_mode", "`name`", export.MatchModes, matchModes)
+	if err != nil {
+		return err
+	}
+	imp.oweTypes, err = imp.resolveReference(export, "owe type", "owe_type", "item", export.OweTypes, oweTypes)
+	if err != nil {
+		return err
+	}
+	imp.badgeTypes, err = imp.resolveReference(export, "badge", "badge", "`name`", export.BadgeTypes, badgeTypes)
+	return err
+}
+
+// resolveReference will return the ID in this instance of each of the given IDs used in the export, found by the name
+// in the given column. Exports before version 2 do not contain names, so IDs are kept if they exist in this instance
+func (imp *officeImport) resolveReference(export *models.OfficeExport, kind string, table string, column string,
+	references []*models.OfficeExportReference, used map[int64]bool) (map[int64]int64, error) {
+	names := make(map[int64]string)
+	for _, reference := range references {
+		names[int64(reference.ID)] = reference.Name
+	}
+	ids := make(map[int64]int64)
+	for id := range used {
+		var err error
+		var target int64
+		if export.Version < 2 {
+			err = imp.tx.QueryRow(fmt.Sprintf("SELECT id FROM %s WHERE id = ?", table), id).Scan(&target)
+		} else {
+			err = imp.tx.QueryRow(fmt.Sprintf("SELECT id FROM %s WHERE %s = ? ORDER BY id LIMIT 1", table, column), names[id]).Scan(&target)
+		}
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%s %d '%s' does not exist in this instance", kind, id, names[id])
+		} else if err != nil {
+			return nil, err
+		}
+		ids[id] = target
+	}
+	return ids, nil
+}
+
+// importOffice will find the office by name, or create it if it does not exist
+func (imp *officeImport) importOffice(export *models.OfficeExport) error {
+	err := imp.tx.QueryRow("SELECT id FROM office WHERE name = ?", export.Office.Name).Scan(&imp.officeID)
+	if err == nil {
+		imp.report.Matched["office"]++
+	} else if err == sql.ErrNoRows {
+		res, err := imp.tx.Exec("INSERT INTO office (name, is_active, is_global) VALUES (?, ?, ?)", export.Office.Name,
+			export.Office.IsActive, export.Office.IsGlobal)
+		if err != nil {
+			return err
+		}
+		imp.officeID, err = res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		imp.report.Created["office"]++
+	} else {
+		return err
+	}
+	imp.report.OfficeID = int(imp.officeID)
+	return nil
+}
+
+// importPlayers will match players by smartcard and then by name within the office, and create any players which do not exist
+func (imp *officeImport) importPlayers(export *models.OfficeExport) error {
+	for _, player := range export.Players {
+		name := strings.TrimSpace(player.FirstName + " " + player.LastName.String)
+		var id int64
+		matchedBy := ""
+		if player.SmartcardUID.Valid && player.SmartcardUID.String != "" {
+			err := imp.tx.QueryRow("SELECT id FROM player WHERE smartcard_uid = ? ORDER BY id LIMIT 1", player.SmartcardUID).Scan(&id)
+			if err == nil {
+				matchedBy = "smartcard"
+			} else if err != sql.ErrNoRows {
+				return err
+			}
+		}
+		if matchedBy == "" {
+			err := imp.tx.QueryRow("SELECT id FROM player WHERE first_name = ? AND last_name <=> ? AND office_id = ? ORDER BY active DESC, id LIMIT 1",
+				player.FirstName, player.LastName, imp.officeID).Scan(&id)
+			if err == nil {
+				matchedBy = "name"
+			} else if err != sql.ErrNoRows {
+				return err
+			}
+		}
+
+		if matchedBy != "" {
+			imp.report.Matched["players"]++
+		} else {
+			res, err := imp.tx.Exec(`INSERT INTO player (first_name, last_name, vocal_name, nickname, slack_handle, color,
+					profile_pic_url, smartcard_uid, board_stream_url, board_stream_css, office_id, active, is_bot, created_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				player.FirstName, player.LastName, player.VocalName, player.Nickname, player.SlackHandle, player.Color, player.ProfilePicURL,
+				player.SmartcardUID, player.BoardStreamURL, player.BoardStreamCSS, imp.officeID, player.IsActive, player.IsBot, player.CreatedAt)
+			if err != nil {
+				return err
+			}
+			id, err = res.LastInsertId()
+			if err != nil {
+				return err
+			}
+			_, err = imp.tx.Exec("INSERT INTO player_elo (player_id) VALUES (?)", id)
+			if err != nil {
+				return err
+			}
+			imp.newPlayers[id] = true
+			imp.report.Created["players"]++
+		}
+		imp.players[int64(player.ID)] = id
+		imp.report.Players = append(imp.report.Players, &models.OfficeImportPlayer{SourceID: player.ID, PlayerID: int(id), Name: name, MatchedBy: matchedBy})
+	}
+	return nil
+}
+
+// importVenues will match venues by name within the office, and create any venues which do not exist
+func (imp *officeImport) importVenues(export *models.OfficeExport) error {
+	for _, venue := range export.Venues {
+		var id int64
+		err := imp.tx.QueryRow("SELECT id FROM venue WHERE name = ? AND office_id = ?", venue.Name, imp.officeID).Scan(&id)
+		if err == nil {
+			imp.venues[venue.ID.Int64] = id
+			imp.report.Matched["venues"]++
+			continue
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+		res, err := imp.tx.Exec("INSERT INTO venue (name, office_id, description) VALUES (?, ?, ?)", venue.Name, imp.officeID, venue.Description)
+		if err != nil {
+			return err
+		}
+		id, err = res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		if venue.Config != nil {
+			_, err = imp.tx.Exec(`INSERT INTO venue_configuration (venue_id, has_dual_monitor, has_led_lights, has_wled_lights, tts_voice, has_smartboard,
+				smartboard_uuid, smartboard_button_number) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, id, venue.Config.HasDualMonitor, venue.Config.HasLEDLights,
+				venue.Config.HasWLEDLights, venue.Config.TTSVoice, venue.Config.HasSmartboard, venue.Config.SmartboardUUID, venue.Config.SmartboardButtonNumber)
+			if err != nil {
+				return err
+			}
+		}
+		imp.venues[venue.ID.Int64] = id
+		imp.report.Created["venues"]++
+	}
+	return nil
+}
+
+// importPresets will create all match presets which does not already exist with the same name
+func (imp *officeImport) importPresets(export *models.OfficeExport) error {
+	for _, preset := range export.Presets {
+		var id int
+		err := imp.tx.QueryRow("SELECT id FROM match_preset WHERE name = ?", preset.Name).Scan(&id)
+		if err == nil {
+			imp.report.Matched["presets"]++
+			continue
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+		var players null.String
+		if len(preset.Players) > 0 {
+			ids := make([]int64, len(preset.Players))
+			for i, playerID := range preset.Players {
+				ids[i] = imp.players[int64(playerID)]
+			}
+			b, err := json.Marshal(ids)
+			if err != nil {
+				return err
+			}
+			players = null.StringFrom(string(b))
+		}
+		_, err = imp.tx.Exec(`INSERT INTO match_preset(name, match_type_id, match_mode_id, starting_score, players, smartcard_uid, description) VALUES(?, ?, ?, ?, ?, ?, ?)`,
+			preset.Name, preset.MatchTypeID, imp.matchModes[int64(preset.MatchModeID)], preset.StartingScore, players, preset.SmartcardUID, preset.Description)
+		if err != nil {
+			return err
+		}
+		imp.report.Created["presets"]++
+	}
+	return nil
+}
+
+// importTournaments will create all tournaments and groups which does not already exist
+func (imp *officeImport) importTournaments(export *models.OfficeExport) error {
+	for _, group := range export.TournamentGroups {
+		var id int64
+		err := imp.tx.QueryRow("SELECT id FROM tournament_group WHERE name = ? AND division <=> ?", group.Name, group.Division).Scan(&id)
+		if err == sql.ErrNoRows {
+			res, err := imp.tx.Exec("INSERT INTO tournament_group (name, division) VALUES (?, ?)", group.Name, group.Division)
+			if err != nil {
+				return err
+			}
+			id, err = res.LastInsertId()
+			if err != nil {
+				return err
+			}
+			imp.report.Created["tournament_groups"]++
+		} else if err != nil {
+			return err
+		}
+		imp.groups[int64(group.ID)] = id
+	}
+
+	for _, tournament := range export.Tournaments {
+		var id int64
+		err := imp.tx.QueryRow("SELECT id FROM tournament WHERE name = ? AND office_id = ?", tournament.Name, imp.officeID).Scan(&id)
+		if err == nil {
+			imp.tournaments[int64(tournament.ID)] = id
+			imp.report.Matched["tournaments"]++
+			continue
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+		res, err := imp.tx.Exec(`
+			INSERT INTO tournament (name, short_name, is_finished, is_playoffs, manual_admin, office_id, start_time, end_time) VALUES
+			(?, ?, ?, ?, ?, ?, ?, ?)`, tournament.Name, tournament.ShortName, tournament.IsFinished, tournament.IsPlayoffs,
+			tournament.ManualAdmin, imp.officeID, tournament.StartTime, tournament.EndTime)
+		if err != nil {
+			return err
+		}
+		id, err = res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for _, player := range tournament.Players {
+			_, err = imp.tx.Exec(`INSERT INTO player2tournament (player_id, tournament_id, tournament_group_id) VALUES (?, ?, ?)`,
+				imp.players[int64(player.PlayerID)], id, imp.groups[int64(player.TournamentGroupID)])
+			if err != nil {
+				return err
+			}
+		}
+		imp.tournaments[int64(tournament.ID)] = id
+		imp.report.Created["tournaments"]++
+	}
+
+	// Playoffs can only be linked once all tournaments exist
+	for _, tournament := range export.Tournaments {
+		if !tournament.PlayoffsTournamentID.Valid {
+			continue
+		}
+		_, err := imp.tx.Exec("UPDATE tournament SET playoffs_tournament_id = ? WHERE id = ? AND playoffs_tournament_id IS NULL",
+			imp.tournaments[tournament.PlayoffsTournamentID.Int64], imp.tournaments[int64(tournament.ID)])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importMatches will create all matches with legs, parameters and visits. Matches which were already imported
+// previously are identified by type and the time they were created, and are skipped
+func (imp *officeImport) importMatches(export *models.OfficeExport) error {
+	for _, match := range export.Matches {
+		var id int64
+		err := imp.tx.QueryRow("SELECT id FROM matches WHERE office_id = ? AND match_type_id = ? AND created_at = ?",
+			imp.officeID, match.MatchTypeID, match.CreatedAt).Scan(&id)
+		if err == nil {
+			imp.matches[int64(match.ID)] = id
+			imp.report.Matched["matches"]++
+			continue
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+
+		res, err := imp.tx.Exec(`
+			INSERT INTO matches (match_type_id, match_mode_id, owe_type_id, venue_id, office_id, is_practice, tournament_id,
+				is_finished, is_abandoned, is_walkover, winner_id, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, match.MatchTypeID, imp.matchModes[int64(match.MatchModeID)], remap(imp.oweTypes, match.OweTypeID),
+			remap(imp.venues, match.VenueID), imp.officeID, match.IsPractice, remap(imp.tournaments, match.TournamentID), match.IsFinished,
+			match.IsAbandoned, match.IsWalkover, remap(imp.players, match.WinnerID), match.CreatedAt, match.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		id, err = res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		imp.matches[int64(match.ID)] = id
+		imp.newMatches[int64(match.ID)] = true
+		imp.report.Created["matches"]++
+
+		var legID int64
+		for i, leg := range match.Legs {
+			legID, err = imp.importLeg(id, leg)
+			if err != nil {
+				return err
+			}
+			if leg.IsFinished && leg.EndTime.Valid {
+				countMatch := i == len(match.Legs)-1 && match.IsFinished && !match.IsAbandoned && !match.IsWalkover
+				err = addStatisticsRollup(context.Background(), imp.tx, int(legID), 1, countMatch)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		_, err = imp.tx.Exec("UPDATE matches SET current_leg_id = ? WHERE id = ?", legID, id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importLeg will create the given leg with players, parameters and visits
+func (imp *officeImport) importLeg(matchID int64, leg *models.OfficeExportLeg) (int64, error) {
+	res, err := imp.tx.Exec(`
+		INSERT INTO leg (starting_score, current_player_id, leg_type_id, match_id, num_players, is_finished, winner_id, has_scores,
+			end_time, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, leg.StartingScore, imp.players[int64(leg.CurrentPlayerID)], leg.LegTypeID, matchID,
+		len(leg.Players), leg.IsFinished, remap(imp.players, leg.WinnerID), leg.HasScores, leg.EndTime, leg.CreatedAt, leg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	legID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	imp.legs[int64(leg.ID)] = legID
+	imp.report.Created["legs"]++
+
+	for _, player := range leg.Players {
+		_, err = imp.tx.Exec("INSERT INTO player2leg (player_id, leg_id, `order`, match_id, handicap) VALUES (?, ?, ?, ?, ?)",
+			imp.players[int64(player.PlayerID)], legID, player.Order, matchID, player.Handicap)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if params := leg.Parameters; params != nil {
+		var outshotType null.Int
+		if params.OutshotType != nil {
+			outshotType = null.IntFrom(int64(params.OutshotType.ID))
+		}
+		numbers := make([]null.Int, 9)
+		for i := 0; i < len(params.Numbers) && i < len(numbers); i++ {
+			numbers[i] = null.IntFrom(int64(params.Numbers[i]))
+		}
+		_, err = imp.tx.Exec(`
+			INSERT INTO leg_parameters (leg_id, outshot_type_id, number_1, number_2, number_3, number_4, number_5, number_6, number_7, number_8,
+				number_9, starting_lives, points_to_win, max_rounds, min_target, max_target)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, legID, outshotType, numbers[0], numbers[1], numbers[2], numbers[3],
+			numbers[4], numbers[5], numbers[6], numbers[7], numbers[8], params.StartingLives, params.PointsToWin, params.MaxRounds,
+			params.MinTarget, params.MaxTarget)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, visit := range leg.Visits {
+		res, err := imp.tx.Exec(`
+			INSERT INTO score(
+				leg_id, player_id,
+				first_dart, first_dart_multiplier,
+				second_dart, second_dart_multiplier,
+				third_dart, third_dart_multiplier,
+				is_bust, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			legID, imp.players[int64(visit.PlayerID)],
+			visit.FirstDart, visit.FirstDartMultiplier,
+			visit.SecondDart, visit.SecondDartMultiplier,
+			visit.ThirdDart, visit.ThirdDartMultiplier,
+			visit.IsBust, visit.CreatedAt)
+		if err != nil {
+			return 0, err
+		}
+		visitID, err := res.LastInsertId()
+		if err != nil {
+			return 0, err
+		}
+		imp.visits[int64(visit.ID)] = visitID
+		imp.report.Created["visits"]++
+	}
+
+	err = imp.importLegStatistics(legID, leg)
+	if err != nil {
+		return 0, err
+	}
+	return legID, nil
+}
+
+// importLegStatistics will insert the statistics of all players in the given leg, as they were exported
+func (imp *officeImport) importLegStatistics(legID int64, leg *models.OfficeExportLeg) error {
+	tables := make(map[string]bool)
+	for _, table := range statisticsTables {
+		tables[table] = true
+	}
+	for _, stats := range leg.Statistics {
+		if !tables[stats.Table] {
+			return fmt.Errorf("leg %d has statistics in unknown table '%s'", leg.ID, stats.Table)
+		}
+		values := make([]string, 0, len(stats.Values))
+		for column := range stats.Values {
+			if column != "id" && column != "leg_id" && column != "player_id" {
+				values = append(values, column)
+			}
+		}
+		sort.Strings(values)
+		columns := append([]string{"leg_id", "player_id"}, values...)
+		args := []interface{}{legID, imp.players[int64(stats.PlayerID)]}
+		for _, column := range values {
+			args = append(args, stats.Values[column])
+		}
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (?%s)", stats.Table, strings.Join(columns, ", "),
+			strings.Repeat(", ?", len(columns)-1))
+		_, err := imp.tx.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+		imp.report.Created["statistics"]++
+	}
+	return nil
+}
+
+// importBadges will add all badges unlocked by the imported players, ignoring badges the player already has
+func (imp *officeImport) importBadges(export *models.OfficeExport) error {
+	for _, badge := range export.Badges {
+		// Badges unlocked in matches which were not imported now would point to the wrong leg and visit
+		if badge.MatchID.Valid && !imp.newMatches[badge.MatchID.Int64] {
+			continue
+		}
+		res, err := imp.tx.Exec(`
+			INSERT IGNORE INTO player2badge (player_id, badge_id, level, value, leg_id, visit_id, match_id, tournament_id, opponent_player_id, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, imp.players[int64(badge.PlayerID)], imp.badgeTypes[int64(badge.BadgeID)], badge.Level, badge.Value,
+			remap(imp.legs, badge.LegID), remap(imp.visits, badge.VisitID), remap(imp.matches, badge.MatchID),
+			remap(imp.tournaments, badge.TournamentID), remap(imp.players, badge.OpponentPlayerID), badge.CreatedAt)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		imp.report.Created["badges"] += int(rows)
+		imp.report.Matched["badges"] += 1 - int(rows)
+	}
+	return nil
+}
+
+// importElo will set the Elo of new players, and add the Elo changelog of new matches.
+// Players which already existed keep their current Elo
+func (imp *officeImport) importElo(export *models.OfficeExport) error {
+	for _, elo := range export.Elo {
+		playerID := imp.players[int64(elo.PlayerID)]
+		if !imp.newPlayers[playerID] {
+			continue
+		}
+		_, err := imp.tx.Exec(`UPDATE player_elo SET current_elo = ?, current_elo_matches = ?, tournament_elo = ?, tournament_elo_matches = ? WHERE player_id = ?`,
+			elo.CurrentElo, elo.CurrentEloMatches, elo.TournamentElo, elo.TournamentEloMatches, playerID)
+		if err != nil {
+			return err
+		}
+		imp.report.Created["elo"]++
+	}
+	for _, change := range export.EloChangelog {
+		if !imp.newMatches[int64(change.MatchID)] {
+			continue
+		}
+		_, err := imp.tx.Exec(`INSERT INTO player_elo_changelog (match_id, player_id, old_elo, new_elo, old_tournament_elo, new_tournament_elo) VALUES (?, ?, ?, ?, ?, ?)`,
+			imp.matches[int64(change.MatchID)], imp.players[int64(change.PlayerID)], change.OldElo, change.NewElo, change.OldTournamentElo, change.NewTournamentElo)
+		if err != nil {
+			return err
+		}
+		imp.report.Created["elo_changelog"]++
+	}
+	return nil
+}
+
+// getOfficeExportPlayers will return all players in the office, and all players who have played matches in it
+func getOfficeExportPlayers(officeID int) ([]*models.OfficeExportPlayer, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			p.id, p.first_name, p.last_name, p.vocal_name, p.nickname, p.slack_handle, p.color, p.profile_pic_url, p.smartcard_uid,
+			p.board_stream_url, p.board_stream_css, p.active, p.is_bot, p.created_at
+		FROM player p
+		WHERE p.office_id = ? OR p.id IN (
+			SELECT p2l.player_id FROM player2leg p2l JOIN matches m ON m.id = p2l.match_id WHERE m.office_id = ?)
+		ORDER BY p.id`, officeID, officeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := make([]*models.OfficeExportPlayer, 0)
+	for rows.Next() {
+		p := new(models.OfficeExportPlayer)
+		err := rows.Scan(&p.ID, &p.FirstName, &p.LastName, &p.VocalName, &p.Nickname, &p.SlackHandle, &p.Color, &p.ProfilePicURL,
+			&p.SmartcardUID, &p.BoardStreamURL, &p.BoardStreamCSS, &p.IsActive, &p.IsBot, &p.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		players = append(players, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return players, nil
+}
+
+// getOfficeExportVenues will return all venues in the office, with configuration
+func getOfficeExportVenues(officeID int) ([]*models.Venue, error) {
+	venues, err := GetVenues()
+	if err != nil {
+		return nil, err
+	}
+	office := make([]*models.Venue, 0)
+	for _, venue := range venues {
+		if venue.OfficeID.Int64 != int64(officeID) {
+			continue
+		}
+		config, err := GetVenueConfiguration(int(venue.ID.Int64))
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		venue.Config = config
+		office = append(office, venue)
+	}
+	return office, nil
+}
+
+// getOfficeExportPresets will return all match presets, only including the given players
+func getOfficeExportPresets(players map[int]bool) ([]*models.OfficeExportPreset, error) {
+	presets, err := GetPresets()
+	if err != nil {
+		return nil, err
+	}
+	export := make([]*models.OfficeExportPreset, 0)
+	for _, preset := range presets {
+		p := &models.OfficeExportPreset{
+			ID:            preset.ID,
+			Name:          preset.Name,
+			MatchTypeID:   preset.MatchType.ID,
+			MatchModeID:   preset.MatchMode.ID,
+			StartingScore: preset.StartingScore,
+			SmartcardUID:  preset.SmartcardUID,
+			Description:   preset.Description,
+		}
+		for _, id := range preset.Players {
+			if players[id] {
+				p.Players = append(p.Players, id)
+			}
+		}
+		export = append(export, p)
+	}
+	return export, nil
+}
+
+// getOfficeExportTournaments will return all tournaments in the office, with participating players
+func getOfficeExportTournaments(officeID int) ([]*models.OfficeExportTournament, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			t.id, t.name, t.short_name, t.is_finished, t.is_playoffs, t.playoffs_tournament_id, t.manual_admin, t.start_time, t.end_time
+		FROM tournament t
+		WHERE t.office_id = ?
+		ORDER BY t.id`, officeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tournaments := make([]*models.OfficeExportTournament, 0)
+	tournamentMap := make(map[int]*models.OfficeExportTournament)
+	for rows.Next() {
+		t := new(models.OfficeExportTournament)
+		err := rows.Scan(&t.ID, &t.Name, &t.ShortName, &t.IsFinished, &t.IsPlayoffs, &t.PlayoffsTournamentID, &t.ManualAdmin,
+			&t.StartTime, &t.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		t.Players = make([]*models.OfficeExportTournamentPlayer, 0)
+		tournaments = append(tournaments, t)
+		tournamentMap[t.ID] = t
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	// Playoffs in other offices cannot be linked when importing
+	for _, t := range tournaments {
+		if t.PlayoffsTournamentID.Valid && tournamentMap[int(t.PlayoffsTournamentID.Int64)] == nil {
+			t.PlayoffsTournamentID = null.Int{}
+		}
+	}
+
+	rows, err = models.DB.Query(`
+		SELECT p2t.tournament_id, p2t.player_id, p2t.tournament_group_id
+		FROM player2tournament p2t
+			JOIN tournament t ON t.id = p2t.tournament_id
+		WHERE t.office_id = ?
+		ORDER BY p2t.tournament_id, p2t.player_id`, officeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tournamentID int
+		p := new(models.OfficeExportTournamentPlayer)
+		err := rows.Scan(&tournamentID, &p.PlayerID, &p.TournamentGroupID)
+		if err != nil {
+			return nil, err
+		}
+		tournamentMap[tournamentID].Players = append(tournamentMap[tournamentID].Players, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return tournaments, nil
+}
+
+// getOfficeExportMatches will return all matches in the office, with legs and visits
+func getOfficeExportMatches(officeID int) ([]*models.OfficeExportMatch, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			m.id, m.match_type_id, m.match_mode_id, m.owe_type_id, m.venue_id, m.tournament_id, m.is_finished, m.is_abandoned,
+			m.is_walkover, m.is_practice, m.winner_id, m.created_at, m.updated_at
+		FROM matches m
+		WHERE m.office_id = ?
+		ORDER BY m.id`, officeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]*models.OfficeExportMatch, 0)
+	matchMap := make(map[int]*models.OfficeExportMatch)
+	for rows.Next() {
+		m := new(models.OfficeExportMatch)
+		err := rows.Scan(&m.ID, &m.MatchTypeID, &m.MatchModeID, &m.OweTypeID, &m.VenueID, &m.TournamentID, &m.IsFinished, &m.IsAbandoned,
+			&m.IsWalkover, &m.IsPractice, &m.WinnerID, &m.CreatedAt, &m.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		m.Legs = make([]*models.OfficeExportLeg, 0)
+		matches = append(matches, m)
+		matchMap[m.ID] = m
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = models.DB.Query(`
+		SELECT
+			l.id, l.match_id, l.leg_type_id, l.starting_score, l.is_finished, l.has_scores, l.winner_id, l.current_player_id,
+			l.created_at, l.updated_at, l.end_time
+		FROM leg l
+			JOIN matches m ON m.id = l.match_id
+		WHERE m.office_id = ?
+		ORDER BY l.id`, officeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	legMap := make(map[int]*models.OfficeExportLeg)
+	for rows.Next() {
+		var matchID int
+		l := new(models.OfficeExportLeg)
+		err := rows.Scan(&l.ID, &matchID, &l.LegTypeID, &l.StartingScore, &l.IsFinished, &l.HasScores, &l.WinnerID, &l.CurrentPlayerID,
+			&l.CreatedAt, &l.UpdatedAt, &l.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		l.Players = make([]*models.OfficeExportLegPlayer, 0)
+		l.Visits = make([]*models.OfficeExportVisit, 0)
+		l.Statistics = make([]*models.OfficeExportStatistics, 0)
+		matchMap[matchID].Legs = append(matchMap[matchID].Legs, l)
+		legMap[l.ID] = l
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = models.DB.Query(`
+		SELECT p2l.leg_id, p2l.player_id, p2l.order, p2l.handicap
+		FROM player2leg p2l
+			JOIN matches m ON m.id = p2l.match_id
+		WHERE m.office_id = ?
+		ORDER BY p2l.leg_id, p2l.order`, officeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var legID int
+		p := new(models.OfficeExportLegPlayer)
+		err := rows.Scan(&legID, &p.PlayerID, &p.Order, &p.Handicap)
+		if err != nil {
+			return nil, err
+		}
+		legMap[legID].Players = append(legMap[legID].Players, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = models.DB.Query(`
+		SELECT
+			s.id, s.leg_id, s.player_id,
+			s.first_dart, s.first_dart_multiplier,
+			s.second_dart, s.second_dart_multiplier,
+			s.third_dart, s.third_dart_multiplier,
+			s.is_bust, s.created_at
+		FROM score s
+			JOIN leg l ON l.id = s.leg_id
+			JOIN matches m ON m.id = l.match_id
+		WHERE m.office_id = ?
+		ORDER BY s.leg_id, s.id`, officeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var legID int
+		v := new(models.OfficeExportVisit)
+		err := rows.Scan(&v.ID, &legID, &v.PlayerID,
+			&v.FirstDart, &v.FirstDartMultiplier,
+			&v.SecondDart, &v.SecondDartMultiplier,
+			&v.ThirdDart, &v.ThirdDartMultiplier,
+			&v.IsBust, &v.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		legMap[legID].Visits = append(legMap[legID].Visits, v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = getOfficeExportStatistics(officeID, legMap)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(legMap) == 0 {
+		return matches, nil
+	}
+	legIDs := make([]int, 0, len(legMap))
+	for id := range legMap {
+		legIDs = append(legIDs, id)
+	}
+	parameters, err := getLegsParameters(legIDs)
+	if err != nil {
+		return nil, err
+	}
+	for id, params := range parameters {
+		leg := legMap[id]
+		params.LegID = 0
+		if params.OutshotType != nil || params.Numbers != nil || params.StartingLives.Valid || params.PointsToWin.Valid ||
+			params.MaxRounds.Valid || params.MinTarget.Valid || params.MaxTarget.Valid {
+			params.Hits = nil
+			leg.Parameters = params
+		}
+	}
+	return matches, nil
+}
+
+// getOfficeExportStatistics will add the statistics of all players to the legs of the office
+func getOfficeExportStatistics(officeID int, legMap map[int]*models.OfficeExportLeg) error {
+	for _, table := range statisticsTables {
+		rows, err := models.DB.Query(fmt.Sprintf(`
+			SELECT s.*
+			FROM %s s
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+			WHERE m.office_id = ?
+			ORDER BY s.leg_id, s.player_id`, table), officeID)
+		if err != nil {
+			return err
+		}
+		columns, err := rows.Columns()
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		for rows.Next() {
+			values := make([]sql.RawBytes, len(columns))
+			dest := make([]interface{}, len(columns))
+			for i := range values {
+				dest[i] = &values[i]
+			}
+			err := rows.Scan(dest...)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			stats := &models.OfficeExportStatistics{Table: table, Values: make(map[string]interface{})}
+			var legID int
+			for i, column := range columns {
+				switch column {
+				case "id":
+				case "leg_id":
+					legID, _ = strconv.Atoi(string(values[i]))
+				case "player_id":
+					stats.PlayerID, _ = strconv.Atoi(string(values[i]))
+				default:
+					if values[i] == nil {
+						stats.Values[column] = nil
+					} else {
+						stats.Values[column] = string(values[i])
+					}
+				}
+			}
+			legMap[legID].Statistics = append(legMap[legID].Statistics, stats)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getOfficeExportBadges will return all badges unlocked by the exported players. Badges unlocked in matches
+// or tournaments in other offices are not included
+func getOfficeExportBadges(export *models.OfficeExport) ([]*models.OfficeExportBadge, error) {
+	players := make(map[int]bool)
+	for _, player := range export.Players {
+		players[player.ID] = true
+	}
+	matches := make(map[int64]bool)
+	legs := make(map[int64]bool)
+	for _, match := range export.Matches {
+		matches[int64(match.ID)] = true
+		for _, leg := range match.Legs {
+			legs[int64(leg.ID)] = true
+		}
+	}
+	tournaments := make(map[int64]bool)
+	for _, tournament := range export.Tournaments {
+		tournaments[int64(tournament.ID)] = true
+	}
+
+	rows, err := models.DB.Query(`
+		SELECT
+			p2b.player_id, p2b.badge_id, p2b.level, p2b.value, p2b.leg_id, p2b.visit_id, p2b.match_id, p2b.tournament_id,
+			p2b.opponent_player_id, p2b.created_at
+		FROM player2badge p2b
+		ORDER BY p2b.player_id, p2b.badge_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	badges := make([]*models.OfficeExportBadge, 0)
+	for rows.Next() {
+		b := new(models.OfficeExportBadge)
+		err := rows.Scan(&b.PlayerID, &b.BadgeID, &b.Level, &b.Value, &b.LegID, &b.VisitID, &b.MatchID, &b.TournamentID,
+			&b.OpponentPlayerID, &b.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if !players[b.PlayerID] || (b.OpponentPlayerID.Valid && !players[int(b.OpponentPlayerID.Int64)]) ||
+			(b.MatchID.Valid && !matches[b.MatchID.Int64]) || (b.LegID.Valid && !legs[b.LegID.Int64]) ||
+			(b.TournamentID.Valid && !tournaments[b.TournamentID.Int64]) {
+			continue
+		}
+		badges = append(badges, b)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return badges, nil
+}
+
+// getOfficeExportReferences will return the ID and name of every row returned by the given query
+func getOfficeExportReferences(query string) ([]*models.OfficeExportReference, error) {
+	rows, err := models.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	references := make([]*models.OfficeExportReference, 0)
+	for rows.Next() {
+		r := new(models.OfficeExportReference)
+		if err := rows.Scan(&r.ID, &r.Name); err != nil {
+			return nil, err
+		}
+		references = append(references, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return references, nil
+}
+
+// getOfficeExportElo will return the Elo of all exported players
+func getOfficeExportElo(officeID int) ([]*models.PlayerElo, error) {
+	rows, err := models.DB.Query(`
+		SELECT pe.player_id, pe.current_elo, pe.current_elo_matches, pe.tournament_elo, pe.tournament_elo_matches
+		FROM player_elo pe
+			JOIN player p ON p.id = pe.player_id
+		WHERE p.office_id = ? OR p.id IN (
+			SELECT p2l.player_id FROM player2leg p2l JOIN matches m ON m.id = p2l.match_id WHERE m.office_id = ?)
+		ORDER BY pe.player_id`, officeID, officeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	elos := make([]*models.PlayerElo, 0)
+	for rows.Next() {
+		p := new(models.PlayerElo)
+		err := rows.Scan(&p.PlayerID, &p.CurrentElo, &p.CurrentEloMatches, &p.TournamentElo, &p.TournamentEloMatches)
+		if err != nil {
+			return nil, err
+		}
+		elos = append(elos, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return elos, nil
+}
+
+// getOfficeExportEloChangelog will return the Elo changelog of all matches in the office
+func getOfficeExportEloChangelog(officeID int) ([]*models.OfficeExportEloChangelog, error) {
+	rows, err := models.DB.Query(`
+		SELECT pec.match_id, pec.player_id, pec.old_elo, pec.new_elo, pec.old_tournament_elo, pec.new_tournament_elo
+		FROM player_elo_changelog pec
+			JOIN matches m ON m.id = pec.match_id
+		WHERE m.office_id = ?
+		ORDER BY pec.match_id, pec.player_id`, officeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changelog := make([]*models.OfficeExportEloChangelog, 0)
+	for rows.Next() {
+		c := new(models.OfficeExportEloChangelog)
+		err := rows.Scan(&c.MatchID, &c.PlayerID, &c.OldElo, &c.NewElo, &c.OldTournamentElo, &c.NewTournamentElo)
+		if err != nil {
+			return nil, err
+		}
+		changelog = append(changelog, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return changelog, nil
+}
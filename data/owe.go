@@ -1,9 +1,14 @@
 package data
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"log"
+	"math"
 
+	"github.com/guregu/null"
+	"github.com/kcapp/api/logging"
 	"github.com/kcapp/api/models"
 )
 
@@ -21,8 +26,12 @@ func GetOwes() ([]*models.Owe, error) {
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return scanOwes(rows)
+}
 
+// scanOwes will read all owes from the given rows, and close the rows
+func scanOwes(rows *sql.Rows) ([]*models.Owe, error) {
+	defer rows.Close()
 	owes := make([]*models.Owe, 0)
 	for rows.Next() {
 		o := new(models.Owe)
@@ -33,33 +42,227 @@ func GetOwes() ([]*models.Owe, error) {
 		}
 		owes = append(owes, o)
 	}
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-
 	return owes, nil
 }
 
 // RegisterPayback will register a payback between the given players
-func RegisterPayback(owe models.Owe) error {
-	stmt, err := models.DB.Prepare(`UPDATE owes SET amount = amount - ? WHERE player_ower_id = ? AND player_owee_id = ? and owe_type_id = ?`)
+func RegisterPayback(ctx context.Context, owe models.Owe) error {
+	tx, err := models.DB.Begin()
 	if err != nil {
 		return err
 	}
-	defer stmt.Close()
-	res, err := stmt.Exec(owe.Amount, owe.PlayerOwerID, owe.PlayerOweeID, owe.OweType.ID)
+	res, err := tx.Exec(`UPDATE owes SET amount = amount - ? WHERE player_ower_id = ? AND player_owee_id = ? and owe_type_id = ?`,
+		owe.Amount, owe.PlayerOwerID, owe.PlayerOweeID, owe.OweType.ID)
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 
 	updatedRows, err := res.RowsAffected()
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 	if updatedRows == 0 {
+		tx.Rollback()
 		return errors.New("no rows were updated when registering payback")
 	}
-	log.Printf("Player %d paid back %d items %d to player %d", owe.PlayerOwerID, owe.Amount, owe.OweType.ID.Int64, owe.PlayerOweeID)
+	err = addOweTransaction(tx, owe.PlayerOwerID, owe.PlayerOweeID, owe.OweType.ID, owe.Amount, null.Int{}, true)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	tx.Commit()
+	logging.Logger(ctx).Printf("Player %d paid back %d items %d to player %d", owe.PlayerOwerID, owe.Amount, owe.OweType.ID.Int64, owe.PlayerOweeID)
+	invalidatePlayers([]int{owe.PlayerOwerID, owe.PlayerOweeID})
+	return nil
+}
+
+// addOweTransaction will record a debt or payback in the owes ledger
+func addOweTransaction(tx *sql.Tx, owerID int, oweeID int, oweTypeID null.Int, amount int, matchID null.Int, isPayback bool) error {
+	_, err := tx.Exec(`
+		INSERT INTO owe_transaction (player_ower_id, player_owee_id, owe_type_id, amount, match_id, is_payback, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, NOW())`, owerID, oweeID, oweTypeID, amount, matchID, isPayback)
+	return err
+}
+
+// GetOweHistory will return the latest transactions in the owes ledger, newest first, or all transactions if limit is 0.
+// If playerID is given, only transactions involving the given player are returned
+func GetOweHistory(playerID null.Int, limit int) ([]*models.OweTransaction, error) {
+	if limit <= 0 {
+		limit = math.MaxInt32
+	}
+	rows, err := models.DB.Query(`
+		SELECT
+			t.id, t.player_ower_id, t.player_owee_id,
+			ot.id, ot.item,
+			t.amount, t.match_id, t.is_payback, t.created_at
+		FROM owe_transaction t
+		JOIN owe_type ot ON ot.id = t.owe_type_id
+		WHERE ? IS NULL OR t.player_ower_id = ? OR t.player_owee_id = ?
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT ?`, playerID, playerID, playerID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := make([]*models.OweTransaction, 0)
+	for rows.Next() {
+		t := new(models.OweTransaction)
+		t.OweType = new(models.OweType)
+		err := rows.Scan(&t.ID, &t.PlayerOwerID, &t.PlayerOweeID, &t.OweType.ID, &t.OweType.Item, &t.Amount, &t.MatchID,
+			&t.IsPayback, &t.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// GetOweBalanceSheet will return the balance between the given player and every player they owe, or are owed by
+func GetOweBalanceSheet(playerID int) (*models.OweBalanceSheet, error) {
+	owes, err := GetOwes()
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := &models.OweBalanceSheet{PlayerID: playerID, Balances: make([]*models.OweBalance, 0)}
+	balances := make(map[[2]int64]*models.OweBalance)
+	for _, owe := range owes {
+		var other int
+		if owe.PlayerOweeID == playerID {
+			other = owe.PlayerOwerID
+		} else if owe.PlayerOwerID == playerID {
+			other = owe.PlayerOweeID
+		} else {
+			continue
+		}
+		key := [2]int64{int64(other), owe.OweType.ID.Int64}
+		balance, ok := balances[key]
+		if !ok {
+			balance = &models.OweBalance{PlayerID: other, OweType: owe.OweType}
+			balances[key] = balance
+			sheet.Balances = append(sheet.Balances, balance)
+		}
+		if owe.PlayerOweeID == playerID {
+			balance.Owed += owe.Amount
+		} else {
+			balance.Owing += owe.Amount
+		}
+		balance.Net = balance.Owed - balance.Owing
+	}
+	return sheet, nil
+}
+
+// GetOweSettlement will suggest paybacks to settle all owes between the given players, using at most one payback less
+// than the number of players with a balance. If no players are given, all owes are settled
+func GetOweSettlement(playerIDs []int) ([]*models.Owe, error) {
+	owes, err := GetOwes()
+	if err != nil {
+		return nil, err
+	}
+	return models.SettleOwes(filterOwes(owes, playerIDs)), nil
+}
+
+// ApplyOweSettlement will settle all owes between the given players, as suggested by GetOweSettlement. As the suggested
+// paybacks may be between players who do not owe each other, every owe between the players is paid back in full in the
+// ledger instead. If no players are given, all owes are settled. The applied paybacks are returned
+func ApplyOweSettlement(ctx context.Context, playerIDs []int) ([]*models.Owe, error) {
+	var paybacks []*models.Owe
+	var settled []*models.Owe
+	err := models.Transaction(models.DB, func(tx *sql.Tx) error {
+		rows, err := tx.Query(`
+			SELECT
+				o.player_ower_id,
+				o.player_owee_id,
+				ot.id, ot.item,
+				o.amount
+			FROM owes o
+			JOIN owe_type ot ON ot.id = o.owe_type_id
+			WHERE o.amount > 0
+			FOR UPDATE`)
+		if err != nil {
+			return err
+		}
+		owes, err := scanOwes(rows)
+		if err != nil {
+			return err
+		}
+		settled = filterOwes(owes, playerIDs)
+		for _, owe := range settled {
+			_, err = tx.Exec("UPDATE owes SET amount = 0 WHERE player_ower_id = ? AND player_owee_id = ? AND owe_type_id = ?",
+				owe.PlayerOwerID, owe.PlayerOweeID, owe.OweType.ID)
+			if err != nil {
+				return err
+			}
+			err = addOweTransaction(tx, owe.PlayerOwerID, owe.PlayerOweeID, owe.OweType.ID, owe.Amount, null.Int{}, true)
+			if err != nil {
+				return err
+			}
+		}
+		paybacks = models.SettleOwes(settled)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	players := make([]int, 0)
+	for _, owe := range settled {
+		players = append(players, owe.PlayerOwerID, owe.PlayerOweeID)
+	}
+	logging.Logger(ctx).Printf("Settled %d owes with %d paybacks", len(settled), len(paybacks))
+	invalidatePlayers(players)
+	return paybacks, nil
+}
+
+// filterOwes will return the owes between the given players, or all owes if no players are given
+func filterOwes(owes []*models.Owe, playerIDs []int) []*models.Owe {
+	if len(playerIDs) == 0 {
+		return owes
+	}
+	group := make(map[int]bool)
+	for _, id := range playerIDs {
+		group[id] = true
+	}
+	filtered := make([]*models.Owe, 0)
+	for _, owe := range owes {
+		if group[owe.PlayerOwerID] && group[owe.PlayerOweeID] {
+			filtered = append(filtered, owe)
+		}
+	}
+	return filtered
+}
+
+// BackfillOweLedger will add the current owes as opening balances to the ledger, if the ledger is empty
+func BackfillOweLedger() error {
+	var transactions int
+	err := models.DB.QueryRow("SELECT COUNT(*) FROM owe_transaction").Scan(&transactions)
+	if err != nil {
+		return err
+	}
+	if transactions > 0 {
+		log.Printf("Owes ledger already contains %d transactions, skipping backfill", transactions)
+		return nil
+	}
+	res, err := models.DB.Exec(`
+		INSERT INTO owe_transaction (player_ower_id, player_owee_id, owe_type_id, amount, match_id, is_payback, created_at)
+		SELECT player_ower_id, player_owee_id, owe_type_id, amount, NULL, 0, NOW() FROM owes WHERE amount > 0`)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	log.Printf("Added %d opening balances to owes ledger", rows)
 	return nil
 }
 
@@ -8,6 +8,7 @@ import (
 	"github.com/guregu/null"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/cache"
 	"github.com/kcapp/api/models"
 	"github.com/kcapp/api/util"
 )
@@ -55,6 +56,41 @@ func GetPlayers() (map[int]*models.Player, error) {
 	return players, nil
 }
 
+// GetPlayersByID returns a map of the players with the given IDs, without the number of matches and legs played
+func GetPlayersByID(ids []int) (map[int]*models.Player, error) {
+	q, args, err := sqlx.In(`
+		SELECT
+			p.id, p.first_name, p.last_name, p.vocal_name, p.nickname, p.slack_handle, p.color, p.profile_pic_url, p.smartcard_uid,
+			 p.board_stream_url, p.board_stream_css, p.active, p.office_id, p.is_bot, p.is_placeholder, p.is_supporter, p.created_at,
+			 p.updated_at
+		FROM player p
+		WHERE p.id IN (?)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := make(map[int]*models.Player)
+	for rows.Next() {
+		p := new(models.Player)
+		err := rows.Scan(&p.ID, &p.FirstName, &p.LastName, &p.VocalName, &p.Nickname, &p.SlackHandle, &p.Color, &p.ProfilePicURL,
+			&p.SmartcardUID, &p.BoardStreamURL, &p.BoardStreamCSS, &p.IsActive, &p.OfficeID, &p.IsBot, &p.IsPlaceholder, &p.IsSupporter,
+			&p.CreatedAt, &p.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		players[p.ID] = p
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return players, nil
+}
+
 // GetActivePlayers returns a map of all active players
 func GetActivePlayers() (map[int]*models.Player, error) {
 	played, err := GetMatchesPlayedPerPlayer()
@@ -268,6 +304,8 @@ func UpdatePlayer(playerID int, player models.Player) error {
 	}
 	tx.Commit()
 	log.Printf("Updated player %s (%v)", player.FirstName, player)
+	invalidatePlayers([]int{playerID})
+	cache.Invalidate(cache.TagStatistics)
 	return nil
 }
 
@@ -461,6 +499,26 @@ func GetPlayersScore(legID int) (map[int]*models.Player2Leg, error) {
 				scores[visit.PlayerID].CurrentScore += score
 			}
 		}
+	} else if matchType == models.GOLF || matchType == models.BASEBALL {
+		visits, err := GetLegVisits(legID)
+		if err != nil {
+			return nil, err
+		}
+		for _, player := range scores {
+			player.CurrentScore = 0
+		}
+
+		round := 1
+		for i, visit := range visits {
+			if i > 0 && i%len(players) == 0 {
+				round++
+			}
+			if matchType == models.GOLF {
+				scores[visit.PlayerID].CurrentScore += visit.CalculateGolfScore(round)
+			} else {
+				scores[visit.PlayerID].CurrentScore += visit.CalculateBaseballScore(round)
+			}
+		}
 	} else if matchType == models.FOURTWENTY {
 		visits, err := GetLegVisits(legID)
 		if err != nil {
@@ -625,6 +683,40 @@ func GetPlayersScore(legID int) (map[int]*models.Player2Leg, error) {
 			player := scores[visit.PlayerID]
 			visit.Calculate170Score(round, player)
 		}
+	} else if matchType == models.BOBS27 {
+		visits, err := GetLegVisits(legID)
+		if err != nil {
+			return nil, err
+		}
+		for _, player := range scores {
+			player.CurrentScore = models.Bobs27StartingScore
+		}
+
+		// Players might be knocked out, so count the rounds played by each player
+		rounds := make(map[int]int)
+		for _, visit := range visits {
+			scores[visit.PlayerID].CurrentScore += visit.CalculateBobs27Score(rounds[visit.PlayerID])
+			rounds[visit.PlayerID]++
+		}
+	} else if matchType == models.ONETWENTYONE {
+		visits, err := GetLegVisits(legID)
+		if err != nil {
+			return nil, err
+		}
+		params, err := GetLegParameters(legID)
+		if err != nil {
+			return nil, err
+		}
+		for _, player := range scores {
+			player.CurrentScore = player.StartingScore
+			player.DartsThrown = 0
+			player.CurrentPoints = null.IntFrom(0)
+		}
+
+		for _, visit := range visits {
+			player := scores[visit.PlayerID]
+			visit.Calculate121Score(player, params)
+		}
 	}
 	return scores, nil
 }
@@ -1012,6 +1104,32 @@ func GetPlayerHeadToHead(player1 int, player2 int) (*models.StatisticsHead2Head,
 	}
 	head2head.Head2HeadWins = head2headWins
 
+	matchIDs := make([]int, len(head2headMatches))
+	for i, match := range head2headMatches {
+		matchIDs[i] = match.ID
+	}
+	legs, err := getHeadToHeadLegs(matchIDs)
+	if err != nil {
+		return nil, err
+	}
+	head2head.MatchTypes = models.NewHead2HeadMatchTypes(player1, player2, legs)
+
+	legIDs := make([]int, len(legs))
+	for i, leg := range legs {
+		legIDs[i] = leg.LegID
+	}
+	moments, err := getHeadToHeadMoments(player1, player2, legIDs)
+	if err != nil {
+		return nil, err
+	}
+	head2head.Moments = moments
+
+	history, err := getHeadToHeadEloHistory(player1, player2)
+	if err != nil {
+		return nil, err
+	}
+	head2head.EloHistory = history
+
 	matches1, err := GetPlayerLastMatches(player1, 5)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,172 @@
+package data
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/cache"
+	"github.com/kcapp/api/models"
+	"github.com/kcapp/api/util"
+)
+
+// GetPlayerExport will return all data stored for the given player
+func GetPlayerExport(id int) (*models.PlayerExport, error) {
+	export := new(models.PlayerExport)
+	export.ExportedAt = time.Now().UTC()
+
+	player, err := GetPlayer(id)
+	if err != nil {
+		return nil, err
+	}
+	export.Player = player
+
+	elos, err := GetPlayersElo(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(elos) > 0 {
+		export.Elo = elos[0]
+	}
+
+	changelog, err := GetPlayerEloChangelog(id, 0, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	export.EloChangelog = changelog.Changelog
+
+	export.Matches, err = getPlayerMatches(id)
+	if err != nil {
+		return nil, err
+	}
+	export.Visits, err = getPlayerVisits(id)
+	if err != nil {
+		return nil, err
+	}
+	export.Badges, err = GetPlayerBadges(id)
+	if err != nil {
+		return nil, err
+	}
+
+	owes, err := GetOwes()
+	if err != nil {
+		return nil, err
+	}
+	export.Owes = make([]*models.Owe, 0)
+	for _, owe := range owes {
+		if owe.PlayerOwerID == id || owe.PlayerOweeID == id {
+			export.Owes = append(export.Owes, owe)
+		}
+	}
+	export.OweHistory, err = GetOweHistory(null.IntFrom(int64(id)), 0)
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// AnonymisePlayer will remove all personal information about the given player, while keeping the match history intact
+func AnonymisePlayer(id int) (*models.Player, error) {
+	_, err := GetPlayer(id)
+	if err != nil {
+		return nil, err
+	}
+	_, err = models.DB.Exec(`
+		UPDATE player SET
+			first_name = 'Anonymous', last_name = NULL, vocal_name = NULL, nickname = NULL, slack_handle = NULL,
+			profile_pic_url = NULL, smartcard_uid = NULL, board_stream_url = NULL, board_stream_css = NULL,
+			active = 0, updated_at = NOW()
+		WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Anonymised player %d", id)
+	invalidatePlayers([]int{id})
+	cache.Invalidate(cache.TagStatistics)
+	return GetPlayer(id)
+}
+
+// getPlayerMatches will return all matches the given player has participated in
+func getPlayerMatches(playerID int) ([]*models.Match, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			m.id, m.is_finished, m.is_abandoned, m.is_walkover, m.is_bye, m.current_leg_id, m.winner_id, m.office_id, m.is_practice,
+			m.created_at, m.updated_at, m.owe_type_id, m.venue_id, m.tournament_id, mt.id, mt.name, mt.description,
+			mm.id, mm.name, mm.short_name, mm.wins_required, mm.legs_required, mm.is_draw_possible, mm.is_challenge,
+			GROUP_CONCAT(DISTINCT all_players.player_id ORDER BY all_players.order) AS 'players'
+		FROM matches m
+			JOIN match_type mt ON mt.id = m.match_type_id
+			JOIN match_mode mm ON mm.id = m.match_mode_id
+			JOIN player2leg p2l ON p2l.match_id = m.id
+			JOIN player2leg all_players ON all_players.match_id = m.id
+		WHERE p2l.player_id = ?
+		GROUP BY m.id
+		ORDER BY m.id`, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]*models.Match, 0)
+	for rows.Next() {
+		m := new(models.Match)
+		m.MatchType = new(models.MatchType)
+		m.MatchMode = new(models.MatchMode)
+		var players string
+		err := rows.Scan(&m.ID, &m.IsFinished, &m.IsAbandoned, &m.IsWalkover, &m.IsBye, &m.CurrentLegID, &m.WinnerID, &m.OfficeID,
+			&m.IsPractice, &m.CreatedAt, &m.UpdatedAt, &m.OweTypeID, &m.VenueID, &m.TournamentID, &m.MatchType.ID, &m.MatchType.Name,
+			&m.MatchType.Description, &m.MatchMode.ID, &m.MatchMode.Name, &m.MatchMode.ShortName, &m.MatchMode.WinsRequired,
+			&m.MatchMode.LegsRequired, &m.MatchMode.IsDrawPossible, &m.MatchMode.IsChallenge, &players)
+		if err != nil {
+			return nil, err
+		}
+		m.Players = util.StringToIntArray(players)
+		matches = append(matches, m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// getPlayerVisits will return all visits thrown by the given player
+func getPlayerVisits(playerID int) ([]*models.Visit, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			id, leg_id, player_id,
+			first_dart, first_dart_multiplier,
+			second_dart, second_dart_multiplier,
+			third_dart, third_dart_multiplier,
+			is_bust,
+			created_at,
+			updated_at
+		FROM score s
+		WHERE player_id = ?
+		ORDER BY id`, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	visits := make([]*models.Visit, 0)
+	for rows.Next() {
+		v := new(models.Visit)
+		v.FirstDart = new(models.Dart)
+		v.SecondDart = new(models.Dart)
+		v.ThirdDart = new(models.Dart)
+		err := rows.Scan(&v.ID, &v.LegID, &v.PlayerID,
+			&v.FirstDart.Value, &v.FirstDart.Multiplier,
+			&v.SecondDart.Value, &v.SecondDart.Multiplier,
+			&v.ThirdDart.Value, &v.ThirdDart.Multiplier,
+			&v.IsBust, &v.CreatedAt, &v.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		visits = append(visits, v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return visits, nil
+}
@@ -0,0 +1,348 @@
+package data
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/cache"
+	"github.com/kcapp/api/models"
+)
+
+// statisticsTables contains all tables storing per leg statistics for a player
+var statisticsTables = []string{
+	"statistics_x01", "statistics_shootout", "statistics_cricket", "statistics_darts_at_x", "statistics_around_the",
+	"statistics_tic_tac_toe", "statistics_bermuda_triangle", "statistics_420", "statistics_kill_bull", "statistics_gotcha",
+	"statistics_jdc_practice", "statistics_knockout", "statistics_scam", "statistics_170", "statistics_bobs_27",
+	"statistics_121", "statistics_golf", "statistics_baseball"}
+
+// MergePlayers will move all history of the duplicate player over to the given player, and deactivate the duplicate.
+// Statistics and Elo for all affected matches are recalculated afterwards. If dryRun is set, no changes are persisted.
+// Returns sql.ErrNoRows if either player does not exist
+func MergePlayers(playerID int, duplicateID int, dryRun bool) (*models.PlayerMergeReport, error) {
+	if playerID == duplicateID {
+		return nil, &models.PlayerMergeError{Err: fmt.Errorf("cannot merge player %d with itself", playerID)}
+	}
+	player, err := GetPlayer(playerID)
+	if err != nil {
+		return nil, err
+	}
+	duplicate, err := GetPlayer(duplicateID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Players who have played against each other cannot be merged, since it would leave a player playing against themselves
+	var shared int
+	err = models.DB.QueryRow(`
+		SELECT COUNT(DISTINCT a.match_id)
+		FROM player2leg a
+			JOIN player2leg b ON b.leg_id = a.leg_id
+		WHERE a.player_id = ? AND b.player_id = ?`, playerID, duplicateID).Scan(&shared)
+	if err != nil {
+		return nil, err
+	}
+	if shared > 0 {
+		return nil, &models.PlayerMergeError{Err: fmt.Errorf("cannot merge players %d and %d, they have played %d matches against each other", playerID, duplicateID, shared)}
+	}
+
+	report := &models.PlayerMergeReport{
+		PlayerID:               playerID,
+		DuplicateID:            duplicateID,
+		DryRun:                 dryRun,
+		Matches:                make([]int, 0),
+		Legs:                   make([]int, 0),
+		UpdatedRows:            make(map[string]int64),
+		StatisticsRecalculated: make(map[string]int),
+		CreatedAt:              time.Now().UTC(),
+	}
+
+	legTypes, err := getLegTypesForPlayer(duplicateID)
+	if err != nil {
+		return nil, err
+	}
+	matches := make(map[int]bool)
+	for legID, leg := range legTypes {
+		report.Legs = append(report.Legs, legID)
+		if !matches[leg.MatchID] {
+			matches[leg.MatchID] = true
+			report.Matches = append(report.Matches, leg.MatchID)
+		}
+	}
+	sort.Ints(report.Legs)
+	sort.Ints(report.Matches)
+
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	exec := func(name string, query string, args ...interface{}) error {
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		report.UpdatedRows[name] += rows
+		return nil
+	}
+
+	updates := []struct {
+		name  string
+		query string
+	}{
+		{"score", "UPDATE score SET player_id = ? WHERE player_id = ?"},
+		{"player2leg", "UPDATE player2leg SET player_id = ? WHERE player_id = ?"},
+		{"bot2player2leg", "UPDATE bot2player2leg SET player_id = ? WHERE player_id = ?"},
+		{"leg", "UPDATE leg SET current_player_id = ? WHERE current_player_id = ?"},
+		{"leg", "UPDATE leg SET winner_id = ? WHERE winner_id = ?"},
+		{"matches", "UPDATE matches SET winner_id = ? WHERE winner_id = ?"},
+		{"player_elo_changelog", "UPDATE player_elo_changelog SET player_id = ? WHERE player_id = ?"},
+		{"player2badge", "UPDATE IGNORE player2badge SET player_id = ? WHERE player_id = ?"},
+		{"player2badge", "UPDATE player2badge SET opponent_player_id = ? WHERE opponent_player_id = ?"},
+		{"player_record", "UPDATE player_record SET player_id = ? WHERE player_id = ?"},
+		{"player2tournament", "UPDATE IGNORE player2tournament SET player_id = ? WHERE player_id = ?"},
+		{"tournament_standings", "UPDATE IGNORE tournament_standings SET player_id = ? WHERE player_id = ?"},
+		{"owe_transaction", "UPDATE owe_transaction SET player_ower_id = ? WHERE player_ower_id = ?"},
+		{"owe_transaction", "UPDATE owe_transaction SET player_owee_id = ? WHERE player_owee_id = ?"},
+	}
+	for _, table := range statisticsTables {
+		updates = append(updates, struct {
+			name  string
+			query string
+		}{table, fmt.Sprintf("UPDATE %s SET player_id = ? WHERE player_id = ?", table)})
+	}
+	for _, update := range updates {
+		err = exec(update.name, update.query, playerID, duplicateID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Rows which could not be moved already exists for the surviving player, so they can be removed
+	for _, table := range []string{"player2badge", "player2tournament", "tournament_standings"} {
+		_, err = tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE player_id = ?", table), duplicateID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Owes are summed together with any existing owes between the same players
+	err = exec("owes", `
+		INSERT INTO owes (player_ower_id, player_owee_id, owe_type_id, amount)
+			SELECT ?, o.player_owee_id, o.owe_type_id, o.amount FROM owes o WHERE o.player_ower_id = ?
+		ON DUPLICATE KEY UPDATE amount = owes.amount + VALUES(amount)`, playerID, duplicateID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = exec("owes", `
+		INSERT INTO owes (player_ower_id, player_owee_id, owe_type_id, amount)
+			SELECT o.player_ower_id, ?, o.owe_type_id, o.amount FROM owes o WHERE o.player_owee_id = ?
+		ON DUPLICATE KEY UPDATE amount = owes.amount + VALUES(amount)`, playerID, duplicateID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	_, err = tx.Exec("DELETE FROM owes WHERE player_ower_id = ? OR player_owee_id = ? OR player_ower_id = player_owee_id", duplicateID, duplicateID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	// Debts between the merged players would otherwise remain in the ledger, owed by a player to themselves
+	_, err = tx.Exec("DELETE FROM owe_transaction WHERE player_ower_id = player_owee_id")
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = mergePresetPlayers(tx, playerID, duplicateID, report)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	_, err = tx.Exec("DELETE FROM player_elo WHERE player_id = ?", duplicateID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Keep the smartcard of the duplicate if the surviving player does not have one
+	smartcard := player.SmartcardUID
+	if !smartcard.Valid {
+		smartcard = duplicate.SmartcardUID
+	}
+	_, err = tx.Exec("UPDATE player SET smartcard_uid = NULL, active = 0, updated_at = NOW() WHERE id = ?", duplicateID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = exec("player", "UPDATE player SET smartcard_uid = ?, updated_at = NOW() WHERE id = ?", smartcard, playerID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if dryRun {
+		tx.Rollback()
+		log.Printf("Dry-run of merging player %d into %d, no changes made", duplicateID, playerID)
+		for _, leg := range legTypes {
+			report.StatisticsRecalculated[models.MatchTypes[leg.MatchType]]++
+			report.EloRecalculated = report.EloRecalculated || leg.MatchType == models.X01
+		}
+		return report, nil
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Merged player %d into %d, moved %d matches", duplicateID, playerID, len(report.Matches))
+
+	// Statistics are only updated in place, so recalculation can safely be repeated if any of it fails
+	legs := make(map[int][]int)
+	for legID, leg := range legTypes {
+		legs[leg.MatchType] = append(legs[leg.MatchType], legID)
+	}
+	for matchType, ids := range legs {
+		if matchType == models.X01 {
+			report.EloRecalculated = true
+		}
+		err = recalculateMergedStatistics(matchType, ids)
+		if err != nil {
+			log.Printf("Unable to recalculate statistics for match type %d: %s", matchType, err)
+			report.RecalculationErrors = append(report.RecalculationErrors,
+				fmt.Sprintf("statistics for %s: %s", models.MatchTypes[matchType], err))
+			continue
+		}
+		report.StatisticsRecalculated[models.MatchTypes[matchType]] += len(ids)
+	}
+
+	// Elo depends on the order of all matches played, so it has to be recalculated from scratch
+	if report.EloRecalculated {
+		err = RecalculateElo(false)
+		if err != nil {
+			log.Printf("Unable to recalculate Elo: %s", err)
+			report.RecalculationErrors = append(report.RecalculationErrors, fmt.Sprintf("elo: %s", err))
+		}
+	}
+	cache.InvalidateAll()
+	return report, nil
+}
+
+// recalculateMergedStatistics will recalculate statistics of the given legs
+func recalculateMergedStatistics(matchType int, legs []int) error {
+	queries, err := getRecalculateStatisticsQueries(matchType, legs)
+	if err != nil {
+		return err
+	}
+	for _, query := range queries {
+		_, err = models.DB.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type mergeLeg struct {
+	MatchID   int
+	MatchType int
+}
+
+// getLegTypesForPlayer will return the match and type of all finished legs played by the given player
+func getLegTypesForPlayer(playerID int) (map[int]*mergeLeg, error) {
+	rows, err := models.DB.Query(`
+		SELECT l.id, l.match_id, IFNULL(l.leg_type_id, m.match_type_id)
+		FROM player2leg p2l
+			JOIN leg l ON l.id = p2l.leg_id
+			JOIN matches m ON m.id = l.match_id
+		WHERE p2l.player_id = ? AND l.is_finished = 1 AND l.has_scores = 1 AND m.is_abandoned = 0
+		ORDER BY l.id`, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	legs := make(map[int]*mergeLeg)
+	for rows.Next() {
+		var legID int
+		leg := new(mergeLeg)
+		err := rows.Scan(&legID, &leg.MatchID, &leg.MatchType)
+		if err != nil {
+			return nil, err
+		}
+		legs[legID] = leg
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return legs, nil
+}
+
+// mergePresetPlayers will replace the duplicate player in any match presets
+func mergePresetPlayers(tx *sql.Tx, playerID int, duplicateID int, report *models.PlayerMergeReport) error {
+	rows, err := tx.Query("SELECT id, players FROM match_preset WHERE players IS NOT NULL")
+	if err != nil {
+		return err
+	}
+	presets := make(map[int][]int)
+	for rows.Next() {
+		var id int
+		var pStr null.String
+		err := rows.Scan(&id, &pStr)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		var players []int
+		err = json.Unmarshal([]byte(pStr.String), &players)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		presets[id] = players
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for id, players := range presets {
+		changed := false
+		merged := make([]int, 0, len(players))
+		for _, id := range players {
+			if id == duplicateID {
+				id = playerID
+				changed = true
+			}
+			if id == playerID && slices.Contains(merged, playerID) {
+				// Player was already part of the preset
+				continue
+			}
+			merged = append(merged, id)
+		}
+		if !changed {
+			continue
+		}
+		b, err := json.Marshal(merged)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE match_preset SET players = ? WHERE id = ?", string(b), id)
+		if err != nil {
+			return err
+		}
+		report.UpdatedRows["match_preset"]++
+	}
+	return nil
+}
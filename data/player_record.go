@@ -0,0 +1,305 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/cache"
+	"github.com/kcapp/api/logging"
+	"github.com/kcapp/api/models"
+)
+
+// recordMetric is a personal record tracked for a match type, with the value of a single leg in s
+type recordMetric struct {
+	name          string
+	table         string
+	value         string
+	lowerIsBetter bool
+}
+
+// recordMetrics contains the personal records tracked for each match type
+var recordMetrics = map[int][]recordMetric{
+	models.X01: {
+		{"three_dart_avg", "statistics_x01", "s.ppd_score * 3 / s.darts_thrown", false},
+		{"first_nine_avg", "statistics_x01", "s.first_nine_ppd_score * 3 / LEAST(s.darts_thrown, 9)", false},
+		{"highest_checkout", "statistics_x01", "s.checkout", false},
+		{"best_301", "statistics_x01", "IF(l.winner_id = s.player_id AND l.starting_score = 301, s.darts_thrown, NULL)", true},
+		{"best_501", "statistics_x01", "IF(l.winner_id = s.player_id AND l.starting_score = 501, s.darts_thrown, NULL)", true},
+		{"best_701", "statistics_x01", "IF(l.winner_id = s.player_id AND l.starting_score = 701, s.darts_thrown, NULL)", true},
+		{"180s", "statistics_x01", "NULLIF(s.180s, 0)", false},
+	},
+	models.SHOOTOUT: {{"score", "statistics_shootout", "s.score", false}},
+	models.CRICKET: {
+		{"mpr", "statistics_cricket", "s.mpr", false},
+		{"first_nine_mpr", "statistics_cricket", "s.first_nine_mpr", false},
+		{"fewest_rounds", "statistics_cricket", "IF(l.winner_id = s.player_id, s.rounds, NULL)", true},
+	},
+	models.DARTSATX:       {{"hit_rate", "statistics_darts_at_x", "s.hit_rate", false}},
+	models.AROUNDTHEWORLD: {{"mpr", "statistics_around_the", "s.mpr", false}},
+	models.SHANGHAI:       {{"score", "statistics_around_the", "s.score", false}},
+	models.AROUNDTHECLOCK: {
+		{"darts_to_finish", "statistics_around_the", "IF(s.score = 21, s.darts_thrown, NULL)", true},
+		{"longest_streak", "statistics_around_the", "s.longest_streak", false},
+	},
+	models.TICTACTOE:       {{"numbers_closed", "statistics_tic_tac_toe", "s.numbers_closed", false}},
+	models.BERMUDATRIANGLE: {{"score", "statistics_bermuda_triangle", "s.score", false}},
+	models.FOURTWENTY:      {{"hit_rate", "statistics_420", "s.total_hit_rate", false}},
+	models.KILLBULL:        {{"score", "statistics_kill_bull", "s.score", false}},
+	models.JDCPRACTICE:     {{"score", "statistics_jdc_practice", "s.score", false}},
+	models.SCAM:            {{"ppd", "statistics_scam", "s.ppd", false}},
+	models.ONESEVENTY:      {{"checkouts", "statistics_170", "s.checkout_completed", false}},
+	models.BOBS27:          {{"score", "statistics_bobs_27", "s.score", false}},
+	models.ONETWENTYONE:    {{"checkouts", "statistics_121", "s.checkouts", false}},
+	models.GOLF:            {{"score", "statistics_golf", "s.score", true}},
+	models.BASEBALL:        {{"score", "statistics_baseball", "s.score", false}},
+}
+
+// CheckLegForRecords will store a new personal record for each player who beat their current record in the given leg
+func CheckLegForRecords(ctx context.Context, leg *models.Leg, matchType int) error {
+	metrics, ok := recordMetrics[matchType]
+	if !ok || len(leg.Players) == 0 {
+		return nil
+	}
+	current, err := getPlayersRecords(leg.Players, matchType)
+	if err != nil {
+		return err
+	}
+	records := make([]*models.PlayerRecord, 0)
+	for _, metric := range metrics {
+		candidates, err := getRecordCandidates(matchType, metric, "s.leg_id = ?", leg.ID)
+		if err != nil {
+			return err
+		}
+		if current[metric.name] == nil {
+			current[metric.name] = make(map[int]*models.PlayerRecord)
+		}
+		records = append(records, models.FindNewRecords(current[metric.name], candidates)...)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return err
+	}
+	err = addPlayerRecords(tx, records)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		logging.Logger(ctx).Printf("[%d] Player %d set a new %s record of %.2f", leg.ID, record.PlayerID, record.Metric, record.Value)
+	}
+	return nil
+}
+
+// RecalculatePlayerRecords will replace the records of all players by replaying every finished leg in the order they were played
+func RecalculatePlayerRecords() error {
+	return recalculatePlayerRecords(nil)
+}
+
+// recalculateLegRecords will replace the records of all players in the given leg, and of the given players, after the
+// leg was modified or deleted
+func recalculateLegRecords(ctx context.Context, legID int, playerIDs []int) error {
+	rows, err := models.DB.Query("SELECT player_id FROM player2leg WHERE leg_id = ?", legID)
+	if err != nil {
+		return err
+	}
+	players, err := scanIDs(rows)
+	if err != nil {
+		return err
+	}
+	players = append(players, playerIDs...)
+	if len(players) == 0 {
+		return nil
+	}
+	err = recalculatePlayerRecords(players)
+	if err != nil {
+		return err
+	}
+	invalidatePlayers(players)
+	cache.Invalidate(cache.TagStatistics)
+	return nil
+}
+
+// recalculatePlayerRecords will replace the records of the given players, or of all players if none are given, by
+// replaying every finished leg in the order they were played
+func recalculatePlayerRecords(playerIDs []int) error {
+	deleteQuery := "DELETE FROM player_record"
+	condition := "l.is_finished = 1"
+	var args []interface{}
+	if len(playerIDs) > 0 {
+		var err error
+		deleteQuery, args, err = sqlx.In(deleteQuery+" WHERE player_id IN (?)", playerIDs)
+		if err != nil {
+			return err
+		}
+		// Both queries take the player ids as their only arguments
+		condition, _, err = sqlx.In(condition+" AND s.player_id IN (?)", playerIDs)
+		if err != nil {
+			return err
+		}
+	}
+
+	tx, err := models.DB.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(deleteQuery, args...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for matchType, metrics := range recordMetrics {
+		for _, metric := range metrics {
+			candidates, err := getRecordCandidates(matchType, metric, condition, args...)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			records := models.FindNewRecords(make(map[int]*models.PlayerRecord), candidates)
+			err = addPlayerRecords(tx, records)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			if len(records) > 0 || len(playerIDs) == 0 {
+				log.Printf("Added %d %s records for match type %d", len(records), metric.name, matchType)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// GetPlayerRecords will return the current personal records of the given player, and the timeline of all records set
+func GetPlayerRecords(playerID int) (*models.PlayerRecords, error) {
+	timeline, err := getPlayerRecords("player_id = ?", playerID)
+	if err != nil {
+		return nil, err
+	}
+	records := &models.PlayerRecords{PlayerID: playerID, Records: models.BestRecords(timeline), Timeline: timeline}
+	// Newest first
+	for i, j := 0, len(timeline)-1; i < j; i, j = i+1, j-1 {
+		timeline[i], timeline[j] = timeline[j], timeline[i]
+	}
+	return records, nil
+}
+
+// GetOfficeRecords will return the all-time best record of each match type and metric among players in the given office
+func GetOfficeRecords(officeID int) ([]*models.PlayerRecord, error) {
+	records, err := getPlayerRecords(`player_id IN (SELECT id FROM player WHERE office_id = ? AND is_bot = 0 AND is_placeholder = 0)`, officeID)
+	if err != nil {
+		return nil, err
+	}
+	return models.BestRecords(records), nil
+}
+
+// getPlayersRecords will return the current records of the given players for the given match type, by metric and player
+func getPlayersRecords(playerIDs []int, matchType int) (map[string]map[int]*models.PlayerRecord, error) {
+	q, args, err := sqlx.In("player_id IN (?) AND match_type_id = ?", playerIDs, matchType)
+	if err != nil {
+		return nil, err
+	}
+	records, err := getPlayerRecords(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	byPlayer := make(map[int][]*models.PlayerRecord)
+	for _, record := range records {
+		byPlayer[record.PlayerID] = append(byPlayer[record.PlayerID], record)
+	}
+	current := make(map[string]map[int]*models.PlayerRecord)
+	for playerID, records := range byPlayer {
+		for _, record := range models.BestRecords(records) {
+			if current[record.Metric] == nil {
+				current[record.Metric] = make(map[int]*models.PlayerRecord)
+			}
+			current[record.Metric][playerID] = record
+		}
+	}
+	return current, nil
+}
+
+// getPlayerRecords will return all records matching the given condition, in the order they were set
+func getPlayerRecords(condition string, args ...interface{}) ([]*models.PlayerRecord, error) {
+	rows, err := models.DB.Query(`
+		SELECT id, player_id, match_type_id, metric, value, previous_value, match_id, leg_id, created_at
+		FROM player_record
+		WHERE `+condition+`
+		ORDER BY created_at, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]*models.PlayerRecord, 0)
+	for rows.Next() {
+		r := new(models.PlayerRecord)
+		err := rows.Scan(&r.ID, &r.PlayerID, &r.MatchType, &r.Metric, &r.Value, &r.PreviousValue, &r.MatchID, &r.LegID, &r.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		for _, metric := range recordMetrics[r.MatchType] {
+			if metric.name == r.Metric {
+				r.LowerIsBetter = metric.lowerIsBetter
+			}
+		}
+		records = append(records, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// getRecordCandidates will return the value of the given metric for each player in legs matching the given condition,
+// in the order the legs were finished
+func getRecordCandidates(matchType int, metric recordMetric, condition string, args ...interface{}) ([]*models.PlayerRecord, error) {
+	rows, err := models.DB.Query(fmt.Sprintf(`
+		SELECT s.player_id, l.match_id, s.leg_id, %s AS value, IFNULL(l.end_time, l.created_at) AS set_at
+		FROM %s s
+			JOIN leg l ON l.id = s.leg_id
+			JOIN matches m ON m.id = l.match_id
+			JOIN player p ON p.id = s.player_id
+		WHERE %s AND IFNULL(l.leg_type_id, m.match_type_id) = ?
+			AND p.is_bot = 0 AND p.is_placeholder = 0
+		HAVING value IS NOT NULL
+		ORDER BY set_at, l.id, s.player_id`, metric.value, metric.table, condition), append(args, matchType)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := make([]*models.PlayerRecord, 0)
+	for rows.Next() {
+		r := &models.PlayerRecord{MatchType: matchType, Metric: metric.name, LowerIsBetter: metric.lowerIsBetter}
+		if err := rows.Scan(&r.PlayerID, &r.MatchID, &r.LegID, &r.Value, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// addPlayerRecords will store the given records
+func addPlayerRecords(tx *sql.Tx, records []*models.PlayerRecord) error {
+	for _, r := range records {
+		_, err := tx.Exec(`
+			INSERT INTO player_record (player_id, match_type_id, metric, value, previous_value, match_id, leg_id, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, r.PlayerID, r.MatchType, r.Metric, r.Value, r.PreviousValue, r.MatchID, r.LegID, r.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package data
+
+import (
+	"github.com/guregu/null"
+	"github.com/kcapp/api/models"
+)
+
+// x01TrendMetrics are the x01 metrics included in a player trend, in the order they are selected
+var x01TrendMetrics = []string{
+	models.TrendThreeDartAvg,
+	models.TrendFirstNineAvg,
+	models.TrendCheckoutPercentage,
+	models.Trend100sPlus,
+	models.Trend140sPlus,
+	models.Trend180s,
+}
+
+// x01TrendColumns selects the numerator and denominator of each x01 trend metric from statistics_x01 s
+const x01TrendColumns = `
+	IFNULL(s.ppd_score, 0) * 3, IFNULL(s.darts_thrown, 0),
+	IFNULL(s.first_nine_ppd, 0) * 3, 1,
+	(s.checkout_percentage IS NOT NULL) * 100, IFNULL(s.checkout_attempts, 0),
+	IFNULL(s.100s_plus, 0), 1,
+	IFNULL(s.140s_plus, 0), 1,
+	IFNULL(s.180s, 0), 1`
+
+// GetPlayerTrend will return rolling windows, form and office percentiles of the given player
+func GetPlayerTrend(playerID int) (*models.PlayerTrend, error) {
+	trend := &models.PlayerTrend{PlayerID: playerID, Metrics: make([]*models.TrendMetric, 0)}
+	err := models.DB.QueryRow("SELECT office_id FROM player WHERE id = ?", playerID).Scan(&trend.OfficeID)
+	if err != nil {
+		return nil, err
+	}
+	maxLegs := models.TrendWindows[len(models.TrendWindows)-1]
+
+	// Enough legs are needed to compare the largest window against the one before it
+	x01Samples, err := getX01TrendSamples(playerID, maxLegs*2)
+	if err != nil {
+		return nil, err
+	}
+	cricketSamples, err := getCricketTrendSamples(playerID, maxLegs*2)
+	if err != nil {
+		return nil, err
+	}
+	trend.X01Legs = len(x01Samples)
+	trend.CricketLegs = len(cricketSamples)
+
+	for i, name := range x01TrendMetrics {
+		samples := make([]models.TrendSample, len(x01Samples))
+		for j, leg := range x01Samples {
+			samples[j] = leg[i]
+		}
+		trend.Metrics = append(trend.Metrics, models.NewTrendMetric(name, samples))
+	}
+	mpr := make([]models.TrendSample, len(cricketSamples))
+	for i, leg := range cricketSamples {
+		mpr[i] = leg[0]
+	}
+	trend.Metrics = append(trend.Metrics, models.NewTrendMetric(models.TrendMPR, mpr))
+
+	if trend.OfficeID.Valid {
+		office, err := getOfficeTrendValues(trend.OfficeID.Int64)
+		if err != nil {
+			return nil, err
+		}
+		for _, metric := range trend.Metrics {
+			for _, window := range metric.Windows {
+				if window.Legs == models.TrendPercentileWindow && window.Value.Valid {
+					metric.OfficePercentile = models.Percentile(window.Value.Float64, office[metric.Name])
+				}
+			}
+		}
+	}
+	return trend, nil
+}
+
+// getX01TrendSamples will return the samples of each x01 trend metric for the last legs of the given player, newest first
+func getX01TrendSamples(playerID int, limit int) ([][]models.TrendSample, error) {
+	rows, err := models.DB.Query(`
+		SELECT `+x01TrendColumns+`
+		FROM statistics_x01 s
+			JOIN leg l ON l.id = s.leg_id
+			JOIN matches m ON m.id = l.match_id
+		WHERE s.player_id = ?
+			AND IFNULL(l.leg_type_id, m.match_type_id) = 1
+			AND l.is_finished = 1 AND m.is_abandoned = 0
+		ORDER BY l.id DESC
+		LIMIT ?`, playerID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	legs := make([][]models.TrendSample, 0)
+	for rows.Next() {
+		leg := make([]models.TrendSample, len(x01TrendMetrics))
+		dest := make([]interface{}, 0, len(leg)*2)
+		for i := range leg {
+			dest = append(dest, &leg[i].Numerator, &leg[i].Denominator)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		legs = append(legs, leg)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return legs, nil
+}
+
+// getCricketTrendSamples will return the samples of each cricket trend metric for the last legs of the given player, newest first
+func getCricketTrendSamples(playerID int, limit int) ([][]models.TrendSample, error) {
+	rows, err := models.DB.Query(`
+		SELECT IFNULL(s.total_marks, 0), IFNULL(s.rounds, 0)
+		FROM statistics_cricket s
+			JOIN leg l ON l.id = s.leg_id
+			JOIN matches m ON m.id = l.match_id
+		WHERE s.player_id = ?
+			AND IFNULL(l.leg_type_id, m.match_type_id) = 4
+			AND l.is_finished = 1 AND m.is_abandoned = 0
+		ORDER BY l.id DESC
+		LIMIT ?`, playerID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	legs := make([][]models.TrendSample, 0)
+	for rows.Next() {
+		sample := models.TrendSample{}
+		if err := rows.Scan(&sample.Numerator, &sample.Denominator); err != nil {
+			return nil, err
+		}
+		legs = append(legs, []models.TrendSample{sample})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return legs, nil
+}
+
+// getOfficeTrendValues will return the value of each trend metric over the last legs of every active player in the given office
+func getOfficeTrendValues(officeID int64) (map[string][]float64, error) {
+	values := make(map[string][]float64)
+	rows, err := models.DB.Query(`
+		SELECT
+			SUM(s.ppd_score) * 3 / SUM(s.darts_thrown),
+			SUM(s.first_nine_ppd) * 3 / COUNT(s.player_id),
+			COUNT(s.checkout_percentage) / SUM(s.checkout_attempts) * 100,
+			SUM(s.100s_plus) / COUNT(s.player_id),
+			SUM(s.140s_plus) / COUNT(s.player_id),
+			SUM(s.180s) / COUNT(s.player_id)
+		FROM (
+			SELECT s.*, ROW_NUMBER() OVER (PARTITION BY s.player_id ORDER BY l.id DESC) AS 'row_num'
+			FROM statistics_x01 s
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+				JOIN player p ON p.id = s.player_id
+			WHERE p.office_id = ? AND p.active = 1 AND p.is_bot = 0 AND p.is_placeholder = 0
+				AND IFNULL(l.leg_type_id, m.match_type_id) = 1
+				AND l.is_finished = 1 AND m.is_abandoned = 0
+		) s
+		WHERE s.row_num <= ?
+		GROUP BY s.player_id`, officeID, models.TrendPercentileWindow)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		metrics := make([]null.Float, len(x01TrendMetrics))
+		dest := make([]interface{}, len(metrics))
+		for i := range metrics {
+			dest[i] = &metrics[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		for i, name := range x01TrendMetrics {
+			if metrics[i].Valid {
+				values[name] = append(values[name], metrics[i].Float64)
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = models.DB.Query(`
+		SELECT SUM(s.total_marks) / SUM(s.rounds)
+		FROM (
+			SELECT s.*, ROW_NUMBER() OVER (PARTITION BY s.player_id ORDER BY l.id DESC) AS 'row_num'
+			FROM statistics_cricket s
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+				JOIN player p ON p.id = s.player_id
+			WHERE p.office_id = ? AND p.active = 1 AND p.is_bot = 0 AND p.is_placeholder = 0
+				AND IFNULL(l.leg_type_id, m.match_type_id) = 4
+				AND l.is_finished = 1 AND m.is_abandoned = 0
+		) s
+		WHERE s.row_num <= ?
+		GROUP BY s.player_id`, officeID, models.TrendPercentileWindow)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var mpr null.Float
+		if err := rows.Scan(&mpr); err != nil {
+			return nil, err
+		}
+		if mpr.Valid {
+			values[models.TrendMPR] = append(values[models.TrendMPR], mpr.Float64)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -33,6 +34,39 @@ func RecalculateStatistics(matchType int, legID int, since string, dryRun bool)
 		legs = append(legs, ids...)
 	}
 
+	queries, err := getRecalculateStatisticsQueries(matchType, legs)
+	if err != nil {
+		return err
+	}
+
+	if len(queries) == 0 {
+		log.Print("No legs to recalculate")
+	} else {
+		if dryRun {
+			for _, query := range queries {
+				log.Print(query)
+			}
+		} else {
+			log.Printf("Executing %d UPDATE queries", len(queries))
+			tx, err := models.DB.Begin()
+			if err != nil {
+				return err
+			}
+			for _, query := range queries {
+				_, err = tx.Exec(query)
+				if err != nil {
+					tx.Rollback()
+					return err
+				}
+			}
+			tx.Commit()
+		}
+	}
+	return nil
+}
+
+// getRecalculateStatisticsQueries will return the queries needed to update statistics for the given legs
+func getRecalculateStatisticsQueries(matchType int, legs []int) ([]string, error) {
 	var queries []string
 	var err error
 	switch matchType {
@@ -69,37 +103,18 @@ func RecalculateStatistics(matchType int, legID int, since string, dryRun bool)
 		queries, err = ReCalculateScamStatistics(legs)
 	case models.ONESEVENTY:
 		queries, err = ReCalculate170Statistics(legs)
+	case models.BOBS27:
+		queries, err = RecalculateBobs27Statistics(legs)
+	case models.ONETWENTYONE:
+		queries, err = Recalculate121Statistics(legs)
+	case models.GOLF:
+		queries, err = RecalculateGolfStatistics(legs)
+	case models.BASEBALL:
+		queries, err = RecalculateBaseballStatistics(legs)
 	default:
-		return fmt.Errorf("cannot recalculate statistics for type %d", matchType)
+		return nil, fmt.Errorf("cannot recalculate statistics for type %d", matchType)
 	}
-	if err != nil {
-		return err
-	}
-
-	if len(queries) == 0 {
-		log.Print("No legs to recalculate")
-	} else {
-		if dryRun {
-			for _, query := range queries {
-				log.Print(query)
-			}
-		} else {
-			log.Printf("Executing %d UPDATE queries", len(queries))
-			tx, err := models.DB.Begin()
-			if err != nil {
-				return err
-			}
-			for _, query := range queries {
-				_, err = tx.Exec(query)
-				if err != nil {
-					tx.Rollback()
-					return err
-				}
-			}
-			tx.Commit()
-		}
-	}
-	return nil
+	return queries, err
 }
 
 // RecalculateElo will recalculate Elo for all players
@@ -233,157 +248,177 @@ func CalculateEloForTournament(tournamentID int) error {
 	return nil
 }
 
-func RecalculateLegBadges() error {
+// RecalculateLegBadges will calculate the badges which should be unlocked in all legs, and add, revoke or update stored
+// badges to match. If dryRun is set, the differences are returned without changing anything
+func RecalculateLegBadges(dryRun bool) (*models.BadgeDiff, error) {
 	ids, err := GetBadgeLegsToRecalculate()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	expected := make(models.BadgeAwards)
 	for _, legID := range ids {
 		log.Printf("Checking Leg %d for badges", legID)
-		leg, err := GetLeg(legID)
-		if err != nil {
-			return err
-		}
-
-		statistics, err := GetPlayerBadgeStatistics(leg.Players, &legID)
+		awards, err := getLegBadgeAwardsForLeg(context.Background(), legID)
 		if err != nil {
-			return err
+			return nil, err
 		}
-
-		// Calculate badges
-		err = CheckLegForBadges(leg, statistics)
-		if err != nil {
-			return err
+		for _, award := range awards {
+			expected.Add(award)
 		}
 	}
 
-	return nil
+	badgeIDs, err := getLegBadgeIDs()
+	if err != nil {
+		return nil, err
+	}
+	return diffBadges(expected, badgeIDs, dryRun)
 }
 
-func RecalculateMatchBadges() error {
+// RecalculateMatchBadges will calculate the badges which should be unlocked in all matches, and add, revoke or update stored
+// badges to match. If dryRun is set, the differences are returned without changing anything
+func RecalculateMatchBadges(dryRun bool) (*models.BadgeDiff, error) {
 	ids, err := GetBadgeMatchesToRecalculate()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	expected := make(models.BadgeAwards)
 	for _, matchID := range ids {
 		log.Printf("Checking match %d for badges", matchID)
 		match, err := GetMatch(matchID)
 		if err != nil {
-			return err
+			return nil, err
 		}
-
-		// Calculate badges
-		err = CheckMatchForBadges(match)
+		awards, err := getMatchBadgeAwards(context.Background(), match)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		for _, award := range awards {
+			expected.Add(award)
 		}
 	}
 
-	return nil
+	badgeIDs, err := getMatchBadgeIDs()
+	if err != nil {
+		return nil, err
+	}
+	return diffBadges(expected, badgeIDs, dryRun)
 }
 
-func RecalculateGlobalBadges() error {
+// RecalculateGlobalBadges will calculate the global and tournament badges of all players, and add, revoke or update stored
+// badges to match. If dryRun is set, the differences are returned without changing anything
+func RecalculateGlobalBadges(dryRun bool) (*models.BadgeDiff, error) {
 	players, err := GetPlayers()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	matchTypes, err := GetPlayersMatchTypes()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	expected := make(models.BadgeAwards)
 	for _, player := range players {
 		if player.IsSupporter {
 			// Add supporter badge
-			err = AddGlobalBadge(player.ID, new(models.BadgeKcappSupporter))
-			if err != nil {
-				return err
-			}
+			expected.Add(newGlobalBadgeAward(player.ID, new(models.BadgeKcappSupporter)))
 		}
 		if player.VocalName.Valid && strings.HasSuffix(player.VocalName.String, ".wav") {
 			// Add vocal name badge
-			err = AddGlobalBadge(player.ID, new(models.BadgeSayMyName))
-			if err != nil {
-				return err
-			}
+			expected.Add(newGlobalBadgeAward(player.ID, new(models.BadgeSayMyName)))
 		}
 
 		// Bye for Now
 		if !player.IsActive {
-			err = AddGlobalBadge(player.ID, new(models.BadgeByeForNow))
-			if err != nil {
-				return err
-			}
+			expected.Add(newGlobalBadgeAward(player.ID, new(models.BadgeByeForNow)))
 		}
 
 		// Old Timer
 		threeYearsAgo := time.Now().AddDate(-3, 0, 0)
 		if player.CreatedAt.Before(threeYearsAgo) {
-			err = AddGlobalBadgeWithTime(player.ID, new(models.BadgeOldTimer), player.CreatedAt.AddDate(3, 0, 0))
-			if err != nil {
-				return err
-			}
+			award := newGlobalBadgeAward(player.ID, new(models.BadgeOldTimer))
+			award.CreatedAt = null.TimeFrom(player.CreatedAt.AddDate(3, 0, 0))
+			expected.Add(award)
 		}
 
 		// Versatile Player
 		types := matchTypes[player.ID]
 		if types >= 5 {
 			b := new(models.BadgeVersatilePlayer)
-			err = AddGlobalLevelBadge(player.ID, models.GetLevel(types, b.Levels()), b)
-			if err != nil {
-				return err
-			}
+			level := models.GetLevel(types, b.Levels())
+			award := newGlobalBadgeAward(player.ID, b)
+			award.Level = null.IntFrom(int64(level))
+			award.Value = null.IntFrom(int64(b.Levels()[level-1]))
+			expected.Add(award)
 		}
 
 		standings, err := GetPlayerTournamentStandings(player.ID)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if len(standings) > 0 {
 			standing := standings[len(standings)-1]
-			err = AddTournamentBadge(player.ID, standing.Tournament.ID, new(models.BadgeItsOfficial), standing.Tournament.EndTime.Time)
-			if err != nil {
-				return err
-			}
+			expected.Add(newTournamentBadgeAward(player.ID, standing.Tournament, new(models.BadgeItsOfficial)))
 		}
 
 		standing := getPlayerTournamentStanding(1, standings)
 		if standing != nil {
-			err = AddTournamentBadge(player.ID, standing.Tournament.ID, new(models.BadgeTournament1st), standing.Tournament.EndTime.Time)
-			if err != nil {
-				return err
-			}
+			expected.Add(newTournamentBadgeAward(player.ID, standing.Tournament, new(models.BadgeTournament1st)))
 		}
 		standing = getPlayerTournamentStanding(2, standings)
 		if standing != nil {
-			err = AddTournamentBadge(player.ID, standing.Tournament.ID, new(models.BadgeTournament2nd), standing.Tournament.EndTime.Time)
-			if err != nil {
-				return err
-			}
+			expected.Add(newTournamentBadgeAward(player.ID, standing.Tournament, new(models.BadgeTournament2nd)))
 		}
 		standing = getPlayerTournamentStanding(3, standings)
 		if standing != nil {
-			err = AddTournamentBadge(player.ID, standing.Tournament.ID, new(models.BadgeTournament3rd), standing.Tournament.EndTime.Time)
-			if err != nil {
-				return err
-			}
+			expected.Add(newTournamentBadgeAward(player.ID, standing.Tournament, new(models.BadgeTournament3rd)))
 		}
 	}
 
 	undefeated, err := GetUndefeatedPlayers()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	for playerID, tournament := range undefeated {
-		err := AddTournamentBadge(playerID, tournament.ID, new(models.BadgeUntouchable), tournament.EndTime.Time)
+		expected.Add(newTournamentBadgeAward(playerID, tournament, new(models.BadgeUntouchable)))
+	}
+
+	badgeIDs := make([]int, 0, len(globalBadges))
+	for _, badge := range globalBadges {
+		badgeIDs = append(badgeIDs, badge.GetID())
+	}
+	return diffBadges(expected, badgeIDs, dryRun)
+}
+
+// diffBadges will compare the expected badges with the stored badges with the given ids, and apply the difference unless dryRun is set
+func diffBadges(expected models.BadgeAwards, badgeIDs []int, dryRun bool) (*models.BadgeDiff, error) {
+	stored, err := getStoredBadgeAwards(badgeIDs, nil)
+	if err != nil {
+		return nil, err
+	}
+	diff := models.DiffBadgeAwards(expected, stored)
+	log.Printf("Found %d badges to add, %d to revoke and %d to update", len(diff.Added), len(diff.Removed), len(diff.Changed))
+	if !dryRun {
+		err = applyBadgeDiff(context.Background(), diff)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
+	return diff, nil
+}
 
-	return nil
+func newGlobalBadgeAward(playerID int, badge models.GlobalBadge) *models.BadgeAward {
+	return &models.BadgeAward{PlayerID: playerID, BadgeID: badge.GetID()}
+}
+
+func newTournamentBadgeAward(playerID int, tournament *models.Tournament, badge models.GlobalBadge) *models.BadgeAward {
+	return &models.BadgeAward{
+		PlayerID:     playerID,
+		BadgeID:      badge.GetID(),
+		TournamentID: null.IntFrom(int64(tournament.ID)),
+		CreatedAt:    tournament.EndTime,
+	}
 }
 
 func getPlayerTournamentStanding(pos int, standings []*models.PlayerTournamentStanding) *models.PlayerTournamentStanding {
@@ -0,0 +1,238 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/kcapp/api/models"
+)
+
+// mysqlDuplicateEntry is the error number returned by MySQL when a unique key is violated
+const mysqlDuplicateEntry = 1062
+
+// GenerateReport will generate and store a report for the given office, covering the given period. If the report has
+// already been generated, models.ErrReportExists is returned
+func GenerateReport(officeID int, period string, from time.Time, to time.Time) (*models.Report, error) {
+	office, err := GetOffice(officeID)
+	if err != nil {
+		return nil, err
+	}
+	content := &models.ReportContent{Office: office.Name}
+
+	current, err := getReportAverages(officeID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	previousFrom, previousTo, err := models.ReportPeriod(period, from)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := getReportAverages(officeID, previousFrom, previousTo)
+	if err != nil {
+		return nil, err
+	}
+	content.TopAverages, content.MostImproved = models.RankReportPlayers(current, previous)
+
+	content.NewBadges, err = getReportBadges(officeID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	content.Tournaments, err = getReportTournaments(officeID, from)
+	if err != nil {
+		return nil, err
+	}
+	content.Owes, err = getReportOwes(officeID)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	res, err := models.DB.Exec(`
+		INSERT INTO report (office_id, period, from_date, to_date, content, created_at) VALUES (?, ?, ?, ?, ?, NOW())`,
+		officeID, period, from, to, string(b))
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+		return nil, models.ErrReportExists
+	} else if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Generated %s report %d for office %d", period, id, officeID)
+	return GetReport(int(id))
+}
+
+// GetReport will return the report with the given id
+func GetReport(id int) (*models.Report, error) {
+	report := new(models.Report)
+	var content string
+	err := models.DB.QueryRow(`
+		SELECT id, office_id, period, from_date, to_date, content, created_at
+		FROM report WHERE id = ?`, id).Scan(&report.ID, &report.OfficeID, &report.Period, &report.From, &report.To,
+		&content, &report.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	report.Content = new(models.ReportContent)
+	if err := json.Unmarshal([]byte(content), report.Content); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetReportForPeriod will return the id of the report for the given office and period, or sql.ErrNoRows if not generated
+func GetReportForPeriod(officeID int, period string, from time.Time) (int, error) {
+	var id int
+	err := models.DB.QueryRow("SELECT id FROM report WHERE office_id = ? AND period = ? AND from_date = ?",
+		officeID, period, from).Scan(&id)
+	return id, err
+}
+
+// getReportAverages will return the X01 average of every player in the office with enough legs in the given period
+func getReportAverages(officeID int, from time.Time, to time.Time) ([]*models.ReportPlayer, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			p.id, CONCAT(p.first_name, IFNULL(CONCAT(' ', p.last_name), '')),
+			COUNT(DISTINCT s.leg_id),
+			SUM(s.ppd_score) * 3 / SUM(s.darts_thrown)
+		FROM statistics_x01 s
+			JOIN player p ON p.id = s.player_id
+			JOIN leg l ON l.id = s.leg_id
+			JOIN matches m ON m.id = l.match_id
+		WHERE m.office_id = ? AND IFNULL(l.leg_type_id, m.match_type_id) = ?
+			AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+			AND l.end_time >= ? AND l.end_time < ?
+			AND p.is_bot = 0 AND p.is_placeholder = 0
+		GROUP BY p.id
+		HAVING COUNT(DISTINCT s.leg_id) >= ?`, officeID, models.X01, from, to, models.ReportMinimumLegs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := make([]*models.ReportPlayer, 0)
+	for rows.Next() {
+		p := new(models.ReportPlayer)
+		if err := rows.Scan(&p.PlayerID, &p.Name, &p.Legs, &p.ThreeDartAvg); err != nil {
+			return nil, err
+		}
+		players = append(players, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return players, nil
+}
+
+// getReportBadges will return all badges unlocked by players in the office in the given period
+func getReportBadges(officeID int, from time.Time, to time.Time) ([]*models.ReportBadge, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			p.id, CONCAT(p.first_name, IFNULL(CONCAT(' ', p.last_name), '')), b.id, b.name, p2b.level, p2b.created_at
+		FROM player2badge p2b
+			JOIN player p ON p.id = p2b.player_id
+			JOIN badge b ON b.id = p2b.badge_id
+		WHERE p.office_id = ? AND p2b.created_at >= ? AND p2b.created_at < ?
+			AND b.secret = 0
+		ORDER BY p2b.created_at`, officeID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	badges := make([]*models.ReportBadge, 0)
+	for rows.Next() {
+		b := new(models.ReportBadge)
+		if err := rows.Scan(&b.PlayerID, &b.Name, &b.BadgeID, &b.Badge, &b.Level, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		badges = append(badges, b)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return badges, nil
+}
+
+// getReportTournaments will return the progress of all tournaments in the office which are running, or finished in the period
+func getReportTournaments(officeID int, from time.Time) ([]*models.ReportTournament, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			t.id, t.name, t.is_finished,
+			COUNT(CASE WHEN m.is_finished = 1 THEN 1 END),
+			COUNT(m.id)
+		FROM tournament t
+			LEFT JOIN matches m ON m.tournament_id = t.id
+		WHERE t.office_id = ? AND (t.is_finished = 0 OR t.end_time >= ?)
+		GROUP BY t.id
+		ORDER BY t.start_time`, officeID, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tournaments := make([]*models.ReportTournament, 0)
+	for rows.Next() {
+		t := new(models.ReportTournament)
+		if err := rows.Scan(&t.TournamentID, &t.Name, &t.IsFinished, &t.MatchesPlayed, &t.MatchesTotal); err != nil {
+			return nil, err
+		}
+		if t.MatchesTotal > 0 {
+			t.PercentComplete = float64(t.MatchesPlayed*100) / float64(t.MatchesTotal)
+		}
+		tournaments = append(tournaments, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return tournaments, nil
+}
+
+// getReportOwes will return all outstanding owes of players in the office
+func getReportOwes(officeID int) ([]*models.ReportOwe, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			ower.id, CONCAT(ower.first_name, IFNULL(CONCAT(' ', ower.last_name), '')),
+			owee.id, CONCAT(owee.first_name, IFNULL(CONCAT(' ', owee.last_name), '')),
+			IFNULL(ot.item, ''), o.amount
+		FROM owes o
+			JOIN owe_type ot ON ot.id = o.owe_type_id
+			JOIN player ower ON ower.id = o.player_ower_id
+			JOIN player owee ON owee.id = o.player_owee_id
+		WHERE o.amount > 0 AND ower.office_id = ?
+		ORDER BY o.amount DESC`, officeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	owes := make([]*models.ReportOwe, 0)
+	for rows.Next() {
+		o := new(models.ReportOwe)
+		if err := rows.Scan(&o.PlayerOwerID, &o.Ower, &o.PlayerOweeID, &o.Owee, &o.Item, &o.Amount); err != nil {
+			return nil, err
+		}
+		owes = append(owes, o)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return owes, nil
+}
+
+// GetActiveOfficeIDs will return the ids of all active offices
+func GetActiveOfficeIDs() ([]int, error) {
+	rows, err := models.DB.Query("SELECT id FROM office WHERE is_active = 1 ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	return scanIDs(rows)
+}
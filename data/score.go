@@ -1,22 +1,21 @@
 package data
 
 import (
+	"context"
 	"errors"
-	"log"
 	"math"
 	"sort"
-	"sync"
 
 	"github.com/guregu/null"
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/logging"
+	"github.com/kcapp/api/metrics"
 	"github.com/kcapp/api/models"
 )
 
-var addVisitLock sync.Mutex
-
 // AddVisit will write the given visit to database
-func AddVisit(visit models.Visit) (*models.Visit, error) {
-	addVisitLock.Lock()
-	defer addVisitLock.Unlock()
+func AddVisit(ctx context.Context, visit models.Visit) (*models.Visit, error) {
+	defer lockLeg(visit.LegID)()
 
 	leg, err := GetLeg(visit.LegID)
 	if err != nil {
@@ -215,6 +214,74 @@ func AddVisit(visit models.Visit) (*models.Visit, error) {
 			// We hit max number of rounds, so we are finished
 			isFinished = true
 		}
+	} else if matchType == models.BOBS27 {
+		// Players might be knocked out, so count the rounds played by each player
+		rounds := make(map[int]int)
+		for _, v := range leg.Visits {
+			rounds[v.PlayerID]++
+		}
+		player := players[visit.PlayerID]
+		player.CurrentScore += visit.CalculateBobs27Score(rounds[visit.PlayerID])
+		rounds[visit.PlayerID]++
+
+		// Leg is finished when all players have either dropped to zero or thrown at every double
+		isFinished = true
+		for _, player := range players {
+			if player.CurrentScore > 0 && rounds[player.PlayerID] < len(models.TargetsBobs27) {
+				isFinished = false
+				break
+			}
+		}
+	} else if matchType == models.ONETWENTYONE {
+		player := players[visit.PlayerID]
+		visit.SetIsBust(player.CurrentScore, models.OUTSHOTDOUBLE)
+		visit.Calculate121Score(player, leg.Parameters)
+
+		for _, player := range players {
+			if leg.Parameters.PointsToWin.Valid && player.CurrentPoints.Int64 >= leg.Parameters.PointsToWin.Int64 {
+				// One player hit required number of checkouts
+				isFinished = true
+				break
+			}
+		}
+
+		round := (len(leg.Visits)+1)/len(leg.Players)/3 + 1
+		if leg.Parameters.MaxRounds.Valid && round > int(leg.Parameters.MaxRounds.Int64) {
+			// We hit max number of rounds, so we are finished
+			isFinished = true
+		}
+	} else if matchType == models.GOLF {
+		hole := len(leg.Visits)/len(leg.Players) + 1
+		if idx := visit.GetGolfHitDart(hole); idx >= 0 {
+			// Only the dart hitting the hole counts, so remove any darts thrown after it
+			if idx < 2 {
+				visit.ThirdDart.Value = null.IntFromPtr(nil)
+			}
+			if idx < 1 {
+				visit.SecondDart.Value = null.IntFromPtr(nil)
+			}
+		}
+		holes := leg.Parameters.GetMaxRounds(models.GolfHoles)
+		isFinished = (len(leg.Visits)+1)%(holes*len(leg.Players)) == 0
+	} else if matchType == models.BASEBALL {
+		inning := len(leg.Visits)/len(leg.Players) + 1
+		players[visit.PlayerID].CurrentScore += visit.CalculateBaseballScore(inning)
+
+		innings := leg.Parameters.GetMaxRounds(models.BaseballInnings)
+		if (len(leg.Visits)+1)%len(leg.Players) == 0 && inning >= innings {
+			// Play extra innings until a single player has the most runs
+			highScore := -1
+			isTied := false
+			for _, player := range players {
+				if player.CurrentScore == highScore {
+					isTied = true
+				} else if player.CurrentScore > highScore {
+					highScore = player.CurrentScore
+					isTied = false
+				}
+			}
+			isFinished = !isTied
+		}
 	}
 
 	// Determine who will be the next player
@@ -268,8 +335,9 @@ func AddVisit(visit models.Visit) (*models.Visit, error) {
 		return nil, err
 	}
 	tx.Commit()
+	metrics.VisitsInserted.WithLabelValues(models.MatchTypes[matchType]).Inc()
 
-	log.Printf("[%d] Added score for player %d, (%d-%d, %d-%d, %d-%d, %t)", visit.LegID, visit.PlayerID, visit.FirstDart.Value.Int64,
+	logging.Logger(ctx).Printf("[%d] Added score for player %d, (%d-%d, %d-%d, %d-%d, %t)", visit.LegID, visit.PlayerID, visit.FirstDart.Value.Int64,
 		visit.FirstDart.Multiplier, visit.SecondDart.Value.Int64, visit.SecondDart.Multiplier, visit.ThirdDart.Value.Int64, visit.ThirdDart.Multiplier,
 		visit.IsBust)
 
@@ -278,17 +346,19 @@ func AddVisit(visit models.Visit) (*models.Visit, error) {
 		if err != nil {
 			return nil, err
 		}
-		err = FinishLeg(visit.LegID, visit.PlayerID, *winnerID)
+		err = finishLeg(ctx, visit.LegID, visit.PlayerID, *winnerID)
 		if err != nil {
 			return nil, err
 		}
+	} else {
+		invalidatePlayers(leg.Players)
 	}
 
 	return &visit, nil
 }
 
 // ModifyVisit modify the scores of a visit
-func ModifyVisit(visit models.Visit) error {
+func ModifyVisit(ctx context.Context, visit models.Visit) error {
 	// FIXME: We need to check if this is a checkout/bust
 	stmt, err := models.DB.Prepare(`
 		UPDATE score SET
@@ -310,14 +380,19 @@ func ModifyVisit(visit models.Visit) error {
 	if err != nil {
 		return err
 	}
-	log.Printf("[%d] Modified score %d, throws: (%d-%d, %d-%d, %d-%d)", visit.LegID, visit.ID, visit.FirstDart.Value.Int64,
+	logging.Logger(ctx).Printf("[%d] Modified score %d, throws: (%d-%d, %d-%d, %d-%d)", visit.LegID, visit.ID, visit.FirstDart.Value.Int64,
 		visit.FirstDart.Multiplier, visit.SecondDart.Value.Int64, visit.SecondDart.Multiplier, visit.ThirdDart.Value.Int64, visit.ThirdDart.Multiplier)
 
+	err = queueFinishedLegRecalculation(ctx, visit.LegID)
+	if err != nil {
+		return err
+	}
+	invalidateLeg(visit.LegID)
 	return nil
 }
 
 // DeleteVisit will delete the visit for the given ID
-func DeleteVisit(id int) error {
+func DeleteVisit(ctx context.Context, id int) error {
 	visit, err := GetVisit(id)
 	if err != nil {
 		return err
@@ -340,19 +415,24 @@ func DeleteVisit(id int) error {
 	}
 	tx.Commit()
 
-	log.Printf("[%d] Deleted visit %d", visit.LegID, visit.ID)
+	logging.Logger(ctx).Printf("[%d] Deleted visit %d", visit.LegID, visit.ID)
+	err = queueFinishedLegRecalculation(ctx, visit.LegID)
+	if err != nil {
+		return err
+	}
+	invalidateLeg(visit.LegID)
 	return nil
 }
 
 // DeleteLastVisit will delete the last visit for the given leg
-func DeleteLastVisit(legID int) error {
+func DeleteLastVisit(ctx context.Context, legID int) error {
 	visits, err := GetLegVisits(legID)
 	if err != nil {
 		return err
 	}
 
 	if len(visits) > 0 {
-		err := DeleteVisit(visits[len(visits)-1].ID)
+		err := DeleteVisit(ctx, visits[len(visits)-1].ID)
 		if err != nil {
 			return err
 		}
@@ -442,6 +522,51 @@ func GetLegVisits(id int) ([]*models.Visit, error) {
 	return visits, nil
 }
 
+// getLegsVisits will return all visits of the given legs, by leg ID
+func getLegsVisits(legIDs []int) (map[int][]*models.Visit, error) {
+	q, args, err := sqlx.In(`
+		SELECT
+			id, leg_id, player_id,
+			first_dart, first_dart_multiplier,
+			second_dart, second_dart_multiplier,
+			third_dart, third_dart_multiplier,
+			is_bust,
+			created_at,
+			updated_at
+		FROM score s
+		WHERE leg_id IN (?)
+		ORDER BY leg_id, id`, legIDs)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	visits := make(map[int][]*models.Visit)
+	for rows.Next() {
+		v := new(models.Visit)
+		v.FirstDart = new(models.Dart)
+		v.SecondDart = new(models.Dart)
+		v.ThirdDart = new(models.Dart)
+		err := rows.Scan(&v.ID, &v.LegID, &v.PlayerID,
+			&v.FirstDart.Value, &v.FirstDart.Multiplier,
+			&v.SecondDart.Value, &v.SecondDart.Multiplier,
+			&v.ThirdDart.Value, &v.ThirdDart.Multiplier,
+			&v.IsBust, &v.CreatedAt, &v.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		visits[v.LegID] = append(visits[v.LegID], v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return visits, nil
+}
+
 // GetVisit will return the visit with the given ID
 func GetVisit(id int) (*models.Visit, error) {
 	v := new(models.Visit)
@@ -0,0 +1,336 @@
+package data
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/guregu/null"
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/models"
+)
+
+// Get121Statistics will return statistics for all players active during the given period
+func Get121Statistics(from string, to string) ([]*models.Statistics121, error) {
+	rows, err := models.DB.Query(`
+			SELECT
+				p.id,
+				COUNT(DISTINCT m.id) AS 'matches_played',
+				COUNT(DISTINCT m2.id) AS 'matches_won',
+				COUNT(DISTINCT l.id) AS 'legs_played',
+				COUNT(DISTINCT l2.id) AS 'legs_won',
+				m.office_id AS 'office_id',
+				SUM(s.darts_thrown),
+				SUM(s.attempts),
+				SUM(s.checkouts),
+				SUM(s.checkouts) / SUM(s.attempts) * 100,
+				MAX(s.highest_checkout),
+				MAX(s.highest_target),
+				SUM(s.checkout_darts) / SUM(s.checkouts)
+			FROM statistics_121 s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+				LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
+				LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
+			WHERE m.updated_at >= ? AND m.updated_at < ?
+				AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+				AND m.match_type_id = 19
+			GROUP BY p.id, m.office_id
+			ORDER BY(COUNT(DISTINCT m2.id) / COUNT(DISTINCT m.id)) DESC, matches_played DESC`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]*models.Statistics121, 0)
+	for rows.Next() {
+		s := new(models.Statistics121)
+		err := rows.Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.OfficeID, &s.DartsThrown,
+			&s.Attempts, &s.Checkouts, &s.CheckoutPercentage, &s.HighestCheckout, &s.HighestTarget, &s.AvgCheckoutDarts)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// Get121StatisticsForLeg will return statistics for all players in the given leg
+func Get121StatisticsForLeg(id int) ([]*models.Statistics121, error) {
+	rows, err := models.DB.Query(`
+			SELECT
+				l.id,
+				p.id,
+				s.darts_thrown,
+				s.attempts,
+				s.checkouts,
+				s.checkout_percentage,
+				s.highest_checkout,
+				s.highest_target,
+				s.checkout_darts / s.checkouts
+			FROM statistics_121 s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN player2leg p2l on l.id = p2l.leg_id AND p.id = p2l.player_id
+			WHERE l.id = ? GROUP BY p.id ORDER BY p2l.order`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]*models.Statistics121, 0)
+	for rows.Next() {
+		s := new(models.Statistics121)
+		err := rows.Scan(&s.LegID, &s.PlayerID, &s.DartsThrown, &s.Attempts, &s.Checkouts, &s.CheckoutPercentage,
+			&s.HighestCheckout, &s.HighestTarget, &s.AvgCheckoutDarts)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// Get121StatisticsForMatch will return statistics for all players in the given match
+func Get121StatisticsForMatch(id int) ([]*models.Statistics121, error) {
+	statistics, err := Get121StatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.Statistics121, 0), nil
+}
+
+// Get121StatisticsForMatches will return statistics for all players in each of the given matches
+func Get121StatisticsForMatches(ids []int) (map[int][]*models.Statistics121, error) {
+	q, args, err := sqlx.In(`
+			SELECT
+				m.id,
+				p.id,
+				SUM(s.darts_thrown),
+				SUM(s.attempts),
+				SUM(s.checkouts),
+				SUM(s.checkouts) / SUM(s.attempts) * 100,
+				MAX(s.highest_checkout),
+				MAX(s.highest_target),
+				SUM(s.checkout_darts) / SUM(s.checkouts)
+			FROM statistics_121 s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+				JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
+			WHERE m.id IN (?)
+			GROUP BY m.id, p.id
+			ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make(map[int][]*models.Statistics121)
+	for rows.Next() {
+		var matchID int
+		s := new(models.Statistics121)
+		err := rows.Scan(&matchID, &s.PlayerID, &s.DartsThrown, &s.Attempts, &s.Checkouts, &s.CheckoutPercentage, &s.HighestCheckout,
+			&s.HighestTarget, &s.AvgCheckoutDarts)
+		if err != nil {
+			return nil, err
+		}
+		stats[matchID] = append(stats[matchID], s)
+	}
+	return stats, nil
+}
+
+// Get121StatisticsForPlayer will return 121 statistics for the given player, including the personal best
+func Get121StatisticsForPlayer(id int) (*models.Statistics121, error) {
+	s := new(models.Statistics121)
+	err := models.DB.QueryRow(`
+			SELECT
+				p.id,
+				COUNT(DISTINCT m.id) AS 'matches_played',
+				COUNT(DISTINCT m2.id) AS 'matches_won',
+				COUNT(DISTINCT l.id) AS 'legs_played',
+				COUNT(DISTINCT l2.id) AS 'legs_won',
+				SUM(s.darts_thrown),
+				SUM(s.attempts),
+				SUM(s.checkouts),
+				SUM(s.checkouts) / SUM(s.attempts) * 100,
+				MAX(s.highest_checkout),
+				MAX(s.highest_target),
+				SUM(s.checkout_darts) / SUM(s.checkouts)
+			FROM statistics_121 s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+				LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
+				LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
+			WHERE s.player_id = ?
+				AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+				AND m.match_type_id = 19
+			GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.DartsThrown,
+		&s.Attempts, &s.Checkouts, &s.CheckoutPercentage, &s.HighestCheckout, &s.HighestTarget, &s.AvgCheckoutDarts)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return new(models.Statistics121), nil
+		}
+		return nil, err
+	}
+
+	err = models.DB.QueryRow(`
+			SELECT s.highest_checkout, s.leg_id
+			FROM statistics_121 s
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+			WHERE s.player_id = ? AND s.highest_checkout IS NOT NULL
+				AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+				AND m.match_type_id = 19
+			ORDER BY s.highest_checkout DESC, s.leg_id ASC
+			LIMIT 1`, id).Scan(&s.BestCheckout, &s.BestCheckoutLeg)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get121HistoryForPlayer will return history of 121 statistics for the given player
+func Get121HistoryForPlayer(id int, start int, limit int) ([]*models.Leg, error) {
+	legs, err := GetLegsOfType(models.ONETWENTYONE, id, start, limit, false)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[int]*models.Leg)
+	for _, leg := range legs {
+		m[leg.ID] = leg
+	}
+
+	rows, err := models.DB.Query(`
+			SELECT
+				l.id,
+				p.id,
+				s.darts_thrown,
+				s.attempts,
+				s.checkouts,
+				s.checkout_percentage,
+				s.highest_checkout,
+				s.highest_target,
+				s.checkout_darts / s.checkouts
+			FROM statistics_121 s
+				LEFT JOIN player p ON p.id = s.player_id
+				LEFT JOIN leg l ON l.id = s.leg_id
+				LEFT JOIN matches m ON m.id = l.match_id
+			WHERE s.player_id = ?
+				AND l.is_finished = 1 AND m.is_abandoned = 0
+				AND m.match_type_id = 19
+			ORDER BY l.id DESC
+			LIMIT ?`, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	legs = make([]*models.Leg, 0)
+	for rows.Next() {
+		s := new(models.Statistics121)
+		err := rows.Scan(&s.LegID, &s.PlayerID, &s.DartsThrown, &s.Attempts, &s.Checkouts, &s.CheckoutPercentage,
+			&s.HighestCheckout, &s.HighestTarget, &s.AvgCheckoutDarts)
+		if err != nil {
+			return nil, err
+		}
+		leg := m[s.LegID]
+		leg.Statistics = s
+		legs = append(legs, leg)
+	}
+	return legs, nil
+}
+
+// Calculate121Statistics will generate 121 statistics for the given leg
+func Calculate121Statistics(legID int) (map[int]*models.Statistics121, error) {
+	leg, err := GetLeg(legID)
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := GetPlayersScore(legID)
+	if err != nil {
+		return nil, err
+	}
+
+	statisticsMap := make(map[int]*models.Statistics121)
+	for _, player := range players {
+		stats := new(models.Statistics121)
+		stats.PlayerID = player.PlayerID
+		stats.HighestTarget = leg.StartingScore
+		statisticsMap[player.PlayerID] = stats
+
+		player.StartingScore = leg.StartingScore
+		player.CurrentScore = leg.StartingScore
+		player.DartsThrown = 0
+		player.CurrentPoints = null.IntFrom(0)
+	}
+
+	for _, visit := range leg.Visits {
+		stats := statisticsMap[visit.PlayerID]
+		player := players[visit.PlayerID]
+
+		target := player.StartingScore
+		checkouts := player.CurrentPoints.Int64
+		dartsThrown := player.DartsThrown + visit.GetDartsThrown()
+		stats.DartsThrown += visit.GetDartsThrown()
+
+		visit.Calculate121Score(player, leg.Parameters)
+		if player.CurrentPoints.Int64 > checkouts {
+			stats.Attempts++
+			stats.Checkouts++
+			stats.CheckoutDarts += dartsThrown
+			if int(stats.HighestCheckout.Int64) < target {
+				stats.HighestCheckout = null.IntFrom(int64(target))
+			}
+		} else if player.DartsThrown == 0 {
+			// 9 darts thrown without checking out
+			stats.Attempts++
+		}
+		if player.StartingScore > stats.HighestTarget {
+			stats.HighestTarget = player.StartingScore
+		}
+	}
+
+	for _, stats := range statisticsMap {
+		if stats.Attempts > 0 {
+			stats.CheckoutPercentage = null.FloatFrom(float64(stats.Checkouts) / float64(stats.Attempts) * 100.0)
+		}
+		if stats.Checkouts > 0 {
+			stats.AvgCheckoutDarts = null.FloatFrom(float64(stats.CheckoutDarts) / float64(stats.Checkouts))
+		}
+	}
+	return statisticsMap, nil
+}
+
+// Recalculate121Statistics will recaulcate statistics for 121 legs
+func Recalculate121Statistics(legs []int) ([]string, error) {
+	queries := make([]string, 0)
+	for _, legID := range legs {
+		stats, err := Calculate121Statistics(legID)
+		if err != nil {
+			return nil, err
+		}
+		for playerID, stat := range stats {
+			query := fmt.Sprintf(`UPDATE statistics_121 SET darts_thrown = %d, attempts = %d, checkouts = %d, highest_target = %d, checkout_darts = %d`,
+				stat.DartsThrown, stat.Attempts, stat.Checkouts, stat.HighestTarget, stat.CheckoutDarts)
+			if stat.CheckoutPercentage.Valid {
+				query += fmt.Sprintf(", checkout_percentage = %f", stat.CheckoutPercentage.Float64)
+			}
+			if stat.HighestCheckout.Valid {
+				query += fmt.Sprintf(", highest_checkout = %d", stat.HighestCheckout.Int64)
+			}
+			query += fmt.Sprintf(" WHERE leg_id = %d AND player_id = %d;", legID, playerID)
+			queries = append(queries, query)
+		}
+	}
+	return queries, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/guregu/null"
+	"github.com/jmoiron/sqlx"
 	"github.com/kcapp/api/models"
 )
 
@@ -132,8 +133,21 @@ func Get170StatisticsForLeg(id int) ([]*models.Statistics170, error) {
 
 // Get170StatisticsForMatch will return statistics for all players in the given match
 func Get170StatisticsForMatch(id int) ([]*models.Statistics170, error) {
-	rows, err := models.DB.Query(`
+	statistics, err := Get170StatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.Statistics170, 0), nil
+}
+
+// Get170StatisticsForMatches will return statistics for all players in each of the given matches
+func Get170StatisticsForMatches(ids []int) (map[int][]*models.Statistics170, error) {
+	q, args, err := sqlx.In(`
 			SELECT
+				m.id,
 				p.id,
 				SUM(s.points),
 				IF(s.darts_thrown = 0, 0, SUM(s.ppd_score) / SUM(s.darts_thrown)),
@@ -156,21 +170,26 @@ func Get170StatisticsForMatch(id int) ([]*models.Statistics170, error) {
 				JOIN leg l ON l.id = s.leg_id
 				JOIN matches m ON m.id = l.match_id
 				JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
-			WHERE m.id = ?
-			GROUP BY p.id
-			ORDER BY p2l.order`, id)
+			WHERE m.id IN (?)
+			GROUP BY m.id, p.id
+			ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make([]*models.Statistics170, 0)
+	stats := make(map[int][]*models.Statistics170)
 	for rows.Next() {
+		var matchID int
 		s := new(models.Statistics170)
 		var darts9, darts8, darts7, darts6, darts5, darts4, darts3 int
 
 		checkoutDarts := make(map[int]int, 0)
-		err := rows.Scan(&s.PlayerID, &s.Points, &s.PPD, &s.ThreeDartAvg, &s.Rounds, &s.CheckoutPercentage, &s.CheckoutCompleted,
+		err := rows.Scan(&matchID, &s.PlayerID, &s.Points, &s.PPD, &s.ThreeDartAvg, &s.Rounds, &s.CheckoutPercentage, &s.CheckoutCompleted,
 			&s.CheckoutAttempts, &s.HighestCheckout, &s.DartsThrown, &darts9, &darts8, &darts7, &darts6, &darts5, &darts4, &darts3)
 		if err != nil {
 			return nil, err
@@ -183,7 +202,7 @@ func Get170StatisticsForMatch(id int) ([]*models.Statistics170, error) {
 		checkoutDarts[4] = darts4
 		checkoutDarts[3] = darts3
 		s.CheckoutDarts = checkoutDarts
-		stats = append(stats, s)
+		stats[matchID] = append(stats[matchID], s)
 	}
 	return stats, nil
 }
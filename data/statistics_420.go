@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/kcapp/api/models"
 )
 
@@ -138,8 +139,21 @@ func Get420StatisticsForLeg(id int) ([]*models.Statistics420, error) {
 
 // Get420StatisticsForMatch will return statistics for all players in the given match
 func Get420StatisticsForMatch(id int) ([]*models.Statistics420, error) {
-	rows, err := models.DB.Query(`
+	statistics, err := Get420StatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.Statistics420, 0), nil
+}
+
+// Get420StatisticsForMatches will return statistics for all players in each of the given matches
+func Get420StatisticsForMatches(ids []int) (map[int][]*models.Statistics420, error) {
+	q, args, err := sqlx.In(`
 		SELECT
+			m.id,
 			p.id,
 			CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
 			SUM(s.total_hit_rate) / COUNT(l.id) as 'total_hit_rate',
@@ -169,19 +183,24 @@ func Get420StatisticsForMatch(id int) ([]*models.Statistics420, error) {
 			JOIN leg l ON l.id = s.leg_id
 			JOIN matches m ON m.id = l.match_id
 			JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
-		WHERE m.id = ?
-		GROUP BY p.id
-		ORDER BY p2l.order`, id)
+		WHERE m.id IN (?)
+		GROUP BY m.id, p.id
+		ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make([]*models.Statistics420, 0)
+	stats := make(map[int][]*models.Statistics420)
 	for rows.Next() {
+		var matchID int
 		s := new(models.Statistics420)
 		h := make([]*float64, 22)
-		err := rows.Scan(&s.PlayerID, &s.Score, &s.TotalHitRate, &h[1], &h[2], &h[3], &h[4], &h[5], &h[6], &h[7], &h[8], &h[9],
+		err := rows.Scan(&matchID, &s.PlayerID, &s.Score, &s.TotalHitRate, &h[1], &h[2], &h[3], &h[4], &h[5], &h[6], &h[7], &h[8], &h[9],
 			&h[10], &h[11], &h[12], &h[13], &h[14], &h[15], &h[16], &h[17], &h[18], &h[19], &h[20], &h[21])
 		if err != nil {
 			return nil, err
@@ -192,7 +211,7 @@ func Get420StatisticsForMatch(id int) ([]*models.Statistics420, error) {
 		}
 		hitrates[25] = *h[21]
 		s.Hitrates = hitrates
-		stats = append(stats, s)
+		stats[matchID] = append(stats[matchID], s)
 	}
 	return stats, nil
 }
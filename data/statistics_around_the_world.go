@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/guregu/null"
+	"github.com/jmoiron/sqlx"
 	"github.com/kcapp/api/models"
 )
 
@@ -144,8 +145,21 @@ func GetAroundTheWorldStatisticsForLeg(id int) ([]*models.StatisticsAroundThe, e
 
 // GetAroundTheWorldStatisticsForMatch will return statistics for all players in the given match
 func GetAroundTheWorldStatisticsForMatch(id int) ([]*models.StatisticsAroundThe, error) {
-	rows, err := models.DB.Query(`
+	statistics, err := GetAroundTheWorldStatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsAroundThe, 0), nil
+}
+
+// GetAroundTheWorldStatisticsForMatches will return statistics for all players in each of the given matches
+func GetAroundTheWorldStatisticsForMatches(ids []int) (map[int][]*models.StatisticsAroundThe, error) {
+	q, args, err := sqlx.In(`
 		SELECT
+			m.id,
 			p.id,
 			SUM(s.darts_thrown) as 'darts_thrown',
 			CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
@@ -177,19 +191,24 @@ func GetAroundTheWorldStatisticsForMatch(id int) ([]*models.StatisticsAroundThe,
 			JOIN leg l ON l.id = s.leg_id
 			JOIN matches m ON m.id = l.match_id
 			JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
-		WHERE m.id = ?
-		GROUP BY p.id
-		ORDER BY p2l.order`, id)
+		WHERE m.id IN (?)
+		GROUP BY m.id, p.id
+		ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make([]*models.StatisticsAroundThe, 0)
+	stats := make(map[int][]*models.StatisticsAroundThe)
 	for rows.Next() {
+		var matchID int
 		s := new(models.StatisticsAroundThe)
 		h := make([]*float64, 26)
-		err := rows.Scan(&s.PlayerID, &s.DartsThrown, &s.Score, &s.MPR, &s.TotalHitRate,
+		err := rows.Scan(&matchID, &s.PlayerID, &s.DartsThrown, &s.Score, &s.MPR, &s.TotalHitRate,
 			&h[1], &h[2], &h[3], &h[4], &h[5], &h[6], &h[7], &h[8], &h[9], &h[10], &h[11],
 			&h[12], &h[13], &h[14], &h[15], &h[16], &h[17], &h[18], &h[19], &h[20], &h[25])
 		if err != nil {
@@ -201,7 +220,7 @@ func GetAroundTheWorldStatisticsForMatch(id int) ([]*models.StatisticsAroundThe,
 		}
 		hitrates[25] = *h[25]
 		s.Hitrates = hitrates
-		stats = append(stats, s)
+		stats[matchID] = append(stats[matchID], s)
 	}
 	return stats, nil
 }
@@ -486,8 +505,21 @@ func GetShanghaiStatisticsForLeg(id int) ([]*models.StatisticsAroundThe, error)
 
 // GetShanghaiStatisticsForMatch will return statistics for all players in the given match
 func GetShanghaiStatisticsForMatch(id int) ([]*models.StatisticsAroundThe, error) {
-	rows, err := models.DB.Query(`
+	statistics, err := GetShanghaiStatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsAroundThe, 0), nil
+}
+
+// GetShanghaiStatisticsForMatches will return statistics for all players in each of the given matches
+func GetShanghaiStatisticsForMatches(ids []int) (map[int][]*models.StatisticsAroundThe, error) {
+	q, args, err := sqlx.In(`
 		SELECT
+			m.id,
 			p.id,
 			SUM(s.darts_thrown) as 'darts_thrown',
 			CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
@@ -519,19 +551,24 @@ func GetShanghaiStatisticsForMatch(id int) ([]*models.StatisticsAroundThe, error
 			JOIN leg l ON l.id = s.leg_id
 			JOIN matches m ON m.id = l.match_id
 			JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
-		WHERE m.id = ?
-		GROUP BY p.id
-		ORDER BY p2l.order`, id)
+		WHERE m.id IN (?)
+		GROUP BY m.id, p.id
+		ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make([]*models.StatisticsAroundThe, 0)
+	stats := make(map[int][]*models.StatisticsAroundThe)
 	for rows.Next() {
+		var matchID int
 		s := new(models.StatisticsAroundThe)
 		h := make([]*null.Float, 21)
-		err := rows.Scan(&s.PlayerID, &s.DartsThrown, &s.Score, &s.MPR, &s.TotalHitRate, &s.Shanghai,
+		err := rows.Scan(&matchID, &s.PlayerID, &s.DartsThrown, &s.Score, &s.MPR, &s.TotalHitRate, &s.Shanghai,
 			&h[1], &h[2], &h[3], &h[4], &h[5], &h[6], &h[7], &h[8], &h[9], &h[10], &h[11],
 			&h[12], &h[13], &h[14], &h[15], &h[16], &h[17], &h[18], &h[19], &h[20])
 		if err != nil {
@@ -547,7 +584,7 @@ func GetShanghaiStatisticsForMatch(id int) ([]*models.StatisticsAroundThe, error
 			}
 		}
 		s.Hitrates = hitrates
-		stats = append(stats, s)
+		stats[matchID] = append(stats[matchID], s)
 	}
 	return stats, nil
 }
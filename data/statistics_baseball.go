@@ -0,0 +1,295 @@
+package data
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/models"
+)
+
+// GetBaseballStatistics will return statistics for all players active during the given period
+func GetBaseballStatistics(from string, to string) ([]*models.StatisticsBaseball, error) {
+	rows, err := models.DB.Query(`
+			SELECT
+				p.id,
+				COUNT(DISTINCT m.id) AS 'matches_played',
+				COUNT(DISTINCT m2.id) AS 'matches_won',
+				COUNT(DISTINCT l.id) AS 'legs_played',
+				COUNT(DISTINCT l2.id) AS 'legs_won',
+				m.office_id AS 'office_id',
+				SUM(s.darts_thrown) as 'darts_thrown',
+				CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
+				SUM(s.innings_played) as 'innings_played',
+				MAX(s.highest_inning) as 'highest_inning',
+				SUM(s.perfect_innings) as 'perfect_innings',
+				SUM(s.hit_rate * s.darts_thrown) / SUM(s.darts_thrown) as 'hit_rate'
+			FROM statistics_baseball s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+				LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
+				LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
+			WHERE m.updated_at >= ? AND m.updated_at < ?
+				AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+				AND m.match_type_id = 21
+			GROUP BY p.id, m.office_id
+			ORDER BY(COUNT(DISTINCT m2.id) / COUNT(DISTINCT m.id)) DESC, matches_played DESC`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]*models.StatisticsBaseball, 0)
+	for rows.Next() {
+		s := new(models.StatisticsBaseball)
+		err := rows.Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.OfficeID, &s.DartsThrown,
+			&s.Score, &s.InningsPlayed, &s.HighestInning, &s.PerfectInnings, &s.HitRate)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// GetBaseballStatisticsForLeg will return statistics for all players in the given leg
+func GetBaseballStatisticsForLeg(id int) ([]*models.StatisticsBaseball, error) {
+	rows, err := models.DB.Query(`
+			SELECT
+				l.id,
+				p.id,
+				s.darts_thrown,
+				s.score,
+				s.innings_played,
+				s.highest_inning,
+				s.perfect_innings,
+				s.hit_rate
+			FROM statistics_baseball s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN player2leg p2l on l.id = p2l.leg_id AND p.id = p2l.player_id
+			WHERE l.id = ? GROUP BY p.id ORDER BY p2l.order`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]*models.StatisticsBaseball, 0)
+	for rows.Next() {
+		s := new(models.StatisticsBaseball)
+		err := rows.Scan(&s.LegID, &s.PlayerID, &s.DartsThrown, &s.Score, &s.InningsPlayed, &s.HighestInning, &s.PerfectInnings, &s.HitRate)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// GetBaseballStatisticsForMatch will return statistics for all players in the given match
+func GetBaseballStatisticsForMatch(id int) ([]*models.StatisticsBaseball, error) {
+	statistics, err := GetBaseballStatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsBaseball, 0), nil
+}
+
+// GetBaseballStatisticsForMatches will return statistics for all players in each of the given matches
+func GetBaseballStatisticsForMatches(ids []int) (map[int][]*models.StatisticsBaseball, error) {
+	q, args, err := sqlx.In(`
+			SELECT
+				m.id,
+				p.id,
+				SUM(s.darts_thrown) as 'darts_thrown',
+				CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
+				SUM(s.innings_played) as 'innings_played',
+				MAX(s.highest_inning) as 'highest_inning',
+				SUM(s.perfect_innings) as 'perfect_innings',
+				SUM(s.hit_rate * s.darts_thrown) / SUM(s.darts_thrown) as 'hit_rate'
+			FROM statistics_baseball s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+				JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
+			WHERE m.id IN (?)
+			GROUP BY m.id, p.id
+			ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make(map[int][]*models.StatisticsBaseball)
+	for rows.Next() {
+		var matchID int
+		s := new(models.StatisticsBaseball)
+		err := rows.Scan(&matchID, &s.PlayerID, &s.DartsThrown, &s.Score, &s.InningsPlayed, &s.HighestInning, &s.PerfectInnings, &s.HitRate)
+		if err != nil {
+			return nil, err
+		}
+		stats[matchID] = append(stats[matchID], s)
+	}
+	return stats, nil
+}
+
+// GetBaseballStatisticsForPlayer will return Baseball statistics for the given player
+func GetBaseballStatisticsForPlayer(id int) (*models.StatisticsBaseball, error) {
+	s := new(models.StatisticsBaseball)
+	err := models.DB.QueryRow(`
+			SELECT
+				p.id,
+				COUNT(DISTINCT m.id) AS 'matches_played',
+				COUNT(DISTINCT m2.id) AS 'matches_won',
+				COUNT(DISTINCT l.id) AS 'legs_played',
+				COUNT(DISTINCT l2.id) AS 'legs_won',
+				SUM(s.darts_thrown) as 'darts_thrown',
+				CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
+				SUM(s.innings_played) as 'innings_played',
+				MAX(s.highest_inning) as 'highest_inning',
+				SUM(s.perfect_innings) as 'perfect_innings',
+				SUM(s.hit_rate * s.darts_thrown) / SUM(s.darts_thrown) as 'hit_rate'
+			FROM statistics_baseball s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+				LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
+				LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
+			WHERE s.player_id = ?
+				AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+				AND m.match_type_id = 21
+			GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.DartsThrown,
+		&s.Score, &s.InningsPlayed, &s.HighestInning, &s.PerfectInnings, &s.HitRate)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return new(models.StatisticsBaseball), nil
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetBaseballHistoryForPlayer will return history of Baseball statistics for the given player
+func GetBaseballHistoryForPlayer(id int, start int, limit int) ([]*models.Leg, error) {
+	legs, err := GetLegsOfType(models.BASEBALL, id, start, limit, false)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[int]*models.Leg)
+	for _, leg := range legs {
+		m[leg.ID] = leg
+	}
+
+	rows, err := models.DB.Query(`
+			SELECT
+				l.id,
+				p.id,
+				s.darts_thrown,
+				s.score,
+				s.innings_played,
+				s.highest_inning,
+				s.perfect_innings,
+				s.hit_rate
+			FROM statistics_baseball s
+				LEFT JOIN player p ON p.id = s.player_id
+				LEFT JOIN leg l ON l.id = s.leg_id
+				LEFT JOIN matches m ON m.id = l.match_id
+			WHERE s.player_id = ?
+				AND l.is_finished = 1 AND m.is_abandoned = 0
+				AND m.match_type_id = 21
+			ORDER BY l.id DESC
+			LIMIT ?`, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	legs = make([]*models.Leg, 0)
+	for rows.Next() {
+		s := new(models.StatisticsBaseball)
+		err := rows.Scan(&s.LegID, &s.PlayerID, &s.DartsThrown, &s.Score, &s.InningsPlayed, &s.HighestInning, &s.PerfectInnings, &s.HitRate)
+		if err != nil {
+			return nil, err
+		}
+		leg := m[s.LegID]
+		leg.Statistics = s
+		legs = append(legs, leg)
+	}
+	return legs, nil
+}
+
+// CalculateBaseballStatistics will generate Baseball statistics for the given leg
+func CalculateBaseballStatistics(legID int) (map[int]*models.StatisticsBaseball, error) {
+	leg, err := GetLeg(legID)
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := GetPlayersScore(legID)
+	if err != nil {
+		return nil, err
+	}
+
+	statisticsMap := make(map[int]*models.StatisticsBaseball)
+	for _, player := range players {
+		stats := new(models.StatisticsBaseball)
+		stats.PlayerID = player.PlayerID
+		statisticsMap[player.PlayerID] = stats
+	}
+
+	hits := make(map[int]int)
+	inning := 1
+	for i, visit := range leg.Visits {
+		if i > 0 && i%len(players) == 0 {
+			inning++
+		}
+		stats := statisticsMap[visit.PlayerID]
+		stats.InningsPlayed++
+		stats.DartsThrown += 3
+
+		runs := visit.CalculateBaseballScore(inning)
+		for _, dart := range visit.GetDarts() {
+			if dart.ValueRaw() == models.GetBaseballTarget(inning) {
+				hits[visit.PlayerID]++
+			}
+		}
+		if runs > stats.HighestInning {
+			stats.HighestInning = runs
+		}
+		if runs == 9 {
+			stats.PerfectInnings++
+		}
+		stats.Score += runs
+	}
+
+	for playerID, stats := range statisticsMap {
+		if stats.DartsThrown > 0 {
+			stats.HitRate = float64(hits[playerID]) / float64(stats.DartsThrown)
+		}
+	}
+	return statisticsMap, nil
+}
+
+// RecalculateBaseballStatistics will recaulcate statistics for Baseball legs
+func RecalculateBaseballStatistics(legs []int) ([]string, error) {
+	queries := make([]string, 0)
+	for _, legID := range legs {
+		stats, err := CalculateBaseballStatistics(legID)
+		if err != nil {
+			return nil, err
+		}
+		for playerID, stat := range stats {
+			queries = append(queries, fmt.Sprintf(`UPDATE statistics_baseball SET darts_thrown = %d, score = %d, innings_played = %d, highest_inning = %d, perfect_innings = %d, hit_rate = %f WHERE leg_id = %d AND player_id = %d;`,
+				stat.DartsThrown, stat.Score, stat.InningsPlayed, stat.HighestInning, stat.PerfectInnings, stat.HitRate, legID, playerID))
+		}
+	}
+	return queries, nil
+}
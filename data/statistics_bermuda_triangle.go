@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/kcapp/api/models"
 )
 
@@ -128,8 +129,21 @@ func GetBermudaTriangleStatisticsForLeg(id int) ([]*models.StatisticsBermudaTria
 
 // GetBermudaTriangleStatisticsForMatch will return statistics for all players in the given match
 func GetBermudaTriangleStatisticsForMatch(id int) ([]*models.StatisticsBermudaTriangle, error) {
-	rows, err := models.DB.Query(`
+	statistics, err := GetBermudaTriangleStatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsBermudaTriangle, 0), nil
+}
+
+// GetBermudaTriangleStatisticsForMatches will return statistics for all players in each of the given matches
+func GetBermudaTriangleStatisticsForMatches(ids []int) (map[int][]*models.StatisticsBermudaTriangle, error) {
+	q, args, err := sqlx.In(`
 		SELECT
+			m.id,
 			p.id,
 			SUM(s.darts_thrown) as 'darts_thrown',
 			CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
@@ -155,19 +169,24 @@ func GetBermudaTriangleStatisticsForMatch(id int) ([]*models.StatisticsBermudaTr
 			JOIN leg l ON l.id = s.leg_id
 			JOIN matches m ON m.id = l.match_id
 			JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
-		WHERE m.id = ?
-		GROUP BY p.id
-		ORDER BY p2l.order`, id)
+		WHERE m.id IN (?)
+		GROUP BY m.id, p.id
+		ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make([]*models.StatisticsBermudaTriangle, 0)
+	stats := make(map[int][]*models.StatisticsBermudaTriangle)
 	for rows.Next() {
+		var matchID int
 		s := new(models.StatisticsBermudaTriangle)
 		h := make([]*float64, 14)
-		err := rows.Scan(&s.PlayerID, &s.DartsThrown, &s.Score, &s.MPR, &s.HighestScoreReached, &s.TotalHitRate,
+		err := rows.Scan(&matchID, &s.PlayerID, &s.DartsThrown, &s.Score, &s.MPR, &s.HighestScoreReached, &s.TotalHitRate,
 			&h[1], &h[2], &h[3], &h[4], &h[5], &h[6], &h[7], &h[8], &h[9], &h[10], &h[11], &h[12], &h[13], &s.HitCount)
 		if err != nil {
 			return nil, err
@@ -177,7 +196,7 @@ func GetBermudaTriangleStatisticsForMatch(id int) ([]*models.StatisticsBermudaTr
 			hitrates[i] = *h[i]
 		}
 		s.Hitrates = hitrates
-		stats = append(stats, s)
+		stats[matchID] = append(stats[matchID], s)
 	}
 	return stats, nil
 }
@@ -0,0 +1,315 @@
+package data
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/models"
+)
+
+// GetBobs27Statistics will return statistics for all players active during the given period
+func GetBobs27Statistics(from string, to string) ([]*models.StatisticsBobs27, error) {
+	rows, err := models.DB.Query(`
+			SELECT
+				p.id,
+				COUNT(DISTINCT m.id) AS 'matches_played',
+				COUNT(DISTINCT m2.id) AS 'matches_won',
+				COUNT(DISTINCT l.id) AS 'legs_played',
+				COUNT(DISTINCT l2.id) AS 'legs_won',
+				m.office_id AS 'office_id',
+				SUM(s.darts_thrown) as 'darts_thrown',
+				CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
+				MAX(s.highest_score) as 'highest_score',
+				SUM(s.rounds_played) as 'rounds_played',
+				SUM(s.doubles_hit) as 'doubles_hit',
+				SUM(s.doubles_hit) / SUM(s.darts_thrown) as 'doubles_hitrate',
+				COUNT(DISTINCT IF(s.is_busted = 0, l.id, NULL)) as 'legs_completed'
+			FROM statistics_bobs_27 s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+				LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
+				LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
+			WHERE m.updated_at >= ? AND m.updated_at < ?
+				AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+				AND m.match_type_id = 18
+			GROUP BY p.id, m.office_id
+			ORDER BY(COUNT(DISTINCT m2.id) / COUNT(DISTINCT m.id)) DESC, matches_played DESC`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]*models.StatisticsBobs27, 0)
+	for rows.Next() {
+		s := new(models.StatisticsBobs27)
+		err := rows.Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.OfficeID, &s.DartsThrown,
+			&s.Score, &s.HighestScore, &s.RoundsPlayed, &s.DoublesHit, &s.DoublesHitrate, &s.LegsCompleted)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// GetBobs27StatisticsForLeg will return statistics for all players in the given leg
+func GetBobs27StatisticsForLeg(id int) ([]*models.StatisticsBobs27, error) {
+	rows, err := models.DB.Query(`
+			SELECT
+				l.id,
+				p.id,
+				s.darts_thrown,
+				s.score,
+				s.highest_score,
+				s.rounds_played,
+				s.doubles_hit,
+				s.doubles_hitrate,
+				s.is_busted
+			FROM statistics_bobs_27 s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN player2leg p2l on l.id = p2l.leg_id AND p.id = p2l.player_id
+			WHERE l.id = ? GROUP BY p.id ORDER BY p2l.order`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]*models.StatisticsBobs27, 0)
+	for rows.Next() {
+		s := new(models.StatisticsBobs27)
+		err := rows.Scan(&s.LegID, &s.PlayerID, &s.DartsThrown, &s.Score, &s.HighestScore, &s.RoundsPlayed, &s.DoublesHit,
+			&s.DoublesHitrate, &s.IsBusted)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// GetBobs27StatisticsForMatch will return statistics for all players in the given match
+func GetBobs27StatisticsForMatch(id int) ([]*models.StatisticsBobs27, error) {
+	statistics, err := GetBobs27StatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsBobs27, 0), nil
+}
+
+// GetBobs27StatisticsForMatches will return statistics for all players in each of the given matches
+func GetBobs27StatisticsForMatches(ids []int) (map[int][]*models.StatisticsBobs27, error) {
+	q, args, err := sqlx.In(`
+			SELECT
+				m.id,
+				p.id,
+				SUM(s.darts_thrown) as 'darts_thrown',
+				CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
+				MAX(s.highest_score) as 'highest_score',
+				SUM(s.rounds_played) as 'rounds_played',
+				SUM(s.doubles_hit) as 'doubles_hit',
+				SUM(s.doubles_hit) / SUM(s.darts_thrown) as 'doubles_hitrate'
+			FROM statistics_bobs_27 s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+				JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
+			WHERE m.id IN (?)
+			GROUP BY m.id, p.id
+			ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make(map[int][]*models.StatisticsBobs27)
+	for rows.Next() {
+		var matchID int
+		s := new(models.StatisticsBobs27)
+		err := rows.Scan(&matchID, &s.PlayerID, &s.DartsThrown, &s.Score, &s.HighestScore, &s.RoundsPlayed, &s.DoublesHit, &s.DoublesHitrate)
+		if err != nil {
+			return nil, err
+		}
+		stats[matchID] = append(stats[matchID], s)
+	}
+	return stats, nil
+}
+
+// GetBobs27StatisticsForPlayer will return Bob's 27 statistics for the given player, including the personal best
+func GetBobs27StatisticsForPlayer(id int) (*models.StatisticsBobs27, error) {
+	s := new(models.StatisticsBobs27)
+	err := models.DB.QueryRow(`
+			SELECT
+				p.id,
+				COUNT(DISTINCT m.id) AS 'matches_played',
+				COUNT(DISTINCT m2.id) AS 'matches_won',
+				COUNT(DISTINCT l.id) AS 'legs_played',
+				COUNT(DISTINCT l2.id) AS 'legs_won',
+				SUM(s.darts_thrown) as 'darts_thrown',
+				CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
+				MAX(s.highest_score) as 'highest_score',
+				SUM(s.rounds_played) as 'rounds_played',
+				SUM(s.doubles_hit) as 'doubles_hit',
+				SUM(s.doubles_hit) / SUM(s.darts_thrown) as 'doubles_hitrate',
+				COUNT(DISTINCT IF(s.is_busted = 0, l.id, NULL)) as 'legs_completed'
+			FROM statistics_bobs_27 s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+				LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
+				LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
+			WHERE s.player_id = ?
+				AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+				AND m.match_type_id = 18
+			GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.DartsThrown,
+		&s.Score, &s.HighestScore, &s.RoundsPlayed, &s.DoublesHit, &s.DoublesHitrate, &s.LegsCompleted)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return new(models.StatisticsBobs27), nil
+		}
+		return nil, err
+	}
+
+	err = models.DB.QueryRow(`
+			SELECT s.score, s.leg_id
+			FROM statistics_bobs_27 s
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+			WHERE s.player_id = ?
+				AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+				AND m.match_type_id = 18
+			ORDER BY s.score DESC, s.leg_id ASC
+			LIMIT 1`, id).Scan(&s.BestScore, &s.BestScoreLeg)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetBobs27HistoryForPlayer will return history of Bob's 27 statistics for the given player
+func GetBobs27HistoryForPlayer(id int, start int, limit int) ([]*models.Leg, error) {
+	legs, err := GetLegsOfType(models.BOBS27, id, start, limit, false)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[int]*models.Leg)
+	for _, leg := range legs {
+		m[leg.ID] = leg
+	}
+
+	rows, err := models.DB.Query(`
+			SELECT
+				l.id,
+				p.id,
+				s.darts_thrown,
+				s.score,
+				s.highest_score,
+				s.rounds_played,
+				s.doubles_hit,
+				s.doubles_hitrate,
+				s.is_busted
+			FROM statistics_bobs_27 s
+				LEFT JOIN player p ON p.id = s.player_id
+				LEFT JOIN leg l ON l.id = s.leg_id
+				LEFT JOIN matches m ON m.id = l.match_id
+			WHERE s.player_id = ?
+				AND l.is_finished = 1 AND m.is_abandoned = 0
+				AND m.match_type_id = 18
+			ORDER BY l.id DESC
+			LIMIT ?`, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	legs = make([]*models.Leg, 0)
+	for rows.Next() {
+		s := new(models.StatisticsBobs27)
+		err := rows.Scan(&s.LegID, &s.PlayerID, &s.DartsThrown, &s.Score, &s.HighestScore, &s.RoundsPlayed, &s.DoublesHit,
+			&s.DoublesHitrate, &s.IsBusted)
+		if err != nil {
+			return nil, err
+		}
+		leg := m[s.LegID]
+		leg.Statistics = s
+		legs = append(legs, leg)
+	}
+	return legs, nil
+}
+
+// CalculateBobs27Statistics will generate Bob's 27 statistics for the given leg
+func CalculateBobs27Statistics(legID int) (map[int]*models.StatisticsBobs27, error) {
+	leg, err := GetLeg(legID)
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := GetPlayersScore(legID)
+	if err != nil {
+		return nil, err
+	}
+
+	statisticsMap := make(map[int]*models.StatisticsBobs27)
+	for _, player := range players {
+		stats := new(models.StatisticsBobs27)
+		stats.PlayerID = player.PlayerID
+		stats.Score = models.Bobs27StartingScore
+		stats.HighestScore = models.Bobs27StartingScore
+		statisticsMap[player.PlayerID] = stats
+	}
+
+	for _, visit := range leg.Visits {
+		stats := statisticsMap[visit.PlayerID]
+		// Players might be knocked out, so rounds are tracked per player
+		round := stats.RoundsPlayed
+		if round >= len(models.TargetsBobs27) {
+			continue
+		}
+		stats.RoundsPlayed++
+		stats.DartsThrown += 3
+
+		target := models.TargetsBobs27[round]
+		for _, dart := range visit.GetDarts() {
+			if dart.GetBobs27Score(target) > 0 {
+				stats.DoublesHit++
+			}
+		}
+		stats.Score += visit.CalculateBobs27Score(round)
+		if stats.Score > stats.HighestScore {
+			stats.HighestScore = stats.Score
+		}
+	}
+
+	for _, stats := range statisticsMap {
+		stats.IsBusted = stats.Score <= 0
+		if stats.DartsThrown > 0 {
+			stats.DoublesHitrate = float64(stats.DoublesHit) / float64(stats.DartsThrown)
+		}
+	}
+	return statisticsMap, nil
+}
+
+// RecalculateBobs27Statistics will recaulcate statistics for Bob's 27 legs
+func RecalculateBobs27Statistics(legs []int) ([]string, error) {
+	queries := make([]string, 0)
+	for _, legID := range legs {
+		stats, err := CalculateBobs27Statistics(legID)
+		if err != nil {
+			return nil, err
+		}
+		for playerID, stat := range stats {
+			queries = append(queries, fmt.Sprintf(`UPDATE statistics_bobs_27 SET darts_thrown = %d, score = %d, highest_score = %d, rounds_played = %d, doubles_hit = %d, doubles_hitrate = %f, is_busted = %t WHERE leg_id = %d AND player_id = %d;`,
+				stat.DartsThrown, stat.Score, stat.HighestScore, stat.RoundsPlayed, stat.DoublesHit, stat.DoublesHitrate, stat.IsBusted, legID, playerID))
+		}
+	}
+	return queries, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/guregu/null"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/kcapp/api/models"
 )
 
@@ -99,8 +100,21 @@ func GetCricketStatisticsForLeg(id int) ([]*models.StatisticsCricket, error) {
 
 // GetCricketStatisticsForMatch will return statistics for all players in the given match
 func GetCricketStatisticsForMatch(id int) ([]*models.StatisticsCricket, error) {
-	rows, err := models.DB.Query(`
+	statistics, err := GetCricketStatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsCricket, 0), nil
+}
+
+// GetCricketStatisticsForMatches will return statistics for all players in each of the given matches
+func GetCricketStatisticsForMatches(ids []int) (map[int][]*models.StatisticsCricket, error) {
+	q, args, err := sqlx.In(`
 		SELECT
+			m.id,
 			p.id AS 'player_id',
 			SUM(s.total_marks),
 			SUM(s.first_nine_marks),
@@ -116,23 +130,28 @@ func GetCricketStatisticsForMatch(id int) ([]*models.StatisticsCricket, error) {
 			JOIN leg l ON l.id = s.leg_id
 			JOIN matches m ON m.id = l.match_id
 			JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
-		WHERE m.id = ?
-		GROUP BY p.id
-		ORDER BY p2l.order`, id)
+		WHERE m.id IN (?)
+		GROUP BY m.id, p.id
+		ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make([]*models.StatisticsCricket, 0)
+	stats := make(map[int][]*models.StatisticsCricket)
 	for rows.Next() {
+		var matchID int
 		s := new(models.StatisticsCricket)
-		err := rows.Scan(&s.PlayerID, &s.TotalMarks, &s.FirstNineMarks, &s.MPR, &s.FirstNineMPR,
+		err := rows.Scan(&matchID, &s.PlayerID, &s.TotalMarks, &s.FirstNineMarks, &s.MPR, &s.FirstNineMPR,
 			&s.Marks5, &s.Marks6, &s.Marks7, &s.Marks8, &s.Marks9)
 		if err != nil {
 			return nil, err
 		}
-		stats = append(stats, s)
+		stats[matchID] = append(stats[matchID], s)
 	}
 	return stats, nil
 }
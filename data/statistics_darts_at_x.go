@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/guregu/null"
+	"github.com/jmoiron/sqlx"
 	"github.com/kcapp/api/models"
 )
 
@@ -97,8 +98,21 @@ func GetDartsAtXStatisticsForLeg(id int) ([]*models.StatisticsDartsAtX, error) {
 
 // GetDartsAtXStatisticsForMatch will return statistics for all players in the given match
 func GetDartsAtXStatisticsForMatch(id int) ([]*models.StatisticsDartsAtX, error) {
-	rows, err := models.DB.Query(`
+	statistics, err := GetDartsAtXStatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsDartsAtX, 0), nil
+}
+
+// GetDartsAtXStatisticsForMatches will return statistics for all players in each of the given matches
+func GetDartsAtXStatisticsForMatches(ids []int) (map[int][]*models.StatisticsDartsAtX, error) {
+	q, args, err := sqlx.In(`
 		SELECT
+			m.id,
 			p.id AS 'player_id',
 			CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
 			SUM(s.singles) as 'singles',
@@ -115,22 +129,27 @@ func GetDartsAtXStatisticsForMatch(id int) ([]*models.StatisticsDartsAtX, error)
 			JOIN leg l ON l.id = s.leg_id
 			JOIN matches m ON m.id = l.match_id
 			JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
-		WHERE m.id = ?
-		GROUP BY p.id
-		ORDER BY p2l.order`, id)
+		WHERE m.id IN (?)
+		GROUP BY m.id, p.id
+		ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make([]*models.StatisticsDartsAtX, 0)
+	stats := make(map[int][]*models.StatisticsDartsAtX)
 	for rows.Next() {
+		var matchID int
 		s := new(models.StatisticsDartsAtX)
-		err := rows.Scan(&s.PlayerID, &s.AvgScore, &s.Singles, &s.Doubles, &s.Triples, &s.HitRate, &s.Hits5, &s.Hits6, &s.Hits7, &s.Hits8, &s.Hits9)
+		err := rows.Scan(&matchID, &s.PlayerID, &s.AvgScore, &s.Singles, &s.Doubles, &s.Triples, &s.HitRate, &s.Hits5, &s.Hits6, &s.Hits7, &s.Hits8, &s.Hits9)
 		if err != nil {
 			return nil, err
 		}
-		stats = append(stats, s)
+		stats[matchID] = append(stats[matchID], s)
 	}
 	return stats, nil
 }
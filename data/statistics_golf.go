@@ -0,0 +1,292 @@
+package data
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/models"
+)
+
+// GetGolfStatistics will return statistics for all players active during the given period
+func GetGolfStatistics(from string, to string) ([]*models.StatisticsGolf, error) {
+	rows, err := models.DB.Query(`
+			SELECT
+				p.id,
+				COUNT(DISTINCT m.id) AS 'matches_played',
+				COUNT(DISTINCT m2.id) AS 'matches_won',
+				COUNT(DISTINCT l.id) AS 'legs_played',
+				COUNT(DISTINCT l2.id) AS 'legs_won',
+				m.office_id AS 'office_id',
+				SUM(s.darts_thrown) as 'darts_thrown',
+				CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
+				SUM(s.holes_played) as 'holes_played',
+				SUM(s.holes_in_one) as 'holes_in_one',
+				SUM(s.holes_missed) as 'holes_missed',
+				SUM(s.holes_played - s.holes_missed) / SUM(s.holes_played) as 'hit_rate'
+			FROM statistics_golf s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+				LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
+				LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
+			WHERE m.updated_at >= ? AND m.updated_at < ?
+				AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+				AND m.match_type_id = 20
+			GROUP BY p.id, m.office_id
+			ORDER BY(COUNT(DISTINCT m2.id) / COUNT(DISTINCT m.id)) DESC, matches_played DESC`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]*models.StatisticsGolf, 0)
+	for rows.Next() {
+		s := new(models.StatisticsGolf)
+		err := rows.Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.OfficeID, &s.DartsThrown,
+			&s.Score, &s.HolesPlayed, &s.HolesInOne, &s.HolesMissed, &s.HitRate)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// GetGolfStatisticsForLeg will return statistics for all players in the given leg
+func GetGolfStatisticsForLeg(id int) ([]*models.StatisticsGolf, error) {
+	rows, err := models.DB.Query(`
+			SELECT
+				l.id,
+				p.id,
+				s.darts_thrown,
+				s.score,
+				s.holes_played,
+				s.holes_in_one,
+				s.holes_missed,
+				s.hit_rate
+			FROM statistics_golf s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN player2leg p2l on l.id = p2l.leg_id AND p.id = p2l.player_id
+			WHERE l.id = ? GROUP BY p.id ORDER BY p2l.order`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]*models.StatisticsGolf, 0)
+	for rows.Next() {
+		s := new(models.StatisticsGolf)
+		err := rows.Scan(&s.LegID, &s.PlayerID, &s.DartsThrown, &s.Score, &s.HolesPlayed, &s.HolesInOne, &s.HolesMissed, &s.HitRate)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// GetGolfStatisticsForMatch will return statistics for all players in the given match
+func GetGolfStatisticsForMatch(id int) ([]*models.StatisticsGolf, error) {
+	statistics, err := GetGolfStatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsGolf, 0), nil
+}
+
+// GetGolfStatisticsForMatches will return statistics for all players in each of the given matches
+func GetGolfStatisticsForMatches(ids []int) (map[int][]*models.StatisticsGolf, error) {
+	q, args, err := sqlx.In(`
+			SELECT
+				m.id,
+				p.id,
+				SUM(s.darts_thrown) as 'darts_thrown',
+				CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
+				SUM(s.holes_played) as 'holes_played',
+				SUM(s.holes_in_one) as 'holes_in_one',
+				SUM(s.holes_missed) as 'holes_missed',
+				SUM(s.holes_played - s.holes_missed) / SUM(s.holes_played) as 'hit_rate'
+			FROM statistics_golf s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+				JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
+			WHERE m.id IN (?)
+			GROUP BY m.id, p.id
+			ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make(map[int][]*models.StatisticsGolf)
+	for rows.Next() {
+		var matchID int
+		s := new(models.StatisticsGolf)
+		err := rows.Scan(&matchID, &s.PlayerID, &s.DartsThrown, &s.Score, &s.HolesPlayed, &s.HolesInOne, &s.HolesMissed, &s.HitRate)
+		if err != nil {
+			return nil, err
+		}
+		stats[matchID] = append(stats[matchID], s)
+	}
+	return stats, nil
+}
+
+// GetGolfStatisticsForPlayer will return Golf statistics for the given player
+func GetGolfStatisticsForPlayer(id int) (*models.StatisticsGolf, error) {
+	s := new(models.StatisticsGolf)
+	err := models.DB.QueryRow(`
+			SELECT
+				p.id,
+				COUNT(DISTINCT m.id) AS 'matches_played',
+				COUNT(DISTINCT m2.id) AS 'matches_won',
+				COUNT(DISTINCT l.id) AS 'legs_played',
+				COUNT(DISTINCT l2.id) AS 'legs_won',
+				SUM(s.darts_thrown) as 'darts_thrown',
+				CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
+				SUM(s.holes_played) as 'holes_played',
+				SUM(s.holes_in_one) as 'holes_in_one',
+				SUM(s.holes_missed) as 'holes_missed',
+				SUM(s.holes_played - s.holes_missed) / SUM(s.holes_played) as 'hit_rate'
+			FROM statistics_golf s
+				JOIN player p ON p.id = s.player_id
+				JOIN leg l ON l.id = s.leg_id
+				JOIN matches m ON m.id = l.match_id
+				LEFT JOIN leg l2 ON l2.id = s.leg_id AND l2.winner_id = p.id
+				LEFT JOIN matches m2 ON m2.id = l.match_id AND m2.winner_id = p.id
+			WHERE s.player_id = ?
+				AND l.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
+				AND m.match_type_id = 20
+			GROUP BY p.id`, id).Scan(&s.PlayerID, &s.MatchesPlayed, &s.MatchesWon, &s.LegsPlayed, &s.LegsWon, &s.DartsThrown,
+		&s.Score, &s.HolesPlayed, &s.HolesInOne, &s.HolesMissed, &s.HitRate)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return new(models.StatisticsGolf), nil
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetGolfHistoryForPlayer will return history of Golf statistics for the given player
+func GetGolfHistoryForPlayer(id int, start int, limit int) ([]*models.Leg, error) {
+	legs, err := GetLegsOfType(models.GOLF, id, start, limit, false)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[int]*models.Leg)
+	for _, leg := range legs {
+		m[leg.ID] = leg
+	}
+
+	rows, err := models.DB.Query(`
+			SELECT
+				l.id,
+				p.id,
+				s.darts_thrown,
+				s.score,
+				s.holes_played,
+				s.holes_in_one,
+				s.holes_missed,
+				s.hit_rate
+			FROM statistics_golf s
+				LEFT JOIN player p ON p.id = s.player_id
+				LEFT JOIN leg l ON l.id = s.leg_id
+				LEFT JOIN matches m ON m.id = l.match_id
+			WHERE s.player_id = ?
+				AND l.is_finished = 1 AND m.is_abandoned = 0
+				AND m.match_type_id = 20
+			ORDER BY l.id DESC
+			LIMIT ?`, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	legs = make([]*models.Leg, 0)
+	for rows.Next() {
+		s := new(models.StatisticsGolf)
+		err := rows.Scan(&s.LegID, &s.PlayerID, &s.DartsThrown, &s.Score, &s.HolesPlayed, &s.HolesInOne, &s.HolesMissed, &s.HitRate)
+		if err != nil {
+			return nil, err
+		}
+		leg := m[s.LegID]
+		leg.Statistics = s
+		legs = append(legs, leg)
+	}
+	return legs, nil
+}
+
+// CalculateGolfStatistics will generate Golf statistics for the given leg
+func CalculateGolfStatistics(legID int) (map[int]*models.StatisticsGolf, error) {
+	leg, err := GetLeg(legID)
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := GetPlayersScore(legID)
+	if err != nil {
+		return nil, err
+	}
+
+	statisticsMap := make(map[int]*models.StatisticsGolf)
+	for _, player := range players {
+		stats := new(models.StatisticsGolf)
+		stats.PlayerID = player.PlayerID
+		statisticsMap[player.PlayerID] = stats
+	}
+
+	hole := 1
+	for i, visit := range leg.Visits {
+		if i > 0 && i%len(players) == 0 {
+			hole++
+		}
+		stats := statisticsMap[visit.PlayerID]
+		stats.HolesPlayed++
+		for _, dart := range visit.GetDarts() {
+			if dart.Value.Valid {
+				stats.DartsThrown++
+			}
+		}
+
+		strokes := visit.CalculateGolfScore(hole)
+		if strokes == 1 {
+			stats.HolesInOne++
+		} else if strokes == models.GolfMissStrokes {
+			stats.HolesMissed++
+		}
+		stats.Score += strokes
+	}
+
+	for _, stats := range statisticsMap {
+		if stats.HolesPlayed > 0 {
+			stats.HitRate = float64(stats.HolesPlayed-stats.HolesMissed) / float64(stats.HolesPlayed)
+		}
+	}
+	return statisticsMap, nil
+}
+
+// RecalculateGolfStatistics will recaulcate statistics for Golf legs
+func RecalculateGolfStatistics(legs []int) ([]string, error) {
+	queries := make([]string, 0)
+	for _, legID := range legs {
+		stats, err := CalculateGolfStatistics(legID)
+		if err != nil {
+			return nil, err
+		}
+		for playerID, stat := range stats {
+			queries = append(queries, fmt.Sprintf(`UPDATE statistics_golf SET darts_thrown = %d, score = %d, holes_played = %d, holes_in_one = %d, holes_missed = %d, hit_rate = %f WHERE leg_id = %d AND player_id = %d;`,
+				stat.DartsThrown, stat.Score, stat.HolesPlayed, stat.HolesInOne, stat.HolesMissed, stat.HitRate, legID, playerID))
+		}
+	}
+	return queries, nil
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/kcapp/api/models"
 )
 
@@ -86,8 +87,21 @@ func GetGotchaStatisticsForLeg(id int) ([]*models.StatisticsGotcha, error) {
 
 // GetGotchaStatisticsForMatch will return statistics for all players in the given match
 func GetGotchaStatisticsForMatch(id int) ([]*models.StatisticsGotcha, error) {
-	rows, err := models.DB.Query(`
+	statistics, err := GetGotchaStatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsGotcha, 0), nil
+}
+
+// GetGotchaStatisticsForMatches will return statistics for all players in each of the given matches
+func GetGotchaStatisticsForMatches(ids []int) (map[int][]*models.StatisticsGotcha, error) {
+	q, args, err := sqlx.In(`
 		SELECT
+			m.id,
 			p.id,
 			SUM(s.darts_thrown) as 'darts_thrown',
 			MAX(s.highest_score) as 'highest_score',
@@ -99,22 +113,27 @@ func GetGotchaStatisticsForMatch(id int) ([]*models.StatisticsGotcha, error) {
 			JOIN leg l ON l.id = s.leg_id
 			JOIN matches m ON m.id = l.match_id
 			JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
-		WHERE m.id = ?
-		GROUP BY p.id
-		ORDER BY p2l.order`, id)
+		WHERE m.id IN (?)
+		GROUP BY m.id, p.id
+		ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make([]*models.StatisticsGotcha, 0)
+	stats := make(map[int][]*models.StatisticsGotcha)
 	for rows.Next() {
+		var matchID int
 		s := new(models.StatisticsGotcha)
-		err := rows.Scan(&s.PlayerID, &s.DartsThrown, &s.HighestScore, &s.TimesReset, &s.OthersReset, &s.Score)
+		err := rows.Scan(&matchID, &s.PlayerID, &s.DartsThrown, &s.HighestScore, &s.TimesReset, &s.OthersReset, &s.Score)
 		if err != nil {
 			return nil, err
 		}
-		stats = append(stats, s)
+		stats[matchID] = append(stats[matchID], s)
 	}
 	return stats, nil
 }
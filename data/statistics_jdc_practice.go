@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/guregu/null"
+	"github.com/jmoiron/sqlx"
 	"github.com/kcapp/api/models"
 )
 
@@ -87,8 +88,21 @@ func GetJDCPracticeStatisticsForLeg(id int) ([]*models.StatisticsJDCPractice, er
 
 // GetJDCPracticeStatisticsForMatch will return statistics for all players in the given match
 func GetJDCPracticeStatisticsForMatch(id int) ([]*models.StatisticsJDCPractice, error) {
-	rows, err := models.DB.Query(`
+	statistics, err := GetJDCPracticeStatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsJDCPractice, 0), nil
+}
+
+// GetJDCPracticeStatisticsForMatches will return statistics for all players in each of the given matches
+func GetJDCPracticeStatisticsForMatches(ids []int) (map[int][]*models.StatisticsJDCPractice, error) {
+	q, args, err := sqlx.In(`
 			SELECT
+				m.id,
 				p.id,
 				SUM(s.darts_thrown) as 'darts_thrown',
 				CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
@@ -100,22 +114,27 @@ func GetJDCPracticeStatisticsForMatch(id int) ([]*models.StatisticsJDCPractice,
 				JOIN leg l ON l.id = s.leg_id
 				JOIN matches m ON m.id = l.match_id
 				JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
-			WHERE m.id = ?
-			GROUP BY p.id
-			ORDER BY p2l.order`, id)
+			WHERE m.id IN (?)
+			GROUP BY m.id, p.id
+			ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make([]*models.StatisticsJDCPractice, 0)
+	stats := make(map[int][]*models.StatisticsJDCPractice)
 	for rows.Next() {
+		var matchID int
 		s := new(models.StatisticsJDCPractice)
-		err := rows.Scan(&s.PlayerID, &s.DartsThrown, &s.Score, &s.MPR, &s.ShanghaiCount, &s.DoublesHitrate)
+		err := rows.Scan(&matchID, &s.PlayerID, &s.DartsThrown, &s.Score, &s.MPR, &s.ShanghaiCount, &s.DoublesHitrate)
 		if err != nil {
 			return nil, err
 		}
-		stats = append(stats, s)
+		stats[matchID] = append(stats[matchID], s)
 	}
 	return stats, nil
 }
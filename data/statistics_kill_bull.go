@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/kcapp/api/models"
 )
 
@@ -93,8 +94,21 @@ func GetKillBullStatisticsForLeg(id int) ([]*models.StatisticsKillBull, error) {
 
 // GetKillBullStatisticsForMatch will return statistics for all players in the given match
 func GetKillBullStatisticsForMatch(id int) ([]*models.StatisticsKillBull, error) {
-	rows, err := models.DB.Query(`
+	statistics, err := GetKillBullStatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsKillBull, 0), nil
+}
+
+// GetKillBullStatisticsForMatches will return statistics for all players in each of the given matches
+func GetKillBullStatisticsForMatches(ids []int) (map[int][]*models.StatisticsKillBull, error) {
+	q, args, err := sqlx.In(`
 		SELECT
+			m.id,
 			p.id,
 			SUM(s.darts_thrown) as 'darts_thrown',
 			CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
@@ -110,22 +124,27 @@ func GetKillBullStatisticsForMatch(id int) ([]*models.StatisticsKillBull, error)
 			JOIN leg l ON l.id = s.leg_id
 			JOIN matches m ON m.id = l.match_id
 			JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
-		WHERE m.id = ?
-		GROUP BY p.id
-		ORDER BY p2l.order`, id)
+		WHERE m.id IN (?)
+		GROUP BY m.id, p.id
+		ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make([]*models.StatisticsKillBull, 0)
+	stats := make(map[int][]*models.StatisticsKillBull)
 	for rows.Next() {
+		var matchID int
 		s := new(models.StatisticsKillBull)
-		err := rows.Scan(&s.PlayerID, &s.DartsThrown, &s.Score, &s.Marks3, &s.Marks4, &s.Marks5, &s.Marks6, &s.LongestStreak, &s.TimesBusted, &s.TotalHitRate)
+		err := rows.Scan(&matchID, &s.PlayerID, &s.DartsThrown, &s.Score, &s.Marks3, &s.Marks4, &s.Marks5, &s.Marks6, &s.LongestStreak, &s.TimesBusted, &s.TotalHitRate)
 		if err != nil {
 			return nil, err
 		}
-		stats = append(stats, s)
+		stats[matchID] = append(stats[matchID], s)
 	}
 	return stats, nil
 }
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/kcapp/api/models"
 )
 
@@ -86,8 +87,21 @@ func GetKnockoutStatisticsForLeg(id int) ([]*models.StatisticsKnockout, error) {
 
 // GetKnockoutStatisticsForMatch will return statistics for all players in the given match
 func GetKnockoutStatisticsForMatch(id int) ([]*models.StatisticsKnockout, error) {
-	rows, err := models.DB.Query(`
+	statistics, err := GetKnockoutStatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsKnockout, 0), nil
+}
+
+// GetKnockoutStatisticsForMatches will return statistics for all players in each of the given matches
+func GetKnockoutStatisticsForMatches(ids []int) (map[int][]*models.StatisticsKnockout, error) {
+	q, args, err := sqlx.In(`
 			SELECT
+				m.id,
 				p.id,
 				SUM(s.darts_thrown) as 'darts_thrown',
 				SUM(s.avg_score) / COUNT(DISTINCT l.id) as 'avg_score',
@@ -99,22 +113,27 @@ func GetKnockoutStatisticsForMatch(id int) ([]*models.StatisticsKnockout, error)
 				JOIN leg l ON l.id = s.leg_id
 				JOIN matches m ON m.id = l.match_id
 				JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
-			WHERE m.id = ?
-			GROUP BY p.id
-			ORDER BY p2l.order`, id)
+			WHERE m.id IN (?)
+			GROUP BY m.id, p.id
+			ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make([]*models.StatisticsKnockout, 0)
+	stats := make(map[int][]*models.StatisticsKnockout)
 	for rows.Next() {
+		var matchID int
 		s := new(models.StatisticsKnockout)
-		err := rows.Scan(&s.PlayerID, &s.DartsThrown, &s.AvgScore, &s.LivesLost, &s.LivesTaken, &s.FinalPosition)
+		err := rows.Scan(&matchID, &s.PlayerID, &s.DartsThrown, &s.AvgScore, &s.LivesLost, &s.LivesTaken, &s.FinalPosition)
 		if err != nil {
 			return nil, err
 		}
-		stats = append(stats, s)
+		stats[matchID] = append(stats[matchID], s)
 	}
 	return stats, nil
 }
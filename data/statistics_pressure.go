@@ -0,0 +1,58 @@
+package data
+
+import (
+	"github.com/kcapp/api/models"
+)
+
+// GetPlayerPressureStatistics will return pressure statistics for the given player
+func GetPlayerPressureStatistics(playerID int) (*models.StatisticsPressure, error) {
+	stats, err := getPressureStatistics("p2l.player_id = ?", playerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return models.NewPlayerStatisticsPressure(playerID), nil
+	}
+	return stats[0], nil
+}
+
+// GetTournamentPressureStatistics will return pressure statistics for all players in the given tournament
+func GetTournamentPressureStatistics(tournamentID int) ([]*models.StatisticsPressure, error) {
+	return getPressureStatistics("m.tournament_id = ?", tournamentID)
+}
+
+// getPressureStatistics will return pressure statistics from all two player legs matching the given condition
+func getPressureStatistics(condition string, args ...interface{}) ([]*models.StatisticsPressure, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			p2l.player_id, l.match_id, l.id, mm.wins_required, l.winner_id, p2l.order,
+			s.ppd_score, s.darts_thrown, s.checkout_attempts
+		FROM player2leg p2l
+			JOIN leg l ON l.id = p2l.leg_id
+			JOIN matches m ON m.id = l.match_id
+			JOIN match_mode mm ON mm.id = m.match_mode_id
+			LEFT JOIN statistics_x01 s ON s.leg_id = l.id AND s.player_id = p2l.player_id
+		WHERE `+condition+`
+			AND l.num_players = 2 AND l.is_finished = 1
+			AND m.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0 AND m.is_bye = 0 AND m.is_practice = 0
+		ORDER BY p2l.player_id, l.match_id, l.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	legs := make([]*models.PressureLeg, 0)
+	for rows.Next() {
+		leg := new(models.PressureLeg)
+		err := rows.Scan(&leg.PlayerID, &leg.MatchID, &leg.LegID, &leg.WinsRequired, &leg.WinnerID, &leg.Order,
+			&leg.PPDScore, &leg.DartsThrown, &leg.CheckoutAttempts)
+		if err != nil {
+			return nil, err
+		}
+		legs = append(legs, leg)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return models.NewStatisticsPressure(legs), nil
+}
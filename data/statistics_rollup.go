@@ -0,0 +1,307 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/cache"
+	"github.com/kcapp/api/logging"
+	"github.com/kcapp/api/models"
+)
+
+const (
+	// rollupVisitScore is the score of a single visit
+	rollupVisitScore = `(s.first_dart * s.first_dart_multiplier + IFNULL(s.second_dart, 0) * s.second_dart_multiplier + IFNULL(s.third_dart, 0) * s.third_dart_multiplier)`
+
+	// rollupColumns are the aggregated columns of statistics_rollup, calculated from the score rows of finished legs
+	rollupColumns = `
+				COUNT(DISTINCT l.id) AS 'legs',
+				COUNT(s.id) AS 'visits',
+				COUNT(s.first_dart) + SUM(IF(s.second_dart IS NULL, 0, 1)) + SUM(IF(s.third_dart IS NULL, 0, 1)) AS 'darts',
+				IFNULL(SUM(IF(s.is_bust = 1, 0, ` + rollupVisitScore + `)), 0) AS 'points',
+				IFNULL(SUM(IF(s.is_bust = 1, ` + rollupVisitScore + `, 0)), 0) AS 'points_busted',
+				IFNULL(SUM(IF(s.is_bust, 0, IF(s.first_dart = 20 AND s.first_dart_multiplier = 3 AND s.second_dart = 20 AND s.second_dart_multiplier = 3 AND s.third_dart = 20 AND s.third_dart_multiplier = 3, 1, 0))), 0) AS 'score_180s',
+				IFNULL(SUM(IF(s.is_bust, 0, IF((s.first_dart = 25 AND s.first_dart_multiplier = 2) OR (s.second_dart = 25 AND s.second_dart_multiplier = 2) OR (s.third_dart = 25 AND s.third_dart_multiplier = 2), 1, 0))), 0) AS 'score_bullseyes',
+				IFNULL(SUM(IF(s.first_dart IN (1,20,5) AND s.first_dart_multiplier = 1 AND s.second_dart IN (1,20,5) AND s.second_dart_multiplier = 1 AND
+					s.third_dart IN (1,20,5) AND s.third_dart_multiplier = 1 AND ` + rollupVisitScore + ` = 26, 1, 0)), 0) AS 'fish_n_chips'`
+
+	// rollupLegFilter excludes legs of matches which are not counted in the statistics
+	rollupLegFilter = `m.is_abandoned = 0 AND m.is_bye = 0 AND m.is_walkover = 0`
+
+	// rollupCheckoutQuery will insert the checkout of all finished x01 legs matching the given condition
+	rollupCheckoutQuery = `
+			INSERT INTO statistics_rollup_checkout (leg_id, office_id, day, player_id, is_practice, checkout,
+				first_dart, first_dart_multiplier, second_dart, second_dart_multiplier, third_dart, third_dart_multiplier)
+			SELECT
+				l.id, IFNULL(m.office_id, 0), DATE(l.end_time), s.player_id, m.is_practice, x.checkout,
+				s.first_dart, s.first_dart_multiplier, s.second_dart, s.second_dart_multiplier, s.third_dart, s.third_dart_multiplier
+			FROM leg l
+				JOIN matches m ON m.id = l.match_id
+				JOIN score s ON s.id = (SELECT MAX(id) FROM score WHERE leg_id = l.id)
+				JOIN statistics_x01 x ON x.leg_id = l.id AND x.player_id = s.player_id
+			WHERE l.is_finished = 1 AND m.match_type_id = 1 AND IFNULL(l.leg_type_id, 1) = 1 AND x.checkout IS NOT NULL
+				AND ` + rollupLegFilter + ` AND %s`
+)
+
+// addStatisticsRollup will add (sign = 1) or subtract (sign = -1) the given finished leg from the daily statistics rollup
+// of the office. If countMatch is set, the match of the leg is counted as well. Legs of abandoned, bye and walkover
+// matches are never part of the rollup
+func addStatisticsRollup(ctx context.Context, tx *sql.Tx, legID int, sign int, countMatch bool) error {
+	matches := 0
+	if countMatch {
+		matches = sign
+	}
+	_, err := tx.Exec(`
+		INSERT INTO statistics_rollup (office_id, day, matches, legs, visits, darts, points, points_busted, score_180s, score_bullseyes, fish_n_chips)
+		SELECT office_id, day, ?, ? * legs, ? * visits, ? * darts, ? * points, ? * points_busted, ? * score_180s, ? * score_bullseyes, ? * fish_n_chips
+		FROM (
+			SELECT
+				IFNULL(m.office_id, 0) AS 'office_id',
+				DATE(l.end_time) AS 'day',`+rollupColumns+`
+			FROM leg l
+				JOIN matches m ON m.id = l.match_id
+				LEFT JOIN score s ON s.leg_id = l.id AND s.player_id IN (SELECT id FROM player WHERE is_bot = 0)
+			WHERE l.id = ? AND l.end_time IS NOT NULL AND `+rollupLegFilter+`
+			GROUP BY l.id
+		) leg
+		ON DUPLICATE KEY UPDATE
+			matches = matches + VALUES(matches),
+			legs = legs + VALUES(legs),
+			visits = visits + VALUES(visits),
+			darts = darts + VALUES(darts),
+			points = points + VALUES(points),
+			points_busted = points_busted + VALUES(points_busted),
+			score_180s = score_180s + VALUES(score_180s),
+			score_bullseyes = score_bullseyes + VALUES(score_bullseyes),
+			fish_n_chips = fish_n_chips + VALUES(fish_n_chips)`,
+		matches, sign, sign, sign, sign, sign, sign, sign, sign, legID)
+	if err != nil {
+		return err
+	}
+
+	if sign > 0 {
+		_, err = tx.Exec(fmt.Sprintf(rollupCheckoutQuery, "l.id = ?"), legID)
+	} else {
+		_, err = tx.Exec("DELETE FROM statistics_rollup_checkout WHERE leg_id = ?", legID)
+	}
+	if err != nil {
+		return err
+	}
+	logging.Logger(ctx).Printf("[%d] Updated statistics rollup (%+d)", legID, sign)
+	return nil
+}
+
+// removeMatchStatisticsRollup will subtract all finished legs of the given match from the daily statistics rollup,
+// and the match itself if it was counted
+func removeMatchStatisticsRollup(ctx context.Context, tx *sql.Tx, match *models.Match) error {
+	rows, err := tx.Query("SELECT id FROM leg WHERE match_id = ? AND is_finished = 1 ORDER BY id", match.ID)
+	if err != nil {
+		return err
+	}
+	legIDs := make([]int, 0)
+	for rows.Next() {
+		var legID int
+		if err = rows.Scan(&legID); err != nil {
+			rows.Close()
+			return err
+		}
+		legIDs = append(legIDs, legID)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	countMatch := match.IsFinished && !match.IsAbandoned && !match.IsBye && !match.IsWalkover
+	for i, legID := range legIDs {
+		err = addStatisticsRollup(ctx, tx, legID, -1, countMatch && i == len(legIDs)-1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getRecalculateStatisticsRollupQueries will return the queries needed to rebuild all rollups since a date,
+// each taking the date as its only parameter
+func getRecalculateStatisticsRollupQueries() []string {
+	return []string{
+		"DELETE FROM statistics_rollup WHERE day >= ?",
+		"DELETE FROM statistics_rollup_checkout WHERE day >= ?",
+		`
+			INSERT INTO statistics_rollup (office_id, day, matches, legs, visits, darts, points, points_busted, score_180s, score_bullseyes, fish_n_chips)
+			SELECT
+				IFNULL(m.office_id, 0) AS 'office_id',
+				DATE(l.end_time) AS 'day',
+				0 AS 'matches',` + rollupColumns + `
+			FROM leg l
+				JOIN matches m ON m.id = l.match_id
+				LEFT JOIN score s ON s.leg_id = l.id AND s.player_id IN (SELECT id FROM player WHERE is_bot = 0)
+			WHERE l.is_finished = 1 AND ` + rollupLegFilter + ` AND l.end_time >= ?
+			GROUP BY IFNULL(m.office_id, 0), DATE(l.end_time)`,
+		`
+			INSERT INTO statistics_rollup (office_id, day, matches)
+			SELECT IFNULL(m.office_id, 0), DATE(l.end_time), COUNT(m.id)
+			FROM matches m
+				JOIN leg l ON l.id = m.current_leg_id
+			WHERE m.is_finished = 1 AND ` + rollupLegFilter + ` AND l.end_time >= ?
+			GROUP BY IFNULL(m.office_id, 0), DATE(l.end_time)
+			ON DUPLICATE KEY UPDATE matches = VALUES(matches)`,
+		fmt.Sprintf(rollupCheckoutQuery, "l.end_time >= ?"),
+	}
+}
+
+// RecalculateStatisticsRollups will rebuild the global and office statistics rollups since the given date
+func RecalculateStatisticsRollups(since string, dryRun bool) error {
+	if since == "" {
+		since = "1970-01-01"
+	}
+	log.Printf("Recalculating statistics rollups since=%s", since)
+
+	queries := getRecalculateStatisticsRollupQueries()
+	if dryRun {
+		for _, query := range queries {
+			log.Printf("%s [since=%s]", query, since)
+		}
+		return nil
+	}
+	err := models.Transaction(models.DB, func(tx *sql.Tx) error {
+		for _, query := range queries {
+			_, err := tx.Exec(query, since)
+			if err != nil {
+				return err
+			}
+		}
+		log.Printf("Recalculated statistics rollups since=%s", since)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	cache.Invalidate(cache.TagStatistics)
+	return nil
+}
+
+// GetGlobalStatistics will return global statistics per office for the given period. Key 0 contains the totals
+// of all offices. If from or to is empty, the period is unbounded
+func GetGlobalStatistics(from string, to string) (map[int]*models.GlobalStatistics, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			office_id,
+			IFNULL(SUM(matches), 0),
+			IFNULL(SUM(legs), 0),
+			IFNULL(SUM(visits), 0),
+			IFNULL(SUM(darts), 0),
+			IFNULL(SUM(points), 0),
+			IFNULL(SUM(points_busted), 0),
+			IFNULL(SUM(score_180s), 0),
+			IFNULL(SUM(score_bullseyes), 0),
+			IFNULL(SUM(fish_n_chips), 0)
+		FROM statistics_rollup
+		WHERE (? = '' OR day >= ?) AND (? = '' OR day < ?)
+		GROUP BY office_id`, from, from, to, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	all := new(models.GlobalStatistics)
+	stats := make(map[int]*models.GlobalStatistics)
+	for rows.Next() {
+		var officeID int
+		s := new(models.GlobalStatistics)
+		err := rows.Scan(&officeID, &s.Matches, &s.Legs, &s.Visits, &s.Darts, &s.Points, &s.PointsBusted, &s.Score180s,
+			&s.ScoreBullseyes, &s.FishNChips)
+		if err != nil {
+			return nil, err
+		}
+		all.Matches += s.Matches
+		all.Legs += s.Legs
+		all.Visits += s.Visits
+		all.Darts += s.Darts
+		all.Points += s.Points
+		all.PointsBusted += s.PointsBusted
+		all.Score180s += s.Score180s
+		all.ScoreBullseyes += s.ScoreBullseyes
+		all.FishNChips += s.FishNChips
+		if officeID != 0 {
+			stats[officeID] = s
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	stats[0] = all
+	return stats, nil
+}
+
+// GetGlobalStatisticsFnc will return global fish and chips statistics for the given period
+func GetGlobalStatisticsFnc(from string, to string) (map[int]*models.GlobalStatistics, error) {
+	global, err := GetGlobalStatistics(from, to)
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[int]*models.GlobalStatistics)
+	for officeID, s := range global {
+		stats[officeID] = &models.GlobalStatistics{FishNChips: s.FishNChips}
+	}
+	return stats, nil
+}
+
+// GetOfficeStatistics will return office statistics for the given period
+func GetOfficeStatistics(from string, to string) ([]*models.OfficeStatistics, error) {
+	return getOfficeCheckoutRollup(`day >= ? AND day < ?`, from, to)
+}
+
+// GetOfficeStatisticsForOffice will return office statistics for the given office and period
+func GetOfficeStatisticsForOffice(officeID int, from string, to string) ([]*models.OfficeStatistics, error) {
+	return getOfficeCheckoutRollup(`office_id = ? AND is_practice = 0 AND day >= ? AND day < ?`, officeID, from, to)
+}
+
+// getOfficeCheckoutRollup will return the distinct checkouts of each player matching the given condition
+func getOfficeCheckoutRollup(condition string, args ...interface{}) ([]*models.OfficeStatistics, error) {
+	rows, err := models.DB.Query(`
+		SELECT
+			player_id,
+			MIN(leg_id),
+			office_id,
+			checkout,
+			first_dart, first_dart_multiplier,
+			second_dart, second_dart_multiplier,
+			third_dart, third_dart_multiplier
+		FROM statistics_rollup_checkout
+		WHERE `+condition+`
+		GROUP BY player_id, office_id, checkout
+		ORDER BY checkout DESC, MIN(leg_id)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]*models.OfficeStatistics, 0)
+	for rows.Next() {
+		s := new(models.OfficeStatistics)
+		first := new(models.Dart)
+		second := new(models.Dart)
+		third := new(models.Dart)
+		var officeID int64
+		err := rows.Scan(&s.PlayerID, &s.LegID, &officeID, &s.Checkout, &first.Value, &first.Multiplier,
+			&second.Value, &second.Multiplier, &third.Value, &third.Multiplier)
+		if err != nil {
+			return nil, err
+		}
+		if officeID != 0 {
+			s.OfficeID = null.IntFrom(officeID)
+		}
+		darts := make([]*models.Dart, 0)
+		s.Darts = append(darts, first, second, third)
+		stats = append(stats, s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
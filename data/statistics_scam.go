@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/kcapp/api/models"
 )
 
@@ -88,8 +89,21 @@ func GetScamStatisticsForLeg(id int) ([]*models.StatisticsScam, error) {
 
 // GetScamStatisticsForMatch will return statistics for all players in the given match
 func GetScamStatisticsForMatch(id int) ([]*models.StatisticsScam, error) {
-	rows, err := models.DB.Query(`
+	statistics, err := GetScamStatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsScam, 0), nil
+}
+
+// GetScamStatisticsForMatches will return statistics for all players in each of the given matches
+func GetScamStatisticsForMatches(ids []int) (map[int][]*models.StatisticsScam, error) {
+	q, args, err := sqlx.In(`
 			SELECT
+				m.id,
 				p.id,
 				SUM(s.darts_thrown_scorer) as 'darts_thrown_scorer',
 				SUM(s.darts_thrown_stopper) as 'darts_thrown_stopper',
@@ -102,22 +116,27 @@ func GetScamStatisticsForMatch(id int) ([]*models.StatisticsScam, error) {
 				JOIN leg l ON l.id = s.leg_id
 				JOIN matches m ON m.id = l.match_id
 				JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
-			WHERE m.id = ?
-			GROUP BY p.id
-			ORDER BY p2l.order`, id)
+			WHERE m.id IN (?)
+			GROUP BY m.id, p.id
+			ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make([]*models.StatisticsScam, 0)
+	stats := make(map[int][]*models.StatisticsScam)
 	for rows.Next() {
+		var matchID int
 		s := new(models.StatisticsScam)
-		err := rows.Scan(&s.PlayerID, &s.DartsThrownScorer, &s.DartsThrownStopper, &s.Score, &s.PPD, &s.ThreeDartAvg, &s.MPR)
+		err := rows.Scan(&matchID, &s.PlayerID, &s.DartsThrownScorer, &s.DartsThrownStopper, &s.Score, &s.PPD, &s.ThreeDartAvg, &s.MPR)
 		if err != nil {
 			return nil, err
 		}
-		stats = append(stats, s)
+		stats[matchID] = append(stats[matchID], s)
 	}
 	return stats, nil
 }
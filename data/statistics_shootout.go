@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/kcapp/api/models"
 )
 
@@ -54,8 +55,21 @@ func GetShootoutStatistics(from string, to string) ([]*models.StatisticsShootout
 
 // GetShootoutStatisticsForMatch will return statistics for the given match
 func GetShootoutStatisticsForMatch(matchID int) ([]*models.StatisticsShootout, error) {
-	rows, err := models.DB.Query(`
+	statistics, err := GetShootoutStatisticsForMatches([]int{matchID})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[matchID]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsShootout, 0), nil
+}
+
+// GetShootoutStatisticsForMatches will return statistics for all players in each of the given matches
+func GetShootoutStatisticsForMatches(ids []int) (map[int][]*models.StatisticsShootout, error) {
+	q, args, err := sqlx.In(`
 		SELECT
+			m.id,
 			p.id AS 'player_id',
 			COUNT(DISTINCT m.id),
 			CAST(SUM(s.score) / COUNT(DISTINCT l.id) AS SIGNED) as 'avg_score',
@@ -68,23 +82,28 @@ func GetShootoutStatisticsForMatch(matchID int) ([]*models.StatisticsShootout, e
 			JOIN player p ON p.id = s.player_id
 			JOIN leg l ON l.id = s.leg_id
 			JOIN matches m ON m.id = l.match_id
-		WHERE m.id = ?
+		WHERE m.id IN (?)
 			AND m.is_finished = 1 AND m.is_abandoned = 0 AND m.is_walkover = 0
 			AND (m.match_type_id = 2 OR l.leg_type_id = 2)
-		GROUP BY p.id`, matchID)
+		GROUP BY m.id, p.id`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make([]*models.StatisticsShootout, 0)
+	stats := make(map[int][]*models.StatisticsShootout)
 	for rows.Next() {
+		var matchID int
 		s := new(models.StatisticsShootout)
-		err := rows.Scan(&s.PlayerID, &s.MatchesPlayed, &s.Score, &s.PPD, &s.Score60sPlus, &s.Score100sPlus, &s.Score140sPlus, &s.Score180s)
+		err := rows.Scan(&matchID, &s.PlayerID, &s.MatchesPlayed, &s.Score, &s.PPD, &s.Score60sPlus, &s.Score100sPlus, &s.Score140sPlus, &s.Score180s)
 		if err != nil {
 			return nil, err
 		}
-		stats = append(stats, s)
+		stats[matchID] = append(stats[matchID], s)
 	}
 	return stats, nil
 }
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/kcapp/api/models"
 )
 
@@ -84,8 +85,21 @@ func GetTicTacToeStatisticsForLeg(id int) ([]*models.StatisticsTicTacToe, error)
 
 // GetTicTacToeStatisticsForMatch will return statistics for all players in the given match
 func GetTicTacToeStatisticsForMatch(id int) ([]*models.StatisticsTicTacToe, error) {
-	rows, err := models.DB.Query(`
+	statistics, err := GetTicTacToeStatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsTicTacToe, 0), nil
+}
+
+// GetTicTacToeStatisticsForMatches will return statistics for all players in each of the given matches
+func GetTicTacToeStatisticsForMatches(ids []int) (map[int][]*models.StatisticsTicTacToe, error) {
+	q, args, err := sqlx.In(`
 		SELECT
+			m.id,
 			p.id AS 'player_id',
 			SUM(darts_Thrown) as 'darts_thrown',
 			SUM(score) as 'score',
@@ -96,22 +110,27 @@ func GetTicTacToeStatisticsForMatch(id int) ([]*models.StatisticsTicTacToe, erro
 			JOIN leg l ON l.id = s.leg_id
 			JOIN matches m ON m.id = l.match_id
 			JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
-		WHERE m.id = ?
-		GROUP BY p.id
-		ORDER BY p2l.order`, id)
+		WHERE m.id IN (?)
+		GROUP BY m.id, p.id
+		ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make([]*models.StatisticsTicTacToe, 0)
+	stats := make(map[int][]*models.StatisticsTicTacToe)
 	for rows.Next() {
+		var matchID int
 		s := new(models.StatisticsTicTacToe)
-		err := rows.Scan(&s.PlayerID, &s.DartsThrown, &s.Score, &s.NumbersClosed, &s.HighestClosed)
+		err := rows.Scan(&matchID, &s.PlayerID, &s.DartsThrown, &s.Score, &s.NumbersClosed, &s.HighestClosed)
 		if err != nil {
 			return nil, err
 		}
-		stats = append(stats, s)
+		stats[matchID] = append(stats[matchID], s)
 	}
 	return stats, nil
 }
@@ -119,8 +119,21 @@ func GetX01StatisticsForLeg(id int) ([]*models.StatisticsX01, error) {
 
 // GetX01StatisticsForMatch will return statistics for all players in the given match
 func GetX01StatisticsForMatch(id int) ([]*models.StatisticsX01, error) {
-	rows, err := models.DB.Query(`
+	statistics, err := GetX01StatisticsForMatches([]int{id})
+	if err != nil {
+		return nil, err
+	}
+	if stats, ok := statistics[id]; ok {
+		return stats, nil
+	}
+	return make([]*models.StatisticsX01, 0), nil
+}
+
+// GetX01StatisticsForMatches will return statistics for all players in each of the given matches
+func GetX01StatisticsForMatches(ids []int) (map[int][]*models.StatisticsX01, error) {
+	q, args, err := sqlx.In(`
 		SELECT
+			m.id,
 			p.id AS 'player_id',
 			SUM(s.ppd_score) / SUM(s.darts_thrown) AS 'ppd',
 			SUM(s.first_nine_ppd) / COUNT(p.id) AS 'first_nine_ppd',
@@ -141,25 +154,30 @@ func GetX01StatisticsForMatch(id int) ([]*models.StatisticsX01, error) {
 			JOIN leg l ON l.id = s.leg_id
 			JOIN matches m ON m.id = l.match_id
 			JOIN player2leg p2l ON p2l.leg_id = l.id AND p2l.player_id = s.player_id
-		WHERE m.id = ?
+		WHERE m.id IN (?)
 			AND m.match_type_id IN (1, 3)
-		GROUP BY p.id
-		ORDER BY p2l.order`, id)
+		GROUP BY m.id, p.id
+		ORDER BY p2l.order`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	stats := make([]*models.StatisticsX01, 0)
+	stats := make(map[int][]*models.StatisticsX01)
 	for rows.Next() {
+		var matchID int
 		s := new(models.StatisticsX01)
-		err := rows.Scan(&s.PlayerID, &s.PPD, &s.FirstNinePPD, &s.ThreeDartAvg, &s.FirstNineThreeDartAvg, &s.Score60sPlus,
+		err := rows.Scan(&matchID, &s.PlayerID, &s.PPD, &s.FirstNinePPD, &s.ThreeDartAvg, &s.FirstNineThreeDartAvg, &s.Score60sPlus,
 			&s.Score100sPlus, &s.Score140sPlus, &s.Score180s, &s.Accuracy20, &s.Accuracy19, &s.AccuracyOverall, &s.CheckoutAttempts,
 			&s.CheckoutPercentage, &s.Checkout)
 		if err != nil {
 			return nil, err
 		}
-		stats = append(stats, s)
+		stats[matchID] = append(stats[matchID], s)
 	}
 	return stats, nil
 }
@@ -770,127 +788,6 @@ func getHighestCheckout(ids []int, statisticsMap map[int]*models.StatisticsX01,
 	return err
 }
 
-// GetOfficeStatistics will return office statistics for the given period
-func GetOfficeStatistics(from string, to string) ([]*models.OfficeStatistics, error) {
-	rows, err := models.DB.Query(`
-		SELECT
-			player_id,
-			leg_id,
-			office_id,
-			MAX(checkout) AS 'checkout',
-			first_dart, first_dart_multiplier,
-			second_dart, second_dart_multiplier,
-			third_dart, third_dart_multiplier
-		FROM (
-			SELECT s.player_id,
-					s.leg_id,
-					m.office_id,
-					s.first_dart * s.first_dart_multiplier +
-						IFNULL(s.second_dart * s.second_dart_multiplier, 0) +
-						IFNULL(s.third_dart * s.third_dart_multiplier, 0) AS 'checkout',
-					s.first_dart, s.first_dart_multiplier,
-					s.second_dart, s.second_dart_multiplier,
-					s.third_dart, s.third_dart_multiplier
-			FROM score s
-				JOIN leg l ON l.id = s.leg_id
-				JOIN matches m ON m.id = l.match_id
-				JOIN player p ON s.player_id = p.id
-				JOIN statistics_x01 x on l.id = x.leg_id
-			WHERE s.id IN (
-				SELECT MAX(id) FROM score
-				WHERE leg_id IN (
-					SELECT id FROM leg WHERE match_id IN (
-						SELECT m.id FROM matches m WHERE m.match_type_id = 1
-						AND m.is_finished = 1 AND m.updated_at >= ? AND m.updated_at < ?)
-					AND (leg_type_id = 1 OR leg_type_id IS NULL))
-				GROUP BY leg_id)
-				AND x.checkout IS NOT NULL
-			ORDER BY checkout DESC, leg_id
-		) checkouts
-		GROUP BY player_id, office_id, checkout
-		ORDER BY checkout DESC, leg_id`, from, to)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	stats := make([]*models.OfficeStatistics, 0)
-	for rows.Next() {
-		s := new(models.OfficeStatistics)
-		first := new(models.Dart)
-		second := new(models.Dart)
-		third := new(models.Dart)
-		err := rows.Scan(&s.PlayerID, &s.LegID, &s.OfficeID, &s.Checkout, &first.Value, &first.Multiplier,
-			&second.Value, &second.Multiplier, &third.Value, &third.Multiplier)
-		if err != nil {
-			return nil, err
-		}
-		darts := make([]*models.Dart, 0)
-		s.Darts = append(darts, first, second, third)
-		stats = append(stats, s)
-	}
-	return stats, nil
-}
-
-// GetOfficeStatisticsForOffice will return office statistics for the given office and period
-func GetOfficeStatisticsForOffice(officeID int, from string, to string) ([]*models.OfficeStatistics, error) {
-	rows, err := models.DB.Query(`
-		SELECT
-			player_id,
-			leg_id,
-			office_id,
-			MAX(checkout) AS 'checkout',
-			first_dart, first_dart_multiplier,
-			second_dart, second_dart_multiplier,
-			third_dart, third_dart_multiplier
-		FROM (
-			SELECT s.player_id,
-					s.leg_id,
-					m.office_id,
-					s.first_dart * s.first_dart_multiplier +
-					IFNULL(s.second_dart * s.second_dart_multiplier, 0) +
-					IFNULL(s.third_dart * s.third_dart_multiplier, 0) AS 'checkout',
-					s.first_dart, s.first_dart_multiplier,
-					s.second_dart, s.second_dart_multiplier,
-					s.third_dart, s.third_dart_multiplier
-			FROM score s
-					JOIN leg l ON l.id = s.leg_id
-					JOIN matches m ON m.id = l.match_id
-					JOIN player p ON s.player_id = p.id
-			WHERE s.id IN (
-				SELECT MAX(id) FROM score
-				WHERE leg_id IN (
-					SELECT id FROM leg WHERE match_id IN (
-						SELECT m.id FROM matches m WHERE m.office_id = ? AND m.match_type_id = 1
-						AND m.is_practice = 0 AND m.is_finished = 1 AND m.updated_at >= ? AND m.updated_at < ?))
-				GROUP BY leg_id)
-			ORDER BY checkout DESC, leg_id
-		) checkouts
-		GROUP BY player_id, office_id, checkout
-		ORDER BY checkout DESC, leg_id`, officeID, from, to)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	stats := make([]*models.OfficeStatistics, 0)
-	for rows.Next() {
-		s := new(models.OfficeStatistics)
-		first := new(models.Dart)
-		second := new(models.Dart)
-		third := new(models.Dart)
-		err := rows.Scan(&s.PlayerID, &s.LegID, &s.OfficeID, &s.Checkout, &first.Value, &first.Multiplier,
-			&second.Value, &second.Multiplier, &third.Value, &third.Multiplier)
-		if err != nil {
-			return nil, err
-		}
-		darts := make([]*models.Dart, 0)
-		s.Darts = append(darts, first, second, third)
-		stats = append(stats, s)
-	}
-	return stats, nil
-}
-
 // RecalculateX01Statistics will recalculate x01 statistics for all legs
 func RecalculateX01Statistics(legs []int) ([]string, error) {
 	queries := make([]string, 0)
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"log"
@@ -9,6 +10,9 @@ import (
 	"time"
 
 	"github.com/guregu/null"
+	"github.com/jmoiron/sqlx"
+	"github.com/kcapp/api/cache"
+	"github.com/kcapp/api/logging"
 	"github.com/kcapp/api/models"
 	"github.com/kcapp/api/util"
 )
@@ -147,6 +151,79 @@ func GetTournament(id int) (*models.Tournament, error) {
 	return tournament, nil
 }
 
+// GetTournamentsByID returns a map of the tournaments with the given IDs, including standings of finished tournaments.
+// Playoff tournaments and presets are not loaded
+func GetTournamentsByID(ids []int) (map[int]*models.Tournament, error) {
+	q, args, err := sqlx.In(`
+		SELECT
+			id, name, short_name, is_finished, is_season, is_playoffs, playoffs_tournament_id, preset_id, manual_admin, office_id, start_time, end_time
+		FROM tournament t WHERE t.id IN (?)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tournaments := make(map[int]*models.Tournament)
+	finished := make([]int, 0)
+	for rows.Next() {
+		tournament := new(models.Tournament)
+		err := rows.Scan(&tournament.ID, &tournament.Name, &tournament.ShortName, &tournament.IsFinished, &tournament.IsSeason,
+			&tournament.IsPlayoffs, &tournament.PlayoffsTournamentID, &tournament.PresetID, &tournament.ManualAdmin, &tournament.OfficeID,
+			&tournament.StartTime, &tournament.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		tournaments[tournament.ID] = tournament
+		if tournament.IsFinished {
+			finished = append(finished, tournament.ID)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(finished) == 0 {
+		return tournaments, nil
+	}
+
+	q, args, err = sqlx.In(`
+		SELECT
+			t.id, t.name, p.id, CONCAT(p.first_name, ' ', IFNULL(p.last_name, "")), ts.rank, ts.elo
+		FROM tournament_standings ts
+			JOIN player p ON p.id = ts.player_id
+			JOIN tournament t ON t.id = ts.tournament_id
+		WHERE ts.tournament_id IN (?)
+		ORDER BY ts.tournament_id, ts.rank`, finished)
+	if err != nil {
+		return nil, err
+	}
+	standings, err := models.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer standings.Close()
+
+	for _, id := range finished {
+		tournaments[id].Standings = make([]*models.TournamentStanding, 0)
+	}
+	for standings.Next() {
+		ts := new(models.TournamentStanding)
+		err := standings.Scan(&ts.TournamentID, &ts.TournamentName, &ts.PlayerID, &ts.PlayerName, &ts.Rank, &ts.Elo)
+		if err != nil {
+			return nil, err
+		}
+		tournament := tournaments[ts.TournamentID]
+		tournament.Standings = append(tournament.Standings, ts)
+	}
+	if err = standings.Err(); err != nil {
+		return nil, err
+	}
+	return tournaments, nil
+}
+
 // GetCurrentTournament will return the current active tournament
 func GetCurrentTournament() (*models.Tournament, error) {
 	var tournamentID int
@@ -837,7 +914,7 @@ func NewTournament(tournament models.Tournament) (*models.Tournament, error) {
 }
 
 // GenerateTournament generates a new tournament
-func GenerateTournament(input models.GenerateTournamentInput) (*models.Tournament, error) {
+func GenerateTournament(ctx context.Context, input models.GenerateTournamentInput) (*models.Tournament, error) {
 	officeID := input.OfficeID
 	tournament, err := NewTournament(models.Tournament{
 		Name:        input.Name,
@@ -868,7 +945,7 @@ func GenerateTournament(input models.GenerateTournamentInput) (*models.Tournamen
 			if value, ok := input.Venues[players[i].TournamentGroupID]; ok {
 				venue = null.IntFrom(int64(value))
 			}
-			match, err := NewMatch(models.Match{
+			match, err := NewMatch(ctx, models.Match{
 				MatchType:    &matchType,
 				MatchMode:    &matchMode,
 				VenueID:      venue,
@@ -891,14 +968,14 @@ func GenerateTournament(input models.GenerateTournamentInput) (*models.Tournamen
 			if err != nil {
 				return nil, err
 			}
-			log.Printf("Generated Match %d for %d vs %d", match.ID, players[i].PlayerID, players[j].PlayerID)
+			logging.Logger(ctx).Printf("Generated Match %d for %d vs %d", match.ID, players[i].PlayerID, players[j].PlayerID)
 		}
 	}
 	return tournament, nil
 }
 
 // GeneratePlayoffsTournament generates playoffs matches for the given tournament
-func GeneratePlayoffsTournament(tournamentID int, input models.GeneratePlayoffsInput) (*models.Tournament, error) {
+func GeneratePlayoffsTournament(ctx context.Context, tournamentID int, input models.GeneratePlayoffsInput) (*models.Tournament, error) {
 	tournament, err := GetTournament(tournamentID)
 	if err != nil {
 		return nil, err
@@ -1003,7 +1080,7 @@ func GeneratePlayoffsTournament(tournamentID int, input models.GeneratePlayoffsI
 
 	matches := make([]*models.Match, 0)
 	// Create Grand Final
-	match, err := createTournamentMatch(playoffs.ID, []int{placeholderHomeID, placeholderAwayID}, startingScore, venueID,
+	match, err := createTournamentMatch(ctx, playoffs.ID, []int{placeholderHomeID, placeholderAwayID}, startingScore, venueID,
 		tournament.OfficeID, matchType, input.MatchModeGFID, maxRounds)
 	if err != nil {
 		return nil, err
@@ -1012,7 +1089,7 @@ func GeneratePlayoffsTournament(tournamentID int, input models.GeneratePlayoffsI
 
 	// Create Semi Final Matches
 	if numPlayers > 4 {
-		semis, err := createTournamentMatches(2, playoffs.ID, []int{placeholderHomeID, placeholderAwayID}, startingScore, venueID,
+		semis, err := createTournamentMatches(ctx, 2, playoffs.ID, []int{placeholderHomeID, placeholderAwayID}, startingScore, venueID,
 			tournament.OfficeID, matchType, input.MatchModeSFID, maxRounds)
 		if err != nil {
 			return nil, err
@@ -1038,7 +1115,7 @@ func GeneratePlayoffsTournament(tournamentID int, input models.GeneratePlayoffsI
 				// Walkover, so use placeholder
 				away = walkoverPlayerID
 			}
-			match, err := createTournamentMatch(playoffs.ID, []int{home, away}, startingScore, venueID,
+			match, err := createTournamentMatch(ctx, playoffs.ID, []int{home, away}, startingScore, venueID,
 				tournament.OfficeID, matchType, input.MatchModeSFID, maxRounds)
 			if err != nil {
 				return nil, err
@@ -1049,7 +1126,7 @@ func GeneratePlayoffsTournament(tournamentID int, input models.GeneratePlayoffsI
 
 	// Create Quarter Final Matches
 	if numPlayers > 8 {
-		quarters, err := createTournamentMatches(4, playoffs.ID, []int{placeholderHomeID, placeholderAwayID}, startingScore, venueID,
+		quarters, err := createTournamentMatches(ctx, 4, playoffs.ID, []int{placeholderHomeID, placeholderAwayID}, startingScore, venueID,
 			tournament.OfficeID, matchType, input.MatchModeQFID, maxRounds)
 		if err != nil {
 			return nil, err
@@ -1069,7 +1146,7 @@ func GeneratePlayoffsTournament(tournamentID int, input models.GeneratePlayoffsI
 				// Walkover, so use placeholder
 				away = walkoverPlayerID
 			}
-			match, err := createTournamentMatch(playoffs.ID, []int{home, away}, startingScore, venueID,
+			match, err := createTournamentMatch(ctx, playoffs.ID, []int{home, away}, startingScore, venueID,
 				tournament.OfficeID, matchType, input.MatchModeLast16ID, maxRounds)
 			if err != nil {
 				return nil, err
@@ -1078,7 +1155,7 @@ func GeneratePlayoffsTournament(tournamentID int, input models.GeneratePlayoffsI
 		}
 	} else {
 		for i := 0; i < 4; i++ {
-			log.Printf("Quarter Final %d", i)
+			logging.Logger(ctx).Printf("Quarter Final %d", i)
 			temp := models.TournamentTemplateQuarterFinals[i]
 			g2 := group2
 			if len(g2) == 0 {
@@ -1096,7 +1173,7 @@ func GeneratePlayoffsTournament(tournamentID int, input models.GeneratePlayoffsI
 				// Walkover, so use placeholder
 				away = walkoverPlayerID
 			}
-			match, err := createTournamentMatch(playoffs.ID, []int{home, away}, startingScore, venueID,
+			match, err := createTournamentMatch(ctx, playoffs.ID, []int{home, away}, startingScore, venueID,
 				tournament.OfficeID, matchType, input.MatchModeQFID, maxRounds)
 			if err != nil {
 				return nil, err
@@ -1181,13 +1258,14 @@ func GeneratePlayoffsTournament(tournamentID int, input models.GeneratePlayoffsI
 			}
 		}
 	}
+	cache.Invalidate(cache.TournamentTag(tournamentID), cache.TournamentTag(playoffs.ID))
 	return GetTournament(playoffs.ID)
 }
 
-func createTournamentMatches(num int, tournamentID int, players []int, startingScore int, venueID null.Int, officeID int, matchType *models.MatchType, matchModeID int, maxRounds null.Int) ([]*models.Match, error) {
+func createTournamentMatches(ctx context.Context, num int, tournamentID int, players []int, startingScore int, venueID null.Int, officeID int, matchType *models.MatchType, matchModeID int, maxRounds null.Int) ([]*models.Match, error) {
 	matches := make([]*models.Match, 0)
 	for i := 0; i < num; i++ {
-		match, err := createTournamentMatch(tournamentID, players, startingScore, venueID, officeID, matchType, matchModeID, maxRounds)
+		match, err := createTournamentMatch(ctx, tournamentID, players, startingScore, venueID, officeID, matchType, matchModeID, maxRounds)
 		if err != nil {
 			return nil, err
 		}
@@ -1196,8 +1274,8 @@ func createTournamentMatches(num int, tournamentID int, players []int, startingS
 	return matches, nil
 }
 
-func createTournamentMatch(tournamentID int, players []int, startingScore int, venueID null.Int, officeID int, matchType *models.MatchType, matchModeID int, maxRounds null.Int) (*models.Match, error) {
-	match, err := NewMatch(models.Match{
+func createTournamentMatch(ctx context.Context, tournamentID int, players []int, startingScore int, venueID null.Int, officeID int, matchType *models.MatchType, matchModeID int, maxRounds null.Int) (*models.Match, error) {
+	match, err := NewMatch(ctx, models.Match{
 		MatchType:    matchType,
 		MatchMode:    &models.MatchMode{ID: matchModeID},
 		VenueID:      venueID,
@@ -1215,7 +1293,7 @@ func createTournamentMatch(tournamentID int, players []int, startingScore int, v
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Generated Match %d for %d vs %d", match.ID, players[0], players[1])
+	logging.Logger(ctx).Printf("Generated Match %d for %d vs %d", match.ID, players[0], players[1])
 
 	return match, nil
 }
@@ -1350,7 +1428,7 @@ func GetTournamentGroupPlayers(tournamentID int, groupID int) ([]*models.Player2
 	return players, nil
 }
 
-func AddPlayerToTournament(playerID int, tournamentGroupID int, tournamentID int) ([]*models.Match, error) {
+func AddPlayerToTournament(ctx context.Context, playerID int, tournamentGroupID int, tournamentID int) ([]*models.Match, error) {
 	tournament, err := GetTournament(tournamentID)
 	if err != nil {
 		return nil, err
@@ -1378,7 +1456,7 @@ func AddPlayerToTournament(playerID int, tournamentGroupID int, tournamentID int
 	// Create matches for new player
 	matches := make([]*models.Match, 0)
 	for i := 0; i < len(players); i++ {
-		match, err := NewMatch(models.Match{
+		match, err := NewMatch(ctx, models.Match{
 			MatchType: tournament.Preset.MatchType,
 			MatchMode: tournament.Preset.MatchMode,
 			//VenueID:      1,
@@ -1393,9 +1471,10 @@ func AddPlayerToTournament(playerID int, tournamentGroupID int, tournamentID int
 		if err != nil {
 			return nil, err
 		}
-		log.Printf("Generated Match %d for %d vs %d", match.ID, players[i].PlayerID, playerID)
+		logging.Logger(ctx).Printf("Generated Match %d for %d vs %d", match.ID, players[i].PlayerID, playerID)
 		matches = append(matches, match)
 	}
+	cache.Invalidate(cache.TournamentTag(tournamentID), cache.PlayerTag(playerID))
 	return matches, nil
 }
 
@@ -1488,6 +1567,7 @@ func FinishTournament(tournamentID int64) error {
 
 	log.Printf("Finished tournament (%d)", tournamentID)
 	tx.Commit()
+	cache.Invalidate(cache.TournamentTag(int(tournamentID)))
 	return nil
 }
 
@@ -0,0 +1,73 @@
+package data_v2
+
+import "github.com/kcapp/api/models"
+
+// GetBadgeUnlocks returns a page of unlocks of the given badge
+func GetBadgeUnlocks(badgeID int, opts *models.ListOptions) (*models.Page, error) {
+	q, err := newListQuery(opts, `
+		FROM player2badge p2b
+			LEFT JOIN badge b ON b.id = p2b.badge_id
+			LEFT JOIN score s on s.id = p2b.visit_id
+			LEFT JOIN player p on p.id = p2b.player_id`,
+		// Players can unlock each level of a badge once, so player and level identifies a single unlock
+		"CONCAT(LPAD(p2b.player_id, 10, '0'), LPAD(IFNULL(p2b.level, 0), 5, '0'))",
+		map[string]string{
+			"created_at": "p2b.created_at",
+			"level":      "IFNULL(p2b.level, 0)",
+		},
+		map[string]string{
+			models.FilterOffice:     "p.office_id = ?",
+			models.FilterPlayer:     "p2b.player_id = ?",
+			models.FilterTournament: "p2b.tournament_id = ?",
+			models.FilterFrom:       "p2b.created_at >= ?",
+			models.FilterTo:         "p2b.created_at < ?",
+		})
+	if err != nil {
+		return nil, &models.ListOptionsError{Err: err}
+	}
+	q.where("p2b.badge_id = ?", badgeID)
+
+	rows, err := q.query(`
+		b.id, b.name, b.description, b.filename,
+		p2b.player_id, p2b.level, p2b.value, p2b.leg_id,
+		p2b.match_id, p2b.tournament_id, p2b.opponent_player_id,
+		p2b.visit_id,
+		s.first_dart, IFNULL(s.first_dart_multiplier, 1),
+		s.second_dart, IFNULL(s.second_dart_multiplier, 1),
+		s.third_dart, IFNULL(s.third_dart_multiplier, 1),
+		p2b.data,
+		p2b.created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	unlocks := make([]interface{}, 0)
+	keys := make([][2]string, 0)
+	for rows.Next() {
+		badge := new(models.PlayerBadge)
+		badge.Badge = new(models.Badge)
+		darts := []*models.Dart{new(models.Dart), new(models.Dart), new(models.Dart)}
+		var key [2]string
+		err := rows.Scan(&badge.Badge.ID, &badge.Badge.Name, &badge.Badge.Description, &badge.Badge.Filename,
+			&badge.PlayerID, &badge.Level, &badge.Value, &badge.LegID,
+			&badge.MatchID, &badge.TournamentID, &badge.OpponentPlayerID,
+			&badge.VisitID,
+			&darts[0].Value, &darts[0].Multiplier,
+			&darts[1].Value, &darts[1].Multiplier,
+			&darts[2].Value, &darts[2].Multiplier,
+			&badge.Data, &badge.CreatedAt, &key[0], &key[1])
+		if err != nil {
+			return nil, err
+		}
+		if badge.VisitID.Valid {
+			badge.Darts = darts
+		}
+		unlocks = append(unlocks, badge)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return q.page(unlocks, keys)
+}
@@ -0,0 +1,67 @@
+package data_v2
+
+import (
+	"github.com/kcapp/api/models"
+	"github.com/kcapp/api/util"
+)
+
+// GetLegs returns a page of legs matching the given options. Visits are not included
+func GetLegs(opts *models.ListOptions) (*models.Page, error) {
+	q, err := newListQuery(opts, `
+		FROM leg l
+			LEFT JOIN player2leg p2l ON p2l.leg_id = l.id
+			LEFT JOIN matches m ON m.id = l.match_id
+			LEFT JOIN match_type mt on mt.id = IFNULL(l.leg_type_id, m.match_type_id)`, "l.id",
+		map[string]string{
+			"id":         "l.id",
+			"created_at": "l.created_at",
+			"end_time":   "IFNULL(l.end_time, l.updated_at)",
+		},
+		map[string]string{
+			models.FilterOffice:     "m.office_id = ?",
+			models.FilterVenue:      "m.venue_id = ?",
+			models.FilterMatchType:  "IFNULL(l.leg_type_id, m.match_type_id) = ?",
+			models.FilterTournament: "m.tournament_id = ?",
+			models.FilterPlayer:     "l.id IN (SELECT leg_id FROM player2leg WHERE player_id = ?)",
+			models.FilterFrom:       "l.created_at >= ?",
+			models.FilterTo:         "l.created_at < ?",
+			models.FilterFinished:   "l.is_finished = ?",
+			models.FilterAbandoned:  "m.is_abandoned = ?",
+		})
+	if err != nil {
+		return nil, &models.ListOptionsError{Err: err}
+	}
+	q.groupBy = "l.id"
+
+	rows, err := q.query(`
+		l.id, l.end_time, l.starting_score, l.is_finished,
+		l.current_player_id, l.winner_id, l.created_at, l.updated_at,
+		l.match_id, l.has_scores, GROUP_CONCAT(p2l.player_id ORDER BY p2l.order ASC) as "players",
+		mt.id as 'match_type_id', mt.name, mt.description`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	legs := make([]interface{}, 0)
+	keys := make([][2]string, 0)
+	for rows.Next() {
+		leg := new(models.Leg)
+		leg.LegType = new(models.MatchType)
+		var players string
+		var key [2]string
+		err := rows.Scan(&leg.ID, &leg.Endtime, &leg.StartingScore, &leg.IsFinished, &leg.CurrentPlayerID,
+			&leg.WinnerPlayerID, &leg.CreatedAt, &leg.UpdatedAt, &leg.MatchID, &leg.HasScores, &players, &leg.LegType.ID,
+			&leg.LegType.Name, &leg.LegType.Description, &key[0], &key[1])
+		if err != nil {
+			return nil, err
+		}
+		leg.Players = util.StringToIntArray(players)
+		legs = append(legs, leg)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return q.page(legs, keys)
+}
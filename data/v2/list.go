@@ -0,0 +1,110 @@
+package data_v2
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/models"
+)
+
+// listQuery is used to build a paginated, filtered and sorted query for a list endpoint.
+// Pagination is keyset based, so the cursor holds the sort value and id of the last returned item
+type listQuery struct {
+	opts       *models.ListOptions
+	from       string
+	groupBy    string
+	id         string
+	sort       string
+	conditions []string
+	args       []interface{}
+}
+
+// newListQuery will create a new query over the given FROM clause. Sorts maps each supported sort option to the
+// column used for sorting, and filters maps each supported filter to a condition taking the filter value as argument
+func newListQuery(opts *models.ListOptions, from string, id string, sorts map[string]string, filters map[string]string) (*listQuery, error) {
+	sort, ok := sorts[opts.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort '%s'", opts.Sort)
+	}
+	q := &listQuery{opts: opts, from: from, id: id, sort: sort}
+	for name, value := range opts.Filters {
+		condition, ok := filters[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported filter '%s'", name)
+		}
+		q.where(condition, value)
+	}
+	return q, nil
+}
+
+// where will add the given condition to the query
+func (q *listQuery) where(condition string, args ...interface{}) {
+	q.conditions = append(q.conditions, condition)
+	q.args = append(q.args, args...)
+}
+
+// whereClause will return the WHERE clause and arguments, optionally including the cursor condition
+func (q *listQuery) whereClause(withCursor bool) (string, []interface{}) {
+	conditions := append([]string{}, q.conditions...)
+	args := append([]interface{}{}, q.args...)
+	if withCursor && q.opts.Cursor != nil {
+		op := ">"
+		if q.opts.Descending {
+			op = "<"
+		}
+		conditions = append(conditions, fmt.Sprintf("(%[1]s %[3]s ? OR (%[1]s = ? AND %[2]s %[3]s ?))", q.sort, q.id, op))
+		args = append(args, q.opts.Cursor.Value, q.opts.Cursor.Value, q.opts.Cursor.ID)
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// count will return the total number of items matching the filters
+func (q *listQuery) count() (int, error) {
+	where, args := q.whereClause(false)
+	var total int
+	err := models.DB.QueryRow(fmt.Sprintf("SELECT COUNT(DISTINCT %s) %s %s", q.id, q.from, where), args...).Scan(&total)
+	if err != nil {
+		return -1, err
+	}
+	return total, nil
+}
+
+// query will execute the query for a single page, selecting the given columns. The sort value and id of each row is
+// selected as the two last columns, and must be scanned by the caller using scanKeys
+func (q *listQuery) query(columns string) (*sql.Rows, error) {
+	where, args := q.whereClause(true)
+	direction := "ASC"
+	if q.opts.Descending {
+		direction = "DESC"
+	}
+	groupBy := ""
+	if q.groupBy != "" {
+		groupBy = "GROUP BY " + q.groupBy
+	}
+	query := fmt.Sprintf("SELECT %s, CAST(%s AS CHAR), CAST(%s AS CHAR) %s %s %s ORDER BY %s %s, %s %s LIMIT ?",
+		columns, q.sort, q.id, q.from, where, groupBy, q.sort, direction, q.id, direction)
+	// Fetch one extra row to know if there is a next page
+	return models.DB.Query(query, append(args, q.opts.Limit+1)...)
+}
+
+// page will return the page of the given items, where keys holds the sort value and id of each item
+func (q *listQuery) page(items []interface{}, keys [][2]string) (*models.Page, error) {
+	total, err := q.count()
+	if err != nil {
+		return nil, err
+	}
+	page := &models.Page{Total: total}
+	if len(items) > q.opts.Limit {
+		items = items[:q.opts.Limit]
+		last := keys[q.opts.Limit-1]
+		cursor := &models.Cursor{Sort: q.opts.SortKey(), Value: last[0], ID: last[1]}
+		page.NextCursor = null.StringFrom(cursor.Encode())
+	}
+	page.Items = items
+	return page, nil
+}
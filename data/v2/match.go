@@ -0,0 +1,89 @@
+package data_v2
+
+import (
+	"github.com/guregu/null"
+	"github.com/kcapp/api/models"
+	"github.com/kcapp/api/util"
+)
+
+// GetMatches returns a page of matches matching the given options
+func GetMatches(opts *models.ListOptions) (*models.Page, error) {
+	q, err := newListQuery(opts, `
+		FROM matches m
+			JOIN match_type mt ON mt.id = m.match_type_id
+			JOIN match_mode mm ON mm.id = m.match_mode_id
+			LEFT JOIN leg l ON l.id = m.current_leg_id
+			LEFT JOIN owe_type ot ON ot.id = m.owe_type_id
+			LEFT JOIN venue v on v.id = m.venue_id
+			LEFT JOIN player2leg p2l ON p2l.match_id = m.id
+			LEFT JOIN leg legs ON legs.id = p2l.leg_id AND legs.winner_id = p2l.player_id`, "m.id",
+		map[string]string{
+			"id":         "m.id",
+			"created_at": "m.created_at",
+			"updated_at": "m.updated_at",
+		},
+		map[string]string{
+			models.FilterOffice:     "m.office_id = ?",
+			models.FilterVenue:      "m.venue_id = ?",
+			models.FilterMatchType:  "m.match_type_id = ?",
+			models.FilterTournament: "m.tournament_id = ?",
+			models.FilterPlayer:     "m.id IN (SELECT match_id FROM player2leg WHERE player_id = ?)",
+			models.FilterFrom:       "m.created_at >= ?",
+			models.FilterTo:         "m.created_at < ?",
+			models.FilterFinished:   "m.is_finished = ?",
+			models.FilterAbandoned:  "m.is_abandoned = ?",
+		})
+	if err != nil {
+		return nil, &models.ListOptionsError{Err: err}
+	}
+	q.where("m.created_at <= NOW() AND m.is_bye <> 1")
+	q.groupBy = "m.id"
+
+	rows, err := q.query(`
+		m.id, m.is_finished, m.is_abandoned, m.is_walkover, m.is_bye, m.current_leg_id, m.winner_id, m.office_id, m.is_practice,
+		m.created_at, m.updated_at, m.owe_type_id, m.venue_id, m.tournament_id, mt.id, mt.name, mt.description, mm.id, mm.name, mm.short_name,
+		mm.wins_required, mm.legs_required, mm.is_draw_possible, mm.is_challenge, ot.id, ot.item, v.id, v.name, v.description,
+		l.updated_at as 'last_throw', GROUP_CONCAT(DISTINCT p2l.player_id ORDER BY p2l.order) AS 'players',
+		GROUP_CONCAT(legs.winner_id ORDER BY legs.id) AS 'legs_won'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]interface{}, 0)
+	keys := make([][2]string, 0)
+	for rows.Next() {
+		m := new(models.Match)
+		m.MatchType = new(models.MatchType)
+		m.MatchMode = new(models.MatchMode)
+		ot := new(models.OweType)
+		venue := new(models.Venue)
+		var players string
+		var legsWon null.String
+		var key [2]string
+		err := rows.Scan(&m.ID, &m.IsFinished, &m.IsAbandoned, &m.IsWalkover, &m.IsBye, &m.CurrentLegID, &m.WinnerID, &m.OfficeID, &m.IsPractice,
+			&m.CreatedAt, &m.UpdatedAt, &m.OweTypeID, &m.VenueID, &m.TournamentID, &m.MatchType.ID, &m.MatchType.Name, &m.MatchType.Description,
+			&m.MatchMode.ID, &m.MatchMode.Name, &m.MatchMode.ShortName, &m.MatchMode.WinsRequired, &m.MatchMode.LegsRequired, &m.MatchMode.IsDrawPossible,
+			&m.MatchMode.IsChallenge, &ot.ID, &ot.Item, &venue.ID, &venue.Name, &venue.Description, &m.LastThrow, &players, &legsWon,
+			&key[0], &key[1])
+		if err != nil {
+			return nil, err
+		}
+		if m.OweTypeID.Valid {
+			m.OweType = ot
+		}
+		if m.VenueID.Valid {
+			m.Venue = venue
+		}
+		m.Players = util.StringToIntArray(players)
+		if legsWon.Valid {
+			m.LegsWon = util.StringToIntArray(legsWon.String)
+		}
+		matches = append(matches, m)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return q.page(matches, keys)
+}
@@ -80,3 +80,122 @@ func GetMatchesPlayedPerPlayer() (map[int]*models.Player, error) {
 	}
 	return played, nil
 }
+
+// GetPlayersPage returns a page of players matching the given options
+func GetPlayersPage(opts *models.ListOptions) (*models.Page, error) {
+	q, err := newListQuery(opts, "FROM player p", "p.id",
+		map[string]string{
+			"id":         "p.id",
+			"name":       "CONCAT(p.first_name, ' ', IFNULL(p.last_name, ''))",
+			"created_at": "p.created_at",
+		},
+		map[string]string{
+			models.FilterOffice:     "p.office_id = ?",
+			models.FilterTournament: "p.id IN (SELECT player_id FROM player2tournament WHERE tournament_id = ?)",
+			models.FilterFrom:       "p.created_at >= ?",
+			models.FilterTo:         "p.created_at < ?",
+		})
+	if err != nil {
+		return nil, &models.ListOptionsError{Err: err}
+	}
+	played, err := GetMatchesPlayedPerPlayer()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.query(`
+		p.id, p.first_name, p.last_name, p.vocal_name, p.nickname, p.slack_handle, p.color, p.profile_pic_url, p.smartcard_uid,
+		p.board_stream_url, p.board_stream_css, p.active, p.office_id, p.is_bot, p.is_placeholder, p.created_at, p.updated_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := make([]interface{}, 0)
+	keys := make([][2]string, 0)
+	for rows.Next() {
+		p := new(models.Player)
+		var key [2]string
+		err := rows.Scan(&p.ID, &p.FirstName, &p.LastName, &p.VocalName, &p.Nickname, &p.SlackHandle, &p.Color, &p.ProfilePicURL,
+			&p.SmartcardUID, &p.BoardStreamURL, &p.BoardStreamCSS, &p.IsActive, &p.OfficeID, &p.IsBot, &p.IsPlaceholder, &p.CreatedAt, &p.UpdatedAt,
+			&key[0], &key[1])
+		if err != nil {
+			return nil, err
+		}
+		if val, ok := played[p.ID]; ok {
+			p.MatchesPlayed = val.MatchesPlayed
+			p.MatchesWon = val.MatchesWon
+			p.LegsPlayed = val.LegsPlayed
+			p.LegsWon = val.LegsWon
+		}
+		players = append(players, p)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return q.page(players, keys)
+}
+
+// GetPlayerEloChangelog returns a page of the Elo changelog for the given player
+func GetPlayerEloChangelog(playerID int, opts *models.ListOptions) (*models.Page, error) {
+	q, err := newListQuery(opts, `
+		FROM player_elo_changelog home
+			JOIN player_elo_changelog away ON away.match_id = home.match_id AND away.player_id <> home.player_id
+			JOIN matches m on m.id = home.match_id
+			JOIN match_type mt on m.match_type_id = mt.id
+			JOIN match_mode mm on m.match_mode_id = mm.id`, "home.id",
+		map[string]string{
+			"id":          "home.id",
+			"finished_at": "m.updated_at",
+		},
+		map[string]string{
+			models.FilterOffice:     "m.office_id = ?",
+			models.FilterMatchType:  "m.match_type_id = ?",
+			models.FilterTournament: "m.tournament_id = ?",
+			models.FilterPlayer:     "away.player_id = ?",
+			models.FilterFrom:       "m.updated_at >= ?",
+			models.FilterTo:         "m.updated_at < ?",
+		})
+	if err != nil {
+		return nil, &models.ListOptionsError{Err: err}
+	}
+	q.where("home.player_id = ?", playerID)
+
+	rows, err := q.query(`
+		home.id, home.match_id, m.updated_at,
+		if(m.tournament_id is null, false, true) as 'is_official',
+		mm.short_name as 'match_mode',
+		mt.name as 'match_type', m.winner_id,
+		home.player_id, home.old_elo, home.new_elo, home.old_tournament_elo, home.new_tournament_elo,
+		away.player_id, away.old_elo, away.new_elo, away.old_tournament_elo, away.new_tournament_elo`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changelog := make([]interface{}, 0)
+	keys := make([][2]string, 0)
+	for rows.Next() {
+		change := new(models.PlayerEloChangelog)
+		home := new(models.PlayerElo)
+		away := new(models.PlayerElo)
+		var key [2]string
+		err := rows.Scan(&change.ID, &change.MatchID, &change.FinishedAt, &change.IsOfficial, &change.MatchMode,
+			&change.MatchType, &change.WinnerID,
+			&home.PlayerID, &home.CurrentElo, &home.CurrentEloNew, &home.TournamentElo, &home.TournamentEloNew,
+			&away.PlayerID, &away.CurrentElo, &away.CurrentEloNew, &away.TournamentElo, &away.TournamentEloNew,
+			&key[0], &key[1])
+		if err != nil {
+			return nil, err
+		}
+		change.HomePlayer = home
+		change.AwayPlayer = away
+		changelog = append(changelog, change)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return q.page(changelog, keys)
+}
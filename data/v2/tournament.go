@@ -0,0 +1,50 @@
+package data_v2
+
+import "github.com/kcapp/api/models"
+
+// GetTournaments returns a page of tournaments matching the given options
+func GetTournaments(opts *models.ListOptions) (*models.Page, error) {
+	q, err := newListQuery(opts, "FROM tournament t", "t.id",
+		map[string]string{
+			"id":         "t.id",
+			"name":       "t.name",
+			"start_time": "IFNULL(t.start_time, '1970-01-01 00:00:00')",
+		},
+		map[string]string{
+			models.FilterOffice:   "t.office_id = ?",
+			models.FilterPlayer:   "t.id IN (SELECT tournament_id FROM player2tournament WHERE player_id = ?)",
+			models.FilterFrom:     "t.start_time >= ?",
+			models.FilterTo:       "t.start_time < ?",
+			models.FilterFinished: "t.is_finished = ?",
+		})
+	if err != nil {
+		return nil, &models.ListOptionsError{Err: err}
+	}
+
+	rows, err := q.query(`
+		t.id, t.name, t.short_name, t.is_finished, t.is_season, t.is_playoffs, t.playoffs_tournament_id, t.preset_id, t.manual_admin,
+		t.office_id, t.start_time, t.end_time`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tournaments := make([]interface{}, 0)
+	keys := make([][2]string, 0)
+	for rows.Next() {
+		tournament := new(models.Tournament)
+		var key [2]string
+		err := rows.Scan(&tournament.ID, &tournament.Name, &tournament.ShortName, &tournament.IsFinished, &tournament.IsSeason, &tournament.IsPlayoffs,
+			&tournament.PlayoffsTournamentID, &tournament.PresetID, &tournament.ManualAdmin, &tournament.OfficeID, &tournament.StartTime,
+			&tournament.EndTime, &key[0], &key[1])
+		if err != nil {
+			return nil, err
+		}
+		tournaments = append(tournaments, tournament)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return q.page(tournaments, keys)
+}
@@ -0,0 +1,305 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/models"
+)
+
+var (
+	queueSubscribersMu sync.Mutex
+	queueSubscribers   = make(map[int]map[chan struct{}]bool)
+)
+
+// GetVenueQueue will return the match now playing at the given venue, and the matches queued up next
+func GetVenueQueue(venueID int) (*models.VenueQueue, error) {
+	queue := &models.VenueQueue{VenueID: venueID, UpNext: make([]*models.QueuedMatch, 0)}
+
+	active, err := SpectateVenue(venueID)
+	if err != nil {
+		return nil, err
+	}
+	if len(active) > 0 {
+		queue.NowPlaying, err = GetMatch(active[0].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := models.DB.Query(`
+		SELECT q.match_id
+		FROM venue_queue q
+			JOIN matches m ON m.id = q.match_id
+		WHERE q.venue_id = ? AND m.is_finished = 0
+		ORDER BY q.position`, venueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matchIDs := make([]int, 0)
+	for rows.Next() {
+		var matchID int
+		err := rows.Scan(&matchID)
+		if err != nil {
+			return nil, err
+		}
+		matchIDs = append(matchIDs, matchID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, matchID := range matchIDs {
+		if queue.NowPlaying != nil && queue.NowPlaying.ID == matchID {
+			continue
+		}
+		match, err := GetMatch(matchID)
+		if err != nil {
+			return nil, err
+		}
+		queue.UpNext = append(queue.UpNext, &models.QueuedMatch{Position: len(queue.UpNext) + 1, Match: match})
+	}
+
+	legDuration, err := getAverageLegDuration(venueID)
+	if err != nil {
+		return nil, err
+	}
+	queue.Estimate(legDuration, time.Now())
+	return queue, nil
+}
+
+// getAverageLegDuration will return the average duration of the last legs played at the given venue
+func getAverageLegDuration(venueID int) (time.Duration, error) {
+	var seconds null.Float
+	err := models.DB.QueryRow(`
+		SELECT AVG(duration) FROM (
+			SELECT TIMESTAMPDIFF(SECOND, l.created_at, l.end_time) AS 'duration'
+			FROM leg l
+				JOIN matches m ON m.id = l.match_id
+			WHERE m.venue_id = ? AND l.is_finished = 1 AND l.has_scores = 1 AND l.end_time IS NOT NULL
+				AND TIMESTAMPDIFF(SECOND, l.created_at, l.end_time) BETWEEN 1 AND 3600
+			ORDER BY l.id DESC
+			LIMIT 20
+		) legs`, venueID).Scan(&seconds)
+	if err != nil {
+		return 0, err
+	}
+	if !seconds.Valid {
+		return models.DefaultLegDuration, nil
+	}
+	return time.Duration(seconds.Float64 * float64(time.Second)), nil
+}
+
+// EnqueueVenueMatch will add the given match to the end of the queue of a venue. If no venue is given, the venue of
+// the match office which is expected to be free first is used
+func EnqueueVenueMatch(input models.VenueQueueInput) (*models.VenueQueue, error) {
+	match, err := GetMatch(input.MatchID)
+	if err != nil {
+		return nil, err
+	}
+	if match.IsFinished {
+		return nil, &models.VenueQueueError{Err: fmt.Errorf("match %d is already finished", match.ID)}
+	}
+
+	venueID := int(input.VenueID.Int64)
+	if !input.VenueID.Valid {
+		if !match.OfficeID.Valid {
+			return nil, &models.VenueQueueError{Err: fmt.Errorf("match %d does not belong to an office", match.ID)}
+		}
+		venueID, err = nextFreeVenue(int(match.OfficeID.Int64))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var previousVenue null.Int
+	err = models.DB.QueryRow("SELECT venue_id FROM venue_queue WHERE match_id = ?", match.ID).Scan(&previousVenue)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	_, err = models.DB.Exec(`
+		INSERT INTO venue_queue (venue_id, match_id, position, created_at)
+			SELECT ?, ?, IFNULL(MAX(position), 0) + 1, NOW() FROM venue_queue WHERE venue_id = ?
+		ON DUPLICATE KEY UPDATE venue_id = VALUES(venue_id), position = VALUES(position)`, venueID, match.ID, venueID)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Queued match %d at venue %d", match.ID, venueID)
+
+	if previousVenue.Valid && int(previousVenue.Int64) != venueID {
+		notifyVenueQueue(int(previousVenue.Int64))
+	}
+	notifyVenueQueue(venueID)
+	return GetVenueQueue(venueID)
+}
+
+// nextFreeVenue will return the venue of the given office which is expected to be free first
+func nextFreeVenue(officeID int) (int, error) {
+	venues, err := GetVenues()
+	if err != nil {
+		return 0, err
+	}
+	venueID := 0
+	shortestWait := 0
+	for _, venue := range venues {
+		if int(venue.OfficeID.Int64) != officeID {
+			continue
+		}
+		queue, err := GetVenueQueue(int(venue.ID.Int64))
+		if err != nil {
+			return 0, err
+		}
+		if venueID == 0 || queue.EstimatedWait < shortestWait {
+			venueID = int(venue.ID.Int64)
+			shortestWait = queue.EstimatedWait
+		}
+	}
+	if venueID == 0 {
+		return 0, &models.VenueQueueError{Err: fmt.Errorf("office %d does not have any venues", officeID)}
+	}
+	return venueID, nil
+}
+
+// ReorderVenueQueue will move the given matches to the front of the queue, in the given order. Matches not given keep
+// their relative order after them
+func ReorderVenueQueue(venueID int, order models.VenueQueueOrder) (*models.VenueQueue, error) {
+	rows, err := models.DB.Query("SELECT match_id FROM venue_queue WHERE venue_id = ? ORDER BY position", venueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	queued := make(map[int]bool)
+	remaining := make([]int, 0)
+	for rows.Next() {
+		var matchID int
+		err := rows.Scan(&matchID)
+		if err != nil {
+			return nil, err
+		}
+		queued[matchID] = true
+		remaining = append(remaining, matchID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ordered := make([]int, 0, len(remaining))
+	moved := make(map[int]bool)
+	for _, matchID := range order.MatchIDs {
+		if !queued[matchID] {
+			return nil, &models.VenueQueueError{Err: fmt.Errorf("match %d is not queued at venue %d", matchID, venueID)}
+		}
+		if !moved[matchID] {
+			ordered = append(ordered, matchID)
+			moved[matchID] = true
+		}
+	}
+	for _, matchID := range remaining {
+		if !moved[matchID] {
+			ordered = append(ordered, matchID)
+		}
+	}
+
+	err = models.Transaction(models.DB, func(tx *sql.Tx) error {
+		for i, matchID := range ordered {
+			_, err := tx.Exec("UPDATE venue_queue SET position = ? WHERE venue_id = ? AND match_id = ?", i+1, venueID, matchID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Reordered queue of venue %d", venueID)
+
+	notifyVenueQueue(venueID)
+	return GetVenueQueue(venueID)
+}
+
+// DequeueVenueMatch will remove the given match from the queue of the given venue
+func DequeueVenueMatch(venueID int, matchID int) error {
+	res, err := models.DB.Exec("DELETE FROM venue_queue WHERE venue_id = ? AND match_id = ?", venueID, matchID)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return &models.VenueQueueError{Err: fmt.Errorf("match %d is not queued at venue %d", matchID, venueID)}
+	}
+	log.Printf("Removed match %d from queue of venue %d", matchID, venueID)
+
+	notifyVenueQueue(venueID)
+	return nil
+}
+
+// removeFromVenueQueue will remove a finished match from any venue queue
+func removeFromVenueQueue(ctx context.Context, matchID int) error {
+	var venueID int
+	err := models.DB.QueryRow("SELECT venue_id FROM venue_queue WHERE match_id = ?", matchID).Scan(&venueID)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return DequeueVenueMatch(venueID, matchID)
+}
+
+// SubscribeVenueQueue will return a channel which receives a value every time the queue of the given venue changes,
+// and a function to unsubscribe. The channel is closed when all subscriptions are closed
+func SubscribeVenueQueue(venueID int) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	queueSubscribersMu.Lock()
+	defer queueSubscribersMu.Unlock()
+	if queueSubscribers[venueID] == nil {
+		queueSubscribers[venueID] = make(map[chan struct{}]bool)
+	}
+	queueSubscribers[venueID][ch] = true
+
+	return ch, func() {
+		queueSubscribersMu.Lock()
+		defer queueSubscribersMu.Unlock()
+		if queueSubscribers[venueID][ch] {
+			delete(queueSubscribers[venueID], ch)
+			close(ch)
+		}
+	}
+}
+
+// CloseVenueQueueSubscriptions will close all subscriptions, used when shutting down
+func CloseVenueQueueSubscriptions() {
+	queueSubscribersMu.Lock()
+	defer queueSubscribersMu.Unlock()
+	for venueID, subscribers := range queueSubscribers {
+		for ch := range subscribers {
+			close(ch)
+		}
+		delete(queueSubscribers, venueID)
+	}
+}
+
+// notifyVenueQueue will notify all subscribers of the given venue that the queue changed
+func notifyVenueQueue(venueID int) {
+	queueSubscribersMu.Lock()
+	defer queueSubscribersMu.Unlock()
+	for ch := range queueSubscribers[venueID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// Subscriber already has a pending notification
+		}
+	}
+}
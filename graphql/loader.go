@@ -0,0 +1,247 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+)
+
+type contextKey struct{}
+
+// loader will batch and cache all loads of a single kind of entity during a request. Keys are queued when a field
+// is resolved, and fetched together the first time any of the returned thunks is called
+type loader struct {
+	mu      sync.Mutex
+	fetch   func(keys []int) (map[int]interface{}, error)
+	loaded  map[int]bool
+	values  map[int]interface{}
+	errs    map[int]error
+	pending []int
+}
+
+func newLoader(fetch func(keys []int) (map[int]interface{}, error)) *loader {
+	return &loader{
+		fetch:  fetch,
+		loaded: make(map[int]bool),
+		values: make(map[int]interface{}),
+		errs:   make(map[int]error),
+	}
+}
+
+// load will queue the given key, and return a thunk resolving the value once all queued keys have been fetched
+func (l *loader) load(key int) func() (interface{}, error) {
+	l.mu.Lock()
+	if !l.loaded[key] {
+		queued := false
+		for _, k := range l.pending {
+			if k == key {
+				queued = true
+				break
+			}
+		}
+		if !queued {
+			l.pending = append(l.pending, key)
+		}
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if !l.loaded[key] {
+			l.dispatch()
+		}
+		return l.values[key], l.errs[key]
+	}
+}
+
+// loadMany will queue all given keys, and return a thunk resolving the values in the same order
+func (l *loader) loadMany(keys []int) func() (interface{}, error) {
+	thunks := make([]func() (interface{}, error), len(keys))
+	for i, key := range keys {
+		thunks[i] = l.load(key)
+	}
+	return func() (interface{}, error) {
+		values := make([]interface{}, 0, len(keys))
+		for _, thunk := range thunks {
+			value, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+			if value != nil {
+				values = append(values, value)
+			}
+		}
+		return values, nil
+	}
+}
+
+// dispatch will fetch all pending keys in a single batch. Must be called with the lock held
+func (l *loader) dispatch() {
+	keys := l.pending
+	l.pending = nil
+	values, err := l.fetch(keys)
+	for _, key := range keys {
+		l.loaded[key] = true
+		if err != nil {
+			l.errs[key] = err
+		} else if value, ok := values[key]; ok {
+			l.values[key] = value
+		}
+	}
+}
+
+// loaders holds all loaders used during a single request
+type loaders struct {
+	players       *loader
+	elo           *loader
+	matches       *loader
+	matchLegs     *loader
+	matchElo      *loader
+	matchMetadata *loader
+	legs          *loader
+	tournaments   *loader
+	mu            sync.Mutex
+	statistics    map[int]*loader
+}
+
+func newLoaders() *loaders {
+	return &loaders{
+		players: newLoader(func(keys []int) (map[int]interface{}, error) {
+			players, err := data.GetPlayersByID(keys)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[int]interface{}, len(players))
+			for id, player := range players {
+				values[id] = player
+			}
+			return values, nil
+		}),
+		elo: newLoader(func(keys []int) (map[int]interface{}, error) {
+			elos, err := data.GetPlayersElo(keys...)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[int]interface{}, len(elos))
+			for _, elo := range elos {
+				values[elo.PlayerID] = elo
+			}
+			return values, nil
+		}),
+		matches: newLoader(func(keys []int) (map[int]interface{}, error) {
+			matches, err := data.GetMatchesByID(keys)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[int]interface{}, len(matches))
+			for id, match := range matches {
+				values[id] = match
+			}
+			return values, nil
+		}),
+		matchLegs: newLoader(func(keys []int) (map[int]interface{}, error) {
+			matchLegs, err := data.GetLegsForMatches(keys)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[int]interface{}, len(keys))
+			for _, id := range keys {
+				legs := matchLegs[id]
+				if legs == nil {
+					legs = make([]*models.Leg, 0)
+				}
+				values[id] = legs
+			}
+			return values, nil
+		}),
+		matchElo: newLoader(func(keys []int) (map[int]interface{}, error) {
+			changes, err := data.GetMatchesEloChange(keys)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[int]interface{}, len(keys))
+			for _, id := range keys {
+				change := changes[id]
+				if change == nil {
+					change = make(map[int]*models.PlayerElo)
+				}
+				values[id] = change
+			}
+			return values, nil
+		}),
+		matchMetadata: newLoader(func(keys []int) (map[int]interface{}, error) {
+			metadata, err := data.GetMatchesMetadata(keys)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[int]interface{}, len(keys))
+			for _, id := range keys {
+				m := metadata[id]
+				if m == nil {
+					m = &models.MatchMetadata{TournamentGroup: new(models.TournamentGroup)}
+				}
+				values[id] = m
+			}
+			return values, nil
+		}),
+		legs: newLoader(func(keys []int) (map[int]interface{}, error) {
+			legs, err := data.GetLegs(keys)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[int]interface{}, len(legs))
+			for _, leg := range legs {
+				values[leg.ID] = leg
+			}
+			return values, nil
+		}),
+		tournaments: newLoader(func(keys []int) (map[int]interface{}, error) {
+			tournaments, err := data.GetTournamentsByID(keys)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[int]interface{}, len(tournaments))
+			for id, tournament := range tournaments {
+				values[id] = tournament
+			}
+			return values, nil
+		}),
+		statistics: make(map[int]*loader),
+	}
+}
+
+// matchStatistics will return the loader of statistics for matches of the given type, as each type is stored separately
+func (l *loaders) matchStatistics(matchType int) *loader {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.statistics[matchType]; !ok {
+		l.statistics[matchType] = newLoader(func(keys []int) (map[int]interface{}, error) {
+			return data.GetStatisticsForMatches(keys, matchType)
+		})
+	}
+	return l.statistics[matchType]
+}
+
+// loadersFrom will return the loaders of the current request
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(contextKey{}).(*loaders)
+}
+
+// players will return a thunk resolving the given player IDs in order
+func players(ctx context.Context, ids []int) func() (interface{}, error) {
+	thunk := loadersFrom(ctx).players.loadMany(ids)
+	return func() (interface{}, error) {
+		values, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		players := make([]*models.Player, 0)
+		for _, value := range values.([]interface{}) {
+			players = append(players, value.(*models.Player))
+		}
+		return players, nil
+	}
+}
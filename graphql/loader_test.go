@@ -0,0 +1,119 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kcapp/api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeFetch will return a fetch function returning the key multiplied by 10, recording each batch requested
+func fakeFetch(batches *[][]int) func(keys []int) (map[int]interface{}, error) {
+	return func(keys []int) (map[int]interface{}, error) {
+		*batches = append(*batches, keys)
+		values := make(map[int]interface{}, len(keys))
+		for _, key := range keys {
+			if key > 0 {
+				values[key] = key * 10
+			}
+		}
+		return values, nil
+	}
+}
+
+// TestLoader_Batch will check that all keys queued before the first thunk is resolved are fetched in a single batch
+func TestLoader_Batch(t *testing.T) {
+	batches := make([][]int, 0)
+	l := newLoader(fakeFetch(&batches))
+
+	first := l.load(1)
+	second := l.load(2)
+	duplicate := l.load(1)
+
+	value, err := second()
+	assert.NoError(t, err)
+	assert.Equal(t, 20, value)
+	value, err = first()
+	assert.NoError(t, err)
+	assert.Equal(t, 10, value)
+	value, err = duplicate()
+	assert.NoError(t, err)
+	assert.Equal(t, 10, value)
+	assert.Equal(t, [][]int{{1, 2}}, batches, "should fetch each key once in a single batch")
+
+	value, err = l.load(2)()
+	assert.NoError(t, err)
+	assert.Equal(t, 20, value)
+	assert.Len(t, batches, 1, "should cache loaded keys")
+
+	value, err = l.load(3)()
+	assert.NoError(t, err)
+	assert.Equal(t, 30, value)
+	assert.Equal(t, [][]int{{1, 2}, {3}}, batches)
+}
+
+// TestLoader_LoadMany will check that values are returned in order, skipping missing values
+func TestLoader_LoadMany(t *testing.T) {
+	batches := make([][]int, 0)
+	l := newLoader(fakeFetch(&batches))
+
+	values, err := l.loadMany([]int{3, -1, 1})()
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{30, 10}, values)
+	assert.Equal(t, [][]int{{3, -1, 1}}, batches)
+}
+
+// TestLoader_Error will check that an error is returned for all keys of the failing batch
+func TestLoader_Error(t *testing.T) {
+	l := newLoader(func(keys []int) (map[int]interface{}, error) {
+		return nil, errors.New("failed")
+	})
+	first := l.load(1)
+	second := l.load(2)
+	_, err := first()
+	assert.EqualError(t, err, "failed")
+	_, err = second()
+	assert.EqualError(t, err, "failed")
+	_, err = l.loadMany([]int{1, 2})()
+	assert.EqualError(t, err, "failed")
+}
+
+// TestPlayers will check that players are resolved through the loader of the request
+func TestPlayers(t *testing.T) {
+	batches := make([][]int, 0)
+	ctx := context.WithValue(context.Background(), contextKey{}, &loaders{
+		players: newLoader(func(keys []int) (map[int]interface{}, error) {
+			batches = append(batches, keys)
+			return map[int]interface{}{1: &models.Player{ID: 1}, 2: &models.Player{ID: 2}}, nil
+		}),
+	})
+
+	first := players(ctx, []int{2, 1})
+	second := players(ctx, []int{1})
+	values, err := first()
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Player{{ID: 2}, {ID: 1}}, values)
+	values, err = second()
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Player{{ID: 1}}, values)
+	assert.Equal(t, [][]int{{2, 1}}, batches)
+}
+
+// TestMatchStatistics will check that statistics of matches of the same type are batched by a single loader
+func TestMatchStatistics(t *testing.T) {
+	l := &loaders{statistics: make(map[int]*loader)}
+	assert.Same(t, l.matchStatistics(models.X01), l.matchStatistics(models.X01))
+	assert.NotSame(t, l.matchStatistics(models.X01), l.matchStatistics(models.CRICKET), "should use a loader per match type")
+}
+
+// TestExecute will check that the schema is valid, and that invalid queries are rejected before resolving any field
+func TestExecute(t *testing.T) {
+	result := Execute(context.Background(), &Request{Query: `{ __schema { queryType { name } } }`})
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, map[string]interface{}{"__schema": map[string]interface{}{"queryType": map[string]interface{}{"name": "Query"}}}, result.Data)
+
+	result = Execute(context.Background(), &Request{Query: `{ match(id: 1) { unknown } }`})
+	assert.Len(t, result.Errors, 1)
+}
@@ -0,0 +1,122 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+)
+
+// Request is the body of a GraphQL request
+type Request struct {
+	Query         string                 `json:"query" validate:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+var schema gql.Schema
+
+func init() {
+	initTypes()
+	idArgument := gql.FieldConfigArgument{"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.Int)}}
+
+	query := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"match": &gql.Field{
+				Type: matchType,
+				Args: idArgument,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return loadersFrom(p.Context).matches.load(p.Args["id"].(int)), nil
+				},
+			},
+			"matches": &gql.Field{
+				Type: gql.NewList(matchType),
+				Args: gql.FieldConfigArgument{
+					"start": &gql.ArgumentConfig{Type: gql.Int, DefaultValue: 0},
+					"limit": &gql.ArgumentConfig{Type: gql.Int, DefaultValue: 25},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return data.GetMatchesLimit(p.Args["start"].(int), p.Args["limit"].(int))
+				},
+			},
+			"active_matches": &gql.Field{
+				Type: gql.NewList(matchType),
+				Args: gql.FieldConfigArgument{
+					"since": &gql.ArgumentConfig{Type: gql.Int, DefaultValue: 2},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return data.GetActiveMatches(p.Args["since"].(int))
+				},
+			},
+			"leg": &gql.Field{
+				Type: legType,
+				Args: idArgument,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return loadersFrom(p.Context).legs.load(p.Args["id"].(int)), nil
+				},
+			},
+			"active_legs": &gql.Field{
+				Type: gql.NewList(legType),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return data.GetActiveLegs()
+				},
+			},
+			"player": &gql.Field{
+				Type: playerType,
+				Args: idArgument,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return loadersFrom(p.Context).players.load(p.Args["id"].(int)), nil
+				},
+			},
+			"players": &gql.Field{
+				Type: gql.NewList(playerType),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					players, err := data.GetPlayers()
+					if err != nil {
+						return nil, err
+					}
+					list := make([]*models.Player, 0, len(players))
+					for _, player := range players {
+						list = append(list, player)
+					}
+					sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+					return list, nil
+				},
+			},
+			"tournament": &gql.Field{
+				Type: tournamentType,
+				Args: idArgument,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return loadersFrom(p.Context).tournaments.load(p.Args["id"].(int)), nil
+				},
+			},
+			"tournaments": &gql.Field{
+				Type: gql.NewList(tournamentType),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return data.GetTournaments()
+				},
+			},
+		},
+	})
+
+	var err error
+	schema, err = gql.NewSchema(gql.SchemaConfig{Query: query})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Execute will execute the given request. All players, matches, legs and tournaments referenced while resolving the
+// request are loaded in batches and cached for the duration of the request
+func Execute(ctx context.Context, request *Request) *gql.Result {
+	return gql.Do(gql.Params{
+		Schema:         schema,
+		RequestString:  request.Query,
+		VariableValues: request.Variables,
+		OperationName:  request.OperationName,
+		Context:        context.WithValue(ctx, contextKey{}, newLoaders()),
+	})
+}
@@ -0,0 +1,409 @@
+package graphql
+
+import (
+	"database/sql/driver"
+	"sort"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+)
+
+// jsonScalar is used for values without a fixed schema, such as statistics which differ per match type
+var jsonScalar = gql.NewScalar(gql.ScalarConfig{
+	Name:        "JSON",
+	Description: "Arbitrary JSON value, serialized the same way as by the REST API",
+	Serialize: func(value interface{}) interface{} {
+		return value
+	},
+})
+
+// resolveValue will resolve a field from the struct field with the matching json tag, unwrapping nullable values
+func resolveValue(p gql.ResolveParams) (interface{}, error) {
+	value, err := gql.DefaultResolveFn(p)
+	if err != nil {
+		return nil, err
+	}
+	if valuer, ok := value.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return value, nil
+}
+
+// field will return a field of the given type, resolved by json tag
+func field(t gql.Output) *gql.Field {
+	return &gql.Field{Type: t, Resolve: resolveValue}
+}
+
+// playerField will return a field resolving the player with the ID returned by the given function
+func playerField(id func(source interface{}) (int, bool)) *gql.Field {
+	return &gql.Field{
+		Type: playerType,
+		Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			playerID, ok := id(p.Source)
+			if !ok {
+				return nil, nil
+			}
+			return loadersFrom(p.Context).players.load(playerID), nil
+		},
+	}
+}
+
+var matchTypeType = gql.NewObject(gql.ObjectConfig{
+	Name: "MatchType",
+	Fields: gql.Fields{
+		"id":          field(gql.Int),
+		"name":        field(gql.String),
+		"description": field(gql.String),
+	},
+})
+
+var matchModeType = gql.NewObject(gql.ObjectConfig{
+	Name: "MatchMode",
+	Fields: gql.Fields{
+		"id":               field(gql.Int),
+		"name":             field(gql.String),
+		"short_name":       field(gql.String),
+		"wins_required":    field(gql.Int),
+		"legs_required":    field(gql.Int),
+		"is_draw_possible": field(gql.Boolean),
+		"is_challenge":     field(gql.Boolean),
+	},
+})
+
+var venueType = gql.NewObject(gql.ObjectConfig{
+	Name: "Venue",
+	Fields: gql.Fields{
+		"id":          field(gql.Int),
+		"name":        field(gql.String),
+		"description": field(gql.String),
+		"office_id":   field(gql.Int),
+	},
+})
+
+var dartType = gql.NewObject(gql.ObjectConfig{
+	Name: "Dart",
+	Fields: gql.Fields{
+		"value":      field(gql.Int),
+		"multiplier": field(gql.Int),
+	},
+})
+
+var playerEloType = gql.NewObject(gql.ObjectConfig{
+	Name: "PlayerElo",
+	Fields: gql.Fields{
+		"current_elo":            field(gql.Int),
+		"current_elo_matches":    field(gql.Int),
+		"tournament_elo":         field(gql.Int),
+		"tournament_elo_matches": field(gql.Int),
+	},
+})
+
+var playerType = gql.NewObject(gql.ObjectConfig{
+	Name: "Player",
+	Fields: gql.Fields{
+		"id":         field(gql.Int),
+		"first_name": field(gql.String),
+		"last_name":  field(gql.String),
+		"name": &gql.Field{
+			Type: gql.String,
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return p.Source.(*models.Player).GetName(), nil
+			},
+		},
+		"vocal_name":      field(gql.String),
+		"nickname":        field(gql.String),
+		"color":           field(gql.String),
+		"profile_pic_url": field(gql.String),
+		"office_id":       field(gql.Int),
+		"is_active":       field(gql.Boolean),
+		"is_bot":          field(gql.Boolean),
+		"is_placeholder":  field(gql.Boolean),
+		"is_supporter":    field(gql.Boolean),
+		"created_at":      field(gql.DateTime),
+		"elo": &gql.Field{
+			Type: playerEloType,
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return loadersFrom(p.Context).elo.load(p.Source.(*models.Player).ID), nil
+			},
+		},
+	},
+})
+
+var visitType = gql.NewObject(gql.ObjectConfig{
+	Name: "Visit",
+	Fields: gql.Fields{
+		"id":     field(gql.Int),
+		"leg_id": field(gql.Int),
+		"player": playerField(func(source interface{}) (int, bool) {
+			return source.(*models.Visit).PlayerID, true
+		}),
+		"first_dart":   field(dartType),
+		"second_dart":  field(dartType),
+		"third_dart":   field(dartType),
+		"score":        field(gql.Int),
+		"marks":        field(gql.Int),
+		"darts_thrown": field(gql.Int),
+		"is_bust":      field(gql.Boolean),
+		"is_checkout":  field(gql.Boolean),
+		"created_at":   field(gql.DateTime),
+	},
+})
+
+var eloChangeType = gql.NewObject(gql.ObjectConfig{
+	Name: "EloChange",
+	Fields: gql.Fields{
+		"player": playerField(func(source interface{}) (int, bool) {
+			return source.(*models.PlayerElo).PlayerID, true
+		}),
+		"old_elo": &gql.Field{Type: gql.Int, Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return p.Source.(*models.PlayerElo).CurrentElo, nil
+		}},
+		"new_elo": &gql.Field{Type: gql.Int, Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return p.Source.(*models.PlayerElo).CurrentEloNew, nil
+		}},
+		"old_tournament_elo": &gql.Field{Type: gql.Int, Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return p.Source.(*models.PlayerElo).TournamentElo.Value()
+		}},
+		"new_tournament_elo": &gql.Field{Type: gql.Int, Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return p.Source.(*models.PlayerElo).TournamentEloNew.Value()
+		}},
+	},
+})
+
+var tournamentGroupType = gql.NewObject(gql.ObjectConfig{
+	Name: "TournamentGroup",
+	Fields: gql.Fields{
+		"id":          field(gql.Int),
+		"name":        field(gql.String),
+		"is_playoffs": field(gql.Boolean),
+	},
+})
+
+var tournamentStandingType = gql.NewObject(gql.ObjectConfig{
+	Name: "TournamentStanding",
+	Fields: gql.Fields{
+		"rank": field(gql.Int),
+		"elo":  field(gql.Int),
+		"player": playerField(func(source interface{}) (int, bool) {
+			return source.(*models.TournamentStanding).PlayerID, true
+		}),
+	},
+})
+
+var (
+	matchType      *gql.Object
+	legType        *gql.Object
+	tournamentType *gql.Object
+)
+
+// initTypes will create the types referencing each other, which cannot be created as part of variable initialization
+func initTypes() {
+	tournamentType = gql.NewObject(gql.ObjectConfig{
+		Name: "Tournament",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":           field(gql.Int),
+				"name":         field(gql.String),
+				"short_name":   field(gql.String),
+				"is_finished":  field(gql.Boolean),
+				"is_season":    field(gql.Boolean),
+				"is_playoffs":  field(gql.Boolean),
+				"manual_admin": field(gql.Boolean),
+				"office_id":    field(gql.Int),
+				"start_time":   field(gql.DateTime),
+				"end_time":     field(gql.DateTime),
+				"playoffs": &gql.Field{
+					Type: tournamentType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						tournament := p.Source.(*models.Tournament)
+						if !tournament.PlayoffsTournamentID.Valid {
+							return nil, nil
+						}
+						return loadersFrom(p.Context).tournaments.load(int(tournament.PlayoffsTournamentID.Int64)), nil
+					},
+				},
+				"standings": field(gql.NewList(tournamentStandingType)),
+				"matches": &gql.Field{
+					Type: gql.NewList(matchType),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						groups, err := data.GetTournamentMatches(p.Source.(*models.Tournament).ID)
+						if err != nil {
+							return nil, err
+						}
+						matches := make([]*models.Match, 0)
+						for _, group := range groups {
+							matches = append(matches, group...)
+						}
+						sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+						return matches, nil
+					},
+				},
+				"statistics": &gql.Field{
+					Type: jsonScalar,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return data.GetTournamentStatistics(p.Source.(*models.Tournament).ID)
+					},
+				},
+			}
+		}),
+	})
+
+	legType = gql.NewObject(gql.ObjectConfig{
+		Name: "Leg",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":             field(gql.Int),
+				"match_id":       field(gql.Int),
+				"starting_score": field(gql.Int),
+				"is_finished":    field(gql.Boolean),
+				"has_scores":     field(gql.Boolean),
+				"leg_type":       field(matchTypeType),
+				"end_time":       field(gql.DateTime),
+				"created_at":     field(gql.DateTime),
+				"updated_at":     field(gql.DateTime),
+				"visits":         field(gql.NewList(visitType)),
+				"current_player": playerField(func(source interface{}) (int, bool) {
+					return source.(*models.Leg).CurrentPlayerID, true
+				}),
+				"winner": playerField(func(source interface{}) (int, bool) {
+					leg := source.(*models.Leg)
+					return int(leg.WinnerPlayerID.Int64), leg.WinnerPlayerID.Valid
+				}),
+				"players": &gql.Field{
+					Type: gql.NewList(playerType),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return players(p.Context, p.Source.(*models.Leg).Players), nil
+					},
+				},
+				"match": &gql.Field{
+					Type: matchType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return loadersFrom(p.Context).matches.load(p.Source.(*models.Leg).MatchID), nil
+					},
+				},
+			}
+		}),
+	})
+
+	matchType = gql.NewObject(gql.ObjectConfig{
+		Name: "Match",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":               field(gql.Int),
+				"created_at":       field(gql.DateTime),
+				"updated_at":       field(gql.DateTime),
+				"end_time":         field(gql.DateTime),
+				"first_throw_time": field(gql.DateTime),
+				"last_throw_time":  field(gql.DateTime),
+				"match_type":       field(matchTypeType),
+				"match_mode":       field(matchModeType),
+				"is_finished":      field(gql.Boolean),
+				"is_abandoned":     field(gql.Boolean),
+				"is_walkover":      field(gql.Boolean),
+				"is_bye":           field(gql.Boolean),
+				"is_practice":      field(gql.Boolean),
+				"office_id":        field(gql.Int),
+				"venue":            field(venueType),
+				"legs_won":         field(gql.NewList(gql.Int)),
+				"winner": playerField(func(source interface{}) (int, bool) {
+					match := source.(*models.Match)
+					return int(match.WinnerID.Int64), match.WinnerID.Valid
+				}),
+				"players": &gql.Field{
+					Type: gql.NewList(playerType),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return players(p.Context, p.Source.(*models.Match).Players), nil
+					},
+				},
+				"legs": &gql.Field{
+					Type: gql.NewList(legType),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						match := p.Source.(*models.Match)
+						if match.Legs != nil {
+							return match.Legs, nil
+						}
+						return loadersFrom(p.Context).matchLegs.load(match.ID), nil
+					},
+				},
+				"current_leg": &gql.Field{
+					Type: legType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						match := p.Source.(*models.Match)
+						if !match.CurrentLegID.Valid {
+							return nil, nil
+						}
+						return loadersFrom(p.Context).legs.load(int(match.CurrentLegID.Int64)), nil
+					},
+				},
+				"elo_change": &gql.Field{
+					Type: gql.NewList(eloChangeType),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						match := p.Source.(*models.Match)
+						eloChanges := func(eloChange map[int]*models.PlayerElo) []*models.PlayerElo {
+							changes := make([]*models.PlayerElo, 0)
+							for _, playerID := range match.Players {
+								if change, ok := eloChange[playerID]; ok {
+									changes = append(changes, change)
+								}
+							}
+							return changes
+						}
+						if match.EloChange != nil {
+							return eloChanges(match.EloChange), nil
+						}
+						thunk := loadersFrom(p.Context).matchElo.load(match.ID)
+						return func() (interface{}, error) {
+							value, err := thunk()
+							if err != nil {
+								return nil, err
+							}
+							return eloChanges(value.(map[int]*models.PlayerElo)), nil
+						}, nil
+					},
+				},
+				"tournament": &gql.Field{
+					Type: tournamentType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						match := p.Source.(*models.Match)
+						if !match.TournamentID.Valid {
+							return nil, nil
+						}
+						return loadersFrom(p.Context).tournaments.load(int(match.TournamentID.Int64)), nil
+					},
+				},
+				"tournament_group": &gql.Field{
+					Type: tournamentGroupType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						match := p.Source.(*models.Match)
+						if match.Tournament == nil || !match.Tournament.TournamentGroupID.Valid {
+							return nil, nil
+						}
+						return &models.TournamentGroup{
+							ID:         int(match.Tournament.TournamentGroupID.Int64),
+							Name:       match.Tournament.TournamentGroupName.String,
+							IsPlayoffs: match.Tournament.IsPlayoffs.Bool,
+						}, nil
+					},
+				},
+				"metadata": &gql.Field{
+					Type: jsonScalar,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						match := p.Source.(*models.Match)
+						if !match.TournamentID.Valid {
+							return nil, nil
+						}
+						return loadersFrom(p.Context).matchMetadata.load(match.ID), nil
+					},
+				},
+				"statistics": &gql.Field{
+					Type: jsonScalar,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						match := p.Source.(*models.Match)
+						return loadersFrom(p.Context).matchStatistics(match.MatchType.ID).load(match.ID), nil
+					},
+				},
+			}
+		}),
+	})
+}
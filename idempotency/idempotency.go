@@ -0,0 +1,177 @@
+package idempotency
+
+import (
+	"bytes"
+	"container/heap"
+	"crypto/sha256"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// KeyHeader is the header clients use to send the idempotency key of a request
+	KeyHeader = "Idempotency-Key"
+	// ReplayedHeader is set on responses replayed from a previous request with the same key
+	ReplayedHeader = "Idempotent-Replayed"
+)
+
+// response is the response of a request with an idempotency key. done is closed once the response is recorded
+type response struct {
+	key     string
+	hash    [sha256.Size]byte
+	done    chan struct{}
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// expiryQueue is a min-heap of recorded responses, ordered by when they expire
+type expiryQueue []*response
+
+func (q expiryQueue) Len() int            { return len(q) }
+func (q expiryQueue) Less(i, j int) bool  { return q[i].expires.Before(q[j].expires) }
+func (q expiryQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *expiryQueue) Push(x interface{}) { *q = append(*q, x.(*response)) }
+func (q *expiryQueue) Pop() interface{} {
+	old := *q
+	res := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return res
+}
+
+var (
+	mu        sync.Mutex
+	responses = make(map[string]*response)
+	expiry    = &expiryQueue{}
+	ttl       = 24 * time.Hour
+)
+
+// SetTTL will set how long responses are kept for replaying
+func SetTTL(d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	ttl = d
+}
+
+// Handler makes retries of a request with the same Idempotency-Key replay the first response, instead of executing
+// the request again. Requests without a key are passed through
+type Handler struct {
+	next http.HandlerFunc
+}
+
+// NewHandler will wrap the given handler
+func NewHandler(next http.HandlerFunc) *Handler {
+	return &Handler{next: next}
+}
+
+// Unwrap will return the wrapped handler
+func (h *Handler) Unwrap() http.Handler {
+	return h.next
+}
+
+// ServeHTTP will execute the request, or replay the response of a previous request with the same key
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get(KeyHeader)
+	if key == "" {
+		h.next(w, r)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("Unable to read request body: %s", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	hash := sha256.Sum256(body)
+	key = r.Method + " " + r.URL.Path + " " + key
+
+	mu.Lock()
+	expire(time.Now())
+	previous, ok := responses[key]
+	if !ok {
+		previous = &response{key: key, hash: hash, done: make(chan struct{})}
+		responses[key] = previous
+	}
+	mu.Unlock()
+
+	if ok {
+		if previous.hash != hash {
+			http.Error(w, "Idempotency-Key was already used for a different request", http.StatusUnprocessableEntity)
+			return
+		}
+		// Wait for the first request, in case it is still in progress
+		<-previous.done
+		if previous.status == 0 {
+			// The first request panicked before a response was recorded
+			http.Error(w, "Request with the same Idempotency-Key failed, retry the request", http.StatusInternalServerError)
+			return
+		}
+		for name, values := range previous.header {
+			// Keep headers already set for this request, such as the request ID
+			if _, ok := w.Header()[name]; !ok {
+				w.Header()[name] = values
+			}
+		}
+		w.Header().Set(ReplayedHeader, "true")
+		w.WriteHeader(previous.status)
+		w.Write(previous.body)
+		return
+	}
+
+	recorder := &recorder{ResponseWriter: w, status: http.StatusOK}
+	completed := false
+	defer func() {
+		mu.Lock()
+		if !completed || recorder.status >= http.StatusInternalServerError {
+			// Allow server errors and panics to be retried
+			delete(responses, key)
+		} else {
+			heap.Push(expiry, previous)
+		}
+		mu.Unlock()
+		// Requests waiting for this one are released even if the handler panics
+		close(previous.done)
+	}()
+	h.next(recorder, r)
+
+	mu.Lock()
+	previous.status = recorder.status
+	previous.header = w.Header().Clone()
+	previous.body = recorder.body.Bytes()
+	previous.expires = time.Now().Add(ttl)
+	mu.Unlock()
+	completed = true
+}
+
+// expire will remove responses which have expired. Must be called with the lock held
+func expire(now time.Time) {
+	for expiry.Len() > 0 && now.After((*expiry)[0].expires) {
+		res := heap.Pop(expiry).(*response)
+		if responses[res.key] == res {
+			delete(responses, res.key)
+		}
+	}
+}
+
+// recorder writes the response, while keeping a copy of it for replaying
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
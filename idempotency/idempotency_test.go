@@ -0,0 +1,127 @@
+package idempotency
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reset will clear all recorded responses
+func reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	responses = make(map[string]*response)
+	expiry = &expiryQueue{}
+}
+
+func serve(h http.Handler, key string, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("POST", "/visit", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(KeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// TestHandlerReplay will check that retries with the same key replay the first response
+func TestHandlerReplay(t *testing.T) {
+	reset()
+	calls := 0
+	h := NewHandler(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "%d", calls)
+	})
+
+	first := serve(h, "a", "{}")
+	assert.Equal(t, http.StatusCreated, first.Code)
+	replay := serve(h, "a", "{}")
+	assert.Equal(t, http.StatusCreated, replay.Code)
+	assert.Equal(t, "1", replay.Body.String())
+	assert.Equal(t, "true", replay.Header().Get(ReplayedHeader))
+	assert.Equal(t, 1, calls)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(h, "a", `{"other": 1}`).Code)
+	assert.Equal(t, "2", serve(h, "", "{}").Body.String())
+	assert.Equal(t, "3", serve(h, "b", "{}").Body.String())
+}
+
+// TestHandlerServerError will check that requests failing with a server error can be retried
+func TestHandlerServerError(t *testing.T) {
+	reset()
+	calls := 0
+	h := NewHandler(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	serve(h, "a", "{}")
+	serve(h, "a", "{}")
+	assert.Equal(t, 2, calls)
+}
+
+// TestHandlerPanic will check that requests waiting for a request which panics are released, and that the key can be retried
+func TestHandlerPanic(t *testing.T) {
+	reset()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := NewHandler(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		panic("failed")
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() { recover() }()
+		serve(h, "a", "{}")
+	}()
+	<-started
+
+	waiting := make(chan *httptest.ResponseRecorder)
+	go func() { waiting <- serve(h, "a", "{}") }()
+	// Give the second request time to start waiting for the first
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	select {
+	case w := <-waiting:
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	case <-time.After(time.Second):
+		t.Fatal("waiting request was not released")
+	}
+
+	mu.Lock()
+	_, ok := responses["POST /visit a"]
+	mu.Unlock()
+	assert.False(t, ok)
+}
+
+// TestExpire will check that only expired responses are removed
+func TestExpire(t *testing.T) {
+	reset()
+	SetTTL(time.Hour)
+	defer SetTTL(24 * time.Hour)
+	h := NewHandler(func(w http.ResponseWriter, r *http.Request) {})
+	serve(h, "a", "{}")
+	serve(h, "b", "{}")
+
+	mu.Lock()
+	responses["POST /visit a"].expires = time.Now().Add(-time.Minute)
+	expire(time.Now())
+	_, a := responses["POST /visit a"]
+	_, b := responses["POST /visit b"]
+	mu.Unlock()
+	assert.False(t, a)
+	assert.True(t, b)
+	assert.Equal(t, 1, expiry.Len())
+}
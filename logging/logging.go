@@ -0,0 +1,157 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kcapp/api/util"
+)
+
+// RequestIDHeader is the header used to pass request IDs between services
+const RequestIDHeader = "X-Request-ID"
+
+type (
+	requestIDKey struct{}
+	loggerKey    struct{}
+)
+
+// entry is a single structured log line
+type entry struct {
+	Time       string  `json:"time"`
+	Level      string  `json:"level"`
+	Type       string  `json:"type,omitempty"`
+	Message    string  `json:"msg,omitempty"`
+	RequestID  string  `json:"request_id,omitempty"`
+	Method     string  `json:"method,omitempty"`
+	Path       string  `json:"path,omitempty"`
+	Route      string  `json:"route,omitempty"`
+	Status     int     `json:"status,omitempty"`
+	Bytes      int     `json:"bytes,omitempty"`
+	DurationMs float64 `json:"duration_ms,omitempty"`
+	RemoteAddr string  `json:"remote_addr,omitempty"`
+	UserAgent  string  `json:"user_agent,omitempty"`
+}
+
+// output is the destination of all structured log entries
+type output struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (o *output) write(e *entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, err = o.out.Write(append(b, '\n'))
+	return err
+}
+
+// jsonWriter converts lines written by a logger to structured log entries, tagged with the given request ID
+type jsonWriter struct {
+	output    *output
+	requestID string
+}
+
+// Write will write the given log line as a JSON entry
+func (w *jsonWriter) Write(p []byte) (int, error) {
+	e := &entry{
+		Time:      time.Now().UTC().Format(time.RFC3339Nano),
+		Level:     "info",
+		Message:   string(bytes.TrimRight(p, "\n")),
+		RequestID: w.requestID,
+	}
+	err := w.output.write(e)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+var out *output
+
+// Init will make the standard logger write structured JSON lines to the given writer
+func Init(w io.Writer) {
+	out = &output{out: w}
+	log.SetFlags(0)
+	log.SetOutput(&jsonWriter{output: out})
+}
+
+// RequestID will return the request ID stored in the given context
+func RequestID(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// Logger will return the logger of the request served with the given context, which tags all lines with
+// the request ID. The standard logger is returned if the context does not belong to a request
+func Logger(ctx context.Context) *log.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*log.Logger); ok {
+		return logger
+	}
+	return log.Default()
+}
+
+// Middleware will assign a request ID to all requests, and write an access log entry once the request is served
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		logger := log.Default()
+		if out != nil {
+			logger = log.New(&jsonWriter{output: out, requestID: id}, "", 0)
+		}
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		r = r.WithContext(context.WithValue(ctx, loggerKey{}, logger))
+
+		recorder := util.NewResponseRecorder(w)
+		next.ServeHTTP(recorder, r)
+
+		if out == nil {
+			return
+		}
+		route := ""
+		if current := mux.CurrentRoute(r); current != nil {
+			route, _ = current.GetPathTemplate()
+		}
+		out.write(&entry{
+			Time:       start.UTC().Format(time.RFC3339Nano),
+			Level:      "info",
+			Type:       "access",
+			RequestID:  id,
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Route:      route,
+			Status:     recorder.Status,
+			Bytes:      recorder.Bytes,
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+			RemoteAddr: r.RemoteAddr,
+			UserAgent:  r.UserAgent(),
+		})
+	})
+}
+
+// newRequestID will generate a new random request ID
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
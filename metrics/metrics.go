@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kcapp/api/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "kcapp"
+
+var (
+	// RequestDuration is the latency of requests per route
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests per route",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	// RequestErrors is the number of requests which failed, per route and status
+	RequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_request_errors_total",
+		Help:      "Number of HTTP requests returning an error status",
+	}, []string{"route", "method", "status"})
+
+	// VisitsInserted is the number of visits inserted per match type
+	VisitsInserted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "visits_inserted_total",
+		Help:      "Number of visits inserted per match type",
+	}, []string{"match_type"})
+
+	// ActiveLegs is the number of legs currently being played, updated whenever a leg is started or finished
+	ActiveLegs = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_legs",
+		Help:      "Number of legs currently being played",
+	})
+
+	// ActiveMatches is the number of matches currently being played, updated whenever a leg is started or finished
+	ActiveMatches = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_matches",
+		Help:      "Number of matches currently being played",
+	})
+)
+
+// Register will register all metrics, including pool statistics of the given database
+func Register(db *sql.DB) {
+	prometheus.MustRegister(
+		RequestDuration,
+		RequestErrors,
+		VisitsInserted,
+		ActiveLegs,
+		ActiveMatches,
+		collectors.NewDBStatsCollector(db, "kcapp"),
+	)
+}
+
+// Handler returns the handler serving all registered metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Middleware will record latency and errors of all requests, labeled by the route template
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := util.NewResponseRecorder(w)
+		next.ServeHTTP(recorder, r)
+
+		route := "unknown"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		RequestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+		if recorder.Status >= http.StatusBadRequest {
+			RequestErrors.WithLabelValues(route, r.Method, strconv.Itoa(recorder.Status)).Inc()
+		}
+	})
+}
@@ -47,25 +47,31 @@ type PlayerBadge struct {
 	Statistics       *PlayerBadgeStatistics `json:"statistics,omitempty"`
 }
 
+// playerBadgeJSON is the JSON representation of PlayerBadge. A separate type is used to get consistent order of JSON key-value pairs
+type playerBadgeJSON struct {
+	Badge            *Badge                 `json:"badge"`
+	PlayerID         int                    `json:"player_id"`
+	Level            null.Int               `json:"level,omitempty"`
+	LegID            null.Int               `json:"leg_id,omitempty"`
+	Value            null.Int               `json:"value,omitempty"`
+	MatchID          null.Int               `json:"match_id,omitempty"`
+	OpponentPlayerID null.Int               `json:"opponent_player_id,omitempty"`
+	TournamentID     null.Int               `json:"tournament_id,omitempty"`
+	VisitID          null.Int               `json:"visit_id,omitempty"`
+	Darts            []*Dart                `json:"darts,omitempty"`
+	DartsString      string                 `json:"darts_string,omitempty"`
+	Data             null.String            `json:"data,omitempty"`
+	CreatedAt        time.Time              `json:"created_at"`
+	Statistics       *PlayerBadgeStatistics `json:"statistics,omitempty"`
+}
+
+// JSONType will return the type PlayerBadge is serialized as, to describe it in the API documentation
+func (pb PlayerBadge) JSONType() interface{} {
+	return playerBadgeJSON{}
+}
+
 // MarshalJSON will marshall the given object to JSON
 func (pb PlayerBadge) MarshalJSON() ([]byte, error) {
-	// Use a type to get consistent order of JSON key-value pairs.
-	type playerBadgeJSON struct {
-		Badge            *Badge                 `json:"badge"`
-		PlayerID         int                    `json:"player_id"`
-		Level            null.Int               `json:"level,omitempty"`
-		LegID            null.Int               `json:"leg_id,omitempty"`
-		Value            null.Int               `json:"value,omitempty"`
-		MatchID          null.Int               `json:"match_id,omitempty"`
-		OpponentPlayerID null.Int               `json:"opponent_player_id,omitempty"`
-		TournamentID     null.Int               `json:"tournament_id,omitempty"`
-		VisitID          null.Int               `json:"visit_id,omitempty"`
-		Darts            []*Dart                `json:"darts,omitempty"`
-		DartsString      string                 `json:"darts_string,omitempty"`
-		Data             null.String            `json:"data,omitempty"`
-		CreatedAt        time.Time              `json:"created_at"`
-		Statistics       *PlayerBadgeStatistics `json:"statistics,omitempty"`
-	}
 	var dartsString string
 	if pb.Darts != nil {
 		dartsString = pb.Darts[0].String()
@@ -111,18 +117,23 @@ type PlayerBadgeStatistics struct {
 	BadgeMap      map[int]interface{} `json:"values"`
 }
 
+// playerBadgeStatisticsJSON is the JSON representation of PlayerBadgeStatistics. A separate type is used to get consistent order of JSON key-value pairs
+type playerBadgeStatisticsJSON struct {
+	PlayerID      int                 `json:"player_id"`
+	Score100sPlus int                 `json:"score_100_plus"`
+	Score140sPlus int                 `json:"score_140_plus"`
+	Score180s     int                 `json:"score_180s"`
+	Shanghais     []int               `json:"shanghais"`
+	BadgeMap      map[int]interface{} `json:"values"`
+}
+
+// JSONType will return the type PlayerBadgeStatistics is serialized as, to describe it in the API documentation
+func (pbs PlayerBadgeStatistics) JSONType() interface{} {
+	return playerBadgeStatisticsJSON{}
+}
+
 // MarshalJSON will marshall the given object to JSON
 func (pbs PlayerBadgeStatistics) MarshalJSON() ([]byte, error) {
-	// Use a type to get consistent order of JSON key-value pairs.
-	type playerBadgeStatisticsJSON struct {
-		PlayerID      int                 `json:"player_id"`
-		Score100sPlus int                 `json:"score_100_plus"`
-		Score140sPlus int                 `json:"score_140_plus"`
-		Score180s     int                 `json:"score_180s"`
-		Shanghais     []int               `json:"shanghais"`
-		BadgeMap      map[int]interface{} `json:"values"`
-	}
-
 	pbs.BadgeMap = make(map[int]interface{}, 0)
 	pbs.BadgeMap[1] = pbs.Score100sPlus
 	pbs.BadgeMap[2] = pbs.Score140sPlus
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/guregu/null"
+)
+
+// BadgeAward represents a badge unlocked by a player, as stored in player2badge
+type BadgeAward struct {
+	PlayerID         int       `json:"player_id"`
+	BadgeID          int       `json:"badge_id"`
+	Level            null.Int  `json:"level,omitempty"`
+	Value            null.Int  `json:"value,omitempty"`
+	MatchID          null.Int  `json:"match_id,omitempty"`
+	LegID            null.Int  `json:"leg_id,omitempty"`
+	VisitID          null.Int  `json:"visit_id,omitempty"`
+	TournamentID     null.Int  `json:"tournament_id,omitempty"`
+	OpponentPlayerID null.Int  `json:"opponent_player_id,omitempty"`
+	CreatedAt        null.Time `json:"created_at"`
+}
+
+// BadgeAwardKey identifies a badge of a player, as each player can only unlock each badge once
+type BadgeAwardKey struct {
+	PlayerID int
+	BadgeID  int
+}
+
+// Key returns the key of this award
+func (award *BadgeAward) Key() BadgeAwardKey {
+	return BadgeAwardKey{PlayerID: award.PlayerID, BadgeID: award.BadgeID}
+}
+
+// String returns a short description of this award, used when printing a diff
+func (award *BadgeAward) String() string {
+	s := fmt.Sprintf("badge %d for player %d", award.BadgeID, award.PlayerID)
+	if award.Level.Valid {
+		s += fmt.Sprintf(" level %d", award.Level.Int64)
+	}
+	if award.LegID.Valid {
+		s += fmt.Sprintf(" on leg %d", award.LegID.Int64)
+	} else if award.MatchID.Valid {
+		s += fmt.Sprintf(" on match %d", award.MatchID.Int64)
+	} else if award.TournamentID.Valid {
+		s += fmt.Sprintf(" in tournament %d", award.TournamentID.Int64)
+	}
+	if award.CreatedAt.Valid {
+		s += fmt.Sprintf(" at %s", award.CreatedAt.Time.Format("2006-01-02 15:04:05"))
+	}
+	return s
+}
+
+// equals checks if the stored award matches this expected award. A missing time means any time is accepted
+func (award *BadgeAward) equals(stored *BadgeAward) bool {
+	if award.CreatedAt.Valid && (!stored.CreatedAt.Valid || award.CreatedAt.Time.Unix() != stored.CreatedAt.Time.Unix()) {
+		return false
+	}
+	return award.Level == stored.Level && award.Value == stored.Value && award.MatchID == stored.MatchID &&
+		award.LegID == stored.LegID && award.VisitID == stored.VisitID && award.TournamentID == stored.TournamentID &&
+		award.OpponentPlayerID == stored.OpponentPlayerID
+}
+
+// BadgeAwards is a set of awards with at most one award per badge and player
+type BadgeAwards map[BadgeAwardKey]*BadgeAward
+
+// Add will add the given award, keeping the highest level, or the first unlock if levels are equal
+func (awards BadgeAwards) Add(award *BadgeAward) {
+	existing, ok := awards[award.Key()]
+	if !ok || award.Level.Int64 > existing.Level.Int64 ||
+		(award.Level.Int64 == existing.Level.Int64 && isEarlier(award, existing)) {
+		awards[award.Key()] = award
+	}
+}
+
+// isEarlier checks if a was unlocked before b, based on the leg or match it was unlocked in
+func isEarlier(a *BadgeAward, b *BadgeAward) bool {
+	if a.LegID.Valid && b.LegID.Valid {
+		return a.LegID.Int64 < b.LegID.Int64
+	}
+	if a.MatchID.Valid && b.MatchID.Valid {
+		return a.MatchID.Int64 < b.MatchID.Int64
+	}
+	return false
+}
+
+// BadgeAwardChange represents a stored award which should be updated
+type BadgeAwardChange struct {
+	From *BadgeAward `json:"from"`
+	To   *BadgeAward `json:"to"`
+}
+
+// BadgeDiff represents the difference between the expected and stored badges
+type BadgeDiff struct {
+	Added   []*BadgeAward       `json:"added"`
+	Removed []*BadgeAward       `json:"removed"`
+	Changed []*BadgeAwardChange `json:"changed"`
+}
+
+// IsEmpty checks if there are no differences
+func (diff *BadgeDiff) IsEmpty() bool {
+	return len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0
+}
+
+// Merge will add all differences from the given diff to this one
+func (diff *BadgeDiff) Merge(other *BadgeDiff) {
+	diff.Added = append(diff.Added, other.Added...)
+	diff.Removed = append(diff.Removed, other.Removed...)
+	diff.Changed = append(diff.Changed, other.Changed...)
+}
+
+// DiffBadgeAwards will return the changes needed to make the stored awards match the expected awards
+func DiffBadgeAwards(expected BadgeAwards, stored BadgeAwards) *BadgeDiff {
+	diff := &BadgeDiff{Added: make([]*BadgeAward, 0), Removed: make([]*BadgeAward, 0), Changed: make([]*BadgeAwardChange, 0)}
+	for key, award := range expected {
+		existing, ok := stored[key]
+		if !ok {
+			diff.Added = append(diff.Added, award)
+		} else if !award.equals(existing) {
+			diff.Changed = append(diff.Changed, &BadgeAwardChange{From: existing, To: award})
+		}
+	}
+	for key, award := range stored {
+		if _, ok := expected[key]; !ok {
+			diff.Removed = append(diff.Removed, award)
+		}
+	}
+	sortAwards(diff.Added)
+	sortAwards(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return lessAward(diff.Changed[i].To, diff.Changed[j].To)
+	})
+	return diff
+}
+
+func sortAwards(awards []*BadgeAward) {
+	sort.Slice(awards, func(i, j int) bool {
+		return lessAward(awards[i], awards[j])
+	})
+}
+
+func lessAward(a *BadgeAward, b *BadgeAward) bool {
+	if a.BadgeID != b.BadgeID {
+		return a.BadgeID < b.BadgeID
+	}
+	return a.PlayerID < b.PlayerID
+}
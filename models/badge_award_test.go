@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBadgeAwardsAdd(t *testing.T) {
+	awards := make(BadgeAwards)
+
+	// The first unlock is kept
+	awards.Add(&BadgeAward{PlayerID: 1, BadgeID: 34, LegID: null.IntFrom(20)})
+	awards.Add(&BadgeAward{PlayerID: 1, BadgeID: 34, LegID: null.IntFrom(30)})
+	awards.Add(&BadgeAward{PlayerID: 1, BadgeID: 34, LegID: null.IntFrom(10)})
+	assert.Equal(t, int64(10), awards[BadgeAwardKey{PlayerID: 1, BadgeID: 34}].LegID.Int64)
+
+	// The highest level is kept
+	awards.Add(&BadgeAward{PlayerID: 1, BadgeID: 3, Level: null.IntFrom(1), LegID: null.IntFrom(10)})
+	awards.Add(&BadgeAward{PlayerID: 1, BadgeID: 3, Level: null.IntFrom(2), LegID: null.IntFrom(40)})
+	awards.Add(&BadgeAward{PlayerID: 1, BadgeID: 3, Level: null.IntFrom(2), LegID: null.IntFrom(50)})
+	award := awards[BadgeAwardKey{PlayerID: 1, BadgeID: 3}]
+	assert.Equal(t, int64(2), award.Level.Int64)
+	assert.Equal(t, int64(40), award.LegID.Int64)
+	assert.Len(t, awards, 2)
+}
+
+func TestDiffBadgeAwards(t *testing.T) {
+	when := time.Date(2025, 1, 2, 20, 0, 0, 0, time.UTC)
+	expected := make(BadgeAwards)
+	expected.Add(&BadgeAward{PlayerID: 1, BadgeID: 3, Level: null.IntFrom(2), Value: null.IntFrom(10), LegID: null.IntFrom(40), CreatedAt: null.TimeFrom(when)})
+	expected.Add(&BadgeAward{PlayerID: 1, BadgeID: 34, LegID: null.IntFrom(10), CreatedAt: null.TimeFrom(when)})
+	expected.Add(&BadgeAward{PlayerID: 2, BadgeID: 29, Level: null.IntFrom(1), Value: null.IntFrom(5)})
+
+	stored := make(BadgeAwards)
+	stored.Add(&BadgeAward{PlayerID: 1, BadgeID: 3, Level: null.IntFrom(3), Value: null.IntFrom(50), LegID: null.IntFrom(60), CreatedAt: null.TimeFrom(when)})
+	stored.Add(&BadgeAward{PlayerID: 2, BadgeID: 29, Level: null.IntFrom(1), Value: null.IntFrom(5), CreatedAt: null.TimeFrom(time.Now())})
+	stored.Add(&BadgeAward{PlayerID: 2, BadgeID: 35, LegID: null.IntFrom(12), CreatedAt: null.TimeFrom(when)})
+
+	diff := DiffBadgeAwards(expected, stored)
+	assert.False(t, diff.IsEmpty())
+	assert.Len(t, diff.Added, 1)
+	assert.Equal(t, 34, diff.Added[0].BadgeID)
+	assert.Len(t, diff.Removed, 1)
+	assert.Equal(t, 35, diff.Removed[0].BadgeID)
+	assert.Len(t, diff.Changed, 1)
+	assert.Equal(t, int64(3), diff.Changed[0].From.Level.Int64)
+	assert.Equal(t, int64(2), diff.Changed[0].To.Level.Int64)
+
+	assert.True(t, DiffBadgeAwards(stored, stored).IsEmpty())
+}
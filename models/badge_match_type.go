@@ -0,0 +1,139 @@
+package models
+
+// LegStatistics contains the statistics of all players in a leg, only the statistics for the type of the leg are set
+type LegStatistics struct {
+	Cricket   []*StatisticsCricket
+	Shootout  []*StatisticsShootout
+	AroundThe []*StatisticsAroundThe
+	TicTacToe []*StatisticsTicTacToe
+	Knockout  []*StatisticsKnockout
+}
+
+var MatchTypeBadges = []MatchTypeBadge{
+	BadgeWhiteHorse{ID: 49},
+	BadgeCleanSweep{ID: 50},
+	BadgeShootoutMaximum{ID: 51},
+	BadgeClockwork{ID: 52},
+	BadgeFlawless{ID: 53},
+	BadgeLastOneStanding{ID: 54},
+}
+
+// MatchTypeBadge is a leg badge which can only be unlocked in legs of a given match type
+type MatchTypeBadge interface {
+	GetID() int
+	GetMatchType() int
+	// Validate returns bool, player.IDs
+	Validate(leg *Leg, statistics *LegStatistics) (bool, []int)
+}
+
+type BadgeWhiteHorse struct{ ID int }
+type BadgeCleanSweep struct{ ID int }
+type BadgeShootoutMaximum struct{ ID int }
+type BadgeClockwork struct{ ID int }
+type BadgeFlawless struct{ ID int }
+type BadgeLastOneStanding struct{ ID int }
+
+func (b BadgeWhiteHorse) GetID() int {
+	return b.ID
+}
+func (b BadgeWhiteHorse) GetMatchType() int {
+	return CRICKET
+}
+func (b BadgeWhiteHorse) Validate(leg *Leg, statistics *LegStatistics) (bool, []int) {
+	playerIDs := make([]int, 0)
+	for _, stats := range statistics.Cricket {
+		if stats.Marks9 > 0 {
+			playerIDs = append(playerIDs, stats.PlayerID)
+		}
+	}
+	return len(playerIDs) > 0, playerIDs
+}
+
+func (b BadgeCleanSweep) GetID() int {
+	return b.ID
+}
+func (b BadgeCleanSweep) GetMatchType() int {
+	return CRICKET
+}
+func (b BadgeCleanSweep) Validate(leg *Leg, statistics *LegStatistics) (bool, []int) {
+	if !leg.WinnerPlayerID.Valid || len(statistics.Cricket) < 2 {
+		return false, nil
+	}
+	for _, stats := range statistics.Cricket {
+		if stats.PlayerID != int(leg.WinnerPlayerID.Int64) && stats.Score.ValueOrZero() > 0 {
+			return false, nil
+		}
+	}
+	return true, []int{int(leg.WinnerPlayerID.Int64)}
+}
+
+func (b BadgeShootoutMaximum) GetID() int {
+	return b.ID
+}
+func (b BadgeShootoutMaximum) GetMatchType() int {
+	return SHOOTOUT
+}
+func (b BadgeShootoutMaximum) Validate(leg *Leg, statistics *LegStatistics) (bool, []int) {
+	playerIDs := make([]int, 0)
+	for _, stats := range statistics.Shootout {
+		if stats.Score180s > 0 {
+			playerIDs = append(playerIDs, stats.PlayerID)
+		}
+	}
+	return len(playerIDs) > 0, playerIDs
+}
+
+func (b BadgeClockwork) GetID() int {
+	return b.ID
+}
+func (b BadgeClockwork) GetMatchType() int {
+	return AROUNDTHECLOCK
+}
+func (b BadgeClockwork) Validate(leg *Leg, statistics *LegStatistics) (bool, []int) {
+	if !leg.WinnerPlayerID.Valid {
+		return false, nil
+	}
+	for _, stats := range statistics.AroundThe {
+		// 1 through 20 and bull, without missing a single dart
+		if stats.PlayerID == int(leg.WinnerPlayerID.Int64) && stats.Score == 21 && stats.DartsThrown <= 21 {
+			return true, []int{stats.PlayerID}
+		}
+	}
+	return false, nil
+}
+
+func (b BadgeFlawless) GetID() int {
+	return b.ID
+}
+func (b BadgeFlawless) GetMatchType() int {
+	return TICTACTOE
+}
+func (b BadgeFlawless) Validate(leg *Leg, statistics *LegStatistics) (bool, []int) {
+	if !leg.WinnerPlayerID.Valid || len(statistics.TicTacToe) < 2 {
+		return false, nil
+	}
+	for _, stats := range statistics.TicTacToe {
+		if stats.PlayerID != int(leg.WinnerPlayerID.Int64) && stats.NumbersClosed > 0 {
+			return false, nil
+		}
+	}
+	return true, []int{int(leg.WinnerPlayerID.Int64)}
+}
+
+func (b BadgeLastOneStanding) GetID() int {
+	return b.ID
+}
+func (b BadgeLastOneStanding) GetMatchType() int {
+	return KNOCKOUT
+}
+func (b BadgeLastOneStanding) Validate(leg *Leg, statistics *LegStatistics) (bool, []int) {
+	if !leg.WinnerPlayerID.Valid || len(statistics.Knockout) < 2 {
+		return false, nil
+	}
+	for _, stats := range statistics.Knockout {
+		if stats.PlayerID == int(leg.WinnerPlayerID.Int64) && stats.LivesLost == 0 {
+			return true, []int{stats.PlayerID}
+		}
+	}
+	return false, nil
+}
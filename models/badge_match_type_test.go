@@ -0,0 +1,88 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBadgeWhiteHorse(t *testing.T) {
+	leg := &Leg{LegType: &MatchType{ID: CRICKET}}
+	statistics := &LegStatistics{Cricket: []*StatisticsCricket{{PlayerID: 1, Marks9: 1}, {PlayerID: 2, Marks8: 2}}}
+
+	valid, players := BadgeWhiteHorse{}.Validate(leg, statistics)
+	assert.True(t, valid)
+	assert.Equal(t, []int{1}, players)
+}
+
+func TestBadgeCleanSweep(t *testing.T) {
+	leg := &Leg{LegType: &MatchType{ID: CRICKET}, WinnerPlayerID: null.IntFrom(1)}
+	statistics := &LegStatistics{Cricket: []*StatisticsCricket{{PlayerID: 1, Score: null.IntFrom(40)}, {PlayerID: 2, Score: null.IntFrom(0)}}}
+
+	valid, players := BadgeCleanSweep{}.Validate(leg, statistics)
+	assert.True(t, valid)
+	assert.Equal(t, []int{1}, players)
+
+	statistics.Cricket[1].Score = null.IntFrom(15)
+	valid, _ = BadgeCleanSweep{}.Validate(leg, statistics)
+	assert.False(t, valid)
+}
+
+func TestBadgeClockwork(t *testing.T) {
+	leg := &Leg{LegType: &MatchType{ID: AROUNDTHECLOCK}, WinnerPlayerID: null.IntFrom(2)}
+	statistics := &LegStatistics{AroundThe: []*StatisticsAroundThe{{PlayerID: 1, Score: 12, DartsThrown: 21}, {PlayerID: 2, Score: 21, DartsThrown: 21}}}
+
+	valid, players := BadgeClockwork{}.Validate(leg, statistics)
+	assert.True(t, valid)
+	assert.Equal(t, []int{2}, players)
+
+	statistics.AroundThe[1].DartsThrown = 24
+	valid, _ = BadgeClockwork{}.Validate(leg, statistics)
+	assert.False(t, valid)
+}
+
+func TestBadgeFlawless(t *testing.T) {
+	leg := &Leg{LegType: &MatchType{ID: TICTACTOE}, WinnerPlayerID: null.IntFrom(1)}
+	statistics := &LegStatistics{TicTacToe: []*StatisticsTicTacToe{{PlayerID: 1, NumbersClosed: 3}, {PlayerID: 2}}}
+
+	valid, players := BadgeFlawless{}.Validate(leg, statistics)
+	assert.True(t, valid)
+	assert.Equal(t, []int{1}, players)
+
+	statistics.TicTacToe[1].NumbersClosed = 1
+	valid, _ = BadgeFlawless{}.Validate(leg, statistics)
+	assert.False(t, valid)
+}
+
+// TestBadgeIDsReserved will check that badges defined in code use unique ids below those available to badge rules
+func TestBadgeIDsReserved(t *testing.T) {
+	ids := make([]int, 0)
+	for _, badge := range LeaderboardBadges {
+		ids = append(ids, badge.GetID())
+	}
+	for _, badge := range MatchBadges {
+		ids = append(ids, badge.GetID())
+	}
+	for _, badge := range LegBadges {
+		ids = append(ids, badge.GetID())
+	}
+	for _, badge := range LegPlayerBadges {
+		ids = append(ids, badge.GetID())
+	}
+	for _, badge := range VisitBadgesLevel {
+		ids = append(ids, badge.GetID())
+	}
+	for _, badge := range VisitBadges {
+		ids = append(ids, badge.GetID())
+	}
+	for _, badge := range MatchTypeBadges {
+		ids = append(ids, badge.GetID())
+	}
+	seen := make(map[int]bool)
+	for _, id := range ids {
+		assert.Less(t, id, MinBadgeRuleID)
+		assert.False(t, seen[id], "badge %d is defined twice", id)
+		seen[id] = true
+	}
+}
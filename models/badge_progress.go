@@ -0,0 +1,97 @@
+package models
+
+import (
+	"math"
+
+	"github.com/guregu/null"
+)
+
+// BadgeProgress represents how close a player is to unlocking a badge, or to the next level of it
+type BadgeProgress struct {
+	Badge         *Badge           `json:"badge"`
+	Unlocked      bool             `json:"unlocked"`
+	Level         null.Int         `json:"level"`
+	Value         null.Int         `json:"value"`
+	NextLevel     null.Int         `json:"next_level"`
+	NextThreshold null.Int         `json:"next_threshold"`
+	Percent       float64          `json:"percent"`
+	NearMisses    []*BadgeNearMiss `json:"near_misses,omitempty"`
+}
+
+// BadgeNearMiss represents the closest a player has been to unlocking a badge
+type BadgeNearMiss struct {
+	Hint     string   `json:"hint"`
+	Value    int      `json:"value"`
+	Required int      `json:"required"`
+	Percent  float64  `json:"percent"`
+	LegID    null.Int `json:"leg_id,omitempty"`
+	VisitID  null.Int `json:"visit_id,omitempty"`
+}
+
+// NewLevelProgress will return the progress towards the next level of a badge, based on the current value
+func NewLevelProgress(badge *Badge, value int, levels []int) *BadgeProgress {
+	progress := &BadgeProgress{Badge: badge, Value: null.IntFrom(int64(value))}
+	if len(levels) == 0 {
+		return progress
+	}
+	if value >= levels[0] {
+		progress.Unlocked = true
+		progress.Level = null.IntFrom(int64(GetLevel(value, levels)))
+	}
+	for i, threshold := range levels {
+		if value < threshold {
+			progress.NextLevel = null.IntFrom(int64(i + 1))
+			progress.NextThreshold = null.IntFrom(int64(threshold))
+			progress.Percent = percent(value, threshold)
+			return progress
+		}
+	}
+	// All levels are unlocked
+	progress.Percent = 100
+	return progress
+}
+
+// NewNearMissProgress will return the progress towards a one-off badge, based on the closest near-misses
+func NewNearMissProgress(badge *Badge, unlocked bool, misses []*BadgeNearMiss) *BadgeProgress {
+	progress := &BadgeProgress{Badge: badge, Unlocked: unlocked}
+	if unlocked {
+		progress.Percent = 100
+		return progress
+	}
+	progress.NearMisses = make([]*BadgeNearMiss, 0)
+	for _, miss := range misses {
+		if miss == nil {
+			continue
+		}
+		progress.NearMisses = append(progress.NearMisses, miss)
+		if miss.Percent > progress.Percent {
+			progress.Percent = miss.Percent
+		}
+	}
+	return progress
+}
+
+// NewNearMiss will return a near-miss where a higher value is closer to the required value
+func NewNearMiss(hint string, value int, required int) *BadgeNearMiss {
+	return &BadgeNearMiss{Hint: hint, Value: value, Required: required, Percent: percent(value, required)}
+}
+
+// NewNearMissLowerIsBetter will return a near-miss where a lower value is closer to the required value, such as darts thrown
+func NewNearMissLowerIsBetter(hint string, value int, required int) *BadgeNearMiss {
+	miss := &BadgeNearMiss{Hint: hint, Value: value, Required: required}
+	if value > 0 {
+		miss.Percent = percent(required, value)
+	}
+	return miss
+}
+
+// percent returns value as a percentage of total, rounded to two decimals and capped at 100
+func percent(value int, total int) float64 {
+	if total <= 0 || value >= total {
+		return 100
+	}
+	if value <= 0 {
+		return 0
+	}
+	return math.Round(float64(value)/float64(total)*10000) / 100
+}
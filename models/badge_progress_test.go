@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLevelProgress(t *testing.T) {
+	badge := &Badge{ID: 3}
+	levels := []int{1, 10, 50, 100}
+
+	progress := NewLevelProgress(badge, 0, levels)
+	assert.False(t, progress.Unlocked)
+	assert.False(t, progress.Level.Valid)
+	assert.Equal(t, int64(1), progress.NextLevel.Int64)
+	assert.Equal(t, int64(1), progress.NextThreshold.Int64)
+	assert.Equal(t, 0.0, progress.Percent)
+
+	progress = NewLevelProgress(badge, 7, levels)
+	assert.True(t, progress.Unlocked)
+	assert.Equal(t, int64(1), progress.Level.Int64)
+	assert.Equal(t, int64(2), progress.NextLevel.Int64)
+	assert.Equal(t, int64(10), progress.NextThreshold.Int64)
+	assert.Equal(t, 70.0, progress.Percent)
+
+	progress = NewLevelProgress(badge, 120, levels)
+	assert.Equal(t, int64(4), progress.Level.Int64)
+	assert.False(t, progress.NextLevel.Valid)
+	assert.False(t, progress.NextThreshold.Valid)
+	assert.Equal(t, 100.0, progress.Percent)
+}
+
+func TestNewNearMissProgress(t *testing.T) {
+	badge := &Badge{ID: 34}
+
+	progress := NewNearMissProgress(badge, false, []*BadgeNearMiss{NewNearMiss("2 of 3 bulls in one visit", 2, 3), nil})
+	assert.False(t, progress.Unlocked)
+	assert.Len(t, progress.NearMisses, 1)
+	assert.Equal(t, 66.67, progress.Percent)
+
+	progress = NewNearMissProgress(badge, true, nil)
+	assert.True(t, progress.Unlocked)
+	assert.Nil(t, progress.NearMisses)
+	assert.Equal(t, 100.0, progress.Percent)
+}
+
+func TestNewNearMissLowerIsBetter(t *testing.T) {
+	miss := NewNearMissLowerIsBetter("Checked out 501 in 12 darts", 12, 9)
+	assert.Equal(t, 75.0, miss.Percent)
+
+	miss = NewNearMissLowerIsBetter("Checked out 501 in 9 darts", 9, 9)
+	assert.Equal(t, 100.0, miss.Percent)
+}
@@ -0,0 +1,449 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/guregu/null"
+	"gopkg.in/yaml.v3"
+)
+
+// Scopes a badge rule can be evaluated in
+const (
+	// BadgeRuleScopeVisit evaluates the rule for each visit of each player in a finished leg
+	BadgeRuleScopeVisit = "visit"
+	// BadgeRuleScopeLeg evaluates the rule once for each player in a finished leg
+	BadgeRuleScopeLeg = "leg"
+	// BadgeRuleScopeMatch evaluates the rule once for each player in a finished match
+	BadgeRuleScopeMatch = "match"
+)
+
+// MinBadgeRuleID is the lowest id a badge rule can use, as lower ids are reserved for badges defined in code
+const MinBadgeRuleID = 100
+
+// BadgeRule struct used for storing a badge defined by an expression instead of code. The condition is an expression
+// returning a bool, which unlocks the badge for the player when true. If levels are given, the value expression
+// returns a number which decides the level unlocked
+type BadgeRule struct {
+	ID          int      `json:"id" yaml:"id" validate:"required"`
+	Name        string   `json:"name" yaml:"name" validate:"required"`
+	Description string   `json:"description" yaml:"description"`
+	Filename    string   `json:"filename" yaml:"filename"`
+	Hidden      bool     `json:"hidden" yaml:"hidden"`
+	Secret      bool     `json:"secret" yaml:"secret"`
+	Scope       string   `json:"scope" yaml:"scope" validate:"required"`
+	Condition   string   `json:"condition" yaml:"condition" validate:"required"`
+	Value       string   `json:"value,omitempty" yaml:"value"`
+	Levels      []int    `json:"levels,omitempty" yaml:"levels"`
+	IsActive    bool     `json:"is_active" yaml:"-"`
+	OfficeID    null.Int `json:"office_id" yaml:"office_id"`
+
+	condition *vm.Program
+	value     *vm.Program
+}
+
+// UnmarshalJSON will unmarshal the rule, making it active unless is_active is given
+func (rule *BadgeRule) UnmarshalJSON(b []byte) error {
+	type badgeRule BadgeRule
+	r := badgeRule{IsActive: true}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	*rule = BadgeRule(r)
+	return nil
+}
+
+// ParseBadgeRules will parse and compile a list of rules given as YAML or JSON. Rules are active unless is_active is
+// set to false
+func ParseBadgeRules(b []byte) ([]*BadgeRule, error) {
+	var raw []struct {
+		BadgeRule `yaml:",inline"`
+		IsActive  *bool `yaml:"is_active"`
+	}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, &BadgeRuleError{Err: err}
+	}
+	rules := make([]*BadgeRule, 0, len(raw))
+	for _, r := range raw {
+		rule := r.BadgeRule
+		rule.IsActive = r.IsActive == nil || *r.IsActive
+		if err := rule.Compile(); err != nil {
+			return nil, &BadgeRuleError{Err: fmt.Errorf("badge rule %d: %w", rule.ID, err)}
+		}
+		rules = append(rules, &rule)
+	}
+	return rules, nil
+}
+
+// BadgeRuleUnlock struct used for storing a badge unlocked by a rule
+type BadgeRuleUnlock struct {
+	BadgeID   int       `json:"badge_id"`
+	PlayerID  int       `json:"player_id"`
+	MatchID   null.Int  `json:"match_id,omitempty"`
+	LegID     null.Int  `json:"leg_id,omitempty"`
+	VisitID   null.Int  `json:"visit_id,omitempty"`
+	Level     null.Int  `json:"level,omitempty"`
+	Value     null.Int  `json:"value,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Award returns the badge award for this unlock
+func (unlock *BadgeRuleUnlock) Award() *BadgeAward {
+	return &BadgeAward{
+		PlayerID:  unlock.PlayerID,
+		BadgeID:   unlock.BadgeID,
+		Level:     unlock.Level,
+		Value:     unlock.Value,
+		MatchID:   unlock.MatchID,
+		LegID:     unlock.LegID,
+		VisitID:   unlock.VisitID,
+		CreatedAt: null.TimeFrom(unlock.CreatedAt),
+	}
+}
+
+// BadgeRuleDryRun struct used for storing the result of evaluating a rule against history, without unlocking anything
+type BadgeRuleDryRun struct {
+	Rule      *BadgeRule         `json:"rule"`
+	Evaluated int                `json:"evaluated"`
+	Unlocks   []*BadgeRuleUnlock `json:"unlocks"`
+}
+
+// BadgeRuleError used when a badge rule is invalid
+type BadgeRuleError struct {
+	Err error
+}
+
+func (e *BadgeRuleError) Error() string {
+	return e.Err.Error()
+}
+
+// RuleDart is a single dart as seen by badge rule expressions
+type RuleDart struct {
+	Value      int  `expr:"value"`
+	Multiplier int  `expr:"multiplier"`
+	Score      int  `expr:"score"`
+	IsMiss     bool `expr:"is_miss"`
+	IsDouble   bool `expr:"is_double"`
+	IsTriple   bool `expr:"is_triple"`
+	IsBull     bool `expr:"is_bull"`
+}
+
+// RuleVisit is a single visit as seen by badge rule expressions
+type RuleVisit struct {
+	ID         int        `expr:"id"`
+	PlayerID   int        `expr:"player_id"`
+	Score      int        `expr:"score"`
+	IsBust     bool       `expr:"is_bust"`
+	IsCheckout bool       `expr:"is_checkout"`
+	Darts      []RuleDart `expr:"darts"`
+}
+
+// RulePlayer is a player in a leg or match as seen by badge rule expressions
+type RulePlayer struct {
+	ID           int         `expr:"id"`
+	IsWinner     bool        `expr:"is_winner"`
+	Visits       []RuleVisit `expr:"visits"`
+	DartsThrown  int         `expr:"darts_thrown"`
+	Points       int         `expr:"points"`
+	ThreeDartAvg float64     `expr:"three_dart_avg"`
+	LegsWon      int         `expr:"legs_won"`
+}
+
+// RuleLeg is a leg as seen by badge rule expressions
+type RuleLeg struct {
+	ID            int         `expr:"id"`
+	MatchID       int         `expr:"match_id"`
+	Type          int         `expr:"type"`
+	StartingScore int         `expr:"starting_score"`
+	NumPlayers    int         `expr:"num_players"`
+	Visits        []RuleVisit `expr:"visits"`
+	Duration      int         `expr:"duration"`
+}
+
+// RuleMatch is a match as seen by badge rule expressions
+type RuleMatch struct {
+	ID           int   `expr:"id"`
+	Type         int   `expr:"type"`
+	WinsRequired int   `expr:"wins_required"`
+	IsChallenge  bool  `expr:"is_challenge"`
+	IsPractice   bool  `expr:"is_practice"`
+	OfficeID     int   `expr:"office_id"`
+	VenueID      int   `expr:"venue_id"`
+	TournamentID int   `expr:"tournament_id"`
+	Players      []int `expr:"players"`
+	Legs         int   `expr:"legs"`
+}
+
+// RuleDate is the time a leg or match finished as seen by badge rule expressions
+type RuleDate struct {
+	Year    int `expr:"year"`
+	Month   int `expr:"month"`
+	Day     int `expr:"day"`
+	Weekday int `expr:"weekday"`
+	Hour    int `expr:"hour"`
+}
+
+// RuleVisitEnv is the environment of rules with visit scope
+type RuleVisitEnv struct {
+	Visit  RuleVisit  `expr:"visit"`
+	Leg    RuleLeg    `expr:"leg"`
+	Player RulePlayer `expr:"player"`
+	Date   RuleDate   `expr:"date"`
+}
+
+// RuleLegEnv is the environment of rules with leg scope
+type RuleLegEnv struct {
+	Leg    RuleLeg    `expr:"leg"`
+	Player RulePlayer `expr:"player"`
+	Date   RuleDate   `expr:"date"`
+}
+
+// RuleMatchEnv is the environment of rules with match scope
+type RuleMatchEnv struct {
+	Match  RuleMatch  `expr:"match"`
+	Player RulePlayer `expr:"player"`
+	Date   RuleDate   `expr:"date"`
+}
+
+// Compile will validate and compile the expressions of the rule. Must be called before the rule is evaluated
+func (rule *BadgeRule) Compile() error {
+	var env interface{}
+	switch rule.Scope {
+	case BadgeRuleScopeVisit:
+		env = RuleVisitEnv{}
+	case BadgeRuleScopeLeg:
+		env = RuleLegEnv{}
+	case BadgeRuleScopeMatch:
+		env = RuleMatchEnv{}
+	default:
+		return &BadgeRuleError{Err: fmt.Errorf("invalid scope '%s', must be one of visit, leg or match", rule.Scope)}
+	}
+
+	condition, err := expr.Compile(rule.Condition, expr.Env(env), expr.AsBool())
+	if err != nil {
+		return &BadgeRuleError{Err: fmt.Errorf("invalid condition: %w", err)}
+	}
+	rule.condition = condition
+
+	rule.value = nil
+	if len(rule.Levels) > 0 {
+		if rule.Value == "" {
+			return &BadgeRuleError{Err: errors.New("value is required when levels are given")}
+		}
+		for i := 1; i < len(rule.Levels); i++ {
+			if rule.Levels[i] <= rule.Levels[i-1] {
+				return &BadgeRuleError{Err: errors.New("levels must be increasing")}
+			}
+		}
+		value, err := expr.Compile(rule.Value, expr.Env(env), expr.AsInt())
+		if err != nil {
+			return &BadgeRuleError{Err: fmt.Errorf("invalid value: %w", err)}
+		}
+		rule.value = value
+	}
+	return nil
+}
+
+// EvaluateLeg will return the badges unlocked in the given finished leg by a rule with visit or leg scope
+func (rule *BadgeRule) EvaluateLeg(leg *Leg, officeID null.Int) ([]*BadgeRuleUnlock, error) {
+	if rule.Scope == BadgeRuleScopeMatch || !rule.appliesTo(officeID) {
+		return nil, nil
+	}
+	ruleLeg := newRuleLeg(leg)
+	date := newRuleDate(leg.UpdatedAt)
+
+	unlocks := make([]*BadgeRuleUnlock, 0)
+	for _, playerID := range leg.Players {
+		player := newRulePlayer(playerID, leg.Visits, leg.WinnerPlayerID.Int64 == int64(playerID), 0)
+		base := BadgeRuleUnlock{BadgeID: rule.ID, PlayerID: playerID, LegID: null.IntFrom(int64(leg.ID)), CreatedAt: leg.UpdatedAt}
+
+		if rule.Scope == BadgeRuleScopeLeg {
+			unlock, err := rule.evaluate(RuleLegEnv{Leg: ruleLeg, Player: player, Date: date}, base)
+			if err != nil {
+				return nil, err
+			}
+			if unlock != nil {
+				unlocks = append(unlocks, unlock)
+			}
+			continue
+		}
+
+		// Unlock on the first matching visit, or the visit reaching the highest level
+		var best *BadgeRuleUnlock
+		for _, visit := range player.Visits {
+			base.VisitID = null.IntFrom(int64(visit.ID))
+			unlock, err := rule.evaluate(RuleVisitEnv{Visit: visit, Leg: ruleLeg, Player: player, Date: date}, base)
+			if err != nil {
+				return nil, err
+			}
+			if unlock == nil {
+				continue
+			}
+			if best == nil || unlock.Value.Int64 > best.Value.Int64 {
+				best = unlock
+			}
+			if rule.value == nil {
+				break
+			}
+		}
+		if best != nil {
+			unlocks = append(unlocks, best)
+		}
+	}
+	return unlocks, nil
+}
+
+// EvaluateMatch will return the badges unlocked in the given finished match by a rule with match scope
+func (rule *BadgeRule) EvaluateMatch(match *Match) ([]*BadgeRuleUnlock, error) {
+	if rule.Scope != BadgeRuleScopeMatch || !rule.appliesTo(match.OfficeID) {
+		return nil, nil
+	}
+	ruleMatch := RuleMatch{
+		ID:           match.ID,
+		Type:         match.MatchType.ID,
+		WinsRequired: match.MatchMode.WinsRequired,
+		IsChallenge:  match.MatchMode.IsChallenge,
+		IsPractice:   match.IsPractice,
+		OfficeID:     int(match.OfficeID.Int64),
+		VenueID:      int(match.VenueID.Int64),
+		TournamentID: int(match.TournamentID.Int64),
+		Players:      match.Players,
+		Legs:         len(match.Legs),
+	}
+	visits := make([]*Visit, 0)
+	for _, leg := range match.Legs {
+		visits = append(visits, leg.Visits...)
+	}
+	when := match.EndTime
+	if when.IsZero() {
+		when = match.UpdatedAt
+	}
+	date := newRuleDate(when)
+
+	unlocks := make([]*BadgeRuleUnlock, 0)
+	for _, playerID := range match.Players {
+		legsWon := 0
+		for _, leg := range match.Legs {
+			if leg.WinnerPlayerID.Int64 == int64(playerID) {
+				legsWon++
+			}
+		}
+		player := newRulePlayer(playerID, visits, match.WinnerID.Int64 == int64(playerID), legsWon)
+		base := BadgeRuleUnlock{BadgeID: rule.ID, PlayerID: playerID, MatchID: null.IntFrom(int64(match.ID)), CreatedAt: when}
+		unlock, err := rule.evaluate(RuleMatchEnv{Match: ruleMatch, Player: player, Date: date}, base)
+		if err != nil {
+			return nil, err
+		}
+		if unlock != nil {
+			unlocks = append(unlocks, unlock)
+		}
+	}
+	return unlocks, nil
+}
+
+// appliesTo will check if the rule is active for the given office
+func (rule *BadgeRule) appliesTo(officeID null.Int) bool {
+	return rule.IsActive && (!rule.OfficeID.Valid || rule.OfficeID.Int64 == officeID.Int64)
+}
+
+// evaluate will run the compiled expressions against the given environment, returning the unlock if the condition holds
+func (rule *BadgeRule) evaluate(env interface{}, base BadgeRuleUnlock) (*BadgeRuleUnlock, error) {
+	if rule.condition == nil {
+		return nil, fmt.Errorf("badge rule %d is not compiled", rule.ID)
+	}
+	result, err := expr.Run(rule.condition, env)
+	if err != nil {
+		return nil, fmt.Errorf("unable to evaluate condition of badge rule %d: %w", rule.ID, err)
+	}
+	if !result.(bool) {
+		return nil, nil
+	}
+	unlock := base
+	if rule.value != nil {
+		result, err := expr.Run(rule.value, env)
+		if err != nil {
+			return nil, fmt.Errorf("unable to evaluate value of badge rule %d: %w", rule.ID, err)
+		}
+		value := result.(int)
+		if value < rule.Levels[0] {
+			return nil, nil
+		}
+		unlock.Value = null.IntFrom(int64(value))
+		unlock.Level = null.IntFrom(int64(GetLevel(value, rule.Levels)))
+	}
+	return &unlock, nil
+}
+
+// newRuleLeg will convert the given leg for use in rule expressions
+func newRuleLeg(leg *Leg) RuleLeg {
+	ruleLeg := RuleLeg{
+		ID:            leg.ID,
+		MatchID:       leg.MatchID,
+		StartingScore: leg.StartingScore,
+		NumPlayers:    len(leg.Players),
+		Visits:        make([]RuleVisit, 0, len(leg.Visits)),
+	}
+	if leg.LegType != nil {
+		ruleLeg.Type = leg.LegType.ID
+	}
+	for _, visit := range leg.Visits {
+		ruleLeg.Visits = append(ruleLeg.Visits, newRuleVisit(visit))
+	}
+	if len(leg.Visits) > 0 {
+		ruleLeg.Duration = int(leg.Visits[len(leg.Visits)-1].CreatedAt.Sub(leg.Visits[0].CreatedAt).Seconds())
+	}
+	return ruleLeg
+}
+
+// newRulePlayer will convert the visits of the given player for use in rule expressions
+func newRulePlayer(playerID int, visits []*Visit, isWinner bool, legsWon int) RulePlayer {
+	player := RulePlayer{ID: playerID, IsWinner: isWinner, Visits: make([]RuleVisit, 0), LegsWon: legsWon}
+	for _, visit := range visits {
+		if visit.PlayerID != playerID {
+			continue
+		}
+		v := newRuleVisit(visit)
+		player.Visits = append(player.Visits, v)
+		for _, dart := range v.Darts {
+			player.DartsThrown++
+			if !visit.IsBust {
+				player.Points += dart.Score
+			}
+		}
+	}
+	if player.DartsThrown > 0 {
+		player.ThreeDartAvg = float64(player.Points) / float64(player.DartsThrown) * 3
+	}
+	return player
+}
+
+// newRuleVisit will convert the given visit for use in rule expressions. Darts not thrown are left out
+func newRuleVisit(visit *Visit) RuleVisit {
+	v := RuleVisit{ID: visit.ID, PlayerID: visit.PlayerID, IsBust: visit.IsBust, IsCheckout: visit.IsCheckout, Darts: make([]RuleDart, 0, 3)}
+	for _, dart := range []*Dart{visit.FirstDart, visit.SecondDart, visit.ThirdDart} {
+		if dart == nil || !dart.Value.Valid {
+			continue
+		}
+		d := RuleDart{
+			Value:      dart.ValueRaw(),
+			Multiplier: int(dart.Multiplier),
+			Score:      dart.GetScore(),
+			IsMiss:     dart.IsMiss(),
+			IsDouble:   dart.IsDouble(),
+			IsTriple:   dart.IsTriple(),
+			IsBull:     dart.IsBull(),
+		}
+		v.Score += d.Score
+		v.Darts = append(v.Darts, d)
+	}
+	return v
+}
+
+// newRuleDate will convert the given time for use in rule expressions
+func newRuleDate(t time.Time) RuleDate {
+	return RuleDate{Year: t.Year(), Month: int(t.Month()), Day: t.Day(), Weekday: int(t.Weekday()), Hour: t.Hour()}
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func ruleVisit(id int, playerID int, darts ...[2]int64) *Visit {
+	visit := &Visit{ID: id, PlayerID: playerID, FirstDart: &Dart{}, SecondDart: &Dart{}, ThirdDart: &Dart{}}
+	for i, dart := range darts {
+		d := &Dart{Value: null.IntFrom(dart[0]), Multiplier: dart[1]}
+		switch i {
+		case 0:
+			visit.FirstDart = d
+		case 1:
+			visit.SecondDart = d
+		case 2:
+			visit.ThirdDart = d
+		}
+	}
+	return visit
+}
+
+func ruleLeg() *Leg {
+	return &Leg{
+		ID:             1,
+		MatchID:        2,
+		LegType:        &MatchType{ID: X01},
+		StartingScore:  301,
+		Players:        []int{1, 2},
+		WinnerPlayerID: null.IntFrom(1),
+		UpdatedAt:      time.Date(2024, 12, 24, 18, 0, 0, 0, time.UTC),
+		Visits: []*Visit{
+			ruleVisit(10, 1, [2]int64{20, 3}, [2]int64{20, 3}, [2]int64{20, 3}),
+			ruleVisit(11, 2, [2]int64{1, 1}, [2]int64{5, 1}, [2]int64{20, 1}),
+			ruleVisit(12, 1, [2]int64{20, 3}, [2]int64{19, 3}, [2]int64{18, 3}),
+			ruleVisit(13, 2, [2]int64{25, 2}, [2]int64{25, 2}, [2]int64{0, 1}),
+		},
+	}
+}
+
+// TestBadgeRuleCompile will check that invalid rules are rejected
+func TestBadgeRuleCompile(t *testing.T) {
+	rules := []BadgeRule{
+		{ID: 100, Scope: "season", Condition: "true"},
+		{ID: 100, Scope: BadgeRuleScopeVisit, Condition: "visit.score"},
+		{ID: 100, Scope: BadgeRuleScopeVisit, Condition: "match.id > 0"},
+		{ID: 100, Scope: BadgeRuleScopeLeg, Condition: "true", Levels: []int{1, 5}},
+		{ID: 100, Scope: BadgeRuleScopeLeg, Condition: "true", Value: "1", Levels: []int{5, 1}},
+	}
+	for _, rule := range rules {
+		err := rule.Compile()
+		assert.IsType(t, &BadgeRuleError{}, err, "should return error")
+	}
+}
+
+// TestBadgeRuleVisit will check that a visit rule unlocks on the first matching visit of each player
+func TestBadgeRuleVisit(t *testing.T) {
+	rule := &BadgeRule{ID: 100, Scope: BadgeRuleScopeVisit, Condition: "visit.score == 26 && all(visit.darts, !.is_double)", IsActive: true}
+	assert.Nil(t, rule.Compile(), "should compile")
+
+	unlocks, err := rule.EvaluateLeg(ruleLeg(), null.Int{})
+	assert.Nil(t, err, "should not return error")
+	assert.Len(t, unlocks, 1, "should unlock for a single player")
+	assert.Equal(t, unlocks[0].PlayerID, 2, "should be equal")
+	assert.Equal(t, unlocks[0].VisitID, null.IntFrom(11), "should be equal")
+	assert.Equal(t, unlocks[0].LegID, null.IntFrom(1), "should be equal")
+}
+
+// TestBadgeRuleLevels will check that the level is decided by the highest value
+func TestBadgeRuleLevels(t *testing.T) {
+	rule := &BadgeRule{ID: 100, Scope: BadgeRuleScopeVisit, Condition: "count(visit.darts, .is_triple) > 0",
+		Value: "visit.score", Levels: []int{100, 170, 180}, IsActive: true}
+	assert.Nil(t, rule.Compile(), "should compile")
+
+	unlocks, err := rule.EvaluateLeg(ruleLeg(), null.Int{})
+	assert.Nil(t, err, "should not return error")
+	assert.Len(t, unlocks, 1, "should unlock for a single player")
+	assert.Equal(t, unlocks[0].PlayerID, 1, "should be equal")
+	assert.Equal(t, unlocks[0].Level, null.IntFrom(3), "should be equal")
+	assert.Equal(t, unlocks[0].Value, null.IntFrom(180), "should be equal")
+	assert.Equal(t, unlocks[0].VisitID, null.IntFrom(10), "should be equal")
+}
+
+// TestBadgeRuleLeg will check that leg rules can use the date and player context, and respect office and active flags
+func TestBadgeRuleLeg(t *testing.T) {
+	rule := &BadgeRule{ID: 100, Scope: BadgeRuleScopeLeg, Condition: "date.month == 12 && date.day == 24 && player.is_winner",
+		IsActive: true, OfficeID: null.IntFrom(1)}
+	assert.Nil(t, rule.Compile(), "should compile")
+
+	unlocks, err := rule.EvaluateLeg(ruleLeg(), null.IntFrom(1))
+	assert.Nil(t, err, "should not return error")
+	assert.Len(t, unlocks, 1, "should unlock for winner")
+	assert.Equal(t, unlocks[0].PlayerID, 1, "should be equal")
+	assert.False(t, unlocks[0].VisitID.Valid, "should not have visit")
+
+	unlocks, _ = rule.EvaluateLeg(ruleLeg(), null.IntFrom(2))
+	assert.Empty(t, unlocks, "should not unlock in other offices")
+
+	rule.IsActive = false
+	unlocks, _ = rule.EvaluateLeg(ruleLeg(), null.IntFrom(1))
+	assert.Empty(t, unlocks, "should not unlock when inactive")
+}
+
+// TestBadgeRuleMatch will check that match rules are evaluated for each player of the match
+func TestBadgeRuleMatch(t *testing.T) {
+	rule := &BadgeRule{ID: 100, Scope: BadgeRuleScopeMatch, Condition: "match.tournament_id > 0 && player.legs_won == 0", IsActive: true}
+	assert.Nil(t, rule.Compile(), "should compile")
+
+	match := &Match{
+		ID:           2,
+		MatchType:    &MatchType{ID: X01},
+		MatchMode:    &MatchMode{WinsRequired: 1},
+		TournamentID: null.IntFrom(5),
+		WinnerID:     null.IntFrom(1),
+		Players:      []int{1, 2},
+		Legs:         []*Leg{ruleLeg()},
+	}
+	unlocks, err := rule.EvaluateMatch(match)
+	assert.Nil(t, err, "should not return error")
+	assert.Len(t, unlocks, 1, "should unlock for loser")
+	assert.Equal(t, unlocks[0].PlayerID, 2, "should be equal")
+	assert.Equal(t, unlocks[0].MatchID, null.IntFrom(2), "should be equal")
+
+	unlocks, _ = rule.EvaluateLeg(ruleLeg(), null.Int{})
+	assert.Empty(t, unlocks, "should not evaluate match rules for legs")
+}
+
+// TestParseBadgeRules will check that rules are parsed from YAML, and active by default
+func TestParseBadgeRules(t *testing.T) {
+	rules, err := ParseBadgeRules([]byte(`
+- id: 100
+  name: Christmas Spirit
+  scope: leg
+  condition: date.month == 12 && player.is_winner
+- id: 101
+  name: Ton Collector
+  scope: visit
+  condition: visit.score >= 100
+  value: visit.score
+  levels: [100, 140, 180]
+  is_active: false
+`))
+	assert.Nil(t, err, "should not return error")
+	assert.Len(t, rules, 2, "should parse all rules")
+	assert.Equal(t, rules[0].Name, "Christmas Spirit", "should be equal")
+	assert.True(t, rules[0].IsActive, "should be active by default")
+	assert.False(t, rules[1].IsActive, "should be inactive")
+	assert.Equal(t, rules[1].Levels, []int{100, 140, 180}, "should be equal")
+
+	_, err = ParseBadgeRules([]byte(`[{"id": 100, "name": "Broken", "scope": "leg", "condition": "player.unknown"}]`))
+	assert.IsType(t, &BadgeRuleError{}, err, "should return error")
+}
@@ -119,6 +119,14 @@ func (dart Dart) GetJDCPracticeScore(target Target) int {
 	return 0
 }
 
+// GetBobs27Score will get the Bob's 27 score for the given dart on target
+func (dart Dart) GetBobs27Score(target Target) int {
+	if target.Value == dart.ValueRaw() && contains(target.multipliers, dart.Multiplier) {
+		return dart.GetScore()
+	}
+	return 0
+}
+
 // IsCheckoutAttempt checks if this dart was a checkout attempt
 func (dart Dart) IsCheckoutAttempt(currentScore int, dartNum int, outshotTypeId int) bool {
 	if !dart.Value.Valid {
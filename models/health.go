@@ -0,0 +1,22 @@
+package models
+
+// Readiness struct used for reporting if the API is ready to serve requests
+type Readiness struct {
+	Ready  bool                    `json:"ready"`
+	Checks map[string]*HealthCheck `json:"checks"`
+}
+
+// HealthCheck struct used for storing the result of a single readiness check
+type HealthCheck struct {
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+// AddCheck will add the given check, marking the service as not ready if it failed
+func (r *Readiness) AddCheck(name string, ok bool, message string) {
+	if r.Checks == nil {
+		r.Checks = make(map[string]*HealthCheck)
+	}
+	r.Checks[name] = &HealthCheck{OK: ok, Message: message}
+	r.Ready = r.Ready && ok
+}
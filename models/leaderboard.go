@@ -0,0 +1,94 @@
+package models
+
+import (
+	"sort"
+
+	"github.com/guregu/null"
+)
+
+// LeaderboardMinimumLegs is the default number of legs a player must have played to be ranked
+const LeaderboardMinimumLegs = 10
+
+// Leaderboard struct used for storing players ranked by the key metric of a match type
+type Leaderboard struct {
+	MatchType         int                 `json:"match_type"`
+	Metric            string              `json:"metric"`
+	LowerIsBetter     bool                `json:"lower_is_better"`
+	OfficeID          null.Int            `json:"office_id"`
+	LastLegs          int                 `json:"last_legs"`
+	MinimumLegs       int                 `json:"minimum_legs"`
+	ActivePeriodWeeks int                 `json:"active_period_weeks"`
+	Entries           []*LeaderboardEntry `json:"entries"`
+}
+
+// LeaderboardEntry struct used for storing the position of a single player on a leaderboard
+type LeaderboardEntry struct {
+	PlayerID      int        `json:"player_id"`
+	OfficeID      null.Int   `json:"office_id"`
+	Rank          int        `json:"rank"`
+	Percentile    float64    `json:"percentile"`
+	Value         float64    `json:"value"`
+	LegsPlayed    int        `json:"legs_played"`
+	LastPlayedLeg null.Time  `json:"last_played_leg"`
+	PreviousRank  null.Int   `json:"previous_rank"`
+	PreviousValue null.Float `json:"previous_value"`
+	RankChange    null.Int   `json:"rank_change"`
+}
+
+// LeaderboardError used when a leaderboard is requested for a match type without one
+type LeaderboardError struct {
+	Err error
+}
+
+func (e *LeaderboardError) Error() string {
+	return e.Err.Error()
+}
+
+// RankLeaderboard will sort the given entries best first, and set rank and percentile of each.
+// Players with the same value share the same rank, and percentile is the share of other players ranked below
+func RankLeaderboard(entries []*LeaderboardEntry, lowerIsBetter bool) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Value == entries[j].Value {
+			return entries[i].PlayerID < entries[j].PlayerID
+		}
+		if lowerIsBetter {
+			return entries[i].Value < entries[j].Value
+		}
+		return entries[i].Value > entries[j].Value
+	})
+
+	for i, entry := range entries {
+		if i > 0 && entry.Value == entries[i-1].Value {
+			entry.Rank = entries[i-1].Rank
+		} else {
+			entry.Rank = i + 1
+		}
+	}
+	for i, entry := range entries {
+		if len(entries) == 1 {
+			entry.Percentile = 100
+			continue
+		}
+		// Entries after the last one sharing this rank are ranked below
+		below := len(entries) - i - 1
+		for j := i + 1; j < len(entries) && entries[j].Rank == entry.Rank; j++ {
+			below--
+		}
+		entry.Percentile = percent(below, len(entries)-1)
+	}
+}
+
+// SetPrevious will set the rank and value of each player on the previous leaderboard, and how many places they moved
+func (l *Leaderboard) SetPrevious(previous []*LeaderboardEntry) {
+	ranks := make(map[int]*LeaderboardEntry)
+	for _, entry := range previous {
+		ranks[entry.PlayerID] = entry
+	}
+	for _, entry := range l.Entries {
+		if prev, ok := ranks[entry.PlayerID]; ok {
+			entry.PreviousRank = null.IntFrom(int64(prev.Rank))
+			entry.PreviousValue = null.FloatFrom(prev.Value)
+			entry.RankChange = null.IntFrom(int64(prev.Rank - entry.Rank))
+		}
+	}
+}
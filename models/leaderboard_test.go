@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankLeaderboard(t *testing.T) {
+	entries := []*LeaderboardEntry{
+		{PlayerID: 1, Value: 45.5},
+		{PlayerID: 2, Value: 60.1},
+		{PlayerID: 3, Value: 52.0},
+		{PlayerID: 4, Value: 52.0},
+		{PlayerID: 5, Value: 38.2},
+	}
+	RankLeaderboard(entries, false)
+
+	assert.Equal(t, 2, entries[0].PlayerID)
+	assert.Equal(t, 1, entries[0].Rank)
+	assert.Equal(t, 100.0, entries[0].Percentile)
+
+	// Equal values share rank and percentile
+	assert.Equal(t, 2, entries[1].Rank)
+	assert.Equal(t, 2, entries[2].Rank)
+	assert.Equal(t, 50.0, entries[1].Percentile)
+	assert.Equal(t, 50.0, entries[2].Percentile)
+	assert.Equal(t, 4, entries[3].Rank)
+
+	assert.Equal(t, 5, entries[4].PlayerID)
+	assert.Equal(t, 5, entries[4].Rank)
+	assert.Equal(t, 0.0, entries[4].Percentile)
+}
+
+func TestRankLeaderboardLowerIsBetter(t *testing.T) {
+	entries := []*LeaderboardEntry{{PlayerID: 1, Value: 30}, {PlayerID: 2, Value: 24}}
+	RankLeaderboard(entries, true)
+	assert.Equal(t, 2, entries[0].PlayerID)
+	assert.Equal(t, 100.0, entries[0].Percentile)
+
+	single := []*LeaderboardEntry{{PlayerID: 1, Value: 30}}
+	RankLeaderboard(single, true)
+	assert.Equal(t, 1, single[0].Rank)
+	assert.Equal(t, 100.0, single[0].Percentile)
+}
+
+func TestLeaderboardSetPrevious(t *testing.T) {
+	leaderboard := &Leaderboard{Entries: []*LeaderboardEntry{{PlayerID: 1, Rank: 1, Value: 55}, {PlayerID: 2, Rank: 2, Value: 50}, {PlayerID: 3, Rank: 3, Value: 40}}}
+	leaderboard.SetPrevious([]*LeaderboardEntry{{PlayerID: 2, Rank: 1, Value: 52}, {PlayerID: 1, Rank: 2, Value: 49}})
+
+	assert.Equal(t, int64(1), leaderboard.Entries[0].RankChange.Int64)
+	assert.Equal(t, int64(-1), leaderboard.Entries[1].RankChange.Int64)
+	assert.Equal(t, 52.0, leaderboard.Entries[1].PreviousValue.Float64)
+	assert.False(t, leaderboard.Entries[2].PreviousRank.Valid)
+}
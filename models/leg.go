@@ -2,6 +2,8 @@ package models
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"math"
 	"math/rand"
 	"time"
@@ -41,6 +43,57 @@ type LegParameters struct {
 	StartingLives null.Int     `json:"starting_lives,omitempty"`
 	PointsToWin   null.Int     `json:"points_to_win,omitempty"`
 	MaxRounds     null.Int     `json:"max_rounds,omitempty"`
+	MinTarget     null.Int     `json:"min_target,omitempty"`
+	MaxTarget     null.Int     `json:"max_target,omitempty"`
+}
+
+// GetMaxRounds will return the configured number of rounds, or the given default if none is configured
+func (params *LegParameters) GetMaxRounds(defaultRounds int) int {
+	if params != nil && params.MaxRounds.Valid && params.MaxRounds.Int64 > 0 {
+		return int(params.MaxRounds.Int64)
+	}
+	return defaultRounds
+}
+
+// Validate will check that the parameters are valid for a leg of the given match type
+func (params *LegParameters) Validate(matchType int) error {
+	switch matchType {
+	case ONETWENTYONE:
+		// Without a target or a limit a leg of 121 would never finish
+		if params == nil || (params.PointsToWin.ValueOrZero() <= 0 && params.MaxRounds.ValueOrZero() <= 0) {
+			return &MatchConfigError{Err: errors.New("121 requires points_to_win or max_rounds")}
+		}
+	case GOLF:
+		if holes := params.GetMaxRounds(GolfHoles); holes != GolfHoles && holes != GolfMaxHoles {
+			return &MatchConfigError{Err: fmt.Errorf("golf must be played over %d or %d holes", GolfHoles, GolfMaxHoles)}
+		}
+	}
+	return nil
+}
+
+// GetNextCheckoutTarget will return the next target in a game of 121, moving it up after a successful checkout
+// and down after a failed one, while keeping it within the configured limits
+func (params LegParameters) GetNextCheckoutTarget(current int, success bool) int {
+	next := current - 1
+	if success {
+		next = current + 1
+	}
+	// 170 is the highest possible checkout, and 2 the lowest
+	max := 170
+	if params.MaxTarget.Valid && int(params.MaxTarget.Int64) < max {
+		max = int(params.MaxTarget.Int64)
+	}
+	min := 2
+	if params.MinTarget.Valid && int(params.MinTarget.Int64) > min {
+		min = int(params.MinTarget.Int64)
+	}
+	if next > max {
+		next = max
+	}
+	if next < min {
+		next = min
+	}
+	return next
 }
 
 // IsTicTacToeWinner will check if the given player has won a game of Tic Tac Toe
@@ -150,35 +203,40 @@ func (params LegParameters) IsTicTacToeDraw() bool {
 	return draw
 }
 
+// legJSON is the JSON representation of Leg. A separate type is used to get consistent order of JSON key-value pairs
+type legJSON struct {
+	ID                 int                 `json:"id"`
+	StartTime          null.Time           `json:"start_time,omitempty"`
+	Endtime            null.Time           `json:"end_time"`
+	StartingScore      int                 `json:"starting_score"`
+	IsFinished         bool                `json:"is_finished"`
+	CurrentPlayerID    int                 `json:"current_player_id"`
+	WinnerPlayerID     null.Int            `json:"winner_player_id"`
+	LegType            *MatchType          `json:"leg_type"`
+	CreatedAt          time.Time           `json:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at"`
+	BoardStreamURL     null.String         `json:"board_stream_url,omitempty"`
+	MatchID            int                 `json:"match_id"`
+	HasScores          bool                `json:"has_scores"`
+	Round              int                 `json:"round"`
+	Players            []int               `json:"players,omitempty"`
+	DartsThrown        int                 `json:"darts_thrown,omitempty"`
+	Visits             []*Visit            `json:"visits"`
+	Hits               map[int64]*Hits     `json:"hits,omitempty"`
+	CheckoutStatistics *CheckoutStatistics `json:"checkout_statistics,omitempty"`
+	Statistics         interface{}         `json:"statistics,omitempty"`
+	Parameters         *LegParameters      `json:"parameters,omitempty"`
+}
+
+// JSONType will return the type Leg is serialized as, to describe it in the API documentation
+func (leg Leg) JSONType() interface{} {
+	return legJSON{}
+}
+
 // MarshalJSON will marshall the given object to JSON
 func (leg Leg) MarshalJSON() ([]byte, error) {
-	// Use a type to get consistent order of JSON key-value pairs.
-	type legJSON struct {
-		ID                 int                 `json:"id"`
-		StartTime          null.Time           `json:"start_time,omitempty"`
-		Endtime            null.Time           `json:"end_time"`
-		StartingScore      int                 `json:"starting_score"`
-		IsFinished         bool                `json:"is_finished"`
-		CurrentPlayerID    int                 `json:"current_player_id"`
-		WinnerPlayerID     null.Int            `json:"winner_player_id"`
-		LegType            *MatchType          `json:"leg_type"`
-		CreatedAt          time.Time           `json:"created_at"`
-		UpdatedAt          time.Time           `json:"updated_at"`
-		BoardStreamURL     null.String         `json:"board_stream_url,omitempty"`
-		MatchID            int                 `json:"match_id"`
-		HasScores          bool                `json:"has_scores"`
-		Round              int                 `json:"round"`
-		Players            []int               `json:"players,omitempty"`
-		DartsThrown        int                 `json:"darts_thrown,omitempty"`
-		Visits             []*Visit            `json:"visits"`
-		Hits               map[int64]*Hits     `json:"hits,omitempty"`
-		CheckoutStatistics *CheckoutStatistics `json:"checkout_statistics,omitempty"`
-		Statistics         interface{}         `json:"statistics,omitempty"`
-		Parameters         *LegParameters      `json:"parameters,omitempty"`
-	}
-
 	round := int(math.Floor(float64(len(leg.Visits))/float64(len(leg.Players))) + 1)
-	if leg.LegType.ID == ONESEVENTY {
+	if leg.LegType.ID == ONESEVENTY || leg.LegType.ID == ONETWENTYONE {
 		round = len(leg.Visits)/len(leg.Players)/3 + 1
 	}
 
@@ -340,7 +398,7 @@ func (p2l *Player2Leg) AddVisitStatistics(leg Leg) {
 			} else if visit.IsScore180() {
 				p2l.VisitStatistics.Score180Counter++
 			}
-			if leg.LegType.ID != ONESEVENTY {
+			if leg.LegType.ID != ONESEVENTY && leg.LegType.ID != ONETWENTYONE {
 				p2l.DartsThrown = visit.DartsThrown
 			}
 		}
@@ -352,6 +410,9 @@ func (player *Player2Leg) IsOut(matchType int, visit Visit) bool {
 	if matchType == KNOCKOUT {
 		// If player has less than 1 life, and is not the current player
 		return player.Lives.Int64 < 1 && player.PlayerID != visit.PlayerID
+	} else if matchType == BOBS27 {
+		// If player has dropped to zero or below, and is not the current player
+		return player.CurrentScore <= 0 && player.PlayerID != visit.PlayerID
 	}
 	// For all other types players are never out
 	return false
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLegParametersValidate_121 will check that a leg of 121 requires either points to win or max rounds
+func TestLegParametersValidate_121(t *testing.T) {
+	var params *LegParameters
+	assert.Error(t, params.Validate(ONETWENTYONE), "should reject missing parameters")
+	assert.Error(t, (&LegParameters{}).Validate(ONETWENTYONE), "should reject leg which never finishes")
+	assert.NoError(t, (&LegParameters{PointsToWin: null.IntFrom(3)}).Validate(ONETWENTYONE), "should accept points to win")
+	assert.NoError(t, (&LegParameters{MaxRounds: null.IntFrom(10)}).Validate(ONETWENTYONE), "should accept max rounds")
+	assert.NoError(t, params.Validate(X01), "should accept missing parameters for other match types")
+}
+
+// TestLegParametersValidate_Golf will check that Golf is played over 9 or 18 holes
+func TestLegParametersValidate_Golf(t *testing.T) {
+	var params *LegParameters
+	assert.NoError(t, params.Validate(GOLF), "should default to 9 holes")
+	assert.NoError(t, (&LegParameters{MaxRounds: null.IntFrom(GolfMaxHoles)}).Validate(GOLF), "should accept 18 holes")
+	assert.Error(t, (&LegParameters{MaxRounds: null.IntFrom(12)}).Validate(GOLF), "should reject 12 holes")
+	assert.Error(t, (&LegParameters{MaxRounds: null.IntFrom(36)}).Validate(GOLF), "should reject more than 18 holes")
+}
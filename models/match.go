@@ -53,6 +53,14 @@ const (
 	SCAM = 16
 	// ONESEVENTY contenst representing type 17
 	ONESEVENTY = 17
+	// BOBS27 constant representing type 18
+	BOBS27 = 18
+	// ONETWENTYONE constant representing type 19
+	ONETWENTYONE = 19
+	// GOLF constant representing type 20
+	GOLF = 20
+	// BASEBALL constant representing type 21
+	BASEBALL = 21
 )
 
 var MatchTypes = map[int]string{
@@ -70,7 +78,13 @@ var MatchTypes = map[int]string{
 	KILLBULL:        "Kill Bull",
 	GOTCHA:          "Gotcha",
 	JDCPRACTICE:     "JDC Practice",
-	KNOCKOUT:        "Knockout"}
+	KNOCKOUT:        "Knockout",
+	SCAM:            "Scam",
+	ONESEVENTY:      "170",
+	BOBS27:          "Bob's 27",
+	ONETWENTYONE:    "121",
+	GOLF:            "Golf",
+	BASEBALL:        "Baseball"}
 
 // TargetsBermudaTriangle contains the target for each round of Bermuda Triangle
 var TargetsBermudaTriangle = [13]Target{
@@ -134,6 +148,44 @@ var TargetsJDCPractice = [19]Target{
 	{Value: 19, multipliers: []int64{1, 2, 3}},
 	{Value: 20, multipliers: []int64{1, 2, 3}}}
 
+// TargetsBobs27 contains the double to aim at for each round of Bob's 27
+var TargetsBobs27 = [21]Target{
+	{Value: 1, multipliers: []int64{2}},
+	{Value: 2, multipliers: []int64{2}},
+	{Value: 3, multipliers: []int64{2}},
+	{Value: 4, multipliers: []int64{2}},
+	{Value: 5, multipliers: []int64{2}},
+	{Value: 6, multipliers: []int64{2}},
+	{Value: 7, multipliers: []int64{2}},
+	{Value: 8, multipliers: []int64{2}},
+	{Value: 9, multipliers: []int64{2}},
+	{Value: 10, multipliers: []int64{2}},
+	{Value: 11, multipliers: []int64{2}},
+	{Value: 12, multipliers: []int64{2}},
+	{Value: 13, multipliers: []int64{2}},
+	{Value: 14, multipliers: []int64{2}},
+	{Value: 15, multipliers: []int64{2}},
+	{Value: 16, multipliers: []int64{2}},
+	{Value: 17, multipliers: []int64{2}},
+	{Value: 18, multipliers: []int64{2}},
+	{Value: 19, multipliers: []int64{2}},
+	{Value: 20, multipliers: []int64{2}},
+	{Value: 25, multipliers: []int64{2}}}
+
+// Bobs27StartingScore is the score each player starts with in Bob's 27
+const Bobs27StartingScore = 27
+
+const (
+	// GolfHoles is the default number of holes in a game of Golf
+	GolfHoles = 9
+	// GolfMaxHoles is the maximum number of holes in a game of Golf
+	GolfMaxHoles = 18
+	// GolfMissStrokes is the number of strokes given for a hole where the target was not hit
+	GolfMissStrokes = 5
+	// BaseballInnings is the default number of innings in a game of Baseball
+	BaseballInnings = 9
+)
+
 // Match struct used for storing matches
 type Match struct {
 	ID               int                `json:"id"`
@@ -169,43 +221,49 @@ type Match struct {
 	LegsWon          []int              `json:"legs_won,omitempty"`
 }
 
+// matchJSON is the JSON representation of Match. A separate type is used to get consistent order of JSON key-value pairs
+type matchJSON struct {
+	ID               int                `json:"id"`
+	CurrentLegID     null.Int           `json:"current_leg_id"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	EndTime          time.Time          `json:"end_time,omitempty"`
+	MatchType        *MatchType         `json:"match_type"`
+	MatchMode        *MatchMode         `json:"match_mode"`
+	WinnerID         null.Int           `json:"winner_id"`
+	IsFinished       bool               `json:"is_finished"`
+	IsAbandoned      bool               `json:"is_abandoned"`
+	IsWalkover       bool               `json:"is_walkover"`
+	IsBye            bool               `json:"is_bye"`
+	IsStarted        bool               `json:"is_started"`
+	IsPlayersDecided bool               `json:"is_players_decided"`
+	HasScores        bool               `json:"has_scores"`
+	OfficeID         null.Int           `json:"office_id,omitempty"`
+	OweTypeID        null.Int           `json:"owe_type_id"`
+	VenueID          null.Int           `json:"venue_id"`
+	IsPractice       bool               `json:"is_practice"`
+	Venue            *Venue             `json:"venue"`
+	OweType          *OweType           `json:"owe_type,omitempty"`
+	TournamentID     null.Int           `json:"tournament_id,omitempty"`
+	Tournament       *MatchTournament   `json:"tournament,omitempty"`
+	Players          []int              `json:"players"`
+	Legs             []*Leg             `json:"legs,omitempty"`
+	CurrentLegNumber string             `json:"current_leg_num"`
+	PlayerHandicaps  map[int]int        `json:"player_handicaps,omitempty"`
+	BotPlayerConfig  map[int]*BotConfig `json:"bot_player_config,omitempty"`
+	FirstThrow       null.Time          `json:"first_throw_time,omitempty"`
+	LastThrow        null.Time          `json:"last_throw_time,omitempty"`
+	EloChange        map[int]*PlayerElo `json:"elo_change,omitempty"`
+	LegsWon          []int              `json:"legs_won,omitempty"`
+}
+
+// JSONType will return the type Match is serialized as, to describe it in the API documentation
+func (match Match) JSONType() interface{} {
+	return matchJSON{}
+}
+
 // MarshalJSON will marshall the given object to JSON
 func (match Match) MarshalJSON() ([]byte, error) {
-	// Use a type to get consistnt order of JSON key-value pairs.
-	type matchJSON struct {
-		ID               int                `json:"id"`
-		CurrentLegID     null.Int           `json:"current_leg_id"`
-		CreatedAt        time.Time          `json:"created_at"`
-		UpdatedAt        time.Time          `json:"updated_at"`
-		EndTime          time.Time          `json:"end_time,omitempty"`
-		MatchType        *MatchType         `json:"match_type"`
-		MatchMode        *MatchMode         `json:"match_mode"`
-		WinnerID         null.Int           `json:"winner_id"`
-		IsFinished       bool               `json:"is_finished"`
-		IsAbandoned      bool               `json:"is_abandoned"`
-		IsWalkover       bool               `json:"is_walkover"`
-		IsBye            bool               `json:"is_bye"`
-		IsStarted        bool               `json:"is_started"`
-		IsPlayersDecided bool               `json:"is_players_decided"`
-		HasScores        bool               `json:"has_scores"`
-		OfficeID         null.Int           `json:"office_id,omitempty"`
-		OweTypeID        null.Int           `json:"owe_type_id"`
-		VenueID          null.Int           `json:"venue_id"`
-		IsPractice       bool               `json:"is_practice"`
-		Venue            *Venue             `json:"venue"`
-		OweType          *OweType           `json:"owe_type,omitempty"`
-		TournamentID     null.Int           `json:"tournament_id,omitempty"`
-		Tournament       *MatchTournament   `json:"tournament,omitempty"`
-		Players          []int              `json:"players"`
-		Legs             []*Leg             `json:"legs,omitempty"`
-		CurrentLegNumber string             `json:"current_leg_num"`
-		PlayerHandicaps  map[int]int        `json:"player_handicaps,omitempty"`
-		BotPlayerConfig  map[int]*BotConfig `json:"bot_player_config,omitempty"`
-		FirstThrow       null.Time          `json:"first_throw_time,omitempty"`
-		LastThrow        null.Time          `json:"last_throw_time,omitempty"`
-		EloChange        map[int]*PlayerElo `json:"elo_change,omitempty"`
-		LegsWon          []int              `json:"legs_won,omitempty"`
-	}
 	legPostfix := [4]string{"st", "nd", "rd", "th"}
 	idx := ((len(match.Legs)+90)%100-10)%10 - 1
 	if idx < 0 {
@@ -5,7 +5,7 @@ import "github.com/guregu/null"
 // MatchPreset struct used for storing a match preset
 type MatchPreset struct {
 	ID            int         `json:"id"`
-	Name          string      `json:"name"`
+	Name          string      `json:"name" validate:"required"`
 	MatchType     *MatchType  `json:"match_type"`
 	MatchMode     *MatchMode  `json:"match_mode"`
 	StartingScore null.Int    `json:"starting_score"`
@@ -3,7 +3,7 @@ package models
 // Office struct used for storing offices
 type Office struct {
 	ID       int    `json:"id"`
-	Name     string `json:"name"`
+	Name     string `json:"name" validate:"required"`
 	IsGlobal bool   `json:"is_global"`
 	IsActive bool   `json:"is_active"`
 }
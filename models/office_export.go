@@ -0,0 +1,366 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// OfficeExportVersion is the current version of the office export format. Version 2 added the names of match modes,
+// owe types and badges, so they can be matched by name when importing
+const OfficeExportVersion = 2
+
+// OfficeExport struct used for moving all data of an office between instances
+type OfficeExport struct {
+	Version          int                         `json:"version"`
+	ExportedAt       time.Time                   `json:"exported_at"`
+	Office           *Office                     `json:"office"`
+	Players          []*OfficeExportPlayer       `json:"players"`
+	Venues           []*Venue                    `json:"venues"`
+	Presets          []*OfficeExportPreset       `json:"presets"`
+	TournamentGroups []*TournamentGroup          `json:"tournament_groups"`
+	Tournaments      []*OfficeExportTournament   `json:"tournaments"`
+	Matches          []*OfficeExportMatch        `json:"matches"`
+	Badges           []*OfficeExportBadge        `json:"badges"`
+	Elo              []*PlayerElo                `json:"elo"`
+	EloChangelog     []*OfficeExportEloChangelog `json:"elo_changelog"`
+	MatchModes       []*OfficeExportReference    `json:"match_modes"`
+	OweTypes         []*OfficeExportReference    `json:"owe_types"`
+	BadgeTypes       []*OfficeExportReference    `json:"badge_types"`
+}
+
+// OfficeExportReference struct used for exporting the name of an entity which is not part of the export, such as a
+// match mode, so it can be matched by name when importing
+type OfficeExportReference struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// OfficeExportPlayer struct used for exporting a player
+type OfficeExportPlayer struct {
+	ID             int         `json:"id"`
+	FirstName      string      `json:"first_name"`
+	LastName       null.String `json:"last_name"`
+	VocalName      null.String `json:"vocal_name"`
+	Nickname       null.String `json:"nickname"`
+	SlackHandle    null.String `json:"slack_handle"`
+	Color          null.String `json:"color"`
+	ProfilePicURL  null.String `json:"profile_pic_url"`
+	SmartcardUID   null.String `json:"smartcard_uid"`
+	BoardStreamURL null.String `json:"board_stream_url"`
+	BoardStreamCSS null.String `json:"board_stream_css"`
+	IsActive       bool        `json:"is_active"`
+	IsBot          bool        `json:"is_bot"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// OfficeExportPreset struct used for exporting a match preset
+type OfficeExportPreset struct {
+	ID            int         `json:"id"`
+	Name          string      `json:"name"`
+	MatchTypeID   int         `json:"match_type_id"`
+	MatchModeID   int         `json:"match_mode_id"`
+	StartingScore null.Int    `json:"starting_score"`
+	Players       []int       `json:"players,omitempty"`
+	SmartcardUID  null.String `json:"smartcard_uid"`
+	Description   null.String `json:"description"`
+}
+
+// OfficeExportTournament struct used for exporting a tournament
+type OfficeExportTournament struct {
+	ID                   int                             `json:"id"`
+	Name                 string                          `json:"name"`
+	ShortName            string                          `json:"short_name"`
+	IsFinished           bool                            `json:"is_finished"`
+	IsPlayoffs           bool                            `json:"is_playoffs"`
+	PlayoffsTournamentID null.Int                        `json:"playoffs_tournament_id"`
+	ManualAdmin          bool                            `json:"manual_admin"`
+	StartTime            null.Time                       `json:"start_time"`
+	EndTime              null.Time                       `json:"end_time"`
+	Players              []*OfficeExportTournamentPlayer `json:"players"`
+}
+
+// OfficeExportTournamentPlayer struct used for exporting a player in a tournament
+type OfficeExportTournamentPlayer struct {
+	PlayerID          int `json:"player_id"`
+	TournamentGroupID int `json:"tournament_group_id"`
+}
+
+// OfficeExportMatch struct used for exporting a match with all legs
+type OfficeExportMatch struct {
+	ID           int                `json:"id"`
+	MatchTypeID  int                `json:"match_type_id"`
+	MatchModeID  int                `json:"match_mode_id"`
+	OweTypeID    null.Int           `json:"owe_type_id"`
+	VenueID      null.Int           `json:"venue_id"`
+	TournamentID null.Int           `json:"tournament_id"`
+	IsFinished   bool               `json:"is_finished"`
+	IsAbandoned  bool               `json:"is_abandoned"`
+	IsWalkover   bool               `json:"is_walkover"`
+	IsPractice   bool               `json:"is_practice"`
+	WinnerID     null.Int           `json:"winner_id"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    null.Time          `json:"updated_at"`
+	Legs         []*OfficeExportLeg `json:"legs"`
+}
+
+// OfficeExportLeg struct used for exporting a leg with all visits
+type OfficeExportLeg struct {
+	ID              int                       `json:"id"`
+	LegTypeID       null.Int                  `json:"leg_type_id"`
+	StartingScore   int                       `json:"starting_score"`
+	IsFinished      bool                      `json:"is_finished"`
+	HasScores       bool                      `json:"has_scores"`
+	WinnerID        null.Int                  `json:"winner_id"`
+	CurrentPlayerID int                       `json:"current_player_id"`
+	CreatedAt       time.Time                 `json:"created_at"`
+	UpdatedAt       null.Time                 `json:"updated_at"`
+	EndTime         null.Time                 `json:"end_time"`
+	Players         []*OfficeExportLegPlayer  `json:"players"`
+	Parameters      *LegParameters            `json:"parameters,omitempty"`
+	Visits          []*OfficeExportVisit      `json:"visits"`
+	Statistics      []*OfficeExportStatistics `json:"statistics"`
+}
+
+// OfficeExportStatistics struct used for exporting the statistics of a player in a leg, as stored in the given table
+type OfficeExportStatistics struct {
+	Table    string                 `json:"table"`
+	PlayerID int                    `json:"player_id"`
+	Values   map[string]interface{} `json:"values"`
+}
+
+// exportColumnName matches the names allowed for statistics tables and columns
+var exportColumnName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// OfficeExportLegPlayer struct used for exporting a player in a leg
+type OfficeExportLegPlayer struct {
+	PlayerID int      `json:"player_id"`
+	Order    int      `json:"order"`
+	Handicap null.Int `json:"handicap"`
+}
+
+// OfficeExportVisit struct used for exporting a visit
+type OfficeExportVisit struct {
+	ID                   int       `json:"id"`
+	PlayerID             int       `json:"player_id"`
+	FirstDart            null.Int  `json:"first_dart"`
+	FirstDartMultiplier  int64     `json:"first_dart_multiplier"`
+	SecondDart           null.Int  `json:"second_dart"`
+	SecondDartMultiplier int64     `json:"second_dart_multiplier"`
+	ThirdDart            null.Int  `json:"third_dart"`
+	ThirdDartMultiplier  int64     `json:"third_dart_multiplier"`
+	IsBust               bool      `json:"is_bust"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+// OfficeExportBadge struct used for exporting a badge unlocked by a player
+type OfficeExportBadge struct {
+	PlayerID         int       `json:"player_id"`
+	BadgeID          int       `json:"badge_id"`
+	Level            null.Int  `json:"level"`
+	Value            null.Int  `json:"value"`
+	LegID            null.Int  `json:"leg_id"`
+	VisitID          null.Int  `json:"visit_id"`
+	MatchID          null.Int  `json:"match_id"`
+	TournamentID     null.Int  `json:"tournament_id"`
+	OpponentPlayerID null.Int  `json:"opponent_player_id"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// OfficeExportEloChangelog struct used for exporting a single Elo change
+type OfficeExportEloChangelog struct {
+	MatchID          int      `json:"match_id"`
+	PlayerID         int      `json:"player_id"`
+	OldElo           int      `json:"old_elo"`
+	NewElo           int      `json:"new_elo"`
+	OldTournamentElo null.Int `json:"old_tournament_elo"`
+	NewTournamentElo null.Int `json:"new_tournament_elo"`
+}
+
+// OfficeImportReport struct used for reporting what was changed by an import
+type OfficeImportReport struct {
+	Version  int                   `json:"version"`
+	DryRun   bool                  `json:"dry_run"`
+	OfficeID int                   `json:"office_id"`
+	Created  map[string]int        `json:"created"`
+	Matched  map[string]int        `json:"matched"`
+	Players  []*OfficeImportPlayer `json:"players"`
+}
+
+// OfficeImportPlayer struct used for reporting how a player was imported
+type OfficeImportPlayer struct {
+	SourceID  int    `json:"source_id"`
+	PlayerID  int    `json:"player_id"`
+	Name      string `json:"name"`
+	MatchedBy string `json:"matched_by,omitempty"`
+}
+
+// Validate will check that the export is of a supported version, and that all references within it can be resolved
+func (export *OfficeExport) Validate() error {
+	if export.Version < 1 || export.Version > OfficeExportVersion {
+		return fmt.Errorf("unsupported export version %d, expected at most %d", export.Version, OfficeExportVersion)
+	}
+	if export.Office == nil || export.Office.Name == "" {
+		return fmt.Errorf("export does not contain an office")
+	}
+
+	players := make(map[int]bool)
+	for _, player := range export.Players {
+		if player.FirstName == "" {
+			return fmt.Errorf("player %d does not have a name", player.ID)
+		}
+		players[player.ID] = true
+	}
+	venues := make(map[int64]bool)
+	for _, venue := range export.Venues {
+		venues[venue.ID.Int64] = true
+	}
+	groups := make(map[int]bool)
+	for _, group := range export.TournamentGroups {
+		groups[group.ID] = true
+	}
+	tournaments := make(map[int64]bool)
+	for _, tournament := range export.Tournaments {
+		tournaments[int64(tournament.ID)] = true
+	}
+	for _, tournament := range export.Tournaments {
+		if tournament.PlayoffsTournamentID.Valid && !tournaments[tournament.PlayoffsTournamentID.Int64] {
+			return fmt.Errorf("tournament %d references unknown playoffs tournament %d", tournament.ID, tournament.PlayoffsTournamentID.Int64)
+		}
+		for _, player := range tournament.Players {
+			if !players[player.PlayerID] {
+				return fmt.Errorf("tournament %d references unknown player %d", tournament.ID, player.PlayerID)
+			}
+			if !groups[player.TournamentGroupID] {
+				return fmt.Errorf("tournament %d references unknown group %d", tournament.ID, player.TournamentGroupID)
+			}
+		}
+	}
+	for _, preset := range export.Presets {
+		for _, id := range preset.Players {
+			if !players[id] {
+				return fmt.Errorf("preset %d references unknown player %d", preset.ID, id)
+			}
+		}
+	}
+
+	// Exports before version 2 do not contain names, and their IDs are only checked to exist when importing
+	hasReferences := export.Version >= 2
+	matchModes := referenceIDs(export.MatchModes)
+	oweTypes := referenceIDs(export.OweTypes)
+	badgeTypes := referenceIDs(export.BadgeTypes)
+	for _, preset := range export.Presets {
+		if hasReferences && !matchModes[preset.MatchModeID] {
+			return fmt.Errorf("preset %d references unknown match mode %d", preset.ID, preset.MatchModeID)
+		}
+	}
+
+	matches := make(map[int64]bool)
+	legs := make(map[int64]bool)
+	visits := make(map[int64]bool)
+	for _, match := range export.Matches {
+		matches[int64(match.ID)] = true
+		if _, ok := MatchTypes[match.MatchTypeID]; !ok {
+			return fmt.Errorf("match %d has unknown match type %d", match.ID, match.MatchTypeID)
+		}
+		if hasReferences && !matchModes[match.MatchModeID] {
+			return fmt.Errorf("match %d references unknown match mode %d", match.ID, match.MatchModeID)
+		}
+		if hasReferences && match.OweTypeID.Valid && !oweTypes[int(match.OweTypeID.Int64)] {
+			return fmt.Errorf("match %d references unknown owe type %d", match.ID, match.OweTypeID.Int64)
+		}
+		if match.VenueID.Valid && !venues[match.VenueID.Int64] {
+			return fmt.Errorf("match %d references unknown venue %d", match.ID, match.VenueID.Int64)
+		}
+		if match.TournamentID.Valid && !tournaments[match.TournamentID.Int64] {
+			return fmt.Errorf("match %d references unknown tournament %d", match.ID, match.TournamentID.Int64)
+		}
+		if match.WinnerID.Valid && !players[int(match.WinnerID.Int64)] {
+			return fmt.Errorf("match %d references unknown winner %d", match.ID, match.WinnerID.Int64)
+		}
+		if len(match.Legs) == 0 {
+			return fmt.Errorf("match %d does not have any legs", match.ID)
+		}
+		for _, leg := range match.Legs {
+			legs[int64(leg.ID)] = true
+			legPlayers := make(map[int]bool)
+			for _, player := range leg.Players {
+				if !players[player.PlayerID] {
+					return fmt.Errorf("leg %d references unknown player %d", leg.ID, player.PlayerID)
+				}
+				legPlayers[player.PlayerID] = true
+			}
+			if !legPlayers[leg.CurrentPlayerID] {
+				return fmt.Errorf("leg %d has current player %d which is not part of the leg", leg.ID, leg.CurrentPlayerID)
+			}
+			if leg.WinnerID.Valid && !legPlayers[int(leg.WinnerID.Int64)] {
+				return fmt.Errorf("leg %d has winner %d which is not part of the leg", leg.ID, leg.WinnerID.Int64)
+			}
+			for _, visit := range leg.Visits {
+				if !legPlayers[visit.PlayerID] {
+					return fmt.Errorf("visit %d in leg %d references player %d which is not part of the leg", visit.ID, leg.ID, visit.PlayerID)
+				}
+				visits[int64(visit.ID)] = true
+			}
+			for _, stats := range leg.Statistics {
+				if !legPlayers[stats.PlayerID] {
+					return fmt.Errorf("statistics in leg %d references player %d which is not part of the leg", leg.ID, stats.PlayerID)
+				}
+				if !exportColumnName.MatchString(stats.Table) {
+					return fmt.Errorf("statistics in leg %d has invalid table '%s'", leg.ID, stats.Table)
+				}
+				for column := range stats.Values {
+					if !exportColumnName.MatchString(column) {
+						return fmt.Errorf("statistics in leg %d has invalid column '%s'", leg.ID, column)
+					}
+				}
+			}
+		}
+	}
+
+	for _, badge := range export.Badges {
+		if hasReferences && !badgeTypes[badge.BadgeID] {
+			return fmt.Errorf("badge %d is not described in the export", badge.BadgeID)
+		}
+		if !players[badge.PlayerID] {
+			return fmt.Errorf("badge %d references unknown player %d", badge.BadgeID, badge.PlayerID)
+		}
+		if badge.LegID.Valid && !legs[badge.LegID.Int64] {
+			return fmt.Errorf("badge %d references unknown leg %d", badge.BadgeID, badge.LegID.Int64)
+		}
+		if badge.VisitID.Valid && !visits[badge.VisitID.Int64] {
+			return fmt.Errorf("badge %d references unknown visit %d", badge.BadgeID, badge.VisitID.Int64)
+		}
+		if badge.MatchID.Valid && !matches[badge.MatchID.Int64] {
+			return fmt.Errorf("badge %d references unknown match %d", badge.BadgeID, badge.MatchID.Int64)
+		}
+		if badge.TournamentID.Valid && !tournaments[badge.TournamentID.Int64] {
+			return fmt.Errorf("badge %d references unknown tournament %d", badge.BadgeID, badge.TournamentID.Int64)
+		}
+		if badge.OpponentPlayerID.Valid && !players[int(badge.OpponentPlayerID.Int64)] {
+			return fmt.Errorf("badge %d references unknown opponent %d", badge.BadgeID, badge.OpponentPlayerID.Int64)
+		}
+	}
+	for _, elo := range export.Elo {
+		if !players[elo.PlayerID] {
+			return fmt.Errorf("elo references unknown player %d", elo.PlayerID)
+		}
+	}
+	for _, change := range export.EloChangelog {
+		if !players[change.PlayerID] || !matches[int64(change.MatchID)] {
+			return fmt.Errorf("elo changelog references unknown player %d or match %d", change.PlayerID, change.MatchID)
+		}
+	}
+	return nil
+}
+
+// referenceIDs will return the IDs of the given references
+func referenceIDs(references []*OfficeExportReference) map[int]bool {
+	ids := make(map[int]bool)
+	for _, reference := range references {
+		ids[reference.ID] = true
+	}
+	return ids
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOfficeExport() *OfficeExport {
+	return &OfficeExport{
+		Version: OfficeExportVersion,
+		Office:  &Office{ID: 1, Name: "Oslo"},
+		Players: []*OfficeExportPlayer{{ID: 1, FirstName: "Alice"}, {ID: 2, FirstName: "Bob"}},
+		Matches: []*OfficeExportMatch{{ID: 10, MatchTypeID: X01, MatchModeID: 1, WinnerID: null.IntFrom(1),
+			Legs: []*OfficeExportLeg{{ID: 100, CurrentPlayerID: 1, WinnerID: null.IntFrom(1),
+				Players: []*OfficeExportLegPlayer{{PlayerID: 1, Order: 1}, {PlayerID: 2, Order: 2}},
+				Visits:  []*OfficeExportVisit{{ID: 1000, PlayerID: 1}, {ID: 1001, PlayerID: 2}},
+				Statistics: []*OfficeExportStatistics{{Table: "statistics_x01", PlayerID: 1,
+					Values: map[string]interface{}{"ppd": "20.5", "checkout": nil}}}}}}},
+		Badges:     []*OfficeExportBadge{{PlayerID: 1, BadgeID: 1, LegID: null.IntFrom(100), VisitID: null.IntFrom(1000)}},
+		MatchModes: []*OfficeExportReference{{ID: 1, Name: "Best of 3"}},
+		BadgeTypes: []*OfficeExportReference{{ID: 1, Name: "Bull's Eye"}},
+	}
+}
+
+// TestOfficeExportValidate will check that valid exports are accepted
+func TestOfficeExportValidate(t *testing.T) {
+	assert.NoError(t, newTestOfficeExport().Validate(), "should be valid")
+}
+
+// TestOfficeExportValidate_Invalid will check that exports with unknown versions or references are rejected
+func TestOfficeExportValidate_Invalid(t *testing.T) {
+	export := newTestOfficeExport()
+	export.Version = OfficeExportVersion + 1
+	assert.Error(t, export.Validate(), "should reject unsupported version")
+
+	export = newTestOfficeExport()
+	export.Matches[0].Legs[0].Players = export.Matches[0].Legs[0].Players[:1]
+	assert.Error(t, export.Validate(), "should reject visit by player not in leg")
+
+	export = newTestOfficeExport()
+	export.Matches[0].VenueID = null.IntFrom(5)
+	assert.Error(t, export.Validate(), "should reject unknown venue")
+
+	export = newTestOfficeExport()
+	export.Badges[0].VisitID = null.IntFrom(5)
+	assert.Error(t, export.Validate(), "should reject badge with unknown visit")
+
+	export = newTestOfficeExport()
+	export.Matches[0].Legs[0].Statistics[0].Values["ppd) VALUES (1); --"] = "1"
+	assert.Error(t, export.Validate(), "should reject statistics with invalid column")
+
+	export = newTestOfficeExport()
+	export.Matches[0].OweTypeID = null.IntFrom(3)
+	assert.Error(t, export.Validate(), "should reject unknown owe type")
+
+	export = newTestOfficeExport()
+	export.BadgeTypes = nil
+	assert.Error(t, export.Validate(), "should reject badge without name")
+
+	export = newTestOfficeExport()
+	export.Version = 1
+	export.BadgeTypes = nil
+	assert.NoError(t, export.Validate(), "should accept version 1 without names")
+}
@@ -1,13 +1,16 @@
 package models
 
 import (
+	"sort"
+	"time"
+
 	"github.com/guregu/null"
 )
 
 // Owe struct used for storing owes
 type Owe struct {
-	PlayerOwerID int      `json:"player_ower_id"`
-	PlayerOweeID int      `json:"player_owee_id"`
+	PlayerOwerID int      `json:"player_ower_id" validate:"required"`
+	PlayerOweeID int      `json:"player_owee_id" validate:"required"`
 	OweType      *OweType `json:"owe_type"`
 	Amount       int      `json:"amount"`
 }
@@ -17,3 +20,106 @@ type OweType struct {
 	ID   null.Int    `json:"id"`
 	Item null.String `json:"item"`
 }
+
+// OweTransaction struct used for storing a single entry in the owes ledger, either a debt from a match or a payback
+type OweTransaction struct {
+	ID           int       `json:"id"`
+	PlayerOwerID int       `json:"player_ower_id"`
+	PlayerOweeID int       `json:"player_owee_id"`
+	OweType      *OweType  `json:"owe_type"`
+	Amount       int       `json:"amount"`
+	MatchID      null.Int  `json:"match_id"`
+	IsPayback    bool      `json:"is_payback"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// OweBalance struct used for storing the balance between a player and another player for a single owe type
+type OweBalance struct {
+	PlayerID int      `json:"player_id"`
+	OweType  *OweType `json:"owe_type"`
+	// Owed is the amount the other player owes the player
+	Owed int `json:"owed"`
+	// Owing is the amount the player owes the other player
+	Owing int `json:"owing"`
+	Net   int `json:"net"`
+}
+
+// OweBalanceSheet struct used for storing all balances of a single player
+type OweBalanceSheet struct {
+	PlayerID int           `json:"player_id"`
+	Balances []*OweBalance `json:"balances"`
+}
+
+// SettleOwes will suggest the paybacks needed to settle the given owes. Owes are first netted per owe type, so only
+// each players total balance matters, and the largest debtor then pays the largest creditor until everyone is settled.
+// This results in at most one payback less than the number of players with a balance
+func SettleOwes(owes []*Owe) []*Owe {
+	types := make(map[int64]*OweType)
+	balances := make(map[int64]map[int]int)
+	for _, owe := range owes {
+		id := owe.OweType.ID.Int64
+		if balances[id] == nil {
+			balances[id] = make(map[int]int)
+			types[id] = owe.OweType
+		}
+		balances[id][owe.PlayerOwerID] -= owe.Amount
+		balances[id][owe.PlayerOweeID] += owe.Amount
+	}
+
+	typeIDs := make([]int64, 0, len(types))
+	for id := range types {
+		typeIDs = append(typeIDs, id)
+	}
+	sort.Slice(typeIDs, func(i, j int) bool { return typeIDs[i] < typeIDs[j] })
+
+	paybacks := make([]*Owe, 0)
+	for _, id := range typeIDs {
+		debtors := make([]*playerBalance, 0)
+		creditors := make([]*playerBalance, 0)
+		for playerID, amount := range balances[id] {
+			if amount < 0 {
+				debtors = append(debtors, &playerBalance{playerID, -amount})
+			} else if amount > 0 {
+				creditors = append(creditors, &playerBalance{playerID, amount})
+			}
+		}
+		sortBalances(debtors)
+		sortBalances(creditors)
+
+		for len(debtors) > 0 && len(creditors) > 0 {
+			debtor, creditor := debtors[0], creditors[0]
+			amount := debtor.amount
+			if creditor.amount < amount {
+				amount = creditor.amount
+			}
+			paybacks = append(paybacks, &Owe{PlayerOwerID: debtor.playerID, PlayerOweeID: creditor.playerID, OweType: types[id], Amount: amount})
+			debtor.amount -= amount
+			creditor.amount -= amount
+			if debtor.amount == 0 {
+				debtors = debtors[1:]
+			}
+			if creditor.amount == 0 {
+				creditors = creditors[1:]
+			}
+			sortBalances(debtors)
+			sortBalances(creditors)
+		}
+	}
+	return paybacks
+}
+
+// playerBalance is the outstanding amount of a single player while settling owes
+type playerBalance struct {
+	playerID int
+	amount   int
+}
+
+// sortBalances will sort by largest amount first, and by player ID to keep suggestions stable
+func sortBalances(balances []*playerBalance) {
+	sort.Slice(balances, func(i, j int) bool {
+		if balances[i].amount == balances[j].amount {
+			return balances[i].playerID < balances[j].playerID
+		}
+		return balances[i].amount > balances[j].amount
+	})
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSettleOwes will check that owes are netted into at most one payback less than the number of players
+func TestSettleOwes(t *testing.T) {
+	beer := &OweType{ID: null.IntFrom(1), Item: null.StringFrom("Beer")}
+	owes := []*Owe{
+		{PlayerOwerID: 1, PlayerOweeID: 2, OweType: beer, Amount: 3},
+		{PlayerOwerID: 2, PlayerOweeID: 1, OweType: beer, Amount: 1},
+		{PlayerOwerID: 2, PlayerOweeID: 3, OweType: beer, Amount: 2},
+	}
+	paybacks := SettleOwes(owes)
+	assert.Len(t, paybacks, 1, "should only need a single payback")
+	assert.Equal(t, paybacks[0].PlayerOwerID, 1, "should be equal")
+	assert.Equal(t, paybacks[0].PlayerOweeID, 3, "should be equal")
+	assert.Equal(t, paybacks[0].Amount, 2, "should be equal")
+}
+
+// TestSettleOwes_Types will check that different owe types are never netted against each other
+func TestSettleOwes_Types(t *testing.T) {
+	beer := &OweType{ID: null.IntFrom(1), Item: null.StringFrom("Beer")}
+	coffee := &OweType{ID: null.IntFrom(2), Item: null.StringFrom("Coffee")}
+	owes := []*Owe{
+		{PlayerOwerID: 1, PlayerOweeID: 2, OweType: beer, Amount: 2},
+		{PlayerOwerID: 2, PlayerOweeID: 1, OweType: coffee, Amount: 2},
+		{PlayerOwerID: 3, PlayerOweeID: 1, OweType: coffee, Amount: 1},
+	}
+	paybacks := SettleOwes(owes)
+	assert.Len(t, paybacks, 3, "should have one payback per debtor and type")
+	assert.Equal(t, *paybacks[0], Owe{PlayerOwerID: 1, PlayerOweeID: 2, OweType: beer, Amount: 2}, "should be equal")
+	assert.Equal(t, *paybacks[1], Owe{PlayerOwerID: 2, PlayerOweeID: 1, OweType: coffee, Amount: 2}, "should be equal")
+	assert.Equal(t, *paybacks[2], Owe{PlayerOwerID: 3, PlayerOweeID: 1, OweType: coffee, Amount: 1}, "should be equal")
+}
+
+// TestSettleOwes_Settled will check that no paybacks are suggested when all owes cancel out
+func TestSettleOwes_Settled(t *testing.T) {
+	beer := &OweType{ID: null.IntFrom(1), Item: null.StringFrom("Beer")}
+	owes := []*Owe{
+		{PlayerOwerID: 1, PlayerOweeID: 2, OweType: beer, Amount: 1},
+		{PlayerOwerID: 2, PlayerOweeID: 3, OweType: beer, Amount: 1},
+		{PlayerOwerID: 3, PlayerOweeID: 1, OweType: beer, Amount: 1},
+	}
+	assert.Empty(t, SettleOwes(owes), "should not suggest any paybacks")
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+const (
+	// DefaultPageLimit is the number of items returned when no limit is given
+	DefaultPageLimit = 25
+	// MaxPageLimit is the maximum number of items returned in a single page
+	MaxPageLimit = 100
+)
+
+// Filters supported by list endpoints. Each endpoint only supports a subset of these
+const (
+	FilterOffice     = "office_id"
+	FilterVenue      = "venue_id"
+	FilterMatchType  = "match_type"
+	FilterPlayer     = "player_id"
+	FilterTournament = "tournament_id"
+	FilterFrom       = "from"
+	FilterTo         = "to"
+	FilterFinished   = "finished"
+	FilterAbandoned  = "abandoned"
+)
+
+// filterTypes holds the type of value of each filter
+var filterTypes = map[string]string{
+	FilterOffice:     "int",
+	FilterVenue:      "int",
+	FilterMatchType:  "int",
+	FilterPlayer:     "int",
+	FilterTournament: "int",
+	FilterFrom:       "time",
+	FilterTo:         "time",
+	FilterFinished:   "bool",
+	FilterAbandoned:  "bool",
+}
+
+// Page is a single page of items returned from a list endpoint
+type Page struct {
+	Items      interface{} `json:"items"`
+	Total      int         `json:"total"`
+	NextCursor null.String `json:"next_cursor"`
+	Next       null.String `json:"next"`
+}
+
+// Cursor points to the last item of the previous page
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// Encode will return the cursor as an opaque string
+func (cursor *Cursor) Encode() string {
+	b, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor will decode a cursor previously returned by Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	cursor := new(Cursor)
+	if err := json.Unmarshal(b, cursor); err != nil || cursor.ID == "" {
+		return nil, errors.New("invalid cursor")
+	}
+	return cursor, nil
+}
+
+// ListOptions holds the pagination, sort and filter options of a list request
+type ListOptions struct {
+	Limit      int
+	Cursor     *Cursor
+	Sort       string
+	Descending bool
+	Filters    map[string]interface{}
+}
+
+// ParseListOptions will parse the list options from the given query parameters. Sort is given as "sort=name", or
+// "sort=-name" for descending order, and is validated against the sort options of the endpoint
+func ParseListOptions(values url.Values, defaultSort string) (*ListOptions, error) {
+	opts := &ListOptions{Limit: DefaultPageLimit, Filters: make(map[string]interface{})}
+
+	if limit := values.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 {
+			return nil, fmt.Errorf("invalid limit '%s'", limit)
+		}
+		if l > MaxPageLimit {
+			l = MaxPageLimit
+		}
+		opts.Limit = l
+	}
+
+	sort := values.Get("sort")
+	if sort == "" {
+		sort = defaultSort
+	}
+	opts.Descending = strings.HasPrefix(sort, "-")
+	opts.Sort = strings.TrimPrefix(sort, "-")
+
+	if cursor := values.Get("cursor"); cursor != "" {
+		c, err := DecodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.Sort != sort {
+			return nil, errors.New("cursor does not match sort")
+		}
+		opts.Cursor = c
+	}
+
+	for name, kind := range filterTypes {
+		value := values.Get(name)
+		if value == "" {
+			continue
+		}
+		switch kind {
+		case "int":
+			i, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s '%s'", name, value)
+			}
+			opts.Filters[name] = i
+		case "bool":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s '%s'", name, value)
+			}
+			opts.Filters[name] = b
+		case "time":
+			t, err := parseFilterTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s '%s'", name, value)
+			}
+			opts.Filters[name] = t
+		}
+	}
+	return opts, nil
+}
+
+// SortKey will return the sort as given in the request, used to tie cursors to a single sort order
+func (opts *ListOptions) SortKey() string {
+	if opts.Descending {
+		return "-" + opts.Sort
+	}
+	return opts.Sort
+}
+
+// parseFilterTime will parse a date (2006-01-02) or a RFC3339 timestamp
+func parseFilterTime(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// ListOptionsError used when a list endpoint is given a sort or filter it does not support
+type ListOptionsError struct {
+	Err error
+}
+
+func (e *ListOptionsError) Error() string {
+	return e.Err.Error()
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseListOptions will check that pagination, sort and filters are parsed from the query
+func TestParseListOptions(t *testing.T) {
+	values, _ := url.ParseQuery("limit=10&sort=-name&office_id=2&finished=true&from=2024-01-31")
+	opts, err := ParseListOptions(values, "id")
+	assert.Nil(t, err, "should not return error")
+	assert.Equal(t, opts.Limit, 10, "should be equal")
+	assert.Equal(t, opts.Sort, "name", "should be equal")
+	assert.Equal(t, opts.Descending, true, "should be descending")
+	assert.Equal(t, opts.Filters[FilterOffice], 2, "should be equal")
+	assert.Equal(t, opts.Filters[FilterFinished], true, "should be equal")
+	assert.Equal(t, opts.Filters[FilterFrom], time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), "should be equal")
+
+	opts, err = ParseListOptions(url.Values{}, "-created_at")
+	assert.Nil(t, err, "should not return error")
+	assert.Equal(t, opts.Limit, DefaultPageLimit, "should be equal")
+	assert.Equal(t, opts.SortKey(), "-created_at", "should be equal")
+	assert.Empty(t, opts.Filters, "should not have filters")
+
+	values, _ = url.ParseQuery("limit=1000")
+	opts, _ = ParseListOptions(values, "id")
+	assert.Equal(t, opts.Limit, MaxPageLimit, "should be capped")
+}
+
+// TestParseListOptions_Invalid will check that invalid options are rejected
+func TestParseListOptions_Invalid(t *testing.T) {
+	for _, query := range []string{"limit=0", "limit=abc", "office_id=abc", "finished=maybe", "from=yesterday", "cursor=invalid"} {
+		values, _ := url.ParseQuery(query)
+		_, err := ParseListOptions(values, "id")
+		assert.NotNil(t, err, "should return error for "+query)
+	}
+}
+
+// TestCursor will check that cursors can be decoded, and are only valid for the sort they were created for
+func TestCursor(t *testing.T) {
+	cursor := &Cursor{Sort: "-created_at", Value: "2024-01-31 12:00:00", ID: "42"}
+	decoded, err := DecodeCursor(cursor.Encode())
+	assert.Nil(t, err, "should not return error")
+	assert.Equal(t, decoded, cursor, "should be equal")
+
+	values := url.Values{"cursor": {cursor.Encode()}}
+	opts, err := ParseListOptions(values, "-created_at")
+	assert.Nil(t, err, "should not return error")
+	assert.Equal(t, opts.Cursor, cursor, "should be equal")
+
+	values.Set("sort", "id")
+	_, err = ParseListOptions(values, "-created_at")
+	assert.NotNil(t, err, "should not allow cursor for other sort")
+}
@@ -11,7 +11,7 @@ import (
 // Player struct used for storing players
 type Player struct {
 	ID             int            `json:"id"`
-	FirstName      string         `json:"first_name"`
+	FirstName      string         `json:"first_name" validate:"required"`
 	LastName       null.String    `json:"last_name"`
 	VocalName      null.String    `json:"vocal_name,omitempty"`
 	Nickname       null.String    `json:"nickname,omitempty"`
@@ -51,38 +51,43 @@ type PlayerOptions struct {
 	ShowCheckoutGuide null.Bool `json:"show_checkout_guide"`
 }
 
+// playerJSON is the JSON representation of Player. A separate type is used to get consistent order of JSON key-value pairs
+type playerJSON struct {
+	ID             int            `json:"id"`
+	Name           string         `json:"name"`
+	FirstName      string         `json:"first_name"`
+	LastName       null.String    `json:"last_name"`
+	DisplayName    string         `json:"display_name"`
+	VocalName      null.String    `json:"vocal_name,omitempty"`
+	Nickname       null.String    `json:"nickname,omitempty"`
+	SlackHandle    null.String    `json:"slack_handle,omitempty"`
+	MatchesPlayed  int            `json:"matches_played"`
+	MatchesWon     int            `json:"matches_won"`
+	LegsPlayed     int            `json:"legs_played"`
+	LegsWon        int            `json:"legs_won"`
+	Color          null.String    `json:"color,omitempty"`
+	ProfilePicURL  null.String    `json:"profile_pic_url,omitempty"`
+	SmartcardUID   null.String    `json:"smartcard_uid,omitempty"`
+	BoardStreamURL null.String    `json:"board_stream_url,omitempty"`
+	BoardStreamCSS null.String    `json:"board_stream_css,omitempty"`
+	OfficeID       null.Int       `json:"office_id,omitempty"`
+	IsActive       bool           `json:"is_active"`
+	IsBot          bool           `json:"is_bot"`
+	IsPlaceholder  bool           `json:"is_placeholder"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	TournamentElo  int            `json:"tournament_elo,omitempty"`
+	CurrentElo     int            `json:"current_elo,omitempty"`
+	PlayerOptions  *PlayerOptions `json:"options,omitempty"`
+}
+
+// JSONType will return the type Player is serialized as, to describe it in the API documentation
+func (player Player) JSONType() interface{} {
+	return playerJSON{}
+}
+
 // MarshalJSON will marshall the given object to JSON
 func (player Player) MarshalJSON() ([]byte, error) {
-	// Use a type to get consistnt order of JSON key-value pairs.
-	type playerJSON struct {
-		ID             int            `json:"id"`
-		Name           string         `json:"name"`
-		FirstName      string         `json:"first_name"`
-		LastName       null.String    `json:"last_name"`
-		DisplayName    string         `json:"display_name"`
-		VocalName      null.String    `json:"vocal_name,omitempty"`
-		Nickname       null.String    `json:"nickname,omitempty"`
-		SlackHandle    null.String    `json:"slack_handle,omitempty"`
-		MatchesPlayed  int            `json:"matches_played"`
-		MatchesWon     int            `json:"matches_won"`
-		LegsPlayed     int            `json:"legs_played"`
-		LegsWon        int            `json:"legs_won"`
-		Color          null.String    `json:"color,omitempty"`
-		ProfilePicURL  null.String    `json:"profile_pic_url,omitempty"`
-		SmartcardUID   null.String    `json:"smartcard_uid,omitempty"`
-		BoardStreamURL null.String    `json:"board_stream_url,omitempty"`
-		BoardStreamCSS null.String    `json:"board_stream_css,omitempty"`
-		OfficeID       null.Int       `json:"office_id,omitempty"`
-		IsActive       bool           `json:"is_active"`
-		IsBot          bool           `json:"is_bot"`
-		IsPlaceholder  bool           `json:"is_placeholder"`
-		CreatedAt      time.Time      `json:"created_at"`
-		UpdatedAt      time.Time      `json:"updated_at"`
-		TournamentElo  int            `json:"tournament_elo,omitempty"`
-		CurrentElo     int            `json:"current_elo,omitempty"`
-		PlayerOptions  *PlayerOptions `json:"options,omitempty"`
-	}
-
 	if player.PlayerOptions != nil && !player.PlayerOptions.SubtractPerDart.Valid {
 		player.PlayerOptions = nil
 	}
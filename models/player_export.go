@@ -0,0 +1,53 @@
+package models
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// PlayerExport struct used for exporting all data stored about a player
+type PlayerExport struct {
+	ExportedAt   time.Time             `json:"exported_at"`
+	Player       *Player               `json:"player"`
+	Elo          *PlayerElo            `json:"elo"`
+	EloChangelog []*PlayerEloChangelog `json:"elo_changelog"`
+	Matches      []*Match              `json:"matches"`
+	Visits       []*Visit              `json:"visits"`
+	Badges       []*PlayerBadge        `json:"badges"`
+	Owes         []*Owe                `json:"owes"`
+	OweHistory   []*OweTransaction     `json:"owe_history"`
+}
+
+// WriteZip will write the export as a ZIP archive, with one JSON file per section
+func (export *PlayerExport) WriteZip(w io.Writer) error {
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"player.json", export.Player},
+		{"elo.json", export.Elo},
+		{"elo_changelog.json", export.EloChangelog},
+		{"matches.json", export.Matches},
+		{"visits.json", export.Visits},
+		{"badges.json", export.Badges},
+		{"owes.json", export.Owes},
+		{"owe_history.json", export.OweHistory},
+	}
+
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		f, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(file.content)
+		if err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// PlayerMergeReport struct used for reporting what was changed when merging two players
+type PlayerMergeReport struct {
+	PlayerID               int              `json:"player_id"`
+	DuplicateID            int              `json:"duplicate_id"`
+	DryRun                 bool             `json:"dry_run"`
+	Matches                []int            `json:"matches"`
+	Legs                   []int            `json:"legs"`
+	UpdatedRows            map[string]int64 `json:"updated_rows"`
+	StatisticsRecalculated map[string]int   `json:"statistics_recalculated"`
+	EloRecalculated        bool             `json:"elo_recalculated"`
+	// RecalculationErrors are failures recalculating statistics or Elo after the merge was committed
+	RecalculationErrors []string  `json:"recalculation_errors,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// PlayerMergeError used when two players cannot be merged
+type PlayerMergeError struct {
+	Err error
+}
+
+func (e *PlayerMergeError) Error() string {
+	return e.Err.Error()
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// PlayerRecord struct used for storing a personal record of a player, set in a single leg
+type PlayerRecord struct {
+	ID            int        `json:"id"`
+	PlayerID      int        `json:"player_id"`
+	MatchType     int        `json:"match_type"`
+	Metric        string     `json:"metric"`
+	LowerIsBetter bool       `json:"lower_is_better"`
+	Value         float64    `json:"value"`
+	PreviousValue null.Float `json:"previous_value"`
+	MatchID       int        `json:"match_id"`
+	LegID         int        `json:"leg_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// PlayerRecords struct used for storing the current personal records of a player, and every record set over time
+type PlayerRecords struct {
+	PlayerID int             `json:"player_id"`
+	Records  []*PlayerRecord `json:"records"`
+	Timeline []*PlayerRecord `json:"timeline"`
+}
+
+// IsNewRecord returns true if the given value beats the current record, or if there is no current record
+func IsNewRecord(current *PlayerRecord, value float64, lowerIsBetter bool) bool {
+	if current == nil {
+		return true
+	}
+	if lowerIsBetter {
+		return value < current.Value
+	}
+	return value > current.Value
+}
+
+// BestRecords returns the best record of each match type and metric, ordered by match type and metric.
+// If several records share the best value, the one set first is returned
+func BestRecords(records []*PlayerRecord) []*PlayerRecord {
+	type key struct {
+		matchType int
+		metric    string
+	}
+	best := make(map[key]*PlayerRecord)
+	for _, record := range records {
+		k := key{record.MatchType, record.Metric}
+		current, ok := best[k]
+		if !ok || IsNewRecord(current, record.Value, record.LowerIsBetter) ||
+			(record.Value == current.Value && record.CreatedAt.Before(current.CreatedAt)) {
+			best[k] = record
+		}
+	}
+	result := make([]*PlayerRecord, 0, len(best))
+	for _, record := range best {
+		result = append(result, record)
+	}
+	SortPlayerRecords(result)
+	return result
+}
+
+// SortPlayerRecords will sort the given records by match type and metric
+func SortPlayerRecords(records []*PlayerRecord) {
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].MatchType != records[j].MatchType {
+			return records[i].MatchType < records[j].MatchType
+		}
+		return records[i].Metric < records[j].Metric
+	})
+}
+
+// FindNewRecords returns the candidates which set a new record, updating the current record of each player.
+// Candidates must be values of a single match type and metric, in the order they were set
+func FindNewRecords(current map[int]*PlayerRecord, candidates []*PlayerRecord) []*PlayerRecord {
+	records := make([]*PlayerRecord, 0)
+	for _, candidate := range candidates {
+		previous := current[candidate.PlayerID]
+		if !IsNewRecord(previous, candidate.Value, candidate.LowerIsBetter) {
+			continue
+		}
+		if previous != nil {
+			candidate.PreviousValue = null.FloatFrom(previous.Value)
+		}
+		current[candidate.PlayerID] = candidate
+		records = append(records, candidate)
+	}
+	return records
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsNewRecord(t *testing.T) {
+	assert.True(t, IsNewRecord(nil, 10, false))
+	assert.True(t, IsNewRecord(&PlayerRecord{Value: 50}, 51, false))
+	assert.False(t, IsNewRecord(&PlayerRecord{Value: 50}, 50, false))
+	assert.True(t, IsNewRecord(&PlayerRecord{Value: 15}, 14, true))
+	assert.False(t, IsNewRecord(&PlayerRecord{Value: 15}, 16, true))
+}
+
+func TestFindNewRecords(t *testing.T) {
+	current := map[int]*PlayerRecord{2: {PlayerID: 2, Value: 60}}
+	candidates := []*PlayerRecord{
+		{PlayerID: 1, LegID: 1, Value: 45},
+		{PlayerID: 2, LegID: 1, Value: 55},
+		{PlayerID: 1, LegID: 2, Value: 42},
+		{PlayerID: 2, LegID: 2, Value: 61.5},
+		{PlayerID: 1, LegID: 3, Value: 45},
+		{PlayerID: 1, LegID: 4, Value: 48.2},
+	}
+	records := FindNewRecords(current, candidates)
+
+	assert.Len(t, records, 3)
+	assert.Equal(t, 1, records[0].LegID)
+	assert.False(t, records[0].PreviousValue.Valid)
+	assert.Equal(t, 2, records[1].PlayerID)
+	assert.Equal(t, null.FloatFrom(60), records[1].PreviousValue)
+	// Equaling a record does not set a new one
+	assert.Equal(t, 4, records[2].LegID)
+	assert.Equal(t, null.FloatFrom(45), records[2].PreviousValue)
+	assert.Equal(t, 48.2, current[1].Value)
+}
+
+func TestFindNewRecordsLowerIsBetter(t *testing.T) {
+	candidates := []*PlayerRecord{
+		{PlayerID: 1, LegID: 1, Value: 21, LowerIsBetter: true},
+		{PlayerID: 1, LegID: 2, Value: 24, LowerIsBetter: true},
+		{PlayerID: 1, LegID: 3, Value: 18, LowerIsBetter: true},
+	}
+	records := FindNewRecords(make(map[int]*PlayerRecord), candidates)
+	assert.Len(t, records, 2)
+	assert.Equal(t, 3, records[1].LegID)
+	assert.Equal(t, null.FloatFrom(21), records[1].PreviousValue)
+}
+
+func TestBestRecords(t *testing.T) {
+	now := time.Now()
+	records := []*PlayerRecord{
+		{PlayerID: 1, MatchType: CRICKET, Metric: "mpr", Value: 2.1, CreatedAt: now},
+		{PlayerID: 1, MatchType: X01, Metric: "three_dart_avg", Value: 55, CreatedAt: now},
+		{PlayerID: 2, MatchType: X01, Metric: "three_dart_avg", Value: 70, CreatedAt: now},
+		{PlayerID: 3, MatchType: X01, Metric: "three_dart_avg", Value: 70, CreatedAt: now.Add(-time.Hour)},
+		{PlayerID: 1, MatchType: X01, Metric: "best_501", Value: 18, LowerIsBetter: true, CreatedAt: now},
+		{PlayerID: 2, MatchType: X01, Metric: "best_501", Value: 21, LowerIsBetter: true, CreatedAt: now},
+	}
+	best := BestRecords(records)
+
+	assert.Len(t, best, 3)
+	assert.Equal(t, "best_501", best[0].Metric)
+	assert.Equal(t, 1, best[0].PlayerID)
+	// The record set first wins a tie
+	assert.Equal(t, "three_dart_avg", best[1].Metric)
+	assert.Equal(t, 3, best[1].PlayerID)
+	assert.Equal(t, CRICKET, best[2].MatchType)
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"math"
+
+	"github.com/guregu/null"
+)
+
+// TrendWindows are the number of most recent legs used for rolling windows
+var TrendWindows = []int{10, 25, 50}
+
+// TrendPercentileWindow is the window which is compared against other players in the same office
+const TrendPercentileWindow = 25
+
+// TrendSignificance is the z-score a change in form must reach to be significant, 1.96 for 95% confidence
+const TrendSignificance = 1.96
+
+const (
+	// FormHot is used when the most recent legs are significantly better than the ones before
+	FormHot = "hot"
+	// FormCold is used when the most recent legs are significantly worse than the ones before
+	FormCold = "cold"
+	// FormSteady is used when there is no significant change, or not enough legs to tell
+	FormSteady = "steady"
+)
+
+// Names of the metrics included in a player trend
+const (
+	TrendThreeDartAvg       = "three_dart_avg"
+	TrendFirstNineAvg       = "first_nine_three_dart_avg"
+	TrendCheckoutPercentage = "checkout_percentage"
+	Trend100sPlus           = "scores_100s_plus_per_leg"
+	Trend140sPlus           = "scores_140s_plus_per_leg"
+	Trend180s               = "scores_180s_per_leg"
+	TrendMPR                = "mpr"
+)
+
+// PlayerTrend struct used for storing the form of a player over the most recent legs
+type PlayerTrend struct {
+	PlayerID    int            `json:"player_id"`
+	OfficeID    null.Int       `json:"office_id"`
+	X01Legs     int            `json:"x01_legs"`
+	CricketLegs int            `json:"cricket_legs"`
+	Metrics     []*TrendMetric `json:"metrics"`
+}
+
+// TrendMetric struct used for storing a single metric over rolling windows
+type TrendMetric struct {
+	Name             string         `json:"name"`
+	Career           null.Float     `json:"career"`
+	Windows          []*TrendWindow `json:"windows"`
+	Form             string         `json:"form"`
+	ZScore           null.Float     `json:"z_score"`
+	OfficePercentile null.Float     `json:"office_percentile"`
+}
+
+// TrendWindow struct used for storing a metric over the given number of most recent legs
+type TrendWindow struct {
+	Legs     int        `json:"legs"`
+	Count    int        `json:"count"`
+	Value    null.Float `json:"value"`
+	Previous null.Float `json:"previous"`
+	Change   null.Float `json:"change"`
+}
+
+// TrendSample is the value of a metric in a single leg, kept as a numerator and denominator so windows are weighted correctly
+type TrendSample struct {
+	Numerator   float64
+	Denominator float64
+}
+
+// NewTrendMetric will calculate rolling windows and form of a metric, from samples ordered from newest to oldest
+func NewTrendMetric(name string, samples []TrendSample) *TrendMetric {
+	metric := &TrendMetric{Name: name, Career: ratio(samples), Form: FormSteady, Windows: make([]*TrendWindow, 0)}
+	for _, legs := range TrendWindows {
+		window := &TrendWindow{Legs: legs, Count: min(legs, len(samples))}
+		window.Value = ratio(samples[:window.Count])
+		if len(samples) > legs {
+			window.Previous = ratio(samples[legs:min(2*legs, len(samples))])
+		}
+		if window.Value.Valid && window.Previous.Valid {
+			window.Change = round(null.FloatFrom(window.Value.Float64 - window.Previous.Float64))
+		}
+		window.Value = round(window.Value)
+		window.Previous = round(window.Previous)
+		metric.Windows = append(metric.Windows, window)
+	}
+
+	recent := TrendWindows[0]
+	if len(samples) >= 2*recent {
+		z := zScore(values(samples[:recent]), values(samples[recent:]))
+		if z.Valid {
+			metric.ZScore = round(z)
+			if z.Float64 >= TrendSignificance {
+				metric.Form = FormHot
+			} else if z.Float64 <= -TrendSignificance {
+				metric.Form = FormCold
+			}
+		}
+	}
+	metric.Career = round(metric.Career)
+	return metric
+}
+
+// Percentile returns the percentage of values which are lower than the given value, counting equal values as half
+func Percentile(value float64, values []float64) null.Float {
+	if len(values) == 0 {
+		return null.Float{}
+	}
+	below := 0.0
+	for _, v := range values {
+		if v < value {
+			below++
+		} else if v == value {
+			below += 0.5
+		}
+	}
+	return round(null.FloatFrom(below / float64(len(values)) * 100))
+}
+
+// ratio returns the sum of numerators divided by the sum of denominators
+func ratio(samples []TrendSample) null.Float {
+	numerator, denominator := 0.0, 0.0
+	for _, sample := range samples {
+		numerator += sample.Numerator
+		denominator += sample.Denominator
+	}
+	if denominator == 0 {
+		return null.Float{}
+	}
+	return null.FloatFrom(numerator / denominator)
+}
+
+// values returns the value of each sample, skipping samples without a denominator
+func values(samples []TrendSample) []float64 {
+	values := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		if sample.Denominator > 0 {
+			values = append(values, sample.Numerator/sample.Denominator)
+		}
+	}
+	return values
+}
+
+// zScore returns the Welch z-score of the difference between the mean of recent and the mean of baseline
+func zScore(recent []float64, baseline []float64) null.Float {
+	if len(recent) < 2 || len(baseline) < 2 {
+		return null.Float{}
+	}
+	meanRecent, varRecent := meanVariance(recent)
+	meanBaseline, varBaseline := meanVariance(baseline)
+	se := math.Sqrt(varRecent/float64(len(recent)) + varBaseline/float64(len(baseline)))
+	if se == 0 {
+		return null.Float{}
+	}
+	return null.FloatFrom((meanRecent - meanBaseline) / se)
+}
+
+// meanVariance returns the mean and sample variance of the given values
+func meanVariance(values []float64) (float64, float64) {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, variance / float64(len(values)-1)
+}
+
+// round returns the value rounded to two decimals
+func round(value null.Float) null.Float {
+	if !value.Valid {
+		return value
+	}
+	return null.FloatFrom(math.Round(value.Float64*100) / 100)
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func samples(values ...float64) []TrendSample {
+	samples := make([]TrendSample, len(values))
+	for i, value := range values {
+		samples[i] = TrendSample{Numerator: value, Denominator: 1}
+	}
+	return samples
+}
+
+func TestNewTrendMetricWindows(t *testing.T) {
+	values := make([]float64, 0)
+	for i := 0; i < 30; i++ {
+		values = append(values, float64(i%2)*10)
+	}
+	metric := NewTrendMetric(TrendThreeDartAvg, samples(values...))
+	assert.Equal(t, 5.0, metric.Career.Float64)
+	assert.Len(t, metric.Windows, len(TrendWindows))
+
+	// 10 most recent legs, compared to the 10 legs before
+	assert.Equal(t, 10, metric.Windows[0].Count)
+	assert.Equal(t, 5.0, metric.Windows[0].Value.Float64)
+	assert.Equal(t, 0.0, metric.Windows[0].Change.Float64)
+
+	// Not enough legs for a full window, or a previous window
+	assert.Equal(t, 30, metric.Windows[2].Count)
+	assert.False(t, metric.Windows[2].Previous.Valid)
+	assert.False(t, metric.Windows[2].Change.Valid)
+	assert.Equal(t, FormSteady, metric.Form)
+}
+
+func TestNewTrendMetricWeighted(t *testing.T) {
+	// Checkout percentage is weighted by attempts, not averaged per leg
+	metric := NewTrendMetric(TrendCheckoutPercentage, []TrendSample{{Numerator: 100, Denominator: 1}, {Numerator: 0, Denominator: 3}})
+	assert.Equal(t, 25.0, metric.Career.Float64)
+
+	metric = NewTrendMetric(TrendMPR, nil)
+	assert.False(t, metric.Career.Valid)
+	assert.False(t, metric.Windows[0].Value.Valid)
+}
+
+func TestNewTrendMetricForm(t *testing.T) {
+	values := make([]float64, 0)
+	for i := 0; i < 10; i++ {
+		values = append(values, 60+float64(i%3))
+	}
+	for i := 0; i < 20; i++ {
+		values = append(values, 45+float64(i%3))
+	}
+	metric := NewTrendMetric(TrendThreeDartAvg, samples(values...))
+	assert.Equal(t, FormHot, metric.Form)
+	assert.True(t, metric.ZScore.Float64 > TrendSignificance)
+
+	for i := 0; i < 10; i++ {
+		values[i] = 30 + float64(i%3)
+	}
+	metric = NewTrendMetric(TrendThreeDartAvg, samples(values...))
+	assert.Equal(t, FormCold, metric.Form)
+
+	// Too few legs to tell
+	metric = NewTrendMetric(TrendThreeDartAvg, samples(values[:15]...))
+	assert.Equal(t, FormSteady, metric.Form)
+	assert.False(t, metric.ZScore.Valid)
+}
+
+func TestPercentile(t *testing.T) {
+	assert.False(t, Percentile(50, nil).Valid)
+	assert.Equal(t, 50.0, Percentile(50, []float64{40, 50, 60}).Float64)
+	assert.Equal(t, 100.0, Percentile(70, []float64{40, 50, 60}).Float64)
+	assert.Equal(t, 0.0, Percentile(30, []float64{40, 50, 60}).Float64)
+}
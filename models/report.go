@@ -0,0 +1,268 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// Periods a report can cover
+const (
+	ReportWeekly  = "weekly"
+	ReportMonthly = "monthly"
+)
+
+// Formats a report can be rendered to
+const (
+	ReportFormatJSON     = "json"
+	ReportFormatMarkdown = "markdown"
+	ReportFormatHTML     = "html"
+)
+
+// ErrReportExists is returned when a report has already been generated for the office and period
+var ErrReportExists = errors.New("report already generated for period")
+
+// ReportTopCount is the number of players listed in top averages and most improved
+const ReportTopCount = 5
+
+// ReportMinimumLegs is the number of legs a player must have played in a period to be included in averages
+const ReportMinimumLegs = 5
+
+// Report struct used for storing a generated summary of an office for a period
+type Report struct {
+	ID        int            `json:"id"`
+	OfficeID  int            `json:"office_id"`
+	Period    string         `json:"period"`
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	CreatedAt time.Time      `json:"created_at"`
+	Content   *ReportContent `json:"content"`
+}
+
+// ReportContent struct used for storing the sections of a report
+type ReportContent struct {
+	Office       string              `json:"office"`
+	TopAverages  []*ReportPlayer     `json:"top_averages"`
+	MostImproved []*ReportPlayer     `json:"most_improved"`
+	NewBadges    []*ReportBadge      `json:"new_badges"`
+	Tournaments  []*ReportTournament `json:"tournaments"`
+	Owes         []*ReportOwe        `json:"owes"`
+}
+
+// ReportPlayer struct used for storing the X01 average of a player in the period of a report
+type ReportPlayer struct {
+	PlayerID     int        `json:"player_id"`
+	Name         string     `json:"name"`
+	Legs         int        `json:"legs"`
+	ThreeDartAvg float64    `json:"three_dart_avg"`
+	PreviousAvg  null.Float `json:"previous_three_dart_avg"`
+	Change       null.Float `json:"change"`
+}
+
+// ReportBadge struct used for storing a badge unlocked in the period of a report
+type ReportBadge struct {
+	PlayerID  int       `json:"player_id"`
+	Name      string    `json:"name"`
+	BadgeID   int       `json:"badge_id"`
+	Badge     string    `json:"badge"`
+	Level     null.Int  `json:"level"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReportTournament struct used for storing the progress of a tournament
+type ReportTournament struct {
+	TournamentID    int     `json:"tournament_id"`
+	Name            string  `json:"name"`
+	IsFinished      bool    `json:"is_finished"`
+	MatchesPlayed   int     `json:"matches_played"`
+	MatchesTotal    int     `json:"matches_total"`
+	PercentComplete float64 `json:"percent_complete"`
+}
+
+// ReportOwe struct used for storing an outstanding owe between two players
+type ReportOwe struct {
+	PlayerOwerID int    `json:"player_ower_id"`
+	Ower         string `json:"ower"`
+	PlayerOweeID int    `json:"player_owee_id"`
+	Owee         string `json:"owee"`
+	Item         string `json:"item"`
+	Amount       int    `json:"amount"`
+}
+
+// ReportPeriod returns the last complete period before the given time. Weeks start on Monday
+func ReportPeriod(period string, now time.Time) (time.Time, time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case ReportWeekly:
+		// Days since Monday
+		offset := (int(today.Weekday()) + 6) % 7
+		to := today.AddDate(0, 0, -offset)
+		return to.AddDate(0, 0, -7), to, nil
+	case ReportMonthly:
+		to := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+		return to.AddDate(0, -1, 0), to, nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("unknown report period '%s'", period)
+}
+
+// Title returns the title of the report
+func (r *Report) Title() string {
+	name := fmt.Sprintf("Office %d", r.OfficeID)
+	if r.Content != nil && r.Content.Office != "" {
+		name = r.Content.Office
+	}
+	period := "Weekly"
+	if r.Period == ReportMonthly {
+		period = "Monthly"
+	}
+	return fmt.Sprintf("%s report for %s, %s - %s", period, name, r.From.Format("2006-01-02"), r.To.AddDate(0, 0, -1).Format("2006-01-02"))
+}
+
+// Markdown returns the report rendered as Markdown
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", r.Title())
+
+	b.WriteString("\n## Top averages\n")
+	if len(r.Content.TopAverages) == 0 {
+		b.WriteString("No players with enough legs\n")
+	}
+	for i, p := range r.Content.TopAverages {
+		fmt.Fprintf(&b, "%d. %s - %.2f (%d legs)\n", i+1, p.Name, p.ThreeDartAvg, p.Legs)
+	}
+
+	b.WriteString("\n## Most improved\n")
+	if len(r.Content.MostImproved) == 0 {
+		b.WriteString("No players improved\n")
+	}
+	for i, p := range r.Content.MostImproved {
+		fmt.Fprintf(&b, "%d. %s - %.2f (%+.2f)\n", i+1, p.Name, p.ThreeDartAvg, p.Change.Float64)
+	}
+
+	b.WriteString("\n## New badges\n")
+	if len(r.Content.NewBadges) == 0 {
+		b.WriteString("No new badges\n")
+	}
+	for _, badge := range r.Content.NewBadges {
+		fmt.Fprintf(&b, "- %s unlocked %s%s\n", badge.Name, badge.Badge, badgeLevel(badge.Level))
+	}
+
+	b.WriteString("\n## Tournaments\n")
+	if len(r.Content.Tournaments) == 0 {
+		b.WriteString("No active tournaments\n")
+	}
+	for _, t := range r.Content.Tournaments {
+		fmt.Fprintf(&b, "- %s: %d of %d matches played (%.0f%%)\n", t.Name, t.MatchesPlayed, t.MatchesTotal, t.PercentComplete)
+	}
+
+	b.WriteString("\n## Owes outstanding\n")
+	if len(r.Content.Owes) == 0 {
+		b.WriteString("No owes outstanding\n")
+	}
+	for _, owe := range r.Content.Owes {
+		fmt.Fprintf(&b, "- %s owes %s %d %s\n", owe.Ower, owe.Owee, owe.Amount, owe.Item)
+	}
+	return b.String()
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"badgeLevel": badgeLevel,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{ .Title }}</title></head>
+<body>
+<h1>{{ .Title }}</h1>
+<h2>Top averages</h2>
+{{ with .Content.TopAverages }}<ol>{{ range . }}
+<li>{{ .Name }} - {{ printf "%.2f" .ThreeDartAvg }} ({{ .Legs }} legs)</li>{{ end }}
+</ol>{{ else }}<p>No players with enough legs</p>{{ end }}
+<h2>Most improved</h2>
+{{ with .Content.MostImproved }}<ol>{{ range . }}
+<li>{{ .Name }} - {{ printf "%.2f" .ThreeDartAvg }} ({{ printf "%+.2f" .Change.Float64 }})</li>{{ end }}
+</ol>{{ else }}<p>No players improved</p>{{ end }}
+<h2>New badges</h2>
+{{ with .Content.NewBadges }}<ul>{{ range . }}
+<li>{{ .Name }} unlocked {{ .Badge }}{{ badgeLevel .Level }}</li>{{ end }}
+</ul>{{ else }}<p>No new badges</p>{{ end }}
+<h2>Tournaments</h2>
+{{ with .Content.Tournaments }}<ul>{{ range . }}
+<li>{{ .Name }}: {{ .MatchesPlayed }} of {{ .MatchesTotal }} matches played ({{ printf "%.0f" .PercentComplete }}%)</li>{{ end }}
+</ul>{{ else }}<p>No active tournaments</p>{{ end }}
+<h2>Owes outstanding</h2>
+{{ with .Content.Owes }}<ul>{{ range . }}
+<li>{{ .Ower }} owes {{ .Owee }} {{ .Amount }} {{ .Item }}</li>{{ end }}
+</ul>{{ else }}<p>No owes outstanding</p>{{ end }}
+</body>
+</html>
+`))
+
+// HTML returns the report rendered as a HTML document
+func (r *Report) HTML() (string, error) {
+	var b bytes.Buffer
+	if err := reportTemplate.Execute(&b, r); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Render returns the report rendered to the given format, together with the content type of the format
+func (r *Report) Render(format string) (string, string, error) {
+	switch format {
+	case ReportFormatJSON, "":
+		b, err := json.Marshal(r)
+		if err != nil {
+			return "", "", err
+		}
+		return string(b), "application/json", nil
+	case ReportFormatMarkdown:
+		return r.Markdown(), "text/markdown; charset=utf-8", nil
+	case ReportFormatHTML:
+		html, err := r.HTML()
+		return html, "text/html; charset=utf-8", err
+	}
+	return "", "", fmt.Errorf("unknown report format '%s'", format)
+}
+
+// badgeLevel returns the level of a badge for display, or nothing if the badge has no levels
+func badgeLevel(level null.Int) string {
+	if !level.Valid {
+		return ""
+	}
+	return fmt.Sprintf(" (level %d)", level.Int64)
+}
+
+// RankReportPlayers will return the players with the highest average, and the players who improved their average the
+// most compared to the previous period, with at most ReportTopCount players in each
+func RankReportPlayers(current []*ReportPlayer, previous []*ReportPlayer) ([]*ReportPlayer, []*ReportPlayer) {
+	averages := make(map[int]float64)
+	for _, p := range previous {
+		averages[p.PlayerID] = p.ThreeDartAvg
+	}
+	top := make([]*ReportPlayer, 0, len(current))
+	improved := make([]*ReportPlayer, 0)
+	for _, p := range current {
+		if avg, ok := averages[p.PlayerID]; ok {
+			p.PreviousAvg = null.FloatFrom(avg)
+			p.Change = null.FloatFrom(math.Round((p.ThreeDartAvg-avg)*100) / 100)
+			if p.Change.Float64 > 0 {
+				improved = append(improved, p)
+			}
+		}
+		top = append(top, p)
+	}
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].ThreeDartAvg > top[j].ThreeDartAvg
+	})
+	sort.SliceStable(improved, func(i, j int) bool {
+		return improved[i].Change.Float64 > improved[j].Change.Float64
+	})
+	return top[:min(len(top), ReportTopCount)], improved[:min(len(improved), ReportTopCount)]
+}
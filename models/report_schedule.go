@@ -0,0 +1,148 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReportSchedule struct used for configuring when reports are generated
+type ReportSchedule struct {
+	Name string `mapstructure:"name"`
+	// Cron is a five field cron expression (minute, hour, day of month, month, day of week), or @daily, @weekly or @monthly
+	Cron   string `mapstructure:"cron"`
+	Period string `mapstructure:"period"`
+	// Offices to generate reports for, all active offices if empty
+	Offices []int `mapstructure:"offices"`
+	Notify  bool  `mapstructure:"notify"`
+}
+
+// CronSchedule is a parsed cron expression
+type CronSchedule struct {
+	minutes     []bool
+	hours       []bool
+	daysOfMonth []bool
+	months      []bool
+	daysOfWeek  []bool
+	// anyDay is set when day of month or day of week is *, in which case both have to match instead of either
+	anyDay bool
+}
+
+var cronDescriptors = map[string]string{
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 1",
+	"@monthly": "0 0 1 * *",
+}
+
+// ParseCron will parse the given cron expression
+func ParseCron(expr string) (*CronSchedule, error) {
+	if descriptor, ok := cronDescriptors[expr]; ok {
+		expr = descriptor
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression '%s' must have 5 fields", expr)
+	}
+	schedule := &CronSchedule{anyDay: fields[2] == "*" || fields[4] == "*"}
+	var err error
+	if schedule.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if schedule.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if schedule.daysOfMonth, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if schedule.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if schedule.daysOfWeek, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	// Both 0 and 7 are Sunday
+	if schedule.daysOfWeek[7] {
+		schedule.daysOfWeek[0] = true
+	}
+	return schedule, nil
+}
+
+// parseCronField will parse a comma separated list of *, values and ranges, each with an optional step
+func parseCronField(field string, min int, max int) ([]bool, error) {
+	values := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s < 1 {
+				return nil, fmt.Errorf("invalid step in cron field '%s'", field)
+			}
+			step = s
+			part = part[:i]
+		}
+		from, to := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			f, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value in cron field '%s'", field)
+			}
+			from, to = f, f
+			if len(bounds) == 2 {
+				if to, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, fmt.Errorf("invalid range in cron field '%s'", field)
+				}
+			} else if step > 1 {
+				to = max
+			}
+		}
+		if from < min || to > max || from > to {
+			return nil, fmt.Errorf("cron field '%s' must be between %d and %d", field, min, max)
+		}
+		for v := from; v <= to; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+// Matches returns true if the given time is within a minute matching the schedule
+func (c *CronSchedule) Matches(t time.Time) bool {
+	return c.minutes[t.Minute()] && c.hours[t.Hour()] && c.months[int(t.Month())] && c.matchesDay(t)
+}
+
+// Next returns the first minute after the given time matching the schedule, or the zero time if none within five years
+func (c *CronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	end := after.AddDate(5, 0, 0)
+	for t.Before(end) {
+		if !c.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minutes[t.Minute()] {
+			return t
+		}
+		t = t.Add(time.Minute)
+	}
+	return time.Time{}
+}
+
+// matchesDay returns true if the day of the given time matches the schedule
+func (c *CronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth := c.daysOfMonth[t.Day()]
+	dayOfWeek := c.daysOfWeek[int(t.Weekday())]
+	if c.anyDay {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCron(t *testing.T) {
+	cron, err := ParseCron("30 8 * * 1-5")
+	assert.NoError(t, err)
+	// Monday 2024-03-04 08:30
+	assert.True(t, cron.Matches(time.Date(2024, 3, 4, 8, 30, 0, 0, time.UTC)))
+	assert.False(t, cron.Matches(time.Date(2024, 3, 4, 8, 31, 0, 0, time.UTC)))
+	// Sunday
+	assert.False(t, cron.Matches(time.Date(2024, 3, 3, 8, 30, 0, 0, time.UTC)))
+
+	cron, err = ParseCron("*/15 0 1,15 * *")
+	assert.NoError(t, err)
+	assert.True(t, cron.Matches(time.Date(2024, 3, 15, 0, 45, 0, 0, time.UTC)))
+	assert.False(t, cron.Matches(time.Date(2024, 3, 15, 0, 40, 0, 0, time.UTC)))
+	assert.False(t, cron.Matches(time.Date(2024, 3, 14, 0, 45, 0, 0, time.UTC)))
+
+	// Both 0 and 7 are Sunday
+	cron, err = ParseCron("0 0 * * 7")
+	assert.NoError(t, err)
+	assert.True(t, cron.Matches(time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	cron, err := ParseCron("@weekly")
+	assert.NoError(t, err)
+	// Wednesday 2024-03-06 to Monday 2024-03-11
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), cron.Next(time.Date(2024, 3, 6, 12, 0, 0, 0, time.UTC)))
+	// A matching time is not returned again
+	assert.Equal(t, time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC), cron.Next(time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)))
+
+	cron, err = ParseCron("@monthly")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), cron.Next(time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC)))
+
+	// Never matching schedule
+	cron, err = ParseCron("0 0 31 2 *")
+	assert.NoError(t, err)
+	assert.True(t, cron.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero())
+}
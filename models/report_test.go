@@ -0,0 +1,111 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportPeriod(t *testing.T) {
+	// Wednesday
+	now := time.Date(2024, 3, 6, 15, 4, 0, 0, time.UTC)
+	from, to, err := ReportPeriod(ReportWeekly, now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), to)
+
+	// On a Monday the week just finished is reported
+	from, _, err = ReportPeriod(ReportWeekly, time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC), from)
+
+	from, to, err = ReportPeriod(ReportMonthly, now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), to)
+
+	_, _, err = ReportPeriod("daily", now)
+	assert.Error(t, err)
+}
+
+func TestRankReportPlayers(t *testing.T) {
+	current := []*ReportPlayer{
+		{PlayerID: 1, ThreeDartAvg: 45.5},
+		{PlayerID: 2, ThreeDartAvg: 60.1},
+		{PlayerID: 3, ThreeDartAvg: 52.0},
+	}
+	previous := []*ReportPlayer{
+		{PlayerID: 1, ThreeDartAvg: 40.25},
+		{PlayerID: 2, ThreeDartAvg: 62.0},
+		{PlayerID: 4, ThreeDartAvg: 70.0},
+	}
+	top, improved := RankReportPlayers(current, previous)
+
+	assert.Equal(t, []int{2, 3, 1}, reportPlayerIDs(top))
+	assert.Equal(t, null.FloatFrom(-1.9), top[0].Change)
+	// Players without a previous average are not ranked as improved
+	assert.False(t, top[1].Change.Valid)
+	assert.Equal(t, []int{1}, reportPlayerIDs(improved))
+	assert.Equal(t, null.FloatFrom(5.25), improved[0].Change)
+}
+
+func TestRankReportPlayersLimit(t *testing.T) {
+	current := make([]*ReportPlayer, 0)
+	for i := 1; i <= ReportTopCount+2; i++ {
+		current = append(current, &ReportPlayer{PlayerID: i, ThreeDartAvg: float64(i)})
+	}
+	top, improved := RankReportPlayers(current, nil)
+	assert.Len(t, top, ReportTopCount)
+	assert.Equal(t, ReportTopCount+2, top[0].PlayerID)
+	assert.Empty(t, improved)
+}
+
+func TestReportRender(t *testing.T) {
+	report := &Report{
+		ID:       1,
+		OfficeID: 2,
+		Period:   ReportWeekly,
+		From:     time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+		Content: &ReportContent{
+			Office:      "Oslo",
+			TopAverages: []*ReportPlayer{{PlayerID: 1, Name: "Alex <Bull> Smith", Legs: 12, ThreeDartAvg: 55.123}},
+			NewBadges:   []*ReportBadge{{PlayerID: 1, Name: "Alex <Bull> Smith", Badge: "Ton Machine", Level: null.IntFrom(2)}},
+		},
+	}
+	assert.Equal(t, "Weekly report for Oslo, 2024-02-26 - 2024-03-03", report.Title())
+
+	markdown, contentType, err := report.Render(ReportFormatMarkdown)
+	assert.NoError(t, err)
+	assert.Equal(t, "text/markdown; charset=utf-8", contentType)
+	assert.True(t, strings.HasPrefix(markdown, "# Weekly report for Oslo"))
+	assert.Contains(t, markdown, "1. Alex <Bull> Smith - 55.12 (12 legs)")
+	assert.Contains(t, markdown, "- Alex <Bull> Smith unlocked Ton Machine (level 2)")
+	assert.Contains(t, markdown, "No players improved")
+	assert.Contains(t, markdown, "No owes outstanding")
+
+	html, contentType, err := report.Render(ReportFormatHTML)
+	assert.NoError(t, err)
+	assert.Equal(t, "text/html; charset=utf-8", contentType)
+	assert.Contains(t, html, "<li>Alex &lt;Bull&gt; Smith - 55.12 (12 legs)</li>")
+	assert.Contains(t, html, "<p>No active tournaments</p>")
+
+	json, contentType, err := report.Render("")
+	assert.NoError(t, err)
+	assert.Equal(t, "application/json", contentType)
+	assert.Contains(t, json, `"office":"Oslo"`)
+
+	_, _, err = report.Render("pdf")
+	assert.Error(t, err)
+}
+
+func reportPlayerIDs(players []*ReportPlayer) []int {
+	ids := make([]int, 0, len(players))
+	for _, p := range players {
+		ids = append(ids, p.PlayerID)
+	}
+	return ids
+}
@@ -0,0 +1,28 @@
+package models
+
+import "github.com/guregu/null"
+
+// Statistics121 struct used for storing statistics for 121
+type Statistics121 struct {
+	ID                 int        `json:"id"`
+	LegID              int        `json:"leg_id"`
+	PlayerID           int        `json:"player_id"`
+	MatchesPlayed      int        `json:"matches_played"`
+	MatchesWon         int        `json:"matches_won"`
+	LegsPlayed         int        `json:"legs_played"`
+	LegsWon            int        `json:"legs_won"`
+	OfficeID           null.Int   `json:"office_id,omitempty"`
+	DartsThrown        int        `json:"darts_thrown"`
+	Attempts           int        `json:"attempts"`
+	Checkouts          int        `json:"checkouts"`
+	CheckoutPercentage null.Float `json:"checkout_percentage"`
+	HighestCheckout    null.Int   `json:"highest_checkout,omitempty"`
+	HighestTarget      int        `json:"highest_target"`
+	AvgCheckoutDarts   null.Float `json:"avg_checkout_darts,omitempty"`
+	// Personal best for the player, and the leg it was set in
+	BestCheckout    null.Int `json:"best_checkout,omitempty"`
+	BestCheckoutLeg null.Int `json:"best_checkout_leg_id,omitempty"`
+
+	// Values used only to calculate statistics
+	CheckoutDarts int `json:"-"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "github.com/guregu/null"
+
+// StatisticsBobs27 struct used for storing statistics for Bob's 27
+type StatisticsBobs27 struct {
+	ID             int      `json:"id"`
+	LegID          int      `json:"leg_id"`
+	PlayerID       int      `json:"player_id"`
+	MatchesPlayed  int      `json:"matches_played"`
+	MatchesWon     int      `json:"matches_won"`
+	LegsPlayed     int      `json:"legs_played"`
+	LegsWon        int      `json:"legs_won"`
+	OfficeID       null.Int `json:"office_id,omitempty"`
+	DartsThrown    int      `json:"darts_thrown,omitempty"`
+	Score          int      `json:"score"`
+	HighestScore   int      `json:"highest_score"`
+	RoundsPlayed   int      `json:"rounds_played"`
+	DoublesHit     int      `json:"doubles_hit"`
+	DoublesHitrate float64  `json:"doubles_hitrate"`
+	IsBusted       bool     `json:"is_busted"`
+	// Personal best for the player, and the leg it was set in
+	BestScore     null.Int `json:"best_score,omitempty"`
+	BestScoreLeg  null.Int `json:"best_score_leg_id,omitempty"`
+	LegsCompleted int      `json:"legs_completed,omitempty"`
+}
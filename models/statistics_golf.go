@@ -0,0 +1,21 @@
+package models
+
+import "github.com/guregu/null"
+
+// StatisticsGolf struct used for storing statistics for Golf
+type StatisticsGolf struct {
+	ID            int      `json:"id"`
+	LegID         int      `json:"leg_id"`
+	PlayerID      int      `json:"player_id"`
+	MatchesPlayed int      `json:"matches_played"`
+	MatchesWon    int      `json:"matches_won"`
+	LegsPlayed    int      `json:"legs_played"`
+	LegsWon       int      `json:"legs_won"`
+	OfficeID      null.Int `json:"office_id,omitempty"`
+	DartsThrown   int      `json:"darts_thrown,omitempty"`
+	Score         int      `json:"score"`
+	HolesPlayed   int      `json:"holes_played"`
+	HolesInOne    int      `json:"holes_in_one"`
+	HolesMissed   int      `json:"holes_missed"`
+	HitRate       float64  `json:"hit_rate"`
+}
@@ -1,5 +1,27 @@
 package models
 
+import (
+	"math"
+	"sort"
+
+	"github.com/guregu/null"
+)
+
+// Head2HeadHighCheckout is the lowest checkout listed as a notable moment between two players
+const Head2HeadHighCheckout = 100
+
+// Head2HeadMomentsLimit is the number of most recent notable moments returned
+const Head2HeadMomentsLimit = 50
+
+const (
+	// MomentMaximum is a visit scoring 180
+	MomentMaximum = "180"
+	// MomentHighCheckout is a checkout of at least Head2HeadHighCheckout
+	MomentHighCheckout = "high_checkout"
+	// MomentBadge is a badge unlocked against the other player
+	MomentBadge = "badge"
+)
+
 // StatisticsHead2Head struct used for storing head to head statistics
 type StatisticsHead2Head struct {
 	LastMatches         map[int][]*Match              `json:"last_matches"`
@@ -10,4 +32,159 @@ type StatisticsHead2Head struct {
 	PlayerVisits        map[int][]*Visit              `json:"player_visits"`
 	PlayerCheckouts     map[int][]*CheckoutStatistics `json:"player_checkouts"`
 	PlayerElos          map[int]*PlayerElo            `json:"player_elo"`
+	MatchTypes          []*Head2HeadMatchType         `json:"match_types"`
+	EloHistory          []*PlayerEloChangelog         `json:"elo_history"`
+	Moments             []*Head2HeadMoment            `json:"moments"`
+}
+
+// Head2HeadMatchType struct used for storing head to head results for a single match type
+type Head2HeadMatchType struct {
+	MatchType        *MatchType         `json:"match_type"`
+	Matches          int                `json:"matches"`
+	Draws            int                `json:"draws"`
+	MatchWins        map[int]int        `json:"match_wins"`
+	Legs             int                `json:"legs"`
+	LegWins          map[int]int        `json:"leg_wins"`
+	AverageLegMargin map[int]float64    `json:"average_leg_margin"`
+	ThrowingFirst    map[int]*LegRecord `json:"throwing_first"`
+	DecidingLegs     map[int]*LegRecord `json:"deciding_legs"`
+	marginTotal      map[int]int
+}
+
+// LegRecord struct used for storing how many legs a player played and won in a given situation
+type LegRecord struct {
+	Played        int     `json:"played"`
+	Won           int     `json:"won"`
+	WinPercentage float64 `json:"win_percentage"`
+}
+
+// Head2HeadLeg struct used for storing the outcome of a single leg between two players
+type Head2HeadLeg struct {
+	MatchID       int
+	LegID         int
+	MatchType     *MatchType
+	WinsRequired  null.Int
+	MatchWinnerID null.Int
+	WinnerID      null.Int
+	FirstPlayerID int
+}
+
+// Head2HeadMoment struct used for storing a notable moment between two players. Value is the score of 180s and
+// checkouts, and the level of badges
+type Head2HeadMoment struct {
+	Type      string    `json:"type"`
+	PlayerID  int       `json:"player_id"`
+	MatchID   null.Int  `json:"match_id"`
+	LegID     null.Int  `json:"leg_id"`
+	VisitID   null.Int  `json:"visit_id"`
+	BadgeID   null.Int  `json:"badge_id"`
+	Value     null.Int  `json:"value"`
+	CreatedAt null.Time `json:"created_at"`
+}
+
+// NewHead2HeadMatchTypes will summarize the given legs between the two players per match type.
+// Legs must be ordered by match and leg, as deciding legs are found by replaying each match
+func NewHead2HeadMatchTypes(player1 int, player2 int, legs []*Head2HeadLeg) []*Head2HeadMatchType {
+	types := make(map[int]*Head2HeadMatchType)
+	result := make([]*Head2HeadMatchType, 0)
+	var wins map[int]int
+	for i, leg := range legs {
+		h2h, ok := types[leg.MatchType.ID]
+		if !ok {
+			h2h = newHead2HeadMatchType(leg.MatchType, player1, player2)
+			types[leg.MatchType.ID] = h2h
+			result = append(result, h2h)
+		}
+		if i == 0 || legs[i-1].MatchID != leg.MatchID {
+			wins = map[int]int{player1: 0, player2: 0}
+		}
+
+		h2h.Legs++
+		winner := int(leg.WinnerID.Int64)
+		if leg.WinnerID.Valid {
+			h2h.LegWins[winner]++
+		}
+		if first, ok := h2h.ThrowingFirst[leg.FirstPlayerID]; ok {
+			first.add(leg.WinnerID.Valid && winner == leg.FirstPlayerID)
+		}
+		// A deciding leg is one where both players are a single leg away from winning the match
+		required := int(leg.WinsRequired.Int64)
+		if leg.WinsRequired.Valid && required > 1 && wins[player1] == required-1 && wins[player2] == required-1 {
+			for _, id := range []int{player1, player2} {
+				h2h.DecidingLegs[id].add(leg.WinnerID.Valid && winner == id)
+			}
+		}
+		if leg.WinnerID.Valid {
+			wins[winner]++
+		}
+
+		// Last leg of the match
+		if i == len(legs)-1 || legs[i+1].MatchID != leg.MatchID {
+			h2h.Matches++
+			if leg.MatchWinnerID.Valid {
+				matchWinner := int(leg.MatchWinnerID.Int64)
+				loser := player1
+				if matchWinner == player1 {
+					loser = player2
+				}
+				h2h.MatchWins[matchWinner]++
+				h2h.marginTotal[matchWinner] += wins[matchWinner] - wins[loser]
+			} else {
+				h2h.Draws++
+			}
+		}
+	}
+
+	for _, h2h := range result {
+		for id, total := range h2h.marginTotal {
+			if h2h.MatchWins[id] > 0 {
+				h2h.AverageLegMargin[id] = math.Round(float64(total)/float64(h2h.MatchWins[id])*100) / 100
+			}
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Matches > result[j].Matches
+	})
+	return result
+}
+
+// newHead2HeadMatchType will return an empty summary for the given match type and players
+func newHead2HeadMatchType(matchType *MatchType, player1 int, player2 int) *Head2HeadMatchType {
+	h2h := &Head2HeadMatchType{
+		MatchType:        matchType,
+		MatchWins:        map[int]int{player1: 0, player2: 0},
+		LegWins:          map[int]int{player1: 0, player2: 0},
+		AverageLegMargin: map[int]float64{player1: 0, player2: 0},
+		ThrowingFirst:    make(map[int]*LegRecord),
+		DecidingLegs:     make(map[int]*LegRecord),
+		marginTotal:      make(map[int]int),
+	}
+	for _, id := range []int{player1, player2} {
+		h2h.ThrowingFirst[id] = new(LegRecord)
+		h2h.DecidingLegs[id] = new(LegRecord)
+	}
+	return h2h
+}
+
+// add will count a leg played, and won if given
+func (r *LegRecord) add(won bool) {
+	r.Played++
+	if won {
+		r.Won++
+	}
+	r.WinPercentage = percent(r.Won, r.Played)
+}
+
+// SortHead2HeadMoments will sort the given moments newest first, and return at most Head2HeadMomentsLimit of them
+func SortHead2HeadMoments(moments []*Head2HeadMoment) []*Head2HeadMoment {
+	sort.SliceStable(moments, func(i, j int) bool {
+		if moments[i].CreatedAt.Time.Equal(moments[j].CreatedAt.Time) {
+			return moments[i].LegID.Int64 > moments[j].LegID.Int64
+		}
+		return moments[i].CreatedAt.Time.After(moments[j].CreatedAt.Time)
+	})
+	if len(moments) > Head2HeadMomentsLimit {
+		return moments[:Head2HeadMomentsLimit]
+	}
+	return moments
 }
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func h2hLeg(matchID int, matchType int, winsRequired int, matchWinner int, winner int, first int) *Head2HeadLeg {
+	return &Head2HeadLeg{MatchID: matchID, MatchType: &MatchType{ID: matchType}, WinsRequired: null.IntFrom(int64(winsRequired)),
+		MatchWinnerID: null.NewInt(int64(matchWinner), matchWinner != 0), WinnerID: null.IntFrom(int64(winner)), FirstPlayerID: first}
+}
+
+func TestNewHead2HeadMatchTypes(t *testing.T) {
+	legs := []*Head2HeadLeg{
+		// Best of 3 X01, won 2-1 by player 1 in a deciding leg
+		h2hLeg(1, X01, 2, 1, 1, 1),
+		h2hLeg(1, X01, 2, 1, 2, 2),
+		h2hLeg(1, X01, 2, 1, 1, 1),
+		// Best of 3 X01, won 2-0 by player 2
+		h2hLeg(2, X01, 2, 2, 2, 2),
+		h2hLeg(2, X01, 2, 2, 2, 1),
+		// Single leg Cricket, won by player 1
+		h2hLeg(3, CRICKET, 1, 1, 1, 2),
+	}
+	types := NewHead2HeadMatchTypes(1, 2, legs)
+	assert.Len(t, types, 2)
+
+	x01 := types[0]
+	assert.Equal(t, X01, x01.MatchType.ID)
+	assert.Equal(t, 2, x01.Matches)
+	assert.Equal(t, 5, x01.Legs)
+	assert.Equal(t, map[int]int{1: 1, 2: 1}, x01.MatchWins)
+	assert.Equal(t, map[int]int{1: 2, 2: 3}, x01.LegWins)
+	assert.Equal(t, 1.0, x01.AverageLegMargin[1])
+	assert.Equal(t, 2.0, x01.AverageLegMargin[2])
+
+	assert.Equal(t, 3, x01.ThrowingFirst[1].Played)
+	assert.Equal(t, 2, x01.ThrowingFirst[1].Won)
+	assert.Equal(t, 66.67, x01.ThrowingFirst[1].WinPercentage)
+	assert.Equal(t, 2, x01.ThrowingFirst[2].Won)
+
+	assert.Equal(t, 1, x01.DecidingLegs[1].Played)
+	assert.Equal(t, 1, x01.DecidingLegs[1].Won)
+	assert.Equal(t, 0, x01.DecidingLegs[2].Won)
+
+	cricket := types[1]
+	assert.Equal(t, 1, cricket.Matches)
+	assert.Equal(t, 0, cricket.DecidingLegs[1].Played)
+	assert.Equal(t, 0, cricket.ThrowingFirst[2].Won)
+}
+
+func TestNewHead2HeadMatchTypesDraw(t *testing.T) {
+	types := NewHead2HeadMatchTypes(1, 2, []*Head2HeadLeg{h2hLeg(1, X01, 2, 0, 1, 1), h2hLeg(1, X01, 2, 0, 2, 2)})
+	assert.Equal(t, 1, types[0].Draws)
+	assert.Equal(t, 0, types[0].MatchWins[1])
+	assert.Equal(t, 0.0, types[0].AverageLegMargin[1])
+}
+
+func TestSortHead2HeadMoments(t *testing.T) {
+	now := time.Now()
+	moments := make([]*Head2HeadMoment, 0)
+	for i := 0; i < Head2HeadMomentsLimit+5; i++ {
+		moments = append(moments, &Head2HeadMoment{Type: MomentMaximum, CreatedAt: null.TimeFrom(now.Add(time.Duration(i) * time.Minute))})
+	}
+	moments = SortHead2HeadMoments(moments)
+	assert.Len(t, moments, Head2HeadMomentsLimit)
+	assert.True(t, moments[0].CreatedAt.Time.After(moments[1].CreatedAt.Time))
+}
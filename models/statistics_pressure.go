@@ -0,0 +1,149 @@
+package models
+
+import (
+	"math"
+	"sort"
+
+	"github.com/guregu/null"
+)
+
+// StatisticsPressure struct used for storing how a player performs depending on the situation of the match
+type StatisticsPressure struct {
+	PlayerID            int        `json:"player_id"`
+	LegsPlayed          int        `json:"legs_played"`
+	ThrowingFirst       *LegRecord `json:"throwing_first"`
+	ThrowingSecond      *LegRecord `json:"throwing_second"`
+	DecidingLegs        *LegRecord `json:"deciding_legs"`
+	Breaks              int        `json:"breaks"`
+	Broken              int        `json:"broken"`
+	MatchDarts          int        `json:"match_darts"`
+	MatchDartsHit       int        `json:"match_darts_hit"`
+	MatchDartConversion null.Float `json:"match_dart_conversion"`
+	LeadingAvg          null.Float `json:"leading_three_dart_avg"`
+	LevelAvg            null.Float `json:"level_three_dart_avg"`
+	TrailingAvg         null.Float `json:"trailing_three_dart_avg"`
+	situations          map[int]*pressureAverage
+}
+
+// PressureLeg struct used for storing a single leg of a player, with statistics if it was a X01 leg
+type PressureLeg struct {
+	PlayerID         int
+	MatchID          int
+	LegID            int
+	WinsRequired     int
+	WinnerID         null.Int
+	Order            int
+	PPDScore         null.Float
+	DartsThrown      null.Int
+	CheckoutAttempts null.Int
+}
+
+// pressureAverage is the score and darts thrown in legs where a player was leading, level or trailing
+type pressureAverage struct {
+	score float64
+	darts int64
+}
+
+// Score in legs of a player compared to the opponent when a leg starts
+const (
+	pressureTrailing = -1
+	pressureLevel    = 0
+	pressureLeading  = 1
+)
+
+// NewPlayerStatisticsPressure will return empty pressure statistics for the given player
+func NewPlayerStatisticsPressure(playerID int) *StatisticsPressure {
+	return &StatisticsPressure{
+		PlayerID:       playerID,
+		ThrowingFirst:  new(LegRecord),
+		ThrowingSecond: new(LegRecord),
+		DecidingLegs:   new(LegRecord),
+		situations:     make(map[int]*pressureAverage),
+	}
+}
+
+// NewStatisticsPressure will calculate pressure statistics for each player of the given legs.
+// Legs must be ordered by player, match and leg, as the score of each match is found by replaying it
+func NewStatisticsPressure(legs []*PressureLeg) []*StatisticsPressure {
+	players := make(map[int]*StatisticsPressure)
+	result := make([]*StatisticsPressure, 0)
+	wins, opponentWins := 0, 0
+	for i, leg := range legs {
+		stats, ok := players[leg.PlayerID]
+		if !ok {
+			stats = NewPlayerStatisticsPressure(leg.PlayerID)
+			players[leg.PlayerID] = stats
+			result = append(result, stats)
+		}
+		if i == 0 || legs[i-1].MatchID != leg.MatchID || legs[i-1].PlayerID != leg.PlayerID {
+			wins, opponentWins = 0, 0
+		}
+
+		won := leg.WinnerID.Valid && int(leg.WinnerID.Int64) == leg.PlayerID
+		stats.LegsPlayed++
+		if leg.Order == 1 {
+			stats.ThrowingFirst.add(won)
+			if !won {
+				stats.Broken++
+			}
+		} else {
+			stats.ThrowingSecond.add(won)
+			if won {
+				stats.Breaks++
+			}
+		}
+		required := leg.WinsRequired
+		if required > 1 && wins == required-1 && opponentWins == required-1 {
+			stats.DecidingLegs.add(won)
+		}
+		// Every dart at a double is a match dart when a single leg wins the match
+		if wins == required-1 && leg.CheckoutAttempts.Valid && leg.CheckoutAttempts.Int64 > 0 {
+			stats.MatchDarts += int(leg.CheckoutAttempts.Int64)
+			if won {
+				stats.MatchDartsHit++
+			}
+		}
+		if leg.PPDScore.Valid && leg.DartsThrown.Valid {
+			situation := pressureLevel
+			if wins > opponentWins {
+				situation = pressureLeading
+			} else if wins < opponentWins {
+				situation = pressureTrailing
+			}
+			avg, ok := stats.situations[situation]
+			if !ok {
+				avg = new(pressureAverage)
+				stats.situations[situation] = avg
+			}
+			avg.score += leg.PPDScore.Float64
+			avg.darts += leg.DartsThrown.Int64
+		}
+
+		if won {
+			wins++
+		} else if leg.WinnerID.Valid {
+			opponentWins++
+		}
+	}
+
+	for _, stats := range result {
+		if stats.MatchDarts > 0 {
+			stats.MatchDartConversion = null.FloatFrom(percent(stats.MatchDartsHit, stats.MatchDarts))
+		}
+		stats.LeadingAvg = stats.situations[pressureLeading].threeDartAvg()
+		stats.LevelAvg = stats.situations[pressureLevel].threeDartAvg()
+		stats.TrailingAvg = stats.situations[pressureTrailing].threeDartAvg()
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].PlayerID < result[j].PlayerID
+	})
+	return result
+}
+
+// threeDartAvg returns the three dart average, or null if no darts were thrown
+func (a *pressureAverage) threeDartAvg() null.Float {
+	if a == nil || a.darts == 0 {
+		return null.Float{}
+	}
+	return null.FloatFrom(math.Round(a.score/float64(a.darts)*300) / 100)
+}
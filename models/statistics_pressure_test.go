@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func pressureLeg(matchID int, winner int, order int, score float64, darts int64, attempts int64) *PressureLeg {
+	return &PressureLeg{PlayerID: 1, MatchID: matchID, WinsRequired: 3, WinnerID: null.IntFrom(int64(winner)), Order: order,
+		PPDScore: null.FloatFrom(score), DartsThrown: null.IntFrom(darts), CheckoutAttempts: null.IntFrom(attempts)}
+}
+
+func TestNewStatisticsPressure(t *testing.T) {
+	legs := []*PressureLeg{
+		// First to 3, won 3-2 by player 1 after trailing 0-2
+		pressureLeg(1, 2, 1, 501, 18, 2),
+		pressureLeg(1, 2, 2, 300, 15, 0),
+		pressureLeg(1, 1, 1, 501, 15, 1),
+		pressureLeg(1, 1, 2, 501, 21, 3),
+		pressureLeg(1, 1, 1, 501, 12, 4),
+	}
+	stats := NewStatisticsPressure(legs)
+	assert.Len(t, stats, 1)
+	s := stats[0]
+
+	assert.Equal(t, 5, s.LegsPlayed)
+	assert.Equal(t, LegRecord{Played: 3, Won: 2, WinPercentage: 66.67}, *s.ThrowingFirst)
+	assert.Equal(t, LegRecord{Played: 2, Won: 1, WinPercentage: 50}, *s.ThrowingSecond)
+	assert.Equal(t, 1, s.Breaks)
+	assert.Equal(t, 1, s.Broken)
+
+	// 2-2 in a first to 3
+	assert.Equal(t, LegRecord{Played: 1, Won: 1, WinPercentage: 100}, *s.DecidingLegs)
+	assert.Equal(t, 4, s.MatchDarts)
+	assert.Equal(t, 1, s.MatchDartsHit)
+	assert.Equal(t, 25.0, s.MatchDartConversion.Float64)
+
+	assert.Equal(t, 100.2, s.LevelAvg.Float64)
+	assert.Equal(t, 76.59, s.TrailingAvg.Float64)
+	assert.False(t, s.LeadingAvg.Valid)
+}
+
+func TestNewStatisticsPressureWithoutX01(t *testing.T) {
+	legs := []*PressureLeg{
+		{PlayerID: 2, MatchID: 1, WinsRequired: 1, WinnerID: null.IntFrom(2), Order: 2},
+		{PlayerID: 1, MatchID: 1, WinsRequired: 1, WinnerID: null.IntFrom(2), Order: 1},
+	}
+	stats := NewStatisticsPressure(legs)
+	assert.Len(t, stats, 2)
+	assert.Equal(t, 1, stats[0].PlayerID)
+	assert.Equal(t, 1, stats[0].Broken)
+	assert.Equal(t, 1, stats[1].Breaks)
+	assert.Equal(t, 0, stats[1].MatchDarts)
+	assert.False(t, stats[1].MatchDartConversion.Valid)
+	assert.False(t, stats[1].LevelAvg.Valid)
+}
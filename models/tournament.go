@@ -62,7 +62,7 @@ type Tournament struct {
 // TournamentGroup struct for storing tournament groups
 type TournamentGroup struct {
 	ID          int      `json:"id"`
-	Name        string   `json:"name"`
+	Name        string   `json:"name" validate:"required"`
 	IsGenerated bool     `json:"is_generated"`
 	IsPlayoffs  bool     `json:"is_playoffs"`
 	Division    null.Int `json:"division,omitempty"`
@@ -70,7 +70,7 @@ type TournamentGroup struct {
 
 // Player2Tournament struct for storing player to tounament links
 type Player2Tournament struct {
-	PlayerID          int  `json:"player_id"`
+	PlayerID          int  `json:"player_id" validate:"required"`
 	TournamentID      int  `json:"tournament_id"`
 	TournamentGroupID int  `json:"tournament_group_id"`
 	IsPromoted        bool `json:"is_promoted"`
@@ -125,7 +125,7 @@ type TournamentPreset struct {
 
 // GenerateTournamentInput struct for storing generate tournament inputs
 type GenerateTournamentInput struct {
-	Name          string               `json:"name"`
+	Name          string               `json:"name" validate:"required"`
 	ShortName     string               `json:"short_name"`
 	IsPlayoffs    bool                 `json:"is_playoffs"`
 	ManualAdmin   bool                 `json:"manual_admin"`
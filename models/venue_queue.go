@@ -0,0 +1,86 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// DefaultLegDuration is the leg duration used to estimate wait times at venues without any recent legs
+const DefaultLegDuration = 5 * time.Minute
+
+// VenueQueue struct used for storing what is playing, and what is up next at a venue
+type VenueQueue struct {
+	VenueID    int            `json:"venue_id"`
+	NowPlaying *Match         `json:"now_playing"`
+	UpNext     []*QueuedMatch `json:"up_next"`
+	// AverageLegDuration is the average duration of recent legs at the venue, in seconds
+	AverageLegDuration int `json:"average_leg_duration"`
+	// EstimatedWait is the number of seconds until the venue is free, after all queued matches are played
+	EstimatedWait int `json:"estimated_wait"`
+}
+
+// QueuedMatch struct used for storing a match waiting to be played at a venue
+type QueuedMatch struct {
+	Position int    `json:"position"`
+	Match    *Match `json:"match"`
+	// EstimatedWait is the number of seconds until the match is expected to start
+	EstimatedWait  int       `json:"estimated_wait"`
+	EstimatedStart time.Time `json:"estimated_start"`
+}
+
+// VenueQueueInput struct used for adding a match to a venue queue. If no venue is given, the match is added to the
+// venue of its office which is expected to be free first
+type VenueQueueInput struct {
+	MatchID int      `json:"match_id" validate:"required"`
+	VenueID null.Int `json:"venue_id"`
+}
+
+// VenueQueueOrder struct used for reordering the queue of a venue
+type VenueQueueOrder struct {
+	MatchIDs []int `json:"match_ids" validate:"required"`
+}
+
+// ExpectedLegs will return the number of legs a match with the given mode is expected to last, halfway between the
+// fewest and most legs possible
+func ExpectedLegs(mode *MatchMode) float64 {
+	most := float64(2*mode.WinsRequired - 1)
+	if mode.LegsRequired.Valid {
+		most = float64(mode.LegsRequired.Int64)
+	}
+	return (float64(mode.WinsRequired) + most) / 2
+}
+
+// Estimate will estimate when each queued match will start, based on the given average leg duration. The match now
+// playing is expected to last for its remaining legs, with the current leg being half done
+func (queue *VenueQueue) Estimate(legDuration time.Duration, now time.Time) {
+	queue.AverageLegDuration = int(legDuration.Seconds())
+
+	wait := 0.0
+	if queue.NowPlaying != nil {
+		played := 0
+		for _, leg := range queue.NowPlaying.Legs {
+			if leg.IsFinished {
+				played++
+			}
+		}
+		remaining := math.Max(ExpectedLegs(queue.NowPlaying.MatchMode)-float64(played), 1) - 0.5
+		wait = remaining * legDuration.Seconds()
+	}
+	for _, queued := range queue.UpNext {
+		queued.EstimatedWait = int(wait)
+		queued.EstimatedStart = now.Add(time.Duration(wait) * time.Second)
+		wait += ExpectedLegs(queued.Match.MatchMode) * legDuration.Seconds()
+	}
+	queue.EstimatedWait = int(wait)
+}
+
+// VenueQueueError used when a match cannot be queued, or the queue cannot be modified as requested
+type VenueQueueError struct {
+	Err error
+}
+
+func (e *VenueQueueError) Error() string {
+	return e.Err.Error()
+}
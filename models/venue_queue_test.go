@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExpectedLegs will check the expected number of legs for different match modes
+func TestExpectedLegs(t *testing.T) {
+	assert.Equal(t, ExpectedLegs(&MatchMode{WinsRequired: 1}), 1.0, "should be equal")
+	assert.Equal(t, ExpectedLegs(&MatchMode{WinsRequired: 3}), 4.0, "should be equal")
+	assert.Equal(t, ExpectedLegs(&MatchMode{WinsRequired: 2, LegsRequired: null.IntFrom(2)}), 2.0, "should be equal")
+}
+
+// TestVenueQueueEstimate will check that wait times include the match now playing and all matches ahead in the queue
+func TestVenueQueueEstimate(t *testing.T) {
+	now := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	queue := &VenueQueue{
+		NowPlaying: &Match{MatchMode: &MatchMode{WinsRequired: 2}, Legs: []*Leg{{IsFinished: true}, {IsFinished: false}}},
+		UpNext: []*QueuedMatch{
+			{Position: 1, Match: &Match{MatchMode: &MatchMode{WinsRequired: 1}}},
+			{Position: 2, Match: &Match{MatchMode: &MatchMode{WinsRequired: 3}}},
+		},
+	}
+	queue.Estimate(4*time.Minute, now)
+
+	// Best of 3 expects 2.5 legs, with 1 played and the current leg half done
+	assert.Equal(t, queue.UpNext[0].EstimatedWait, 240, "should be equal")
+	assert.Equal(t, queue.UpNext[0].EstimatedStart, now.Add(4*time.Minute), "should be equal")
+	assert.Equal(t, queue.UpNext[1].EstimatedWait, 480, "should be equal")
+	assert.Equal(t, queue.EstimatedWait, 1440, "should be equal")
+	assert.Equal(t, queue.AverageLegDuration, 240, "should be equal")
+}
+
+// TestVenueQueueEstimate_Free will check that the first queued match can start right away on a free venue
+func TestVenueQueueEstimate_Free(t *testing.T) {
+	queue := &VenueQueue{UpNext: []*QueuedMatch{{Position: 1, Match: &Match{MatchMode: &MatchMode{WinsRequired: 1}}}}}
+	queue.Estimate(DefaultLegDuration, time.Now())
+	assert.Equal(t, queue.UpNext[0].EstimatedWait, 0, "should be equal")
+	assert.Equal(t, queue.EstimatedWait, 300, "should be equal")
+}
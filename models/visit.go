@@ -17,7 +17,7 @@ type Visit struct {
 	ID          int         `json:"id"`
 	LegID       int         `json:"leg_id"`
 	PlayerID    int         `json:"player_id"`
-	FirstDart   *Dart       `json:"first_dart"`
+	FirstDart   *Dart       `json:"first_dart" validate:"required"`
 	SecondDart  *Dart       `json:"second_dart"`
 	ThirdDart   *Dart       `json:"third_dart"`
 	IsBust      bool        `json:"is_bust"`
@@ -478,6 +478,54 @@ func (visit *Visit) Calculate420Score(round int) int {
 	return score
 }
 
+// CalculateGolfScore will calculate the number of strokes for the given hole. The first dart hitting the hole number
+// counts, with a double giving 1 stroke, a treble 2 and a single 3, while missing with all three darts gives 5 strokes
+func (visit *Visit) CalculateGolfScore(hole int) int {
+	for _, dart := range visit.GetDarts() {
+		if dart.ValueRaw() != hole {
+			continue
+		}
+		if dart.IsDouble() {
+			return 1
+		} else if dart.IsTriple() {
+			return 2
+		}
+		return 3
+	}
+	return GolfMissStrokes
+}
+
+// GetGolfHitDart will return the index of the dart which counted for the given hole, or -1 if no dart hit it
+func (visit *Visit) GetGolfHitDart(hole int) int {
+	for i, dart := range visit.GetDarts() {
+		if dart.ValueRaw() == hole {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetBaseballTarget will return the number to aim at in the given inning of Baseball
+func GetBaseballTarget(inning int) int {
+	if inning > 20 {
+		return BULLSEYE
+	}
+	return inning
+}
+
+// CalculateBaseballScore will calculate the runs scored in the given inning. The inning number is the target, with
+// bull used for innings past 20, and each hit scores runs equal to the multiplier
+func (visit *Visit) CalculateBaseballScore(inning int) int {
+	target := GetBaseballTarget(inning)
+	score := 0
+	for _, dart := range visit.GetDarts() {
+		if dart.ValueRaw() == target {
+			score += int(dart.Multiplier)
+		}
+	}
+	return score
+}
+
 // CalculateKillBullScore will calculate the score for the given visit
 func (visit *Visit) CalculateKillBullScore() int {
 	score := 0
@@ -627,6 +675,50 @@ func (visit *Visit) Calculate170Score(round int, player *Player2Leg) int {
 	return score
 }
 
+// CalculateBobs27Score will calculate the score for the given visit. Each hit on the double adds the value of the double,
+// while missing it with all three darts subtracts the value of the double
+func (visit *Visit) CalculateBobs27Score(round int) int {
+	if round < 0 || round >= len(TargetsBobs27) {
+		return 0
+	}
+	target := TargetsBobs27[round]
+
+	score := 0
+	score += visit.FirstDart.GetBobs27Score(target)
+	score += visit.SecondDart.GetBobs27Score(target)
+	score += visit.ThirdDart.GetBobs27Score(target)
+	if score == 0 {
+		score = -target.Value * 2
+	}
+	return score
+}
+
+// Calculate121Score will calculate the score for the given visit in a game of 121. The target is stored as the
+// starting score of the player, and is moved up after a successful checkout and down after 9 darts without one
+func (visit *Visit) Calculate121Score(player *Player2Leg, params *LegParameters) int {
+	player.DartsThrown += 3
+	score := 0
+	if !visit.IsBust {
+		player.CurrentScore -= visit.GetScore()
+		score = visit.GetScore()
+	}
+
+	if player.CurrentScore == 0 && visit.GetLastDart().IsDouble() {
+		// We hit a checkout, move target up
+		player.StartingScore = params.GetNextCheckoutTarget(player.StartingScore, true)
+		player.CurrentScore = player.StartingScore
+		player.CurrentPoints.Int64++
+		player.DartsThrown = 0
+	} else if player.DartsThrown%9 == 0 {
+		// 9 Darts have been thrown, move target down
+		player.StartingScore = params.GetNextCheckoutTarget(player.StartingScore, false)
+		player.CurrentScore = player.StartingScore
+		player.DartsThrown = 0
+		score = 0
+	}
+	return score
+}
+
 // IsShanghai will check if the given visit is a "Shanghai". A Shanghai visit is one where a single, double and triple multipler is hit with each dart
 func (visit *Visit) IsShanghai() bool {
 	first := visit.FirstDart
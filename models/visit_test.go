@@ -39,3 +39,72 @@ func TestIsVisitCheckout_Master(t *testing.T) {
 	visit = Visit{FirstDart: &Dart{Value: null.IntFrom(3), Multiplier: 3}, SecondDart: &Dart{}, ThirdDart: &Dart{}}
 	assert.Equal(t, visit.IsVisitCheckout(9, OUTSHOTMASTER), true, "should be checkout")
 }
+
+// TestCalculateBobs27Score will check that hits on the double are added, and a full miss subtracts the double
+func TestCalculateBobs27Score(t *testing.T) {
+	visit := Visit{FirstDart: &Dart{Value: null.IntFrom(1), Multiplier: 2},
+		SecondDart: &Dart{Value: null.IntFrom(1), Multiplier: 1},
+		ThirdDart:  &Dart{Value: null.IntFrom(1), Multiplier: 2}}
+	assert.Equal(t, 4, visit.CalculateBobs27Score(0), "should add both doubles")
+
+	visit = Visit{FirstDart: &Dart{Value: null.IntFrom(20), Multiplier: 2}, SecondDart: &Dart{}, ThirdDart: &Dart{}}
+	assert.Equal(t, -4, visit.CalculateBobs27Score(1), "should subtract double 2")
+
+	visit = Visit{FirstDart: &Dart{Value: null.IntFrom(25), Multiplier: 2}, SecondDart: &Dart{}, ThirdDart: &Dart{}}
+	assert.Equal(t, 50, visit.CalculateBobs27Score(20), "should add bullseye")
+	assert.Equal(t, 0, visit.CalculateBobs27Score(21), "should not score outside of rounds")
+}
+
+// TestCalculate121Score will check that the target moves up after a checkout and down after 9 darts
+func TestCalculate121Score(t *testing.T) {
+	params := &LegParameters{MinTarget: null.IntFrom(121)}
+	player := &Player2Leg{CurrentScore: 121, StartingScore: 121}
+
+	visit := Visit{FirstDart: &Dart{Value: null.IntFrom(20), Multiplier: 3},
+		SecondDart: &Dart{Value: null.IntFrom(11), Multiplier: 3},
+		ThirdDart:  &Dart{Value: null.IntFrom(14), Multiplier: 2}}
+	assert.Equal(t, 121, visit.Calculate121Score(player, params))
+	assert.Equal(t, 122, player.StartingScore, "should move target up")
+	assert.Equal(t, int64(1), player.CurrentPoints.Int64, "should count checkout")
+
+	miss := Visit{FirstDart: &Dart{}, SecondDart: &Dart{}, ThirdDart: &Dart{}}
+	miss.Calculate121Score(player, params)
+	miss.Calculate121Score(player, params)
+	miss.Calculate121Score(player, params)
+	assert.Equal(t, 121, player.StartingScore, "should move target down")
+	assert.Equal(t, 121, player.CurrentScore)
+
+	miss.Calculate121Score(player, params)
+	miss.Calculate121Score(player, params)
+	miss.Calculate121Score(player, params)
+	assert.Equal(t, 121, player.StartingScore, "should not move target below minimum")
+}
+
+// TestCalculateGolfScore will check that the first dart hitting the hole is counted
+func TestCalculateGolfScore(t *testing.T) {
+	visit := Visit{FirstDart: &Dart{Value: null.IntFrom(3), Multiplier: 2}, SecondDart: &Dart{}, ThirdDart: &Dart{}}
+	assert.Equal(t, 1, visit.CalculateGolfScore(3), "double should be a hole in one")
+
+	visit = Visit{FirstDart: &Dart{Value: null.IntFrom(17), Multiplier: 1},
+		SecondDart: &Dart{Value: null.IntFrom(3), Multiplier: 3},
+		ThirdDart:  &Dart{Value: null.IntFrom(3), Multiplier: 2}}
+	assert.Equal(t, 2, visit.CalculateGolfScore(3), "first hit should count")
+	assert.Equal(t, 1, visit.GetGolfHitDart(3), "second dart should be the hit")
+
+	visit = Visit{FirstDart: &Dart{Value: null.IntFrom(3), Multiplier: 1}, SecondDart: &Dart{}, ThirdDart: &Dart{}}
+	assert.Equal(t, 3, visit.CalculateGolfScore(3), "single should be 3 strokes")
+	assert.Equal(t, GolfMissStrokes, visit.CalculateGolfScore(4), "miss should be max strokes")
+	assert.Equal(t, -1, visit.GetGolfHitDart(4), "no dart should be the hit")
+}
+
+// TestCalculateBaseballScore will check that runs are counted for hits on the inning number
+func TestCalculateBaseballScore(t *testing.T) {
+	visit := Visit{FirstDart: &Dart{Value: null.IntFrom(5), Multiplier: 3},
+		SecondDart: &Dart{Value: null.IntFrom(5), Multiplier: 1},
+		ThirdDart:  &Dart{Value: null.IntFrom(20), Multiplier: 3}}
+	assert.Equal(t, 4, visit.CalculateBaseballScore(5), "should count multipliers of hits")
+	assert.Equal(t, 0, visit.CalculateBaseballScore(6), "should not count other numbers")
+
+	visit = Visit{FirstDart: &Dart{Value: null.IntFrom(25), Multiplier: 2}, SecondDart: &Dart{}, ThirdDart: &Dart{}}
+	assert.Equal(t, 2, visit.CalculateBaseballScore(21), "should target bull in extra innings")
+}
@@ -0,0 +1,164 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Route describes the request and response bodies of a single route, keyed by "<METHOD> <path template>"
+type Route struct {
+	Request  interface{}
+	Response interface{}
+}
+
+// Routes maps "<METHOD> <path template>" to the bodies of the route
+type Routes map[string]Route
+
+// Document is a OpenAPI 3 document
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components *Components                      `json:"components"`
+}
+
+// Info contains metadata about the API
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Operation is a single method on a path
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path parameter of an operation
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is the body of a request
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response is a single response of an operation
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+var pathParameter = regexp.MustCompile(`\{([^}:]+)(:[^}]+)?\}`)
+
+// NewDocument will create a document describing all routes registered on the given router, using the given
+// request and response bodies
+func NewDocument(router *mux.Router, info Info, routes Routes) (*Document, error) {
+	doc := &Document{
+		OpenAPI:    "3.0.3",
+		Info:       info,
+		Paths:      make(map[string]map[string]*Operation),
+		Components: &Components{Schemas: make(map[string]*Schema)},
+	}
+	errorSchema := doc.Components.SchemaFor(reflect.TypeOf(Error{}))
+	operationIDs := make(map[string]int)
+
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		handler := handlerName(route.GetHandler())
+
+		for _, method := range methods {
+			if method == http.MethodOptions {
+				continue
+			}
+			operation := &Operation{
+				OperationID: handler,
+				Tags:        []string{strings.Split(strings.TrimPrefix(template, "/"), "/")[0]},
+				Responses:   make(map[string]*Response),
+			}
+			if len(methods) > 1 {
+				operation.OperationID = handler + method[:1] + strings.ToLower(method[1:])
+			}
+			// Handlers serving multiple routes get a unique ID per route
+			operationIDs[operation.OperationID]++
+			if count := operationIDs[operation.OperationID]; count > 1 {
+				operation.OperationID = fmt.Sprintf("%s%d", operation.OperationID, count)
+			}
+			for _, match := range pathParameter.FindAllStringSubmatch(template, -1) {
+				operation.Parameters = append(operation.Parameters,
+					&Parameter{Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+			}
+
+			body := routes[method+" "+template]
+			if body.Request != nil {
+				operation.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+					"application/json": {Schema: doc.Components.RequestSchemaFor(reflect.TypeOf(body.Request))}}}
+				operation.Responses["400"] = &Response{Description: "Invalid request body", Content: map[string]*MediaType{
+					"application/json": {Schema: errorSchema}}}
+			}
+			if body.Response != nil {
+				operation.Responses["200"] = &Response{Description: "OK", Content: map[string]*MediaType{
+					"application/json": {Schema: doc.Components.SchemaFor(reflect.TypeOf(body.Response))}}}
+			} else {
+				operation.Responses["200"] = &Response{Description: "OK"}
+			}
+
+			path := pathParameter.ReplaceAllString(template, "{$1}")
+			if doc.Paths[path] == nil {
+				doc.Paths[path] = make(map[string]*Operation)
+			}
+			doc.Paths[path][strings.ToLower(method)] = operation
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// handlerName will return the name of the function handling the route
+func handlerName(handler http.Handler) string {
+	if handler == nil {
+		return ""
+	}
+	if wrapper, ok := handler.(interface{ Unwrap() http.Handler }); ok {
+		return handlerName(wrapper.Unwrap())
+	}
+	if fn, ok := handler.(http.HandlerFunc); ok {
+		name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+		name = name[strings.LastIndex(name, "/")+1:]
+		name = strings.TrimSuffix(name, "-fm")
+		if i := strings.LastIndex(name, "."); i >= 0 && !strings.HasPrefix(name[i+1:], "func") {
+			return name[i+1:]
+		}
+		return strings.ReplaceAll(name, ".", "_")
+	}
+	return reflect.TypeOf(handler).String()
+}
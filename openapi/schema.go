@@ -0,0 +1,182 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// Schema is a OpenAPI 3 schema object
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Any can be used as request or response of an operation which does not have a fixed schema
+type Any struct{}
+
+// JSONTyper is implemented by types with a custom MarshalJSON, returning a value of the type which is serialized instead.
+// Responses are described by the returned type, while requests are still decoded into, and described by, the type itself
+type JSONTyper interface {
+	JSONType() interface{}
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	anyType       = reflect.TypeOf(Any{})
+	jsonTyperType = reflect.TypeOf((*JSONTyper)(nil)).Elem()
+	// nullTypes are types from guregu/null, which are serialized as the wrapped value or null
+	nullTypes = map[reflect.Type]*Schema{
+		reflect.TypeOf(null.Int{}):    {Type: "integer", Format: "int64", Nullable: true},
+		reflect.TypeOf(null.Float{}):  {Type: "number", Format: "double", Nullable: true},
+		reflect.TypeOf(null.String{}): {Type: "string", Nullable: true},
+		reflect.TypeOf(null.Bool{}):   {Type: "boolean", Nullable: true},
+		reflect.TypeOf(null.Time{}):   {Type: "string", Format: "date-time", Nullable: true},
+	}
+)
+
+// Components holds all named schemas referenced from operations
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// SchemaFor will return the schema of the given type, as serialized in responses. Named structs are added to the
+// components and referenced
+func (c *Components) SchemaFor(t reflect.Type) *Schema {
+	return c.schemaFor(t, false)
+}
+
+// RequestSchemaFor will return the schema of the given type, as decoded from request bodies. Structs containing
+// types which implement JSONTyper are added to the components with a "Request" suffix
+func (c *Components) RequestSchemaFor(t reflect.Type) *Schema {
+	return c.schemaFor(t, true)
+}
+
+func (c *Components) schemaFor(t reflect.Type, request bool) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+	if schema, ok := nullTypes[t]; ok {
+		s := *schema
+		return &s
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean", Nullable: nullable}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32", Nullable: nullable}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64", Nullable: nullable}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double", Nullable: nullable}
+	case reflect.String:
+		return &Schema{Type: "string", Nullable: nullable}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte", Nullable: true}
+		}
+		return &Schema{Type: "array", Items: c.schemaFor(t.Elem(), request), Nullable: true}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: c.schemaFor(t.Elem(), request), Nullable: true}
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time", Nullable: nullable}
+		}
+		if t == anyType {
+			return &Schema{}
+		}
+		if t.Name() == "" {
+			return c.structSchema(t, request)
+		}
+		name := t.Name()
+		if request && hasJSONType(t, make(map[reflect.Type]bool)) {
+			name += "Request"
+		}
+		if _, ok := c.Schemas[name]; !ok {
+			// Register before resolving fields, to support recursive types
+			c.Schemas[name] = &Schema{Type: "object"}
+			fields := t
+			if !request && t.Implements(jsonTyperType) {
+				fields = reflect.TypeOf(reflect.Zero(t).Interface().(JSONTyper).JSONType())
+			}
+			c.Schemas[name] = c.structSchema(fields, request)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+// structSchema will return the schema of the exported fields of the given struct. Fields tagged with
+// `validate:"required"` are required to be present and not null
+func (c *Components) structSchema(t reflect.Type, request bool) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag := field.Tag.Get("json"); tag != "" {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		} else if field.Anonymous {
+			embedded := c.structSchema(field.Type, request)
+			for key, value := range embedded.Properties {
+				schema.Properties[key] = value
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+		schema.Properties[name] = c.schemaFor(field.Type, request)
+		if field.Tag.Get("validate") == "required" {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// hasJSONType returns true if the given type, or any struct it contains, implements JSONTyper, in which case
+// the type is serialized differently than it is decoded
+func hasJSONType(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return false
+	}
+	seen[t] = true
+	if t.Implements(jsonTyperType) {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() && hasJSONType(t.Field(i).Type, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve will return the schema referenced by the given schema, if any
+func (c *Components) resolve(schema *Schema) *Schema {
+	if schema.Ref == "" {
+		return schema
+	}
+	return c.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+}
@@ -0,0 +1,74 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/kcapp/api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newComponents() *Components {
+	return &Components{Schemas: make(map[string]*Schema)}
+}
+
+// TestSchemaFor_MatchesWireFormat will check that the response schema of types with a custom MarshalJSON contains
+// every key actually serialized
+func TestSchemaFor_MatchesWireFormat(t *testing.T) {
+	c := newComponents()
+	schema := c.resolve(c.SchemaFor(reflect.TypeOf(models.Leg{})))
+
+	leg := models.Leg{
+		ID:             1,
+		LegType:        &models.MatchType{ID: models.X01},
+		Players:        []int{1, 2},
+		BoardStreamURL: null.StringFrom("http://localhost"),
+		DartsThrown:    3,
+		Parameters:     &models.LegParameters{},
+		CreatedAt:      time.Now(),
+	}
+	b, err := json.Marshal(leg)
+	assert.NoError(t, err)
+	var wire map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &wire))
+
+	for key := range wire {
+		assert.Contains(t, schema.Properties, key, "schema should describe serialized key %s", key)
+	}
+	assert.Contains(t, schema.Properties, "round")
+	assert.Equal(t, "#/components/schemas/Leg", c.SchemaFor(reflect.TypeOf(&models.Leg{})).Ref)
+}
+
+// TestRequestSchemaFor will check that request bodies are described by the fields they are decoded into
+func TestRequestSchemaFor(t *testing.T) {
+	c := newComponents()
+
+	ref := c.RequestSchemaFor(reflect.TypeOf(models.Match{}))
+	assert.Equal(t, "#/components/schemas/MatchRequest", ref.Ref)
+	match := c.resolve(ref)
+	assert.NotContains(t, match.Properties, "current_leg_num", "should not contain keys only serialized in responses")
+	assert.Equal(t, "#/components/schemas/LegRequest", match.Properties["legs"].Items.Ref)
+	assert.NotContains(t, c.resolve(match.Properties["legs"].Items).Properties, "round")
+
+	player := c.resolve(c.RequestSchemaFor(reflect.TypeOf(models.Player{})))
+	assert.Equal(t, []string{"first_name"}, player.Required)
+
+	ref = c.RequestSchemaFor(reflect.TypeOf(models.Visit{}))
+	assert.Equal(t, "#/components/schemas/Visit", ref.Ref, "should share schema of types serialized as decoded")
+	assert.Equal(t, ref.Ref, c.SchemaFor(reflect.TypeOf(models.Visit{})).Ref)
+}
+
+// TestSchemaFor_Types will check the schema of basic and nullable types
+func TestSchemaFor_Types(t *testing.T) {
+	c := newComponents()
+	assert.Equal(t, &Schema{Type: "integer", Format: "int32"}, c.SchemaFor(reflect.TypeOf(0)))
+	assert.Equal(t, &Schema{Type: "integer", Format: "int32", Nullable: true}, c.SchemaFor(reflect.TypeOf(new(int))))
+	assert.Equal(t, &Schema{Type: "integer", Format: "int64", Nullable: true}, c.SchemaFor(reflect.TypeOf(null.Int{})))
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, c.SchemaFor(reflect.TypeOf(time.Time{})))
+	assert.Equal(t, &Schema{}, c.SchemaFor(reflect.TypeOf(Any{})))
+	assert.Equal(t, "array", c.SchemaFor(reflect.TypeOf([]string{})).Type)
+	assert.Equal(t, "object", c.SchemaFor(reflect.TypeOf(map[string]int{})).Type)
+}
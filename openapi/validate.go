@@ -0,0 +1,199 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Error is returned as body of all requests failing validation
+type Error struct {
+	Error  string        `json:"error"`
+	Fields []*FieldError `json:"fields,omitempty"`
+}
+
+// FieldError describes a single invalid field in a request body
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// WriteError will write the given error as a 400 Bad Request response
+func WriteError(w http.ResponseWriter, e *Error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(e)
+}
+
+// DecodeError will convert an error returned by json.Decoder into a structured error
+func DecodeError(err error) *Error {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		path := "$"
+		if typeErr.Field != "" {
+			path += "." + typeErr.Field
+		}
+		return &Error{Error: "invalid request body", Fields: []*FieldError{
+			{Path: path, Message: fmt.Sprintf("expected %s, got %s", typeErr.Type.String(), typeErr.Value)}}}
+	case errors.As(err, &syntaxErr):
+		return &Error{Error: "invalid request body", Fields: []*FieldError{
+			{Path: "$", Message: fmt.Sprintf("malformed JSON at offset %d: %s", syntaxErr.Offset, syntaxErr.Error())}}}
+	case errors.Is(err, io.EOF):
+		return &Error{Error: "invalid request body", Fields: []*FieldError{{Path: "$", Message: "request body is empty"}}}
+	default:
+		return &Error{Error: "invalid request body", Fields: []*FieldError{{Path: "$", Message: err.Error()}}}
+	}
+}
+
+// Validate will validate the given JSON body against the schema, returning all invalid fields
+func (c *Components) Validate(schema *Schema, body []byte) []*FieldError {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return DecodeError(err).Fields
+	}
+	errs := make([]*FieldError, 0)
+	c.validate(schema, value, "$", &errs)
+	return errs
+}
+
+func (c *Components) validate(schema *Schema, value interface{}, path string, errs *[]*FieldError) {
+	schema = c.resolve(schema)
+	if schema == nil || value == nil {
+		// Missing values are handled by the required check of the parent
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, &FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch schema.Type {
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("expected boolean, got %s", describe(value))
+		}
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			fail("expected integer, got %s", describe(value))
+		} else if _, err := n.Int64(); err != nil {
+			fail("expected integer, got %s", n.String())
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			fail("expected number, got %s", describe(value))
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			fail("expected string, got %s", describe(value))
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			fail("expected array, got %s", describe(value))
+			return
+		}
+		for i, item := range items {
+			c.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			fail("expected object, got %s", describe(value))
+			return
+		}
+		for _, name := range schema.Required {
+			if object[name] == nil {
+				*errs = append(*errs, &FieldError{Path: path + "." + name, Message: "is required"})
+			}
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if property, ok := schema.Properties[key]; ok {
+				c.validate(property, object[key], path+"."+key, errs)
+			} else if schema.AdditionalProperties != nil {
+				c.validate(schema.AdditionalProperties, object[key], path+"."+key, errs)
+			}
+		}
+	}
+}
+
+// describe will return the JSON type of the given value
+func describe(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number " + v.String()
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "null"
+	}
+}
+
+// Middleware will validate the body of all requests with a documented request body, and reject invalid requests
+// with a structured 400 error. Fields are reported as paths from the root of the body, e.g. "$.first_dart.value"
+func (d *Document) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := mux.CurrentRoute(r)
+		if route == nil || r.Body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		schema := d.requestSchema(r.Method, template)
+		if schema == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			WriteError(w, &Error{Error: "unable to read request body"})
+			return
+		}
+		if fields := d.Components.Validate(schema, body); len(fields) > 0 {
+			WriteError(w, &Error{Error: fmt.Sprintf("invalid request body for %s %s", r.Method, template), Fields: fields})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestSchema will return the schema of the request body for the given operation, if any
+func (d *Document) requestSchema(method string, template string) *Schema {
+	path, ok := d.Paths[pathParameter.ReplaceAllString(template, "{$1}")]
+	if !ok {
+		return nil
+	}
+	operation, ok := path[strings.ToLower(method)]
+	if !ok || operation.RequestBody == nil {
+		return nil
+	}
+	return operation.RequestBody.Content["application/json"].Schema
+}
@@ -0,0 +1,92 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+type testDart struct {
+	Value      null.Int `json:"value" validate:"required"`
+	Multiplier int64    `json:"multiplier"`
+}
+
+type testVisit struct {
+	LegID  int                `json:"leg_id" validate:"required"`
+	Darts  []*testDart        `json:"darts"`
+	Extra  map[string]float64 `json:"extra"`
+	IsBust bool               `json:"is_bust"`
+}
+
+// TestValidate will check that all invalid fields are reported with their path
+func TestValidate(t *testing.T) {
+	c := newComponents()
+	schema := c.RequestSchemaFor(reflect.TypeOf(testVisit{}))
+
+	assert.Empty(t, c.Validate(schema, []byte(`{"leg_id": 1, "darts": [{"value": 20, "multiplier": 3}], "extra": {"a": 1.5}}`)))
+
+	errs := c.Validate(schema, []byte(`{"darts": [{"value": 20}, {"value": "20", "multiplier": 1.5}], "extra": {"a": "b"}, "is_bust": 1}`))
+	assert.Equal(t, []*FieldError{
+		{Path: "$.leg_id", Message: "is required"},
+		{Path: "$.darts[1].multiplier", Message: "expected integer, got 1.5"},
+		{Path: "$.darts[1].value", Message: "expected integer, got string"},
+		{Path: "$.extra.a", Message: "expected number, got string"},
+		{Path: "$.is_bust", Message: "expected boolean, got number 1"},
+	}, errs)
+
+	errs = c.Validate(schema, []byte(`{"leg_id": null}`))
+	assert.Equal(t, []*FieldError{{Path: "$.leg_id", Message: "is required"}}, errs, "null should not satisfy required")
+
+	errs = c.Validate(schema, []byte(`[]`))
+	assert.Equal(t, []*FieldError{{Path: "$", Message: "expected object, got array"}}, errs)
+
+	errs = c.Validate(schema, []byte(`{"leg_id": `))
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "$", errs[0].Path)
+}
+
+// TestDecodeError will check that decoding errors are converted to field errors
+func TestDecodeError(t *testing.T) {
+	var visit testVisit
+	err := json.NewDecoder(strings.NewReader(`{"leg_id": "1"}`)).Decode(&visit)
+	assert.Equal(t, "$.leg_id", DecodeError(err).Fields[0].Path)
+
+	err = json.NewDecoder(strings.NewReader(``)).Decode(&visit)
+	assert.Equal(t, "request body is empty", DecodeError(err).Fields[0].Message)
+
+	assert.Equal(t, "failed", DecodeError(errors.New("failed")).Fields[0].Message)
+}
+
+// TestMiddleware will check that invalid request bodies are rejected before reaching the handler
+func TestMiddleware(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/visit/{id}", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}).Methods("POST")
+	doc, err := NewDocument(router, Info{Title: "test"}, Routes{"POST /visit/{id}": {Request: testVisit{}}})
+	assert.NoError(t, err)
+	router.Use(doc.Middleware)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/visit/1", strings.NewReader(`{"leg_id": 1}`)))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `{"leg_id": 1}`, recorder.Body.String(), "handler should receive the original body")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/visit/1", strings.NewReader(`{"leg_id": "1"}`)))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	var e Error
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&e))
+	assert.Equal(t, "invalid request body for POST /visit/{id}", e.Error)
+	assert.Equal(t, []*FieldError{{Path: "$.leg_id", Message: "expected integer, got string"}}, e.Fields)
+}
@@ -0,0 +1,124 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// bucket is a token bucket for a single client
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter limits the rate of write requests per client using token buckets. Each client may burst up to Burst
+// requests, after which requests are allowed at Rate requests per second
+type Limiter struct {
+	Rate  float64
+	Burst int
+	// TrustForwardedFor will identify clients by the first address in X-Forwarded-For, when running behind a proxy
+	TrustForwardedFor bool
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+	exempt  map[string]bool
+}
+
+// NewLimiter will create a new limiter with the given rate and burst
+func NewLimiter(rate float64, burst int, trustForwardedFor bool) *Limiter {
+	return &Limiter{
+		Rate:              rate,
+		Burst:             burst,
+		TrustForwardedFor: trustForwardedFor,
+		buckets:           make(map[string]*bucket),
+		exempt:            make(map[string]bool),
+	}
+}
+
+// Exempt will never limit requests to the given route templates, for routes which use POST without writing anything
+func (l *Limiter) Exempt(templates ...string) {
+	for _, template := range templates {
+		l.exempt[template] = true
+	}
+}
+
+// Allow will take a token from the bucket of the given client. If no token is available, the time until the next
+// token is available is returned
+func (l *Limiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: float64(l.Burst), last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(float64(l.Burst), b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.Rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep will remove buckets which have been refilled, so idle clients do not use memory. Must be called with the lock held
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < time.Minute {
+		return
+	}
+	l.swept = now
+	full := time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+	for client, b := range l.buckets {
+		if now.Sub(b.last) > full {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// Client will return the identifier of the client making the given request
+func (l *Limiter) Client(r *http.Request) string {
+	if l.TrustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Middleware will reject write requests with 429 Too Many Requests once the client has exceeded its rate limit
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if route := mux.CurrentRoute(r); route != nil {
+			if template, err := route.GetPathTemplate(); err == nil && l.exempt[template] {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		if ok, wait := l.Allow(l.Client(r)); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAllow will check that clients may burst up to the limit, and are limited separately
+func TestAllow(t *testing.T) {
+	l := NewLimiter(1, 2, false)
+	ok, _ := l.Allow("a")
+	assert.True(t, ok)
+	ok, _ = l.Allow("a")
+	assert.True(t, ok)
+	ok, wait := l.Allow("a")
+	assert.False(t, ok)
+	assert.Greater(t, wait.Seconds(), 0.0)
+
+	ok, _ = l.Allow("b")
+	assert.True(t, ok)
+}
+
+// TestClient will check that clients are identified by X-Forwarded-For only when trusted
+func TestClient(t *testing.T) {
+	r := httptest.NewRequest("POST", "/visit", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("X-Forwarded-For", "192.168.1.1, 10.0.0.2")
+
+	assert.Equal(t, "10.0.0.1", NewLimiter(1, 1, false).Client(r))
+	assert.Equal(t, "192.168.1.1", NewLimiter(1, 1, true).Client(r))
+}
+
+// TestMiddleware will check that only writes to routes which are not exempt are limited
+func TestMiddleware(t *testing.T) {
+	l := NewLimiter(1, 1, false)
+	l.Exempt("/graphql")
+	router := mux.NewRouter()
+	router.Use(l.Middleware)
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	router.HandleFunc("/visit", ok).Methods("GET", "POST")
+	router.HandleFunc("/graphql", ok).Methods("POST")
+
+	serve := func(method string, path string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+	assert.Equal(t, http.StatusOK, serve("POST", "/visit"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("POST", "/visit"))
+	assert.Equal(t, http.StatusOK, serve("GET", "/visit"))
+	assert.Equal(t, http.StatusOK, serve("POST", "/graphql"))
+}
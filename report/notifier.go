@@ -0,0 +1,76 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/kcapp/api/models"
+)
+
+// Notifier is used to deliver generated reports, e.g. to a chat channel
+type Notifier interface {
+	Notify(report *models.Report) error
+}
+
+var notifier Notifier
+
+// SetNotifier will set the notifier used for delivering reports. Reports are not delivered when notifier is nil
+func SetNotifier(n Notifier) {
+	notifier = n
+}
+
+// LogNotifier will write reports to the log
+type LogNotifier struct {
+	Format string
+}
+
+// Notify will write the rendered report to the log
+func (n *LogNotifier) Notify(report *models.Report) error {
+	body, _, err := report.Render(n.Format)
+	if err != nil {
+		return err
+	}
+	log.Printf("Report %d:\n%s", report.ID, body)
+	return nil
+}
+
+// WebhookNotifier will post reports to a webhook. The body is compatible with Slack and Mattermost incoming webhooks
+type WebhookNotifier struct {
+	URL    string
+	Format string
+	client *http.Client
+}
+
+// NewWebhookNotifier will create a new notifier posting reports rendered to the given format to the given URL
+func NewWebhookNotifier(url string, format string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, Format: format, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify will post the rendered report to the webhook
+func (n *WebhookNotifier) Notify(report *models.Report) error {
+	body, _, err := report.Render(n.Format)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"text":      body,
+		"title":     report.Title(),
+		"report_id": report.ID,
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/kcapp/api/data"
+	"github.com/kcapp/api/models"
+)
+
+// schedule is a report schedule with its parsed cron expression
+type schedule struct {
+	*models.ReportSchedule
+	cron *models.CronSchedule
+}
+
+// Validate will check that the cron expression and period of every schedule are valid
+func Validate(schedules []*models.ReportSchedule) error {
+	_, err := parseSchedules(schedules)
+	return err
+}
+
+// parseSchedules will parse the cron expression of every schedule, checking that their periods are valid
+func parseSchedules(schedules []*models.ReportSchedule) ([]*schedule, error) {
+	parsed := make([]*schedule, 0, len(schedules))
+	for _, s := range schedules {
+		cron, err := models.ParseCron(s.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule for report '%s': %w", s.Name, err)
+		}
+		if _, _, err := models.ReportPeriod(s.Period, time.Now()); err != nil {
+			return nil, fmt.Errorf("invalid period for report '%s': %w", s.Name, err)
+		}
+		parsed = append(parsed, &schedule{ReportSchedule: s, cron: cron})
+	}
+	return parsed, nil
+}
+
+// Start will generate reports according to the given schedules until the context is cancelled. Reports for the last
+// complete period of each schedule are generated on startup, in case they were due while the API was not running.
+// Schedules should be checked using Validate before starting
+func Start(ctx context.Context, schedules []*models.ReportSchedule) {
+	parsed, err := parseSchedules(schedules)
+	if err != nil {
+		log.Printf("Unable to schedule reports: %s", err)
+		return
+	}
+	for _, s := range parsed {
+		log.Printf("Scheduled %s report '%s', next at %s", s.Period, s.Name, s.cron.Next(time.Now()).Format(time.RFC3339))
+	}
+	if len(parsed) == 0 {
+		return
+	}
+	now := time.Now()
+	for _, s := range parsed {
+		Run(s.ReportSchedule, now)
+	}
+
+	for {
+		now := time.Now()
+		timer := time.NewTimer(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case t := <-timer.C:
+			for _, s := range parsed {
+				if s.cron.Matches(t) {
+					Run(s.ReportSchedule, t)
+				}
+			}
+		}
+	}
+}
+
+// Run will generate reports for the last complete period before the given time, for all offices of the schedule.
+// Reports already generated for the period, e.g. by another instance, are skipped
+func Run(s *models.ReportSchedule, now time.Time) {
+	from, to, err := models.ReportPeriod(s.Period, now)
+	if err != nil {
+		log.Printf("Unable to run report '%s': %s", s.Name, err)
+		return
+	}
+	offices := s.Offices
+	if len(offices) == 0 {
+		offices, err = data.GetActiveOfficeIDs()
+		if err != nil {
+			log.Printf("Unable to get offices for report '%s': %s", s.Name, err)
+			return
+		}
+	}
+	for _, officeID := range offices {
+		if _, err := data.GetReportForPeriod(officeID, s.Period, from); err != sql.ErrNoRows {
+			if err != nil {
+				log.Printf("Unable to check %s report for office %d: %s", s.Period, officeID, err)
+			}
+			continue
+		}
+		report, err := data.GenerateReport(officeID, s.Period, from, to)
+		if err == models.ErrReportExists {
+			// Generated by another instance since it was checked
+			continue
+		} else if err != nil {
+			log.Printf("Unable to generate %s report for office %d: %s", s.Period, officeID, err)
+			continue
+		}
+		if s.Notify && notifier != nil {
+			if err := notifier.Notify(report); err != nil {
+				log.Printf("Unable to deliver report %d: %s", report.ID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/kcapp/api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestValidate will check that invalid schedules are rejected before reports are started
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(nil))
+	assert.NoError(t, Validate([]*models.ReportSchedule{{Name: "weekly", Cron: "0 8 * * 1", Period: models.ReportWeekly}}))
+	assert.Error(t, Validate([]*models.ReportSchedule{{Name: "weekly", Cron: "0 8 * *", Period: models.ReportWeekly}}))
+	assert.Error(t, Validate([]*models.ReportSchedule{{Name: "daily", Cron: "@daily", Period: "daily"}}))
+}
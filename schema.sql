@@ -0,0 +1,178 @@
+-- Tables and rows used by this API which are not yet part of the migrations in kcapp/database.
+-- All statements can be applied to an existing database, and are safe to run more than once.
+
+-- Statistics for Bob's 27, 121, Golf and Baseball legs
+CREATE TABLE IF NOT EXISTS statistics_bobs_27 (
+    id              INT NOT NULL AUTO_INCREMENT,
+    leg_id          INT NOT NULL,
+    player_id       INT NOT NULL,
+    darts_thrown    INT NOT NULL,
+    score           INT NOT NULL,
+    highest_score   INT NOT NULL,
+    rounds_played   INT NOT NULL,
+    doubles_hit     INT NOT NULL,
+    doubles_hitrate DECIMAL(6, 3) NOT NULL,
+    is_busted       TINYINT(1) NOT NULL DEFAULT 0,
+    PRIMARY KEY (id),
+    UNIQUE KEY statistics_bobs_27_leg_player (leg_id, player_id),
+    KEY statistics_bobs_27_player (player_id)
+);
+
+CREATE TABLE IF NOT EXISTS statistics_121 (
+    id                  INT NOT NULL AUTO_INCREMENT,
+    leg_id              INT NOT NULL,
+    player_id           INT NOT NULL,
+    darts_thrown        INT NOT NULL,
+    attempts            INT NOT NULL,
+    checkouts           INT NOT NULL,
+    checkout_percentage DECIMAL(6, 3) NULL,
+    highest_checkout    INT NULL,
+    highest_target      INT NOT NULL,
+    checkout_darts      INT NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY statistics_121_leg_player (leg_id, player_id),
+    KEY statistics_121_player (player_id)
+);
+
+CREATE TABLE IF NOT EXISTS statistics_golf (
+    id           INT NOT NULL AUTO_INCREMENT,
+    leg_id       INT NOT NULL,
+    player_id    INT NOT NULL,
+    darts_thrown INT NOT NULL,
+    score        INT NOT NULL,
+    holes_played INT NOT NULL,
+    holes_in_one INT NOT NULL,
+    holes_missed INT NOT NULL,
+    hit_rate     DECIMAL(6, 3) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY statistics_golf_leg_player (leg_id, player_id),
+    KEY statistics_golf_player (player_id)
+);
+
+CREATE TABLE IF NOT EXISTS statistics_baseball (
+    id              INT NOT NULL AUTO_INCREMENT,
+    leg_id          INT NOT NULL,
+    player_id       INT NOT NULL,
+    darts_thrown    INT NOT NULL,
+    score           INT NOT NULL,
+    innings_played  INT NOT NULL,
+    highest_inning  INT NOT NULL,
+    perfect_innings INT NOT NULL,
+    hit_rate        DECIMAL(6, 3) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY statistics_baseball_leg_player (leg_id, player_id),
+    KEY statistics_baseball_player (player_id)
+);
+
+-- Daily statistics per office, updated when legs are finished, undone or deleted. Office 0 holds matches without an office
+CREATE TABLE IF NOT EXISTS statistics_rollup (
+    office_id       INT NOT NULL,
+    day             DATE NOT NULL,
+    matches         INT NOT NULL DEFAULT 0,
+    legs            INT NOT NULL DEFAULT 0,
+    visits          INT NOT NULL DEFAULT 0,
+    darts           INT NOT NULL DEFAULT 0,
+    points          BIGINT NOT NULL DEFAULT 0,
+    points_busted   BIGINT NOT NULL DEFAULT 0,
+    score_180s      INT NOT NULL DEFAULT 0,
+    score_bullseyes INT NOT NULL DEFAULT 0,
+    fish_n_chips    INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (office_id, day),
+    KEY statistics_rollup_day (day)
+);
+
+CREATE TABLE IF NOT EXISTS statistics_rollup_checkout (
+    leg_id                 INT NOT NULL,
+    office_id              INT NOT NULL,
+    day                    DATE NOT NULL,
+    player_id              INT NOT NULL,
+    is_practice            TINYINT(1) NOT NULL DEFAULT 0,
+    checkout               INT NOT NULL,
+    first_dart             INT NULL,
+    first_dart_multiplier  INT NOT NULL DEFAULT 1,
+    second_dart            INT NULL,
+    second_dart_multiplier INT NOT NULL DEFAULT 1,
+    third_dart             INT NULL,
+    third_dart_multiplier  INT NOT NULL DEFAULT 1,
+    PRIMARY KEY (leg_id),
+    KEY statistics_rollup_checkout_day (day),
+    KEY statistics_rollup_checkout_office (office_id, checkout)
+);
+
+-- Every debt and payback between players, in addition to the balances in owes
+CREATE TABLE IF NOT EXISTS owe_transaction (
+    id             INT NOT NULL AUTO_INCREMENT,
+    player_ower_id INT NOT NULL,
+    player_owee_id INT NOT NULL,
+    owe_type_id    INT NOT NULL,
+    amount         INT NOT NULL,
+    match_id       INT NULL,
+    is_payback     TINYINT(1) NOT NULL DEFAULT 0,
+    created_at     DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    KEY owe_transaction_ower (player_ower_id),
+    KEY owe_transaction_owee (player_owee_id),
+    KEY owe_transaction_created_at (created_at)
+);
+
+-- Matches waiting for a board at a venue
+CREATE TABLE IF NOT EXISTS venue_queue (
+    venue_id   INT NOT NULL,
+    match_id   INT NOT NULL,
+    position   INT NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (match_id),
+    KEY venue_queue_position (venue_id, position)
+);
+
+-- Badges defined by expressions instead of code, using badge ids from 100
+CREATE TABLE IF NOT EXISTS badge_rule (
+    badge_id       INT NOT NULL,
+    scope          VARCHAR(10) NOT NULL,
+    condition_expr TEXT NOT NULL,
+    value_expr     TEXT NULL,
+    levels         VARCHAR(255) NULL,
+    is_active      TINYINT(1) NOT NULL DEFAULT 1,
+    office_id      INT NULL,
+    created_at     DATETIME NOT NULL,
+    updated_at     DATETIME NOT NULL,
+    PRIMARY KEY (badge_id)
+);
+
+-- Weekly and monthly office reports, generated once per office and period
+CREATE TABLE IF NOT EXISTS report (
+    id         INT NOT NULL AUTO_INCREMENT,
+    office_id  INT NOT NULL,
+    period     VARCHAR(10) NOT NULL,
+    from_date  DATETIME NOT NULL,
+    to_date    DATETIME NOT NULL,
+    content    MEDIUMTEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY report_office_period (office_id, period, from_date)
+);
+
+-- Personal records of players, with every record set over time
+CREATE TABLE IF NOT EXISTS player_record (
+    id             INT NOT NULL AUTO_INCREMENT,
+    player_id      INT NOT NULL,
+    match_type_id  INT NOT NULL,
+    metric         VARCHAR(32) NOT NULL,
+    value          DECIMAL(10, 3) NOT NULL,
+    previous_value DECIMAL(10, 3) NULL,
+    match_id       INT NOT NULL,
+    leg_id         INT NOT NULL,
+    created_at     DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    KEY player_record_player (player_id, match_type_id, metric),
+    KEY player_record_leg (leg_id)
+);
+
+-- Badges for non-X01 legs
+INSERT IGNORE INTO badge (id, name, description, filename, hidden, secret, levels) VALUES
+    (49, 'White Horse', 'Score 9 marks in a single Cricket visit', 'white_horse.svg', 0, 0, NULL),
+    (50, 'Clean Sweep', 'Win a Cricket leg without your opponents scoring a point', 'clean_sweep.svg', 0, 0, NULL),
+    (51, 'Shootout Maximum', 'Score 180 in a single 9 Dart Shootout visit', 'shootout_maximum.svg', 0, 0, NULL),
+    (52, 'Clockwork', 'Win an Around the Clock leg without missing a single dart', 'clockwork.svg', 0, 0, NULL),
+    (53, 'Flawless', 'Win a Tic-Tac-Toe leg without your opponents closing a number', 'flawless.svg', 0, 0, NULL),
+    (54, 'Last One Standing', 'Win a Knockout leg without losing a life', 'last_one_standing.svg', 0, 0, NULL);